@@ -0,0 +1,30 @@
+-- +up
+CREATE TABLE `market_trades`
+(
+    `gid`            BIGINT UNSIGNED         NOT NULL AUTO_INCREMENT,
+
+    `id`             BIGINT UNSIGNED         NOT NULL,
+
+    `exchange`       VARCHAR(24)             NOT NULL DEFAULT '',
+
+    `symbol`         VARCHAR(20)             NOT NULL,
+
+    `price`          DECIMAL(16, 8) UNSIGNED NOT NULL,
+
+    `quantity`       DECIMAL(16, 8) UNSIGNED NOT NULL,
+
+    `quote_quantity` DECIMAL(16, 8) UNSIGNED NOT NULL,
+
+    `side`           VARCHAR(4)              NOT NULL DEFAULT '',
+
+    `is_buyer`       BOOLEAN                 NOT NULL DEFAULT FALSE,
+
+    `traded_at`      DATETIME(3)             NOT NULL,
+
+    PRIMARY KEY (`gid`),
+    UNIQUE KEY `id` (`exchange`, `symbol`, `id`),
+    INDEX `idx_market_trades_traded_at` (`exchange`, `symbol`, `traded_at`)
+);
+
+-- +down
+DROP TABLE IF EXISTS `market_trades`;
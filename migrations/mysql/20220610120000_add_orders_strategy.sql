@@ -0,0 +1,10 @@
+-- +up
+-- +begin
+ALTER TABLE `orders` ADD COLUMN `strategy` VARCHAR(32) NULL;
+-- +end
+
+-- +down
+
+-- +begin
+ALTER TABLE `orders` DROP COLUMN `strategy`;
+-- +end
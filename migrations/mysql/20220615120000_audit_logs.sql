@@ -0,0 +1,26 @@
+-- +up
+CREATE TABLE `audit_logs`
+(
+    `gid`       BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+
+    `actor`     VARCHAR(128)    NOT NULL,
+
+    `role`      VARCHAR(16)     NOT NULL DEFAULT '',
+
+    `command`   VARCHAR(64)     NOT NULL,
+
+    `arguments` TEXT            NOT NULL,
+
+    `outcome`   VARCHAR(16)     NOT NULL,
+
+    `error`     TEXT            NOT NULL,
+
+    `time`      DATETIME(3)     NOT NULL,
+
+    PRIMARY KEY (`gid`),
+    INDEX `idx_audit_logs_actor` (`actor`, `time`),
+    INDEX `idx_audit_logs_command` (`command`, `time`)
+);
+
+-- +down
+DROP TABLE IF EXISTS `audit_logs`;
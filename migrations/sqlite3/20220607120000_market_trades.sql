@@ -0,0 +1,27 @@
+-- +up
+-- +begin
+CREATE TABLE `market_trades`
+(
+    `gid`            INTEGER PRIMARY KEY AUTOINCREMENT,
+    `id`             INTEGER        NOT NULL,
+    `exchange`       VARCHAR(24)    NOT NULL DEFAULT '',
+    `symbol`         VARCHAR(20)    NOT NULL,
+    `price`          DECIMAL(16, 8) NOT NULL,
+    `quantity`       DECIMAL(16, 8) NOT NULL,
+    `quote_quantity` DECIMAL(16, 8) NOT NULL,
+    `side`           VARCHAR(4)     NOT NULL DEFAULT '',
+    `is_buyer`       BOOLEAN        NOT NULL DEFAULT FALSE,
+    `traded_at`      DATETIME(3)    NOT NULL
+);
+-- +end
+
+-- +begin
+CREATE UNIQUE INDEX `idx_market_trades_id` ON `market_trades` (`exchange`, `symbol`, `id`);
+-- +end
+
+-- +begin
+CREATE INDEX `idx_market_trades_traded_at` ON `market_trades` (`exchange`, `symbol`, `traded_at`);
+-- +end
+
+-- +down
+DROP TABLE IF EXISTS `market_trades`;
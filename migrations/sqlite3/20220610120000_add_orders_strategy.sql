@@ -0,0 +1,10 @@
+-- +up
+-- +begin
+ALTER TABLE `orders` ADD COLUMN `strategy` VARCHAR(32) NULL;
+-- +end
+
+-- +down
+
+-- +begin
+ALTER TABLE `orders` RENAME COLUMN `strategy` TO `strategy_deleted`;
+-- +end
@@ -0,0 +1,25 @@
+-- +up
+-- +begin
+CREATE TABLE `audit_logs`
+(
+    `gid`       INTEGER PRIMARY KEY AUTOINCREMENT,
+    `actor`     VARCHAR(128) NOT NULL,
+    `role`      VARCHAR(16)  NOT NULL DEFAULT '',
+    `command`   VARCHAR(64)  NOT NULL,
+    `arguments` TEXT         NOT NULL DEFAULT '',
+    `outcome`   VARCHAR(16)  NOT NULL,
+    `error`     TEXT         NOT NULL DEFAULT '',
+    `time`      DATETIME(3)  NOT NULL
+);
+-- +end
+
+-- +begin
+CREATE INDEX `idx_audit_logs_actor` ON `audit_logs` (`actor`, `time`);
+-- +end
+
+-- +begin
+CREATE INDEX `idx_audit_logs_command` ON `audit_logs` (`command`, `time`);
+-- +end
+
+-- +down
+DROP TABLE IF EXISTS `audit_logs`;
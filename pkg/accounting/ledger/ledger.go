@@ -0,0 +1,557 @@
+package ledger
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type EntryType string
+
+const (
+	EntryTypeTrade          EntryType = "trade"
+	EntryTypeTradingFee     EntryType = "tradingFee"
+	EntryTypeRealizedGain   EntryType = "realizedGain"
+	EntryTypeDeposit        EntryType = "deposit"
+	EntryTypeWithdraw       EntryType = "withdraw"
+	EntryTypeWithdrawFee    EntryType = "withdrawFee"
+	EntryTypeReward         EntryType = "reward"
+	EntryTypeMarginLoan     EntryType = "marginLoan"
+	EntryTypeMarginRepay    EntryType = "marginRepay"
+	EntryTypeMarginInterest EntryType = "marginInterest"
+)
+
+const (
+	AccountTransfers      = "Equity:Transfers"
+	AccountTradingFees    = "Expenses:Fees:Trading"
+	AccountWithdrawFees   = "Expenses:Fees:Withdraw"
+	AccountInterests      = "Expenses:Interests"
+	AccountRealizedLosses = "Expenses:RealizedLosses"
+	AccountRealizedGains  = "Income:RealizedGains"
+)
+
+func assetAccount(exchange types.ExchangeName, asset string) string {
+	return "Assets:" + exchange.String() + ":" + asset
+}
+
+func liabilityAccount(exchange types.ExchangeName, asset string) string {
+	return "Liabilities:" + exchange.String() + ":" + asset
+}
+
+func tradingAccount(exchange types.ExchangeName, symbol string) string {
+	return "Trading:" + exchange.String() + ":" + symbol
+}
+
+func rewardAccount(rewardType types.RewardType) string {
+	return "Income:Rewards:" + string(rewardType)
+}
+
+// Entry is a double-entry journal line, the amount of the asset is moved from the credit account to the debit account.
+type Entry struct {
+	Time      time.Time          `json:"time"`
+	Exchange  types.ExchangeName `json:"exchange"`
+	Type      EntryType          `json:"type"`
+	Debit     string             `json:"debit"`
+	Credit    string             `json:"credit"`
+	Asset     string             `json:"asset"`
+	Amount    fixedpoint.Value   `json:"amount"`
+	FiatValue fixedpoint.Value   `json:"fiatValue"`
+	Reference string             `json:"reference"`
+}
+
+type Entries []Entry
+
+func (entries Entries) CsvHeader() []string {
+	return []string{"time", "exchange", "type", "debit", "credit", "asset", "amount", "fiat_value", "reference"}
+}
+
+func (entries Entries) CsvRecords() [][]string {
+	var records [][]string
+	for _, e := range entries {
+		records = append(records, []string{
+			e.Time.Format(time.RFC3339),
+			e.Exchange.String(),
+			string(e.Type),
+			e.Debit,
+			e.Credit,
+			e.Asset,
+			e.Amount.String(),
+			e.FiatValue.String(),
+			e.Reference,
+		})
+	}
+	return records
+}
+
+// AssetSummary summarizes the ledger entries of an asset, the fiat values are valued at the entry time.
+type AssetSummary struct {
+	Asset string `json:"asset"`
+
+	Deposited fixedpoint.Value `json:"deposited"`
+	Withdrawn fixedpoint.Value `json:"withdrawn"`
+	Bought    fixedpoint.Value `json:"bought"`
+	Sold      fixedpoint.Value `json:"sold"`
+	Borrowed  fixedpoint.Value `json:"borrowed"`
+	Repaid    fixedpoint.Value `json:"repaid"`
+
+	Fees     fixedpoint.Value `json:"fees"`
+	FeesFiat fixedpoint.Value `json:"feesFiat"`
+
+	RewardIncome     fixedpoint.Value `json:"rewardIncome"`
+	RewardIncomeFiat fixedpoint.Value `json:"rewardIncomeFiat"`
+
+	InterestExpense     fixedpoint.Value `json:"interestExpense"`
+	InterestExpenseFiat fixedpoint.Value `json:"interestExpenseFiat"`
+
+	// RealizedGainFiat is the realized gain of the trades with the asset as the base asset
+	RealizedGainFiat fixedpoint.Value `json:"realizedGainFiat"`
+}
+
+type Summary []AssetSummary
+
+func (summary Summary) CsvHeader() []string {
+	return []string{"asset", "deposited", "withdrawn", "bought", "sold", "borrowed", "repaid", "fees", "fees_fiat", "reward_income", "reward_income_fiat", "interest_expense", "interest_expense_fiat", "realized_gain_fiat"}
+}
+
+func (summary Summary) CsvRecords() [][]string {
+	var records [][]string
+	for _, s := range summary {
+		records = append(records, []string{
+			s.Asset,
+			s.Deposited.String(),
+			s.Withdrawn.String(),
+			s.Bought.String(),
+			s.Sold.String(),
+			s.Borrowed.String(),
+			s.Repaid.String(),
+			s.Fees.String(),
+			s.FeesFiat.String(),
+			s.RewardIncome.String(),
+			s.RewardIncomeFiat.String(),
+			s.InterestExpense.String(),
+			s.InterestExpenseFiat.String(),
+			s.RealizedGainFiat.String(),
+		})
+	}
+	return records
+}
+
+// Total sums the fiat values of the summary
+func (summary Summary) Total() (total AssetSummary) {
+	total.Asset = "TOTAL"
+	for _, s := range summary {
+		total.FeesFiat = total.FeesFiat.Add(s.FeesFiat)
+		total.RewardIncomeFiat = total.RewardIncomeFiat.Add(s.RewardIncomeFiat)
+		total.InterestExpenseFiat = total.InterestExpenseFiat.Add(s.InterestExpenseFiat)
+		total.RealizedGainFiat = total.RealizedGainFiat.Add(s.RealizedGainFiat)
+	}
+	return total
+}
+
+type Report struct {
+	Since        time.Time `json:"since"`
+	Until        time.Time `json:"until"`
+	FiatCurrency string    `json:"fiatCurrency"`
+	Entries      Entries   `json:"entries"`
+	Summary      Summary   `json:"summary"`
+
+	// MissingPrices is the number of the entries that can not be valued in the fiat currency
+	MissingPrices int `json:"missingPrices"`
+}
+
+// Builder merges the trades, the transfers, the rewards and the margin records into a double-entry ledger
+// in the time range [Since, Until), the realized gains are calculated with the tax lots of the cost basis method.
+type Builder struct {
+	FiatCurrency string
+
+	// FiatPrice returns the price of the asset in the fiat currency
+	FiatPrice pnl.PriceFunc
+
+	Method pnl.CostBasisMethod
+
+	Since, Until time.Time
+
+	entries       Entries
+	summaries     map[string]*AssetSummary
+	missingPrices int
+}
+
+func NewBuilder(fiatCurrency string, fiatPrice pnl.PriceFunc, method pnl.CostBasisMethod, since, until time.Time) *Builder {
+	return &Builder{
+		FiatCurrency: fiatCurrency,
+		FiatPrice:    fiatPrice,
+		Method:       method,
+		Since:        since,
+		Until:        until,
+		summaries:    make(map[string]*AssetSummary),
+	}
+}
+
+func (b *Builder) inRange(t time.Time) bool {
+	return !t.Before(b.Since) && t.Before(b.Until)
+}
+
+func (b *Builder) summary(asset string) *AssetSummary {
+	s, ok := b.summaries[asset]
+	if !ok {
+		s = &AssetSummary{Asset: asset}
+		b.summaries[asset] = s
+	}
+	return s
+}
+
+// fiatValue returns the value of the amount of the asset in the fiat currency at the given time
+func (b *Builder) fiatValue(asset string, amount fixedpoint.Value, t time.Time) fixedpoint.Value {
+	if asset == b.FiatCurrency {
+		return amount
+	}
+
+	if b.FiatPrice != nil {
+		if price, ok := b.FiatPrice(asset, t); ok {
+			return amount.Mul(price)
+		}
+	}
+
+	log.Warnf("%s price in %s not found at %s, the fiat value is zero", asset, b.FiatCurrency, t.Format(time.RFC3339))
+	b.missingPrices++
+	return fixedpoint.Zero
+}
+
+func (b *Builder) add(e Entry) {
+	b.entries = append(b.entries, e)
+}
+
+// AddTrades adds the trades of the symbols in the markets, the trades before Since are used for the cost basis only.
+func (b *Builder) AddTrades(markets types.MarketMap, trades []types.Trade) error {
+	groups := make(map[types.ExchangeName]map[string][]types.Trade)
+	for _, trade := range trades {
+		if _, ok := groups[trade.Exchange]; !ok {
+			groups[trade.Exchange] = make(map[string][]types.Trade)
+		}
+		groups[trade.Exchange][trade.Symbol] = append(groups[trade.Exchange][trade.Symbol], trade)
+	}
+
+	for exchange, symbols := range groups {
+		for symbol, symbolTrades := range symbols {
+			market, ok := markets[symbol]
+			if !ok {
+				return fmt.Errorf("market %s not found", symbol)
+			}
+
+			if err := b.addSymbolTrades(exchange, market, types.SortTradesAscending(symbolTrades)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *Builder) addSymbolTrades(exchange types.ExchangeName, market types.Market, trades []types.Trade) error {
+	calculator := &pnl.LotCalculator{
+		Method:       b.Method,
+		Market:       market,
+		FiatCurrency: b.FiatCurrency,
+		FiatPrice:    b.FiatPrice,
+		FeePrice: func(currency string, t time.Time) (fixedpoint.Value, bool) {
+			if b.FiatPrice == nil {
+				return fixedpoint.Zero, false
+			}
+
+			feePrice, ok := b.FiatPrice(currency, t)
+			if !ok {
+				return fixedpoint.Zero, false
+			}
+
+			quotePrice, ok := b.FiatPrice(market.QuoteCurrency, t)
+			if !ok || quotePrice.IsZero() {
+				return fixedpoint.Zero, false
+			}
+
+			return feePrice.Div(quotePrice), true
+		},
+	}
+
+	lastPrice := fixedpoint.Zero
+	if len(trades) > 0 {
+		lastPrice = trades[len(trades)-1].Price
+	}
+
+	report, err := calculator.Calculate(market.Symbol, trades, lastPrice)
+	if err != nil {
+		return err
+	}
+
+	trading := tradingAccount(exchange, market.Symbol)
+	baseAccount := assetAccount(exchange, market.BaseCurrency)
+	quoteAccount := assetAccount(exchange, market.QuoteCurrency)
+
+	for _, trade := range trades {
+		t := trade.Time.Time()
+		if !b.inRange(t) {
+			continue
+		}
+
+		quoteQuantity := trade.QuoteQuantity
+		if quoteQuantity.IsZero() {
+			quoteQuantity = trade.Price.Mul(trade.Quantity)
+		}
+
+		fiatValue := b.fiatValue(market.QuoteCurrency, quoteQuantity, t)
+		reference := fmt.Sprintf("trade:%d", trade.ID)
+
+		if trade.IsBuyer {
+			b.add(Entry{Time: t, Exchange: exchange, Type: EntryTypeTrade, Debit: baseAccount, Credit: trading, Asset: market.BaseCurrency, Amount: trade.Quantity, FiatValue: fiatValue, Reference: reference})
+			b.add(Entry{Time: t, Exchange: exchange, Type: EntryTypeTrade, Debit: trading, Credit: quoteAccount, Asset: market.QuoteCurrency, Amount: quoteQuantity, FiatValue: fiatValue, Reference: reference})
+			b.summary(market.BaseCurrency).Bought = b.summary(market.BaseCurrency).Bought.Add(trade.Quantity)
+		} else {
+			b.add(Entry{Time: t, Exchange: exchange, Type: EntryTypeTrade, Debit: trading, Credit: baseAccount, Asset: market.BaseCurrency, Amount: trade.Quantity, FiatValue: fiatValue, Reference: reference})
+			b.add(Entry{Time: t, Exchange: exchange, Type: EntryTypeTrade, Debit: quoteAccount, Credit: trading, Asset: market.QuoteCurrency, Amount: quoteQuantity, FiatValue: fiatValue, Reference: reference})
+			b.summary(market.BaseCurrency).Sold = b.summary(market.BaseCurrency).Sold.Add(trade.Quantity)
+		}
+
+		if trade.Fee.Sign() > 0 && trade.FeeCurrency != "" {
+			feeFiat := b.fiatValue(trade.FeeCurrency, trade.Fee, t)
+			b.add(Entry{Time: t, Exchange: exchange, Type: EntryTypeTradingFee, Debit: AccountTradingFees, Credit: assetAccount(exchange, trade.FeeCurrency), Asset: trade.FeeCurrency, Amount: trade.Fee, FiatValue: feeFiat, Reference: reference})
+
+			s := b.summary(trade.FeeCurrency)
+			s.Fees = s.Fees.Add(trade.Fee)
+			s.FeesFiat = s.FeesFiat.Add(feeFiat)
+		}
+	}
+
+	for _, d := range report.Ledger {
+		if !b.inRange(d.CloseTime) || d.PnLFiat.IsZero() {
+			continue
+		}
+
+		e := Entry{
+			Time:      d.CloseTime,
+			Exchange:  exchange,
+			Type:      EntryTypeRealizedGain,
+			Asset:     b.FiatCurrency,
+			Amount:    d.PnLFiat.Abs(),
+			FiatValue: d.PnLFiat.Abs(),
+			Reference: fmt.Sprintf("lot:%d:%d", d.OpenTradeID, d.CloseTradeID),
+		}
+
+		if d.PnLFiat.Sign() > 0 {
+			e.Debit, e.Credit = trading, AccountRealizedGains
+		} else {
+			e.Debit, e.Credit = AccountRealizedLosses, trading
+		}
+
+		b.add(e)
+
+		s := b.summary(market.BaseCurrency)
+		s.RealizedGainFiat = s.RealizedGainFiat.Add(d.PnLFiat)
+	}
+
+	return nil
+}
+
+func (b *Builder) AddDeposits(deposits []types.Deposit) {
+	for _, d := range deposits {
+		t := d.Time.Time()
+		if !b.inRange(t) {
+			continue
+		}
+
+		b.add(Entry{
+			Time:      t,
+			Exchange:  d.Exchange,
+			Type:      EntryTypeDeposit,
+			Debit:     assetAccount(d.Exchange, d.Asset),
+			Credit:    AccountTransfers,
+			Asset:     d.Asset,
+			Amount:    d.Amount,
+			FiatValue: b.fiatValue(d.Asset, d.Amount, t),
+			Reference: "deposit:" + d.TransactionID,
+		})
+
+		s := b.summary(d.Asset)
+		s.Deposited = s.Deposited.Add(d.Amount)
+	}
+}
+
+func (b *Builder) AddWithdraws(withdraws []types.Withdraw) {
+	for _, w := range withdraws {
+		t := w.ApplyTime.Time()
+		if !b.inRange(t) {
+			continue
+		}
+
+		reference := "withdraw:" + w.TransactionID
+		b.add(Entry{
+			Time:      t,
+			Exchange:  w.Exchange,
+			Type:      EntryTypeWithdraw,
+			Debit:     AccountTransfers,
+			Credit:    assetAccount(w.Exchange, w.Asset),
+			Asset:     w.Asset,
+			Amount:    w.Amount,
+			FiatValue: b.fiatValue(w.Asset, w.Amount, t),
+			Reference: reference,
+		})
+
+		s := b.summary(w.Asset)
+		s.Withdrawn = s.Withdrawn.Add(w.Amount)
+
+		if w.TransactionFee.Sign() > 0 {
+			feeCurrency := w.TransactionFeeCurrency
+			if feeCurrency == "" {
+				feeCurrency = w.Asset
+			}
+
+			feeFiat := b.fiatValue(feeCurrency, w.TransactionFee, t)
+			b.add(Entry{
+				Time:      t,
+				Exchange:  w.Exchange,
+				Type:      EntryTypeWithdrawFee,
+				Debit:     AccountWithdrawFees,
+				Credit:    assetAccount(w.Exchange, feeCurrency),
+				Asset:     feeCurrency,
+				Amount:    w.TransactionFee,
+				FiatValue: feeFiat,
+				Reference: reference,
+			})
+
+			fs := b.summary(feeCurrency)
+			fs.Fees = fs.Fees.Add(w.TransactionFee)
+			fs.FeesFiat = fs.FeesFiat.Add(feeFiat)
+		}
+	}
+}
+
+func (b *Builder) AddRewards(rewards []types.Reward) {
+	for _, r := range rewards {
+		t := r.CreatedAt.Time()
+		if !b.inRange(t) {
+			continue
+		}
+
+		fiatValue := b.fiatValue(r.Currency, r.Quantity, t)
+		b.add(Entry{
+			Time:      t,
+			Exchange:  r.Exchange,
+			Type:      EntryTypeReward,
+			Debit:     assetAccount(r.Exchange, r.Currency),
+			Credit:    rewardAccount(r.Type),
+			Asset:     r.Currency,
+			Amount:    r.Quantity,
+			FiatValue: fiatValue,
+			Reference: "reward:" + r.UUID,
+		})
+
+		s := b.summary(r.Currency)
+		s.RewardIncome = s.RewardIncome.Add(r.Quantity)
+		s.RewardIncomeFiat = s.RewardIncomeFiat.Add(fiatValue)
+	}
+}
+
+func (b *Builder) AddMarginLoans(loans []types.MarginLoan) {
+	for _, l := range loans {
+		t := l.Time.Time()
+		if !b.inRange(t) {
+			continue
+		}
+
+		b.add(Entry{
+			Time:      t,
+			Exchange:  l.Exchange,
+			Type:      EntryTypeMarginLoan,
+			Debit:     assetAccount(l.Exchange, l.Asset),
+			Credit:    liabilityAccount(l.Exchange, l.Asset),
+			Asset:     l.Asset,
+			Amount:    l.Principle,
+			FiatValue: b.fiatValue(l.Asset, l.Principle, t),
+			Reference: fmt.Sprintf("loan:%d", l.TransactionID),
+		})
+
+		s := b.summary(l.Asset)
+		s.Borrowed = s.Borrowed.Add(l.Principle)
+	}
+}
+
+func (b *Builder) AddMarginRepays(repays []types.MarginRepay) {
+	for _, r := range repays {
+		t := r.Time.Time()
+		if !b.inRange(t) {
+			continue
+		}
+
+		b.add(Entry{
+			Time:      t,
+			Exchange:  r.Exchange,
+			Type:      EntryTypeMarginRepay,
+			Debit:     liabilityAccount(r.Exchange, r.Asset),
+			Credit:    assetAccount(r.Exchange, r.Asset),
+			Asset:     r.Asset,
+			Amount:    r.Principle,
+			FiatValue: b.fiatValue(r.Asset, r.Principle, t),
+			Reference: fmt.Sprintf("repay:%d", r.TransactionID),
+		})
+
+		s := b.summary(r.Asset)
+		s.Repaid = s.Repaid.Add(r.Principle)
+	}
+}
+
+// AddMarginInterests adds the interests, which are accrued to the liabilities
+func (b *Builder) AddMarginInterests(interests []types.MarginInterest) {
+	for _, i := range interests {
+		t := i.Time.Time()
+		if !b.inRange(t) {
+			continue
+		}
+
+		fiatValue := b.fiatValue(i.Asset, i.Interest, t)
+		b.add(Entry{
+			Time:      t,
+			Exchange:  i.Exchange,
+			Type:      EntryTypeMarginInterest,
+			Debit:     AccountInterests,
+			Credit:    liabilityAccount(i.Exchange, i.Asset),
+			Asset:     i.Asset,
+			Amount:    i.Interest,
+			FiatValue: fiatValue,
+			Reference: "interest:" + i.Asset + i.IsolatedSymbol,
+		})
+
+		s := b.summary(i.Asset)
+		s.InterestExpense = s.InterestExpense.Add(i.Interest)
+		s.InterestExpenseFiat = s.InterestExpenseFiat.Add(fiatValue)
+	}
+}
+
+// Build returns the report with the entries sorted by time and the summary sorted by asset
+func (b *Builder) Build() *Report {
+	entries := make(Entries, len(b.entries))
+	copy(entries, b.entries)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+
+	var summary Summary
+	for _, s := range b.summaries {
+		summary = append(summary, *s)
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		return summary[i].Asset < summary[j].Asset
+	})
+
+	return &Report{
+		Since:         b.Since,
+		Until:         b.Until,
+		FiatCurrency:  b.FiatCurrency,
+		Entries:       entries,
+		Summary:       summary,
+		MissingPrices: b.missingPrices,
+	}
+}
@@ -0,0 +1,123 @@
+package ledger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ledgerTestMarkets = types.MarketMap{
+	"BTCUSDT": types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+}
+
+func newLedgerTestTrade(id uint64, side types.SideType, quantity, price, fee string, t time.Time) types.Trade {
+	return types.Trade{
+		ID:          id,
+		Exchange:    types.ExchangeBinance,
+		Symbol:      "BTCUSDT",
+		Side:        side,
+		IsBuyer:     side == types.SideTypeBuy,
+		Quantity:    fixedpoint.MustNewFromString(quantity),
+		Price:       fixedpoint.MustNewFromString(price),
+		Fee:         fixedpoint.MustNewFromString(fee),
+		FeeCurrency: "USDT",
+		Time:        types.Time(t),
+	}
+}
+
+func ledgerTestPrice(currency string, t time.Time) (fixedpoint.Value, bool) {
+	switch currency {
+	case "USDT":
+		return fixedpoint.NewFromInt(30), true
+	case "BTC":
+		return fixedpoint.NewFromInt(600000), true
+	}
+	return fixedpoint.Zero, false
+}
+
+func TestBuilder(t *testing.T) {
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := since.AddDate(1, 0, 0)
+
+	b := NewBuilder("TWD", ledgerTestPrice, pnl.CostBasisMethodFIFO, since, until)
+
+	// the trade of the previous year is used for the cost basis only
+	err := b.AddTrades(ledgerTestMarkets, []types.Trade{
+		newLedgerTestTrade(1, types.SideTypeBuy, "1", "100", "0", since.AddDate(0, -1, 0)),
+		newLedgerTestTrade(2, types.SideTypeSell, "1", "200", "1", since.Add(time.Hour)),
+	})
+	assert.NoError(t, err)
+
+	b.AddDeposits([]types.Deposit{
+		{Exchange: types.ExchangeBinance, Asset: "USDT", Amount: fixedpoint.NewFromInt(1000), Time: types.Time(since.Add(-time.Hour)), TransactionID: "d0"},
+		{Exchange: types.ExchangeBinance, Asset: "USDT", Amount: fixedpoint.NewFromInt(500), Time: types.Time(since.Add(2 * time.Hour)), TransactionID: "d1"},
+	})
+
+	b.AddWithdraws([]types.Withdraw{
+		{Exchange: types.ExchangeBinance, Asset: "BTC", Amount: fixedpoint.NewFromFloat(0.1), TransactionFee: fixedpoint.NewFromFloat(0.0005), ApplyTime: types.Time(since.Add(3 * time.Hour)), TransactionID: "w1"},
+	})
+
+	b.AddRewards([]types.Reward{
+		{Exchange: types.ExchangeBinance, UUID: "r1", Type: types.RewardCommission, Currency: "DOGE", Quantity: fixedpoint.NewFromInt(10), CreatedAt: types.Time(since.Add(4 * time.Hour))},
+	})
+
+	b.AddMarginInterests([]types.MarginInterest{
+		{Exchange: types.ExchangeBinance, Asset: "USDT", Interest: fixedpoint.NewFromInt(2), Time: types.Time(since.Add(5 * time.Hour))},
+	})
+
+	report := b.Build()
+	assert.Equal(t, 1, report.MissingPrices)
+
+	var entryTypes []EntryType
+	for _, e := range report.Entries {
+		entryTypes = append(entryTypes, e.Type)
+	}
+	assert.Equal(t, []EntryType{
+		EntryTypeTrade, EntryTypeTrade, EntryTypeTradingFee, EntryTypeRealizedGain,
+		EntryTypeDeposit,
+		EntryTypeWithdraw, EntryTypeWithdrawFee,
+		EntryTypeReward,
+		EntryTypeMarginInterest,
+	}, entryTypes)
+
+	sell := report.Entries[0]
+	assert.Equal(t, "Trading:binance:BTCUSDT", sell.Debit)
+	assert.Equal(t, "Assets:binance:BTC", sell.Credit)
+	assert.Equal(t, "6000", sell.FiatValue.String())
+
+	// cost basis 100 * 30, proceeds (200 - 1) * 30
+	gain := report.Entries[3]
+	assert.Equal(t, AccountRealizedGains, gain.Credit)
+	assert.Equal(t, "2970", gain.Amount.String())
+
+	assert.Equal(t, "Income:Rewards:commission", report.Entries[7].Credit)
+	assert.Equal(t, "0", report.Entries[7].FiatValue.String())
+
+	summaries := make(map[string]AssetSummary)
+	for _, s := range report.Summary {
+		summaries[s.Asset] = s
+	}
+
+	assert.Equal(t, "1", summaries["BTC"].Sold.String())
+	assert.Equal(t, "0.1", summaries["BTC"].Withdrawn.String())
+	assert.Equal(t, "300", summaries["BTC"].FeesFiat.String())
+	assert.Equal(t, "2970", summaries["BTC"].RealizedGainFiat.String())
+	assert.Equal(t, "500", summaries["USDT"].Deposited.String())
+	assert.Equal(t, "1", summaries["USDT"].Fees.String())
+	assert.Equal(t, "60", summaries["USDT"].InterestExpenseFiat.String())
+	assert.Equal(t, "10", summaries["DOGE"].RewardIncome.String())
+
+	total := report.Summary.Total()
+	assert.Equal(t, "330", total.FeesFiat.String())
+
+	records := report.Summary.CsvRecords()
+	for _, record := range records {
+		assert.Len(t, record, len(report.Summary.CsvHeader()))
+	}
+	assert.Len(t, report.Entries.CsvRecords(), len(report.Entries))
+}
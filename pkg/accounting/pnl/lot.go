@@ -0,0 +1,432 @@
+package pnl
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type CostBasisMethod string
+
+const (
+	CostBasisMethodAverageCost CostBasisMethod = "avg"
+
+	// CostBasisMethodFIFO closes the oldest lots first
+	CostBasisMethodFIFO CostBasisMethod = "fifo"
+
+	// CostBasisMethodLIFO closes the newest lots first
+	CostBasisMethodLIFO CostBasisMethod = "lifo"
+
+	// CostBasisMethodHIFO closes the lots with the highest cost first (the lowest price first for the short lots)
+	CostBasisMethodHIFO CostBasisMethod = "hifo"
+
+	// CostBasisMethodSpecific closes the lots specified by the closing trade, the rest are closed in FIFO
+	CostBasisMethodSpecific CostBasisMethod = "specific"
+)
+
+var SupportedCostBasisMethods = []CostBasisMethod{
+	CostBasisMethodAverageCost,
+	CostBasisMethodFIFO,
+	CostBasisMethodLIFO,
+	CostBasisMethodHIFO,
+	CostBasisMethodSpecific,
+}
+
+func (m CostBasisMethod) IsSupported() bool {
+	for _, method := range SupportedCostBasisMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// PriceFunc returns the price of the currency in the target currency at the given time
+type PriceFunc func(currency string, t time.Time) (fixedpoint.Value, bool)
+
+// Lot is an open tax lot opened by a trade.
+// For the long lots, Value is the cost basis including the fee,
+// for the short lots, Value is the proceeds excluding the fee.
+type Lot struct {
+	TradeID   uint64             `json:"tradeID"`
+	Exchange  types.ExchangeName `json:"exchange"`
+	Symbol    string             `json:"symbol"`
+	Side      types.SideType     `json:"side"`
+	Time      time.Time          `json:"time"`
+	Quantity  fixedpoint.Value   `json:"quantity"`
+	Value     fixedpoint.Value   `json:"value"`
+	FiatValue fixedpoint.Value   `json:"fiatValue"`
+}
+
+// UnitPrice returns the value per unit of the lot
+func (lot *Lot) UnitPrice() fixedpoint.Value {
+	if lot.Quantity.IsZero() {
+		return fixedpoint.Zero
+	}
+	return lot.Value.Div(lot.Quantity)
+}
+
+// take removes the quantity from the lot and returns the value and the fiat value of the quantity
+func (lot *Lot) take(quantity fixedpoint.Value) (value, fiatValue fixedpoint.Value) {
+	if quantity.Compare(lot.Quantity) >= 0 {
+		value, fiatValue = lot.Value, lot.FiatValue
+		lot.Quantity, lot.Value, lot.FiatValue = fixedpoint.Zero, fixedpoint.Zero, fixedpoint.Zero
+		return value, fiatValue
+	}
+
+	value = lot.Value.Mul(quantity).Div(lot.Quantity)
+	fiatValue = lot.FiatValue.Mul(quantity).Div(lot.Quantity)
+	lot.Quantity = lot.Quantity.Sub(quantity)
+	lot.Value = lot.Value.Sub(value)
+	lot.FiatValue = lot.FiatValue.Sub(fiatValue)
+	return value, fiatValue
+}
+
+// LotDisposal is a closed part of a lot, which is the entry of the lot ledger
+type LotDisposal struct {
+	Exchange      types.ExchangeName `json:"exchange"`
+	Symbol        string             `json:"symbol"`
+	Side          types.SideType     `json:"side"`
+	Quantity      fixedpoint.Value   `json:"quantity"`
+	OpenTradeID   uint64             `json:"openTradeID"`
+	OpenTime      time.Time          `json:"openTime"`
+	CloseTradeID  uint64             `json:"closeTradeID"`
+	CloseTime     time.Time          `json:"closeTime"`
+	CostBasis     fixedpoint.Value   `json:"costBasis"`
+	Proceeds      fixedpoint.Value   `json:"proceeds"`
+	PnL           fixedpoint.Value   `json:"pnl"`
+	CostBasisFiat fixedpoint.Value   `json:"costBasisFiat"`
+	ProceedsFiat  fixedpoint.Value   `json:"proceedsFiat"`
+	PnLFiat       fixedpoint.Value   `json:"pnlFiat"`
+}
+
+// HoldingPeriod returns the duration between the opening trade and the closing trade
+func (d LotDisposal) HoldingPeriod() time.Duration {
+	return d.CloseTime.Sub(d.OpenTime)
+}
+
+type LotLedger []LotDisposal
+
+func (ledger LotLedger) CsvHeader() []string {
+	return []string{"exchange", "symbol", "side", "quantity", "open_trade_id", "open_time", "close_trade_id", "close_time", "holding_days", "cost_basis", "proceeds", "pnl", "cost_basis_fiat", "proceeds_fiat", "pnl_fiat"}
+}
+
+func (ledger LotLedger) CsvRecords() [][]string {
+	var records [][]string
+	for _, d := range ledger {
+		records = append(records, []string{
+			d.Exchange.String(),
+			d.Symbol,
+			d.Side.String(),
+			d.Quantity.String(),
+			fmt.Sprintf("%d", d.OpenTradeID),
+			d.OpenTime.Format(time.RFC3339),
+			fmt.Sprintf("%d", d.CloseTradeID),
+			d.CloseTime.Format(time.RFC3339),
+			fmt.Sprintf("%d", int(d.HoldingPeriod().Hours()/24)),
+			d.CostBasis.String(),
+			d.Proceeds.String(),
+			d.PnL.String(),
+			d.CostBasisFiat.String(),
+			d.ProceedsFiat.String(),
+			d.PnLFiat.String(),
+		})
+	}
+	return records
+}
+
+// LotCalculator calculates the realized PnL by matching the closing trades to the open lots with the cost basis method.
+// The fees are added to the cost basis of the opening trades and deducted from the proceeds of the closing trades,
+// the fees not in the quote currency are converted by FeePrice, the base currency fees are valued at the trade price.
+// The base currency fee of a buy trade is deducted from the received quantity instead, so the lot holds the quantity actually received,
+// and the base currency fee of a sell trade is added to the disposed quantity, so the lots are reduced by the quantity actually paid.
+type LotCalculator struct {
+	Method CostBasisMethod
+	Market types.Market
+
+	// SpecificLots maps the closing trade ID to the opening trade IDs for the specific method
+	SpecificLots map[uint64][]uint64
+
+	// FeePrice returns the price of the fee currency in the quote currency
+	FeePrice PriceFunc
+
+	// FiatCurrency is the currency of the fiat values, the quote values are converted by FiatPrice at the trade time.
+	// The fiat values are the quote values if it's empty.
+	FiatCurrency string
+	FiatPrice    PriceFunc
+}
+
+func (c *LotCalculator) Calculate(symbol string, trades []types.Trade, currentPrice fixedpoint.Value) (*LotPnLReport, error) {
+	report := &LotPnLReport{
+		Symbol:       symbol,
+		Market:       c.Market,
+		Method:       c.Method,
+		FiatCurrency: c.FiatCurrency,
+		LastPrice:    currentPrice,
+		CurrencyFees: map[string]fixedpoint.Value{},
+	}
+
+	if report.FiatCurrency == "" {
+		report.FiatCurrency = c.Market.QuoteCurrency
+	}
+
+	var lots []*Lot
+	var tradeIDs = map[uint64]struct{}{}
+
+	for _, trade := range trades {
+		if trade.Symbol != symbol {
+			continue
+		}
+
+		if _, exists := tradeIDs[trade.ID]; exists {
+			log.Warnf("duplicated trade: %+v", trade)
+			continue
+		}
+		tradeIDs[trade.ID] = struct{}{}
+
+		fiatRate, err := c.fiatRate(trade.Time.Time())
+		if err != nil {
+			return nil, err
+		}
+
+		value := trade.QuoteQuantity
+		if value.IsZero() {
+			value = trade.Price.Mul(trade.Quantity)
+		}
+
+		// the base currency fee is paid from the base asset, so it's already in the value of the trade:
+		// the fee of the buy trade is deducted from the received quantity,
+		// and the fee of the sell trade is added to the disposed quantity.
+		fee := c.feeInQuote(trade)
+		tradeQuantity := trade.Quantity
+		if trade.FeeCurrency == c.Market.BaseCurrency && trade.FeeCurrency != "" {
+			if trade.IsBuyer {
+				tradeQuantity = tradeQuantity.Sub(trade.Fee)
+			} else {
+				tradeQuantity = tradeQuantity.Add(trade.Fee)
+			}
+			fee = fixedpoint.Zero
+		}
+
+		if tradeQuantity.Sign() <= 0 {
+			log.Warnf("the fee of trade %d is not less than the quantity, the trade is ignored", trade.ID)
+			continue
+		}
+
+		// only the processed trades are counted
+		if report.NumTrades == 0 {
+			report.StartTime = trade.Time.Time()
+		}
+		report.NumTrades++
+		report.CurrencyFees[trade.FeeCurrency] = report.CurrencyFees[trade.FeeCurrency].Add(trade.Fee)
+		report.Fee = report.Fee.Add(c.feeInQuote(trade))
+
+		if trade.IsBuyer {
+			report.BuyVolume = report.BuyVolume.Add(trade.Quantity)
+		} else {
+			report.SellVolume = report.SellVolume.Add(trade.Quantity)
+		}
+
+		// close the lots of the opposite side
+		remaining := tradeQuantity
+		for _, lot := range c.matchLots(lots, trade) {
+			if remaining.IsZero() {
+				break
+			}
+
+			quantity := fixedpoint.Min(lot.Quantity, remaining)
+			remaining = remaining.Sub(quantity)
+
+			closeValue := value.Mul(quantity).Div(tradeQuantity)
+			closeFee := fee.Mul(quantity).Div(tradeQuantity)
+			lotValue, lotFiatValue := lot.take(quantity)
+
+			d := LotDisposal{
+				Exchange:     trade.Exchange,
+				Symbol:       symbol,
+				Side:         lot.Side,
+				Quantity:     quantity,
+				OpenTradeID:  lot.TradeID,
+				OpenTime:     lot.Time,
+				CloseTradeID: trade.ID,
+				CloseTime:    trade.Time.Time(),
+			}
+
+			if lot.Side == types.SideTypeBuy {
+				d.CostBasis, d.CostBasisFiat = lotValue, lotFiatValue
+				d.Proceeds = closeValue.Sub(closeFee)
+				d.ProceedsFiat = d.Proceeds.Mul(fiatRate)
+			} else {
+				d.Proceeds, d.ProceedsFiat = lotValue, lotFiatValue
+				d.CostBasis = closeValue.Add(closeFee)
+				d.CostBasisFiat = d.CostBasis.Mul(fiatRate)
+			}
+
+			d.PnL = d.Proceeds.Sub(d.CostBasis)
+			d.PnLFiat = d.ProceedsFiat.Sub(d.CostBasisFiat)
+
+			report.Ledger = append(report.Ledger, d)
+			report.Profit = report.Profit.Add(d.PnL)
+			report.FiatProfit = report.FiatProfit.Add(d.PnLFiat)
+			if d.PnL.Sign() > 0 {
+				report.GrossProfit = report.GrossProfit.Add(d.PnL)
+			} else {
+				report.GrossLoss = report.GrossLoss.Add(d.PnL)
+			}
+		}
+
+		lots = removeClosedLots(lots)
+
+		// open a new lot with the remaining quantity
+		if remaining.Sign() > 0 {
+			lot := &Lot{
+				TradeID:  trade.ID,
+				Exchange: trade.Exchange,
+				Symbol:   symbol,
+				Time:     trade.Time.Time(),
+				Quantity: remaining,
+			}
+
+			if trade.IsBuyer {
+				lot.Side = types.SideTypeBuy
+				lot.Value = value.Add(fee).Mul(remaining).Div(tradeQuantity)
+			} else {
+				lot.Side = types.SideTypeSell
+				lot.Value = value.Sub(fee).Mul(remaining).Div(tradeQuantity)
+			}
+
+			lot.FiatValue = lot.Value.Mul(fiatRate)
+			lots = append(lots, lot)
+		}
+	}
+
+	for _, lot := range lots {
+		report.OpenLots = append(report.OpenLots, *lot)
+
+		marketValue := lot.Quantity.Mul(currentPrice)
+		if lot.Side == types.SideTypeBuy {
+			report.BaseAssetPosition = report.BaseAssetPosition.Add(lot.Quantity)
+			report.UnrealizedProfit = report.UnrealizedProfit.Add(marketValue.Sub(lot.Value))
+		} else {
+			report.BaseAssetPosition = report.BaseAssetPosition.Sub(lot.Quantity)
+			report.UnrealizedProfit = report.UnrealizedProfit.Add(lot.Value.Sub(marketValue))
+		}
+	}
+
+	return report, nil
+}
+
+// matchLots returns the open lots of the opposite side in the closing order of the method
+func (c *LotCalculator) matchLots(lots []*Lot, trade types.Trade) []*Lot {
+	var candidates []*Lot
+	for _, lot := range lots {
+		if trade.IsBuyer == (lot.Side == types.SideTypeSell) {
+			candidates = append(candidates, lot)
+		}
+	}
+
+	// the lots are appended in the time order
+	switch c.Method {
+	case CostBasisMethodLIFO:
+		for i, j := 0, len(candidates)-1; i < j; i, j = i+1, j-1 {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		}
+
+	case CostBasisMethodHIFO:
+		sort.SliceStable(candidates, func(i, j int) bool {
+			a, b := candidates[i].UnitPrice(), candidates[j].UnitPrice()
+			if candidates[i].Side == types.SideTypeBuy {
+				return a.Compare(b) > 0
+			}
+			return a.Compare(b) < 0
+		})
+
+	case CostBasisMethodSpecific:
+		ids, ok := c.SpecificLots[trade.ID]
+		if !ok {
+			break
+		}
+
+		var specific, rest []*Lot
+		for _, id := range ids {
+			for _, lot := range candidates {
+				if lot.TradeID == id {
+					specific = append(specific, lot)
+				}
+			}
+		}
+
+		for _, lot := range candidates {
+			if !containsLot(specific, lot) {
+				rest = append(rest, lot)
+			}
+		}
+
+		candidates = append(specific, rest...)
+	}
+
+	return candidates
+}
+
+func containsLot(lots []*Lot, lot *Lot) bool {
+	for _, l := range lots {
+		if l == lot {
+			return true
+		}
+	}
+	return false
+}
+
+func removeClosedLots(lots []*Lot) []*Lot {
+	var open []*Lot
+	for _, lot := range lots {
+		if lot.Quantity.Sign() > 0 {
+			open = append(open, lot)
+		}
+	}
+	return open
+}
+
+// feeInQuote returns the fee of the trade in the quote currency
+func (c *LotCalculator) feeInQuote(trade types.Trade) fixedpoint.Value {
+	switch trade.FeeCurrency {
+	case "", c.Market.QuoteCurrency:
+		return trade.Fee
+
+	case c.Market.BaseCurrency:
+		return trade.Fee.Mul(trade.Price)
+	}
+
+	if trade.Fee.IsZero() {
+		return fixedpoint.Zero
+	}
+
+	if c.FeePrice != nil {
+		if price, ok := c.FeePrice(trade.FeeCurrency, trade.Time.Time()); ok {
+			return trade.Fee.Mul(price)
+		}
+	}
+
+	log.Warnf("can not convert the fee %s %s of trade %d into %s, the fee is ignored", trade.Fee.String(), trade.FeeCurrency, trade.ID, c.Market.QuoteCurrency)
+	return fixedpoint.Zero
+}
+
+// fiatRate returns the price of the quote currency in the fiat currency
+func (c *LotCalculator) fiatRate(t time.Time) (fixedpoint.Value, error) {
+	if c.FiatCurrency == "" || c.FiatCurrency == c.Market.QuoteCurrency {
+		return fixedpoint.One, nil
+	}
+
+	if c.FiatPrice != nil {
+		if price, ok := c.FiatPrice(c.Market.QuoteCurrency, t); ok {
+			return price, nil
+		}
+	}
+
+	return fixedpoint.Zero, fmt.Errorf("%s price in %s not found at %s", c.Market.QuoteCurrency, c.FiatCurrency, t.Format(time.RFC3339))
+}
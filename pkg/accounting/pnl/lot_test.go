@@ -0,0 +1,244 @@
+package pnl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var lotTestMarket = types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}
+
+func newLotTestTrade(id uint64, side types.SideType, quantity, price, fee, feeCurrency string, t time.Time) types.Trade {
+	return types.Trade{
+		ID:          id,
+		Exchange:    types.ExchangeBinance,
+		Symbol:      "BTCUSDT",
+		Side:        side,
+		IsBuyer:     side == types.SideTypeBuy,
+		Quantity:    fixedpoint.MustNewFromString(quantity),
+		Price:       fixedpoint.MustNewFromString(price),
+		Fee:         fixedpoint.MustNewFromString(fee),
+		FeeCurrency: feeCurrency,
+		Time:        types.Time(t),
+	}
+}
+
+func newLotTestTrades() []types.Trade {
+	t0 := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []types.Trade{
+		newLotTestTrade(1, types.SideTypeBuy, "1", "100", "0.1", "USDT", t0),
+		newLotTestTrade(2, types.SideTypeBuy, "1", "200", "0", "USDT", t0.Add(24*time.Hour)),
+		newLotTestTrade(3, types.SideTypeSell, "1.5", "300", "0.45", "USDT", t0.Add(48*time.Hour)),
+	}
+}
+
+func TestLotCalculator_FIFO(t *testing.T) {
+	c := &LotCalculator{Method: CostBasisMethodFIFO, Market: lotTestMarket}
+	report, err := c.Calculate("BTCUSDT", newLotTestTrades(), fixedpoint.NewFromInt(250))
+	assert.NoError(t, err)
+
+	if assert.Len(t, report.Ledger, 2) {
+		assert.Equal(t, uint64(1), report.Ledger[0].OpenTradeID)
+		assert.Equal(t, "100.1", report.Ledger[0].CostBasis.String())
+		assert.Equal(t, "299.7", report.Ledger[0].Proceeds.String())
+		assert.Equal(t, "199.6", report.Ledger[0].PnL.String())
+
+		assert.Equal(t, uint64(2), report.Ledger[1].OpenTradeID)
+		assert.Equal(t, "0.5", report.Ledger[1].Quantity.String())
+		assert.Equal(t, "49.85", report.Ledger[1].PnL.String())
+	}
+
+	assert.Equal(t, "249.45", report.Profit.String())
+	assert.Equal(t, "0.55", report.Fee.String())
+	assert.Equal(t, "0.5", report.BaseAssetPosition.String())
+	assert.Equal(t, "25", report.UnrealizedProfit.String())
+
+	if assert.Len(t, report.OpenLots, 1) {
+		assert.Equal(t, uint64(2), report.OpenLots[0].TradeID)
+	}
+}
+
+func TestLotCalculator_LIFO(t *testing.T) {
+	c := &LotCalculator{Method: CostBasisMethodLIFO, Market: lotTestMarket}
+	report, err := c.Calculate("BTCUSDT", newLotTestTrades(), fixedpoint.NewFromInt(250))
+	assert.NoError(t, err)
+
+	if assert.Len(t, report.Ledger, 2) {
+		assert.Equal(t, uint64(2), report.Ledger[0].OpenTradeID)
+		assert.Equal(t, "99.7", report.Ledger[0].PnL.String())
+		assert.Equal(t, uint64(1), report.Ledger[1].OpenTradeID)
+		assert.Equal(t, "99.8", report.Ledger[1].PnL.String())
+	}
+
+	assert.Equal(t, "199.5", report.Profit.String())
+	if assert.Len(t, report.OpenLots, 1) {
+		assert.Equal(t, uint64(1), report.OpenLots[0].TradeID)
+		assert.Equal(t, "50.05", report.OpenLots[0].Value.String())
+	}
+}
+
+func TestLotCalculator_Specific(t *testing.T) {
+	trades := newLotTestTrades()
+	trades = append(trades, newLotTestTrade(4, types.SideTypeSell, "0.5", "300", "0", "USDT", trades[2].Time.Time().Add(time.Hour)))
+
+	// close lot 2 first, then the rest in FIFO
+	c := &LotCalculator{
+		Method:       CostBasisMethodSpecific,
+		Market:       lotTestMarket,
+		SpecificLots: map[uint64][]uint64{3: {2}},
+	}
+	report, err := c.Calculate("BTCUSDT", trades, fixedpoint.NewFromInt(250))
+	assert.NoError(t, err)
+
+	var openTradeIDs []uint64
+	for _, d := range report.Ledger {
+		openTradeIDs = append(openTradeIDs, d.OpenTradeID)
+	}
+	assert.Equal(t, []uint64{2, 1, 1}, openTradeIDs)
+	assert.Len(t, report.OpenLots, 0)
+	assert.Equal(t, "0", report.BaseAssetPosition.String())
+}
+
+func TestLotCalculator_Short(t *testing.T) {
+	t0 := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	trades := []types.Trade{
+		newLotTestTrade(1, types.SideTypeSell, "1", "100", "0", "USDT", t0),
+		newLotTestTrade(2, types.SideTypeBuy, "2", "80", "0", "USDT", t0.Add(time.Hour)),
+	}
+
+	c := &LotCalculator{Method: CostBasisMethodFIFO, Market: lotTestMarket}
+	report, err := c.Calculate("BTCUSDT", trades, fixedpoint.NewFromInt(90))
+	assert.NoError(t, err)
+
+	if assert.Len(t, report.Ledger, 1) {
+		assert.Equal(t, types.SideTypeSell, report.Ledger[0].Side)
+		assert.Equal(t, "100", report.Ledger[0].Proceeds.String())
+		assert.Equal(t, "80", report.Ledger[0].CostBasis.String())
+		assert.Equal(t, "20", report.Ledger[0].PnL.String())
+	}
+
+	assert.Equal(t, "1", report.BaseAssetPosition.String())
+	assert.Equal(t, "10", report.UnrealizedProfit.String())
+}
+
+func TestLotCalculator_FeeAndFiat(t *testing.T) {
+	t0 := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(24 * time.Hour)
+	trades := []types.Trade{
+		newLotTestTrade(1, types.SideTypeBuy, "1", "100", "0.01", "BNB", t0),
+		newLotTestTrade(2, types.SideTypeSell, "1", "200", "0.2", "USDT", t1),
+	}
+
+	c := &LotCalculator{
+		Method:       CostBasisMethodFIFO,
+		Market:       lotTestMarket,
+		FiatCurrency: "TWD",
+		FeePrice: func(currency string, t time.Time) (fixedpoint.Value, bool) {
+			if currency == "BNB" {
+				return fixedpoint.NewFromInt(400), true
+			}
+			return fixedpoint.Zero, false
+		},
+		FiatPrice: func(currency string, t time.Time) (fixedpoint.Value, bool) {
+			if t.Before(t1) {
+				return fixedpoint.NewFromInt(30), true
+			}
+			return fixedpoint.NewFromInt(31), true
+		},
+	}
+
+	report, err := c.Calculate("BTCUSDT", trades, fixedpoint.NewFromInt(200))
+	assert.NoError(t, err)
+
+	if assert.Len(t, report.Ledger, 1) {
+		d := report.Ledger[0]
+		assert.Equal(t, "104", d.CostBasis.String())
+		assert.Equal(t, "199.8", d.Proceeds.String())
+		assert.Equal(t, "95.8", d.PnL.String())
+		assert.Equal(t, "3120", d.CostBasisFiat.String())
+		assert.Equal(t, "6193.8", d.ProceedsFiat.String())
+		assert.Equal(t, "3073.8", d.PnLFiat.String())
+	}
+
+	assert.Equal(t, "3073.8", report.FiatProfit.String())
+
+	// the fiat price is required
+	c.FiatPrice = nil
+	_, err = c.Calculate("BTCUSDT", trades, fixedpoint.NewFromInt(200))
+	assert.Error(t, err)
+}
+
+func TestLotCalculator_BaseCurrencyFee(t *testing.T) {
+	t0 := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	trades := []types.Trade{
+		// 0.999 BTC is received
+		newLotTestTrade(1, types.SideTypeBuy, "1", "100", "0.001", "BTC", t0),
+		newLotTestTrade(2, types.SideTypeSell, "0.999", "200", "0", "USDT", t0.Add(24*time.Hour)),
+	}
+
+	c := &LotCalculator{Method: CostBasisMethodFIFO, Market: lotTestMarket}
+	report, err := c.Calculate("BTCUSDT", trades, fixedpoint.NewFromInt(200))
+	assert.NoError(t, err)
+
+	if assert.Len(t, report.Ledger, 1) {
+		d := report.Ledger[0]
+		assert.Equal(t, "0.999", d.Quantity.String())
+		assert.Equal(t, "100", d.CostBasis.String())
+		assert.Equal(t, "199.8", d.Proceeds.String())
+		assert.Equal(t, "99.8", d.PnL.String())
+	}
+
+	assert.Equal(t, "0.1", report.Fee.String())
+	assert.Equal(t, "0", report.BaseAssetPosition.String())
+	assert.Empty(t, report.OpenLots)
+}
+
+func TestLotCalculator_SellBaseCurrencyFee(t *testing.T) {
+	t0 := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	ethTrade := newLotTestTrade(3, types.SideTypeBuy, "1", "10", "0.1", "ETH", t0)
+	ethTrade.Symbol = "ETHUSDT"
+
+	trades := []types.Trade{
+		newLotTestTrade(1, types.SideTypeBuy, "1", "100", "0", "USDT", t0),
+		// 0.501 BTC is paid
+		newLotTestTrade(2, types.SideTypeSell, "0.5", "200", "0.001", "BTC", t0.Add(24*time.Hour)),
+		// the trades that are not processed are not counted
+		ethTrade,
+		newLotTestTrade(4, types.SideTypeBuy, "0.001", "200", "0.001", "BTC", t0.Add(48*time.Hour)),
+	}
+
+	c := &LotCalculator{Method: CostBasisMethodFIFO, Market: lotTestMarket}
+	report, err := c.Calculate("BTCUSDT", trades, fixedpoint.NewFromInt(200))
+	assert.NoError(t, err)
+
+	if assert.Len(t, report.Ledger, 1) {
+		d := report.Ledger[0]
+		assert.Equal(t, "0.501", d.Quantity.String())
+		assert.Equal(t, "50.1", d.CostBasis.String())
+		assert.Equal(t, "100", d.Proceeds.String())
+		assert.Equal(t, "49.9", d.PnL.String())
+	}
+
+	assert.Equal(t, "0.499", report.BaseAssetPosition.String())
+	assert.Equal(t, 2, report.NumTrades)
+	assert.Equal(t, "0.2", report.Fee.String())
+	assert.Equal(t, "0.001", report.CurrencyFees["BTC"].String())
+	assert.NotContains(t, report.CurrencyFees, "ETH")
+}
+
+func TestLotLedger_CsvRecords(t *testing.T) {
+	c := &LotCalculator{Method: CostBasisMethodFIFO, Market: lotTestMarket}
+	report, err := c.Calculate("BTCUSDT", newLotTestTrades(), fixedpoint.NewFromInt(250))
+	assert.NoError(t, err)
+
+	records := report.Ledger.CsvRecords()
+	assert.Len(t, records, 2)
+	for _, record := range records {
+		assert.Len(t, record, len(report.Ledger.CsvHeader()))
+	}
+	assert.Equal(t, "2", records[0][8])
+}
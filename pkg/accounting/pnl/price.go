@@ -0,0 +1,60 @@
+package pnl
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// KLineQuerier queries the stored klines, which is implemented by service.BacktestService
+type KLineQuerier interface {
+	QueryKLinesForward(exchange types.ExchangeName, symbol string, interval types.Interval, startTime time.Time, limit int) ([]types.KLine, error)
+	QueryKLinesBackward(exchange types.ExchangeName, symbol string, interval types.Interval, endTime time.Time, limit int) ([]types.KLine, error)
+}
+
+// NewKLinePriceFunc returns a PriceFunc that looks up the daily close price of the currency in the target currency,
+// the {currency}{target} and the {target}{currency} symbols are queried.
+func NewKLinePriceFunc(querier KLineQuerier, exchange types.ExchangeName, target string) PriceFunc {
+	return func(currency string, t time.Time) (fixedpoint.Value, bool) {
+		if currency == target {
+			return fixedpoint.One, true
+		}
+
+		if price, ok := queryDailyClose(querier, exchange, currency+target, t); ok {
+			return price, true
+		}
+
+		if price, ok := queryDailyClose(querier, exchange, target+currency, t); ok {
+			return fixedpoint.One.Div(price), true
+		}
+
+		return fixedpoint.Zero, false
+	}
+}
+
+// queryDailyClose returns the close price of the last daily kline closed before t,
+// or the daily kline that contains t if there is no earlier kline.
+func queryDailyClose(querier KLineQuerier, exchange types.ExchangeName, symbol string, t time.Time) (fixedpoint.Value, bool) {
+	klines, err := querier.QueryKLinesBackward(exchange, symbol, types.Interval1d, t, 1)
+	if err != nil {
+		log.WithError(err).Errorf("can not query %s klines", symbol)
+		return fixedpoint.Zero, false
+	}
+
+	if len(klines) == 0 {
+		klines, err = querier.QueryKLinesForward(exchange, symbol, types.Interval1d, t, 1)
+		if err != nil {
+			log.WithError(err).Errorf("can not query %s klines", symbol)
+			return fixedpoint.Zero, false
+		}
+	}
+
+	if len(klines) == 0 || klines[0].Close.Sign() <= 0 {
+		return fixedpoint.Zero, false
+	}
+
+	return klines[0].Close, true
+}
@@ -98,3 +98,74 @@ func (report AverageCostPnLReport) SlackAttachment() slack.Attachment {
 		FooterIcon: "",
 	}
 }
+
+type LotPnLReport struct {
+	Symbol       string           `json:"symbol"`
+	Market       types.Market     `json:"market"`
+	Method       CostBasisMethod  `json:"method"`
+	FiatCurrency string           `json:"fiatCurrency"`
+	LastPrice    fixedpoint.Value `json:"lastPrice"`
+	StartTime    time.Time        `json:"startTime"`
+	NumTrades    int              `json:"numTrades"`
+
+	Profit           fixedpoint.Value `json:"profit"`
+	FiatProfit       fixedpoint.Value `json:"fiatProfit"`
+	GrossProfit      fixedpoint.Value `json:"grossProfit"`
+	GrossLoss        fixedpoint.Value `json:"grossLoss"`
+	UnrealizedProfit fixedpoint.Value `json:"unrealizedProfit"`
+
+	BuyVolume         fixedpoint.Value            `json:"buyVolume,omitempty"`
+	SellVolume        fixedpoint.Value            `json:"sellVolume,omitempty"`
+	BaseAssetPosition fixedpoint.Value            `json:"baseAssetPosition"`
+	Fee               fixedpoint.Value            `json:"fee"`
+	CurrencyFees      map[string]fixedpoint.Value `json:"currencyFees"`
+
+	OpenLots []Lot     `json:"openLots"`
+	Ledger   LotLedger `json:"ledger"`
+}
+
+func (report *LotPnLReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(report, "", "  ")
+}
+
+func (report LotPnLReport) Print() {
+	color.Green("COST BASIS METHOD: %s", report.Method)
+	color.Green("TRADES SINCE: %v", report.StartTime)
+	color.Green("NUMBER OF TRADES: %d", report.NumTrades)
+	color.Green("BASE ASSET POSITION: %s", report.BaseAssetPosition.String())
+	color.Green("OPEN LOTS: %d", len(report.OpenLots))
+	color.Green("CLOSED LOTS: %d", len(report.Ledger))
+
+	color.Green("TOTAL BUY VOLUME: %v", report.BuyVolume)
+	color.Green("TOTAL SELL VOLUME: %v", report.SellVolume)
+
+	color.Green("CURRENT PRICE: %s", report.Market.FormatPrice(report.LastPrice))
+	color.Green("FEE: %s %s", report.Fee.String(), report.Market.QuoteCurrency)
+	color.Green("CURRENCY FEES:")
+	for currency, fee := range report.CurrencyFees {
+		color.Green(" - %s: %s", currency, fee.String())
+	}
+
+	color.Green("GROSS PROFIT: %s %s", report.GrossProfit.String(), report.Market.QuoteCurrency)
+	color.Red("GROSS LOSS: %s %s", report.GrossLoss.String(), report.Market.QuoteCurrency)
+
+	if report.Profit.Sign() > 0 {
+		color.Green("REALIZED PROFIT: %s %s", report.Profit.String(), report.Market.QuoteCurrency)
+	} else {
+		color.Red("REALIZED PROFIT: %s %s", report.Profit.String(), report.Market.QuoteCurrency)
+	}
+
+	if report.FiatCurrency != report.Market.QuoteCurrency {
+		if report.FiatProfit.Sign() > 0 {
+			color.Green("REALIZED PROFIT IN %s: %s", report.FiatCurrency, report.FiatProfit.String())
+		} else {
+			color.Red("REALIZED PROFIT IN %s: %s", report.FiatCurrency, report.FiatProfit.String())
+		}
+	}
+
+	if report.UnrealizedProfit.Sign() > 0 {
+		color.Green("UNREALIZED PROFIT: %s %s", report.UnrealizedProfit.String(), report.Market.QuoteCurrency)
+	} else {
+		color.Red("UNREALIZED PROFIT: %s %s", report.UnrealizedProfit.String(), report.Market.QuoteCurrency)
+	}
+}
@@ -0,0 +1,172 @@
+package alert
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	defaultMoveWindow    = 1
+	defaultVolumeWindow  = 20
+	defaultCooldown      = 15 * time.Minute
+	defaultCheckInterval = time.Minute
+)
+
+var (
+	defaultInterval         = types.Interval1h
+	defaultVolumeMultiplier = fixedpoint.NewFromInt(3)
+)
+
+// Alert is an alert rule with its trigger state
+type Alert struct {
+	bbgo.AlertRule
+
+	// Dynamic alerts are added from the interaction, the other alerts are loaded from the config
+	Dynamic bool `json:"dynamic,omitempty"`
+
+	// Disabled is set when a one-shot alert is triggered
+	Disabled bool `json:"disabled,omitempty"`
+
+	CreatedAt   time.Time `json:"createdAt"`
+	TriggeredAt time.Time `json:"triggeredAt,omitempty"`
+
+	// LastPrice is the price of the last check, it's used for detecting the price cross
+	LastPrice fixedpoint.Value `json:"lastPrice,omitempty"`
+}
+
+// MarshalJSON encodes the cooldown in the duration string, e.g. "15m0s",
+// since types.Duration decodes the JSON number in seconds.
+func (a Alert) MarshalJSON() ([]byte, error) {
+	type alert Alert
+
+	var cooldown string
+	if a.Cooldown > 0 {
+		cooldown = a.Cooldown.Duration().String()
+	}
+
+	return json.Marshal(struct {
+		alert
+		Cooldown string `json:"cooldown,omitempty"`
+	}{alert: alert(a), Cooldown: cooldown})
+}
+
+// String returns the short description of the alert rule
+func (a *Alert) String() string {
+	var desc string
+	switch a.Condition {
+	case bbgo.AlertConditionCross, bbgo.AlertConditionAbove, bbgo.AlertConditionBelow:
+		desc = fmt.Sprintf("%s %s", a.Condition, a.Price.String())
+
+	case bbgo.AlertConditionMove:
+		desc = fmt.Sprintf("move %s in %d %s klines", a.Percentage.Percentage(), a.Window, a.Interval)
+
+	case bbgo.AlertConditionVolume:
+		desc = fmt.Sprintf("volume %sx of %d %s klines", a.Multiplier.String(), a.Window, a.Interval)
+
+	case bbgo.AlertConditionIndicator:
+		desc = fmt.Sprintf("%s on %s", a.Expression, a.Interval)
+
+	case bbgo.AlertConditionFunding:
+		desc = fmt.Sprintf("funding rate %s", a.FundingRate.Percentage())
+
+	default:
+		desc = string(a.Condition)
+	}
+
+	var flags []string
+	if a.Recurring {
+		flags = append(flags, "recurring")
+	}
+
+	if a.Disabled {
+		flags = append(flags, "triggered")
+	}
+
+	s := fmt.Sprintf("#%s %s:%s %s", a.ID, a.Session, a.Symbol, desc)
+	if len(flags) > 0 {
+		s += " (" + strings.Join(flags, ", ") + ")"
+	}
+
+	return s
+}
+
+// Event is the notification of a triggered alert
+type Event struct {
+	Alert   Alert
+	Message string
+	Time    time.Time
+}
+
+func (e *Event) PlainText() string {
+	return fmt.Sprintf("🔔 Alert #%s %s: %s", e.Alert.ID, e.Alert.Symbol, e.Message)
+}
+
+// normalizeRule fills the default values of the rule and validates the condition parameters
+func normalizeRule(rule *bbgo.AlertRule) error {
+	if len(rule.Symbol) == 0 {
+		return fmt.Errorf("alert symbol is required")
+	}
+
+	rule.Symbol = strings.ToUpper(rule.Symbol)
+	rule.Condition = bbgo.AlertCondition(strings.ToLower(string(rule.Condition)))
+
+	switch rule.Condition {
+	case bbgo.AlertConditionCross, bbgo.AlertConditionAbove, bbgo.AlertConditionBelow:
+		if rule.Price.Sign() <= 0 {
+			return fmt.Errorf("%s alert requires a positive price", rule.Condition)
+		}
+
+	case bbgo.AlertConditionMove:
+		if rule.Percentage.Sign() <= 0 {
+			return fmt.Errorf("move alert requires a positive percentage")
+		}
+
+		if rule.Window == 0 {
+			rule.Window = defaultMoveWindow
+		}
+
+	case bbgo.AlertConditionVolume:
+		if rule.Multiplier.IsZero() {
+			rule.Multiplier = defaultVolumeMultiplier
+		}
+
+		if rule.Window == 0 {
+			rule.Window = defaultVolumeWindow
+		}
+
+	case bbgo.AlertConditionIndicator:
+		if len(rule.Expression) == 0 {
+			return fmt.Errorf("indicator alert requires an expression")
+		}
+
+	case bbgo.AlertConditionFunding:
+		if rule.FundingRate.IsZero() {
+			return fmt.Errorf("funding alert requires a funding rate threshold")
+		}
+
+	default:
+		return fmt.Errorf("unsupported alert condition %q", rule.Condition)
+	}
+
+	if rule.Window < 0 {
+		return fmt.Errorf("alert window can not be negative")
+	}
+
+	if len(rule.Interval) == 0 {
+		rule.Interval = defaultInterval
+	} else if _, ok := types.SupportedIntervals[rule.Interval]; !ok {
+		return fmt.Errorf("unsupported interval %s", rule.Interval)
+	}
+
+	if rule.Recurring && rule.Cooldown == 0 {
+		rule.Cooldown = types.Duration(defaultCooldown)
+	}
+
+	return nil
+}
@@ -0,0 +1,293 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/expr"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// numOfWarmUpKLines is the number of the klines loaded for warming up the indicators
+const numOfWarmUpKLines = 500
+
+type premiumIndexQuerier interface {
+	QueryPremiumIndex(ctx context.Context, symbol string) (*types.PremiumIndex, error)
+}
+
+// indicatorState keeps the klines and the compiled expression of an indicator alert
+type indicatorState struct {
+	store *bbgo.MarketDataStore
+	expr  *expr.Expr
+
+	// lastKLineTime is the start time of the last closed kline added to the store
+	lastKLineTime time.Time
+}
+
+func newIndicatorState(rule bbgo.AlertRule) (*indicatorState, error) {
+	store := bbgo.NewMarketDataStore(rule.Symbol)
+	compiled, err := expr.Compile(rule.Expression, expr.Env{
+		Interval:     rule.Interval,
+		IndicatorSet: bbgo.NewStandardIndicatorSet(rule.Symbol, store),
+		Store:        store,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &indicatorState{store: store, expr: compiled}, nil
+}
+
+// checker evaluates the alert conditions of one check round,
+// the tickers are cached so that the alerts of the same symbol share one query.
+type checker struct {
+	engine  *Engine
+	now     time.Time
+	tickers map[string]*types.Ticker
+}
+
+func newChecker(engine *Engine, now time.Time) *checker {
+	return &checker{
+		engine:  engine,
+		now:     now,
+		tickers: make(map[string]*types.Ticker),
+	}
+}
+
+func (c *checker) check(ctx context.Context, a *Alert) (string, bool, error) {
+	session, ok := c.engine.sessions[a.Session]
+	if !ok {
+		return "", false, fmt.Errorf("session %q not found", a.Session)
+	}
+
+	switch a.Condition {
+	case bbgo.AlertConditionCross, bbgo.AlertConditionAbove, bbgo.AlertConditionBelow:
+		return c.checkPrice(ctx, session, a)
+
+	case bbgo.AlertConditionMove:
+		return c.checkMove(ctx, session, a)
+
+	case bbgo.AlertConditionVolume:
+		return c.checkVolume(ctx, session, a)
+
+	case bbgo.AlertConditionIndicator:
+		return c.checkIndicator(ctx, session, a)
+
+	case bbgo.AlertConditionFunding:
+		return c.checkFundingRate(ctx, session, a)
+	}
+
+	return "", false, fmt.Errorf("unsupported alert condition %q", a.Condition)
+}
+
+func (c *checker) ticker(ctx context.Context, session *bbgo.ExchangeSession, symbol string) (*types.Ticker, error) {
+	key := session.Name + ":" + symbol
+	if ticker, ok := c.tickers[key]; ok {
+		return ticker, nil
+	}
+
+	ticker, err := session.Exchange.QueryTicker(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	c.tickers[key] = ticker
+	return ticker, nil
+}
+
+func (c *checker) checkPrice(ctx context.Context, session *bbgo.ExchangeSession, a *Alert) (string, bool, error) {
+	ticker, err := c.ticker(ctx, session, a.Symbol)
+	if err != nil {
+		return "", false, err
+	}
+
+	price := ticker.Last
+	lastPrice := a.LastPrice
+	a.LastPrice = price
+
+	switch a.Condition {
+	case bbgo.AlertConditionAbove:
+		if price.Compare(a.Price) >= 0 {
+			return fmt.Sprintf("price %s is above %s", formatPrice(session, a.Symbol, price), formatPrice(session, a.Symbol, a.Price)), true, nil
+		}
+
+	case bbgo.AlertConditionBelow:
+		if price.Compare(a.Price) <= 0 {
+			return fmt.Sprintf("price %s is below %s", formatPrice(session, a.Symbol, price), formatPrice(session, a.Symbol, a.Price)), true, nil
+		}
+
+	case bbgo.AlertConditionCross:
+		// the price of the first check is only recorded
+		if lastPrice.IsZero() {
+			return "", false, nil
+		}
+
+		if lastPrice.Compare(a.Price) < 0 && price.Compare(a.Price) >= 0 {
+			return fmt.Sprintf("price crossed above %s, last price %s", formatPrice(session, a.Symbol, a.Price), formatPrice(session, a.Symbol, price)), true, nil
+		}
+
+		if lastPrice.Compare(a.Price) > 0 && price.Compare(a.Price) <= 0 {
+			return fmt.Sprintf("price crossed below %s, last price %s", formatPrice(session, a.Symbol, a.Price), formatPrice(session, a.Symbol, price)), true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+func (c *checker) queryKLines(ctx context.Context, session *bbgo.ExchangeSession, a *Alert, limit int) ([]types.KLine, error) {
+	endTime := c.now
+	klines, err := session.Exchange.QueryKLines(ctx, a.Symbol, a.Interval, types.KLineQueryOptions{
+		Limit:   limit,
+		EndTime: &endTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+
+	return klines, nil
+}
+
+func (c *checker) checkMove(ctx context.Context, session *bbgo.ExchangeSession, a *Alert) (string, bool, error) {
+	klines, err := c.queryKLines(ctx, session, a, a.Window)
+	if err != nil {
+		return "", false, err
+	}
+
+	if len(klines) == 0 {
+		return "", false, nil
+	}
+
+	open := klines[0].Open
+	if open.IsZero() {
+		return "", false, nil
+	}
+
+	price := klines[len(klines)-1].Close
+	change := price.Sub(open).Div(open)
+	if change.Abs().Compare(a.Percentage) < 0 {
+		return "", false, nil
+	}
+
+	return fmt.Sprintf("price moved %s in the last %d %s klines, from %s to %s",
+		change.FormatPercentage(2),
+		a.Window, a.Interval,
+		formatPrice(session, a.Symbol, open),
+		formatPrice(session, a.Symbol, price)), true, nil
+}
+
+func (c *checker) checkVolume(ctx context.Context, session *bbgo.ExchangeSession, a *Alert) (string, bool, error) {
+	klines, err := c.queryKLines(ctx, session, a, a.Window+1)
+	if err != nil {
+		return "", false, err
+	}
+
+	// the volume spike needs at least one kline to compare with
+	if len(klines) < 2 {
+		return "", false, nil
+	}
+
+	last := klines[len(klines)-1]
+	previous := klines[:len(klines)-1]
+
+	sum := fixedpoint.Zero
+	for _, k := range previous {
+		sum = sum.Add(k.Volume)
+	}
+
+	average := sum.Div(fixedpoint.NewFromInt(int64(len(previous))))
+	if average.IsZero() || last.Volume.Compare(average.Mul(a.Multiplier)) < 0 {
+		return "", false, nil
+	}
+
+	return fmt.Sprintf("volume %s is %sx of the average volume %s of the last %d %s klines",
+		last.Volume.String(),
+		last.Volume.Div(average).Round(2, fixedpoint.Down).String(),
+		average.Round(4, fixedpoint.Down).String(),
+		len(previous), a.Interval), true, nil
+}
+
+func (c *checker) checkIndicator(ctx context.Context, session *bbgo.ExchangeSession, a *Alert) (string, bool, error) {
+	state, err := c.engine.indicatorState(a)
+	if err != nil {
+		return "", false, err
+	}
+
+	// the first query loads the history for warming up the indicators
+	warmUp := state.lastKLineTime.IsZero()
+
+	limit := numOfWarmUpKLines
+	if !warmUp {
+		limit = int(c.now.Sub(state.lastKLineTime)/a.Interval.Duration()) + 1
+		if limit > numOfWarmUpKLines {
+			limit = numOfWarmUpKLines
+		}
+	}
+
+	klines, err := c.queryKLines(ctx, session, a, limit)
+	if err != nil {
+		return "", false, err
+	}
+
+	updated := false
+	for _, k := range klines {
+		startTime := k.StartTime.Time()
+
+		// skip the klines already added and the kline that is not closed yet
+		if !startTime.After(state.lastKLineTime) || startTime.Add(a.Interval.Duration()).After(c.now) {
+			continue
+		}
+
+		k.Interval = a.Interval
+		state.store.AddKLine(k)
+		state.lastKLineTime = startTime
+		updated = true
+	}
+
+	// the condition is only evaluated when a new kline is closed after the warm-up,
+	// so that the alert is not triggered by the history
+	if warmUp || !updated || !state.expr.Last() {
+		return "", false, nil
+	}
+
+	return fmt.Sprintf("%s is true on the %s kline closed at %s",
+		a.Expression, a.Interval,
+		state.lastKLineTime.Add(a.Interval.Duration()).Format(time.RFC3339)), true, nil
+}
+
+func (c *checker) checkFundingRate(ctx context.Context, session *bbgo.ExchangeSession, a *Alert) (string, bool, error) {
+	querier, ok := session.Exchange.(premiumIndexQuerier)
+	if !ok {
+		return "", false, fmt.Errorf("session %s does not support the funding rate query", session.Name)
+	}
+
+	index, err := querier.QueryPremiumIndex(ctx, a.Symbol)
+	if err != nil {
+		return "", false, err
+	}
+
+	rate := index.LastFundingRate
+	if a.FundingRate.Sign() > 0 && rate.Compare(a.FundingRate) < 0 {
+		return "", false, nil
+	}
+
+	if a.FundingRate.Sign() < 0 && rate.Compare(a.FundingRate) > 0 {
+		return "", false, nil
+	}
+
+	return fmt.Sprintf("funding rate %s reached the threshold %s", rate.Percentage(), a.FundingRate.Percentage()), true, nil
+}
+
+func formatPrice(session *bbgo.ExchangeSession, symbol string, price fixedpoint.Value) string {
+	if market, ok := session.Market(symbol); ok {
+		return market.FormatPrice(price)
+	}
+
+	return price.String()
+}
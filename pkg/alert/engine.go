@@ -0,0 +1,380 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// snapshot is the persisted state of the engine
+type snapshot struct {
+	Sequence int     `json:"sequence"`
+	Alerts   []Alert `json:"alerts"`
+}
+
+// Engine checks the price and indicator alerts periodically with the exchange REST API,
+// it runs independently of the strategies, so the alerts can be added for any symbol at runtime.
+type Engine struct {
+	Config *bbgo.AlertConfig
+
+	sessions       map[string]*bbgo.ExchangeSession
+	defaultSession string
+	notifier       bbgo.Notifier
+	store          service.Store
+
+	mu       sync.Mutex
+	sequence int
+	alerts   []*Alert
+
+	// indicators are the market data and the compiled expressions of the indicator alerts, guarded by mu
+	indicators map[string]*indicatorState
+
+	// checkMu serializes the check rounds
+	checkMu sync.Mutex
+}
+
+func NewEngine(config *bbgo.AlertConfig, sessions map[string]*bbgo.ExchangeSession, notifier bbgo.Notifier) (*Engine, error) {
+	if config == nil {
+		config = &bbgo.AlertConfig{}
+	}
+
+	if config.CheckInterval == 0 {
+		config.CheckInterval = types.Duration(defaultCheckInterval)
+	}
+
+	e := &Engine{
+		Config:         config,
+		sessions:       sessions,
+		defaultSession: config.Session,
+		notifier:       notifier,
+		indicators:     make(map[string]*indicatorState),
+	}
+
+	if len(e.defaultSession) == 0 {
+		var names []string
+		for name := range sessions {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+		if len(names) > 0 {
+			e.defaultSession = names[0]
+		}
+	}
+
+	for i, rule := range config.Rules {
+		if len(rule.ID) == 0 {
+			rule.ID = fmt.Sprintf("%s-%s-%d", strings.ToLower(rule.Symbol), rule.Condition, i+1)
+		}
+
+		if _, err := e.add(rule, false, time.Now()); err != nil {
+			return nil, fmt.Errorf("alert rule #%d: %w", i+1, err)
+		}
+	}
+
+	return e, nil
+}
+
+// SetStore loads the persisted alerts from the store.
+// The alerts added from the interaction are restored, the trigger states of the config alerts are restored if the rules are not changed.
+func (e *Engine) SetStore(store service.Store) {
+	e.store = store
+
+	var s snapshot
+	if err := store.Load(&s); err != nil {
+		if err != service.ErrPersistenceNotExists {
+			log.WithError(err).Errorf("[alert] can not load the alerts")
+		}
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.sequence = s.Sequence
+	for _, persisted := range s.Alerts {
+		persisted := persisted
+		if persisted.Dynamic {
+			if e.find(persisted.ID) == nil {
+				e.alerts = append(e.alerts, &persisted)
+			}
+			continue
+		}
+
+		if a := e.find(persisted.ID); a != nil && reflect.DeepEqual(a.AlertRule, persisted.AlertRule) {
+			a.Disabled = persisted.Disabled
+			a.CreatedAt = persisted.CreatedAt
+			a.TriggeredAt = persisted.TriggeredAt
+			a.LastPrice = persisted.LastPrice
+		}
+	}
+}
+
+// Add adds a one-shot or a recurring alert at runtime
+func (e *Engine) Add(rule bbgo.AlertRule) (*Alert, error) {
+	e.mu.Lock()
+	e.sequence++
+	rule.ID = strconv.Itoa(e.sequence)
+	e.mu.Unlock()
+
+	a, err := e.add(rule, true, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	e.save()
+	return a, nil
+}
+
+func (e *Engine) add(rule bbgo.AlertRule, dynamic bool, now time.Time) (*Alert, error) {
+	if len(rule.Session) == 0 {
+		rule.Session = e.defaultSession
+	}
+
+	session, ok := e.sessions[rule.Session]
+	if !ok {
+		return nil, fmt.Errorf("session %q not found", rule.Session)
+	}
+
+	if err := normalizeRule(&rule); err != nil {
+		return nil, err
+	}
+
+	// the markets are not loaded if the session is not initialized yet
+	if markets := session.Markets(); len(markets) > 0 {
+		if _, ok := markets[rule.Symbol]; !ok {
+			return nil, fmt.Errorf("market %s is not found in session %s", rule.Symbol, rule.Session)
+		}
+	}
+
+	if rule.Condition == bbgo.AlertConditionIndicator {
+		if _, err := newIndicatorState(rule); err != nil {
+			return nil, err
+		}
+	}
+
+	a := &Alert{
+		AlertRule: rule,
+		Dynamic:   dynamic,
+		CreatedAt: now,
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.find(rule.ID) != nil {
+		return nil, fmt.Errorf("duplicated alert id %s", rule.ID)
+	}
+
+	e.alerts = append(e.alerts, a)
+	return a, nil
+}
+
+// Remove removes the alert added from the interaction,
+// the alerts defined in the config can only be removed from the config file.
+func (e *Engine) Remove(id string) error {
+	id = strings.TrimPrefix(id, "#")
+
+	e.mu.Lock()
+	var removed bool
+	for i, a := range e.alerts {
+		if a.ID != id {
+			continue
+		}
+
+		if !a.Dynamic {
+			e.mu.Unlock()
+			return fmt.Errorf("alert #%s is defined in the config, please remove it from the config file", id)
+		}
+
+		e.alerts = append(e.alerts[:i], e.alerts[i+1:]...)
+		delete(e.indicators, id)
+		removed = true
+		break
+	}
+	e.mu.Unlock()
+
+	if !removed {
+		return fmt.Errorf("alert #%s not found", id)
+	}
+
+	e.save()
+	return nil
+}
+
+// Alerts returns the copies of the alerts
+func (e *Engine) Alerts() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	alerts := make([]Alert, 0, len(e.alerts))
+	for _, a := range e.alerts {
+		alerts = append(alerts, *a)
+	}
+
+	return alerts
+}
+
+// Run checks the alerts every check interval until the context is done
+func (e *Engine) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.Config.CheckInterval.Duration())
+	defer ticker.Stop()
+
+	e.Check(ctx, time.Now())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			e.Check(ctx, now)
+		}
+	}
+}
+
+// Check evaluates the enabled alerts and sends the notifications of the triggered ones.
+// The alerts are evaluated without holding the lock, since the conditions are checked with the exchange API.
+func (e *Engine) Check(ctx context.Context, now time.Time) {
+	e.checkMu.Lock()
+	defer e.checkMu.Unlock()
+
+	e.mu.Lock()
+	var alerts []Alert
+	for _, a := range e.alerts {
+		if !a.Disabled {
+			alerts = append(alerts, *a)
+		}
+	}
+	e.mu.Unlock()
+
+	type result struct {
+		alert     Alert
+		message   string
+		triggered bool
+	}
+
+	checker := newChecker(e, now)
+	var results []result
+	for _, a := range alerts {
+		a := a
+		message, triggered, err := checker.check(ctx, &a)
+		if err != nil {
+			log.WithError(err).Warnf("[alert] can not check the alert %s", a.String())
+			continue
+		}
+
+		results = append(results, result{alert: a, message: message, triggered: triggered})
+	}
+
+	var events []*Event
+	e.mu.Lock()
+	for _, r := range results {
+		// the alert can be removed or changed while it's being checked
+		a := e.find(r.alert.ID)
+		if a == nil {
+			delete(e.indicators, r.alert.ID)
+			continue
+		}
+
+		if a.Disabled || !reflect.DeepEqual(a.AlertRule, r.alert.AlertRule) {
+			continue
+		}
+
+		a.LastPrice = r.alert.LastPrice
+		if !r.triggered {
+			continue
+		}
+
+		if a.Recurring && !a.TriggeredAt.IsZero() && now.Sub(a.TriggeredAt) < a.Cooldown.Duration() {
+			continue
+		}
+
+		a.TriggeredAt = now
+		if !a.Recurring {
+			a.Disabled = true
+		}
+
+		events = append(events, &Event{Alert: *a, Message: r.message, Time: now})
+	}
+	e.mu.Unlock()
+
+	for _, event := range events {
+		e.notify(event)
+	}
+
+	e.save()
+}
+
+func (e *Engine) notify(event *Event) {
+	log.Infof("[alert] %s", event.PlainText())
+
+	channel := event.Alert.Channel
+	if len(channel) == 0 {
+		channel = e.Config.Channel
+	}
+
+	if len(channel) > 0 {
+		e.notifier.NotifyTo(channel, event)
+		return
+	}
+
+	e.notifier.Notify(event)
+}
+
+// indicatorState returns the indicator state of the alert, the state is created if it's not found
+func (e *Engine) indicatorState(a *Alert) (*indicatorState, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if state, ok := e.indicators[a.ID]; ok {
+		return state, nil
+	}
+
+	state, err := newIndicatorState(a.AlertRule)
+	if err != nil {
+		return nil, err
+	}
+
+	e.indicators[a.ID] = state
+	return state, nil
+}
+
+// find returns the alert of the id, the caller must hold the lock
+func (e *Engine) find(id string) *Alert {
+	for _, a := range e.alerts {
+		if a.ID == id {
+			return a
+		}
+	}
+
+	return nil
+}
+
+func (e *Engine) save() {
+	if e.store == nil {
+		return
+	}
+
+	e.mu.Lock()
+	s := snapshot{Sequence: e.sequence}
+	for _, a := range e.alerts {
+		s.Alerts = append(s.Alerts, *a)
+	}
+	e.mu.Unlock()
+
+	if err := e.store.Save(s); err != nil {
+		log.WithError(err).Errorf("[alert] can not save the alerts")
+	}
+}
@@ -0,0 +1,329 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+type recordNotifier struct {
+	channels []string
+	messages []string
+}
+
+func (n *recordNotifier) NotifyTo(channel string, obj interface{}, args ...interface{}) {
+	n.channels = append(n.channels, channel)
+	n.Notify(obj, args...)
+}
+
+func (n *recordNotifier) Notify(obj interface{}, args ...interface{}) {
+	if event, ok := obj.(*Event); ok {
+		n.messages = append(n.messages, event.PlainText())
+		return
+	}
+
+	n.messages = append(n.messages, fmt.Sprintf(fmt.Sprint(obj), args...))
+}
+
+func (n *recordNotifier) SendPhotoTo(channel string, buffer *bytes.Buffer) {}
+
+func (n *recordNotifier) SendPhoto(buffer *bytes.Buffer) {}
+
+// futuresExchange is the exchange supports the funding rate query
+type futuresExchange struct {
+	*mocks.MockExchange
+
+	fundingRate fixedpoint.Value
+}
+
+func (e *futuresExchange) QueryPremiumIndex(ctx context.Context, symbol string) (*types.PremiumIndex, error) {
+	return &types.PremiumIndex{Symbol: symbol, LastFundingRate: e.fundingRate}, nil
+}
+
+func newTestKLines(startTime time.Time, interval types.Interval, closes, volumes []float64) []types.KLine {
+	var klines []types.KLine
+	for i, c := range closes {
+		k := types.KLine{
+			Symbol:    "BTCUSDT",
+			Interval:  interval,
+			StartTime: types.Time(startTime.Add(time.Duration(i) * interval.Duration())),
+			Open:      fixedpoint.NewFromFloat(c),
+			High:      fixedpoint.NewFromFloat(c),
+			Low:       fixedpoint.NewFromFloat(c),
+			Close:     fixedpoint.NewFromFloat(c),
+			Volume:    fixedpoint.One,
+		}
+
+		if i > 0 {
+			k.Open = fixedpoint.NewFromFloat(closes[i-1])
+		}
+
+		if i < len(volumes) {
+			k.Volume = fixedpoint.NewFromFloat(volumes[i])
+		}
+
+		klines = append(klines, k)
+	}
+
+	return klines
+}
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		args string
+		want bbgo.AlertRule
+	}{
+		{
+			args: "BTCUSDT cross 30000",
+			want: bbgo.AlertRule{Symbol: "BTCUSDT", Condition: bbgo.AlertConditionCross, Price: fixedpoint.NewFromInt(30000)},
+		},
+		{
+			args: "binance:ETHUSDT move 5% 4h 3 recurring cooldown=1h",
+			want: bbgo.AlertRule{
+				Session: "binance", Symbol: "ETHUSDT", Condition: bbgo.AlertConditionMove,
+				Percentage: fixedpoint.NewFromFloat(0.05), Interval: types.Interval4h, Window: 3,
+				Recurring: true, Cooldown: types.Duration(time.Hour),
+			},
+		},
+		{
+			args: "BTCUSDT volume 3x 15m",
+			want: bbgo.AlertRule{Symbol: "BTCUSDT", Condition: bbgo.AlertConditionVolume, Multiplier: fixedpoint.NewFromInt(3), Interval: types.Interval15m},
+		},
+		{
+			args: "BTCUSDT indicator rsi(close,14)>70 1h channel=#alerts",
+			want: bbgo.AlertRule{Symbol: "BTCUSDT", Condition: bbgo.AlertConditionIndicator, Expression: "rsi(close,14)>70", Interval: types.Interval1h, Channel: "#alerts"},
+		},
+		{
+			args: "BTCUSDT funding -0.1%",
+			want: bbgo.AlertRule{Symbol: "BTCUSDT", Condition: bbgo.AlertConditionFunding, FundingRate: fixedpoint.NewFromFloat(-0.001)},
+		},
+	}
+
+	for _, test := range tests {
+		rule, err := ParseRule(strings.Fields(test.args))
+		if assert.NoError(t, err, test.args) {
+			assert.Equal(t, test.want, rule, test.args)
+		}
+	}
+
+	for _, args := range []string{"BTCUSDT cross", "BTCUSDT touch 30000", "BTCUSDT move 5% 1h x", "BTCUSDT cross 30000 cooldown=abc"} {
+		_, err := ParseRule(strings.Fields(args))
+		assert.Error(t, err, args)
+	}
+}
+
+func TestEngine_PriceAlerts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	var price = fixedpoint.NewFromInt(29000)
+	mockEx.EXPECT().QueryTicker(gomock.Any(), "BTCUSDT").DoAndReturn(func(ctx context.Context, symbol string) (*types.Ticker, error) {
+		return &types.Ticker{Last: price}, nil
+	}).AnyTimes()
+
+	session := bbgo.NewExchangeSession("binance", mockEx)
+	notifier := &recordNotifier{}
+	engine, err := NewEngine(&bbgo.AlertConfig{
+		Channel: "#alerts",
+		Rules: []bbgo.AlertRule{
+			{Symbol: "BTCUSDT", Condition: bbgo.AlertConditionAbove, Price: fixedpoint.NewFromInt(30000), Recurring: true},
+		},
+	}, map[string]*bbgo.ExchangeSession{"binance": session}, notifier)
+	require.NoError(t, err)
+
+	cross, err := engine.Add(bbgo.AlertRule{Symbol: "btcusdt", Condition: bbgo.AlertConditionCross, Price: fixedpoint.NewFromInt(30000)})
+	require.NoError(t, err)
+	assert.Equal(t, "1", cross.ID)
+	assert.Equal(t, "binance", cross.Session)
+
+	ctx := context.Background()
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	engine.Check(ctx, now)
+	assert.Empty(t, notifier.messages)
+
+	price = fixedpoint.NewFromInt(30500)
+	engine.Check(ctx, now.Add(time.Minute))
+	assert.Equal(t, []string{
+		"🔔 Alert #btcusdt-above-1 BTCUSDT: price 30500 is above 30000",
+		"🔔 Alert #1 BTCUSDT: price crossed above 30000, last price 30500",
+	}, notifier.messages)
+	assert.Equal(t, []string{"#alerts", "#alerts"}, notifier.channels)
+
+	// the one-shot alert is disabled and the recurring alert is in the cooldown period
+	engine.Check(ctx, now.Add(2*time.Minute))
+	assert.Len(t, notifier.messages, 2)
+
+	alerts := engine.Alerts()
+	if assert.Len(t, alerts, 2) {
+		assert.False(t, alerts[0].Disabled)
+		assert.True(t, alerts[1].Disabled)
+	}
+
+	engine.Check(ctx, now.Add(20*time.Minute))
+	assert.Len(t, notifier.messages, 3)
+
+	assert.Error(t, engine.Remove("btcusdt-above-1"), "the config alert can not be removed")
+	assert.NoError(t, engine.Remove("#1"))
+	assert.Error(t, engine.Remove("1"))
+
+	_, err = engine.Add(bbgo.AlertRule{Session: "max", Symbol: "BTCUSDT", Condition: bbgo.AlertConditionCross, Price: fixedpoint.One})
+	assert.EqualError(t, err, `session "max" not found`)
+}
+
+func TestEngine_KLineAlerts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	startTime := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	closes := []float64{100, 101, 102, 103, 104, 105, 110}
+	volumes := []float64{10, 10, 10, 10, 10, 10, 50}
+	klines := newTestKLines(startTime, types.Interval1h, closes, volumes)
+
+	mockEx.EXPECT().QueryKLines(gomock.Any(), "BTCUSDT", types.Interval1h, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+			var result []types.KLine
+			for _, k := range klines {
+				if !k.StartTime.Time().After(*options.EndTime) {
+					result = append(result, k)
+				}
+			}
+
+			if len(result) > options.Limit {
+				result = result[len(result)-options.Limit:]
+			}
+			return result, nil
+		}).AnyTimes()
+
+	session := bbgo.NewExchangeSession("binance", mockEx)
+	notifier := &recordNotifier{}
+	engine, err := NewEngine(&bbgo.AlertConfig{
+		Rules: []bbgo.AlertRule{
+			{ID: "move", Symbol: "BTCUSDT", Condition: bbgo.AlertConditionMove, Percentage: fixedpoint.NewFromFloat(0.04), Window: 2},
+			{ID: "volume", Symbol: "BTCUSDT", Condition: bbgo.AlertConditionVolume, Window: 5},
+			{ID: "indicator", Symbol: "BTCUSDT", Condition: bbgo.AlertConditionIndicator, Expression: "close > sma(close, 3) and close >= 105"},
+		},
+	}, map[string]*bbgo.ExchangeSession{"binance": session}, notifier)
+	require.NoError(t, err)
+
+	_, err = engine.Add(bbgo.AlertRule{Symbol: "BTCUSDT", Condition: bbgo.AlertConditionIndicator, Expression: "close >"})
+	assert.Error(t, err)
+
+	ctx := context.Background()
+
+	// the indicator is warmed up with the closed klines, the history doesn't trigger the alert
+	engine.Check(ctx, startTime.Add(5*time.Hour+30*time.Minute))
+	assert.Empty(t, notifier.messages)
+
+	// the kline of 105 is closed, the kline of 110 is not closed yet
+	engine.Check(ctx, startTime.Add(6*time.Hour+30*time.Minute))
+	assert.Equal(t, []string{
+		"🔔 Alert #move BTCUSDT: price moved 5.76% in the last 2 1h klines, from 104 to 110",
+		"🔔 Alert #volume BTCUSDT: volume 50 is 5x of the average volume 10 of the last 5 1h klines",
+		"🔔 Alert #indicator BTCUSDT: close > sma(close, 3) and close >= 105 is true on the 1h kline closed at 2022-06-01T06:00:00Z",
+	}, notifier.messages)
+
+	for _, a := range engine.Alerts() {
+		assert.True(t, a.Disabled, a.ID)
+	}
+}
+
+func TestEngine_FundingRate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(4)
+
+	futures := &futuresExchange{MockExchange: mockEx, fundingRate: fixedpoint.NewFromFloat(0.0005)}
+	notifier := &recordNotifier{}
+	engine, err := NewEngine(&bbgo.AlertConfig{
+		Session: "binance-futures",
+		Rules: []bbgo.AlertRule{
+			{ID: "high", Symbol: "BTCUSDT", Condition: bbgo.AlertConditionFunding, FundingRate: fixedpoint.NewFromFloat(0.001)},
+			{ID: "low", Symbol: "BTCUSDT", Condition: bbgo.AlertConditionFunding, FundingRate: fixedpoint.NewFromFloat(-0.0001)},
+			{ID: "spot", Session: "binance", Symbol: "BTCUSDT", Condition: bbgo.AlertConditionFunding, FundingRate: fixedpoint.NewFromFloat(0.0001)},
+		},
+	}, map[string]*bbgo.ExchangeSession{
+		"binance":         bbgo.NewExchangeSession("binance", mockEx),
+		"binance-futures": bbgo.NewExchangeSession("binance-futures", futures),
+	}, notifier)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	engine.Check(ctx, now)
+	assert.Empty(t, notifier.messages)
+
+	futures.fundingRate = fixedpoint.MustNewFromString("0.0012")
+	engine.Check(ctx, now.Add(time.Minute))
+	assert.Equal(t, []string{"🔔 Alert #high BTCUSDT: funding rate 0.12% reached the threshold 0.1%"}, notifier.messages)
+}
+
+func TestEngine_Persistence(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+	mockEx.EXPECT().QueryTicker(gomock.Any(), "BTCUSDT").Return(&types.Ticker{Last: fixedpoint.NewFromInt(31000)}, nil).AnyTimes()
+
+	sessions := map[string]*bbgo.ExchangeSession{"binance": bbgo.NewExchangeSession("binance", mockEx)}
+	config := &bbgo.AlertConfig{
+		Rules: []bbgo.AlertRule{
+			{ID: "above", Symbol: "BTCUSDT", Condition: bbgo.AlertConditionAbove, Price: fixedpoint.NewFromInt(30000)},
+			{ID: "below", Symbol: "BTCUSDT", Condition: bbgo.AlertConditionBelow, Price: fixedpoint.NewFromInt(20000)},
+		},
+	}
+
+	store := service.NewMemoryService().NewStore("bbgo", "alerts")
+	engine, err := NewEngine(config, sessions, &recordNotifier{})
+	require.NoError(t, err)
+	engine.SetStore(store)
+
+	_, err = engine.Add(bbgo.AlertRule{Symbol: "BTCUSDT", Condition: bbgo.AlertConditionCross, Price: fixedpoint.NewFromInt(32000), Recurring: true})
+	require.NoError(t, err)
+
+	engine.Check(context.Background(), time.Now())
+
+	// the trigger state of the changed config rule is reset
+	config.Rules[1].Price = fixedpoint.NewFromInt(21000)
+
+	engine, err = NewEngine(config, sessions, &recordNotifier{})
+	require.NoError(t, err)
+	engine.SetStore(store)
+
+	alerts := engine.Alerts()
+	if assert.Len(t, alerts, 3) {
+		assert.Equal(t, "above", alerts[0].ID)
+		assert.True(t, alerts[0].Disabled)
+		assert.Equal(t, "below", alerts[1].ID)
+		assert.False(t, alerts[1].Disabled)
+		assert.Equal(t, "1", alerts[2].ID)
+		assert.True(t, alerts[2].Dynamic)
+		assert.Equal(t, "31000", alerts[2].LastPrice.String())
+		assert.Equal(t, types.Duration(defaultCooldown), alerts[2].Cooldown)
+	}
+
+	a, err := engine.Add(bbgo.AlertRule{Symbol: "BTCUSDT", Condition: bbgo.AlertConditionBelow, Price: fixedpoint.NewFromInt(25000)})
+	require.NoError(t, err)
+	assert.Equal(t, "2", a.ID)
+}
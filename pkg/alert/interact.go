@@ -0,0 +1,191 @@
+package alert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/interact"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const usage = `Usage:
+/alert add [session:]SYMBOL cross|above|below PRICE
+/alert add [session:]SYMBOL move PERCENTAGE [INTERVAL] [WINDOW]
+/alert add [session:]SYMBOL volume MULTIPLIER [INTERVAL] [WINDOW]
+/alert add [session:]SYMBOL indicator "EXPRESSION" [INTERVAL]
+/alert add [session:]SYMBOL funding RATE
+/alert list
+/alert remove ID
+
+The alerts are one-shot by default, append "recurring" (and optionally "cooldown=30m") to keep the alert after it's triggered.`
+
+func (e *Engine) Commands(i *interact.Interact) {
+	i.PrivateCommand("/alerts", "List the alerts", func(reply interact.Reply) error {
+		reply.Message(e.listMessage())
+		return nil
+	}).RequireRole(interact.RoleViewer)
+
+	i.PrivateCommand("/alert", "Add, list or remove the alerts, e.g. /alert add BTCUSDT cross 30000", func(reply interact.Reply, args ...string) error {
+		if len(args) == 0 {
+			reply.Message(usage)
+			return nil
+		}
+
+		switch strings.ToLower(args[0]) {
+		case "add":
+			rule, err := ParseRule(args[1:])
+			if err != nil {
+				reply.Message(fmt.Sprintf("%s\n\n%s", err.Error(), usage))
+				return err
+			}
+
+			a, err := e.Add(rule)
+			if err != nil {
+				reply.Message(fmt.Sprintf("Unable to add the alert: %s", err.Error()))
+				return err
+			}
+
+			reply.Message(fmt.Sprintf("Alert %s is added", a.String()))
+
+		case "list", "ls":
+			reply.Message(e.listMessage())
+
+		case "remove", "rm", "delete":
+			if len(args) < 2 {
+				reply.Message("Please specify the alert id, e.g. /alert remove 1")
+				return nil
+			}
+
+			if err := e.Remove(args[1]); err != nil {
+				reply.Message(fmt.Sprintf("Unable to remove the alert: %s", err.Error()))
+				return err
+			}
+
+			reply.Message(fmt.Sprintf("Alert #%s is removed", strings.TrimPrefix(args[1], "#")))
+
+		default:
+			reply.Message(usage)
+		}
+
+		return nil
+	})
+}
+
+func (e *Engine) listMessage() string {
+	alerts := e.Alerts()
+	if len(alerts) == 0 {
+		return "No alert"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Alerts:\n")
+	for _, a := range alerts {
+		sb.WriteString(a.String())
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// ParseRule parses the arguments of the /alert add command, e.g.
+//
+//	BTCUSDT cross 30000
+//	binance:ETHUSDT move 5% 1h 4 recurring cooldown=1h
+//	BTCUSDT indicator "rsi(close, 14) > 70" 4h
+func ParseRule(args []string) (rule bbgo.AlertRule, err error) {
+	var positional []string
+	for _, arg := range args {
+		switch {
+		case strings.EqualFold(arg, "recurring"):
+			rule.Recurring = true
+
+		case strings.HasPrefix(arg, "cooldown="):
+			cooldown, err := time.ParseDuration(strings.TrimPrefix(arg, "cooldown="))
+			if err != nil {
+				return rule, fmt.Errorf("invalid cooldown %q: %w", arg, err)
+			}
+
+			rule.Cooldown = types.Duration(cooldown)
+			rule.Recurring = true
+
+		case strings.HasPrefix(arg, "channel="):
+			rule.Channel = strings.TrimPrefix(arg, "channel=")
+
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	if len(positional) < 3 {
+		return rule, fmt.Errorf("the symbol, the condition and the threshold are required")
+	}
+
+	if parts := strings.SplitN(positional[0], ":", 2); len(parts) == 2 {
+		rule.Session = parts[0]
+		rule.Symbol = parts[1]
+	} else {
+		rule.Symbol = positional[0]
+	}
+
+	rule.Condition = bbgo.AlertCondition(strings.ToLower(positional[1]))
+
+	threshold := positional[2]
+	rest := positional[3:]
+
+	switch rule.Condition {
+	case bbgo.AlertConditionCross, bbgo.AlertConditionAbove, bbgo.AlertConditionBelow:
+		rule.Price, err = fixedpoint.NewFromString(threshold)
+
+	case bbgo.AlertConditionMove:
+		rule.Percentage, err = fixedpoint.NewFromString(threshold)
+		if err == nil {
+			err = parseIntervalWindow(&rule, rest)
+		}
+
+	case bbgo.AlertConditionVolume:
+		rule.Multiplier, err = fixedpoint.NewFromString(strings.TrimSuffix(strings.ToLower(threshold), "x"))
+		if err == nil {
+			err = parseIntervalWindow(&rule, rest)
+		}
+
+	case bbgo.AlertConditionIndicator:
+		rule.Expression = threshold
+		err = parseIntervalWindow(&rule, rest)
+		if err == nil && rule.Window > 0 {
+			err = fmt.Errorf("indicator alert does not accept the window argument")
+		}
+
+	case bbgo.AlertConditionFunding:
+		rule.FundingRate, err = fixedpoint.NewFromString(threshold)
+
+	default:
+		err = fmt.Errorf("unsupported alert condition %q", positional[1])
+	}
+
+	return rule, err
+}
+
+func parseIntervalWindow(rule *bbgo.AlertRule, args []string) error {
+	if len(args) > 0 {
+		rule.Interval = types.Interval(args[0])
+	}
+
+	if len(args) > 1 {
+		window, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid window %q: %w", args[1], err)
+		}
+
+		rule.Window = window
+	}
+
+	if len(args) > 2 {
+		return fmt.Errorf("too many arguments: %s", strings.Join(args[2:], " "))
+	}
+
+	return nil
+}
@@ -60,6 +60,8 @@ type Exchange struct {
 
 	MarketDataStream types.StandardStreamEmitter
 
+	userDataStream types.StandardStreamEmitter
+
 	trades      map[string][]types.Trade
 	tradesMutex sync.Mutex
 
@@ -71,6 +73,12 @@ type Exchange struct {
 
 	markets types.MarketMap
 
+	// MarketTradeService provides the stored market trades for the market trade subscriptions
+	MarketTradeService *service.MarketTradeService
+
+	// marketTradeBuffers buffer the market trades of the symbols that subscribe the market trades
+	marketTradeBuffers map[string]*marketTradeBuffer
+
 	Src *ExchangeDataSource
 }
 
@@ -104,12 +112,19 @@ func NewExchange(sourceName types.ExchangeName, sourceExchange types.Exchange, s
 		currentTime:    startTime,
 		closedOrders:   make(map[string][]types.Order),
 		trades:         make(map[string][]types.Trade),
+
+		marketTradeBuffers: make(map[string]*marketTradeBuffer),
 	}
 
 	e.resetMatchingBooks()
 	return e, nil
 }
 
+// CurrentTime returns the time of the current kline
+func (e *Exchange) CurrentTime() time.Time {
+	return e.currentTime
+}
+
 func (e *Exchange) addTrade(trade types.Trade) {
 	e.tradesMutex.Lock()
 	e.trades[trade.Symbol] = append(e.trades[trade.Symbol], trade)
@@ -265,15 +280,53 @@ func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticke
 	}, nil
 }
 
+// QueryTickers returns the tickers of the last klines, the symbols without the matching book are skipped
 func (e *Exchange) QueryTickers(ctx context.Context, symbol ...string) (map[string]types.Ticker, error) {
-	// Not using Tickers in back test (yet)
-	return nil, ErrUnimplemented
+	if len(symbol) == 0 {
+		e.matchingBooksMutex.Lock()
+		for s := range e.matchingBooks {
+			symbol = append(symbol, s)
+		}
+		e.matchingBooksMutex.Unlock()
+	}
+
+	tickers := make(map[string]types.Ticker)
+	for _, s := range symbol {
+		matching, ok := e.matchingBook(s)
+		if !ok || matching.lastKLine.Close.IsZero() {
+			continue
+		}
+
+		ticker, err := e.QueryTicker(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+
+		tickers[s] = *ticker
+	}
+
+	return tickers, nil
 }
 
 func (e *Exchange) Name() types.ExchangeName {
 	return e.publicExchange.Name()
 }
 
+// SupportedInterval returns the intervals supported by the source exchange,
+// since the backtest klines are synced from the source exchange.
+func (e *Exchange) SupportedInterval() map[types.Interval]int {
+	if provider, ok := e.publicExchange.(types.CustomIntervalProvider); ok {
+		return provider.SupportedInterval()
+	}
+
+	return types.SupportedIntervals
+}
+
+func (e *Exchange) IsSupportedInterval(interval types.Interval) bool {
+	_, ok := e.SupportedInterval()[interval]
+	return ok
+}
+
 func (e *Exchange) PlatformFeeCurrency() string {
 	return e.publicExchange.PlatformFeeCurrency()
 }
@@ -298,6 +351,8 @@ func (e *Exchange) matchingBook(symbol string) (*SimplePriceMatching, bool) {
 }
 
 func (e *Exchange) BindUserData(userDataStream types.StandardStreamEmitter) {
+	e.userDataStream = userDataStream
+
 	userDataStream.OnTradeUpdate(func(trade types.Trade) {
 		e.addTrade(trade)
 	})
@@ -332,6 +387,14 @@ func (e *Exchange) SubscribeMarketData(startTime, endTime time.Time, extraInterv
 		case types.KLineChannel:
 			loadedIntervals[sub.Options.Interval] = struct{}{}
 
+		case types.MarketTradeChannel:
+			if e.MarketTradeService == nil {
+				log.Errorf("market trade service is not configured, the market trades of %s will not be replayed", sub.Symbol)
+				continue
+			}
+
+			e.marketTradeBuffers[sub.Symbol] = &marketTradeBuffer{}
+
 		default:
 			// Since Environment is not yet been injected at this point, no hard error
 			log.Errorf("stream channel %s is not supported in backtest", sub.Channel)
@@ -378,6 +441,7 @@ func (e *Exchange) ConsumeKLine(k types.KLine) {
 		// here we generate trades and order updates
 		matching.processKLine(kline1m)
 		matching.nextKLine = &k
+		e.emitMarketTrades(kline1m)
 		for _, kline := range matching.klineCache {
 			e.MarketDataStream.EmitKLineClosed(kline)
 			for _, h := range e.Src.Callbacks {
@@ -390,6 +454,50 @@ func (e *Exchange) ConsumeKLine(k types.KLine) {
 	matching.klineCache[k.Interval] = k
 }
 
+// marketTradeQueryWindow is the time range of the market trades loaded by a single query
+const marketTradeQueryWindow = time.Hour
+
+type marketTradeBuffer struct {
+	trades []types.Trade
+
+	// until is the end time (exclusive) of the loaded market trades
+	until time.Time
+}
+
+// emitMarketTrades replays the stored market trades in the time range of the 1m kline,
+// the trades are loaded by marketTradeQueryWindow instead of querying for every kline.
+func (e *Exchange) emitMarketTrades(k types.KLine) {
+	buffer, ok := e.marketTradeBuffers[k.Symbol]
+	if !ok {
+		return
+	}
+
+	endTime := k.EndTime.Time()
+	for !endTime.Before(buffer.until) {
+		since := k.StartTime.Time()
+		if since.Before(buffer.until) {
+			since = buffer.until
+		}
+
+		until := since.Add(marketTradeQueryWindow)
+		trades, err := e.MarketTradeService.Query(context.Background(), e.sourceName, k.Symbol, since, until.Add(-time.Millisecond))
+		if err != nil {
+			log.WithError(err).Errorf("can not query the market trades of %s", k.Symbol)
+			return
+		}
+
+		buffer.trades = append(buffer.trades, trades...)
+		buffer.until = until
+	}
+
+	n := 0
+	for ; n < len(buffer.trades) && !buffer.trades[n].Time.Time().After(endTime); n++ {
+		e.MarketDataStream.EmitMarketTrade(buffer.trades[n])
+	}
+
+	buffer.trades = buffer.trades[n:]
+}
+
 func (e *Exchange) CloseMarketData() error {
 	if err := e.MarketDataStream.Close(); err != nil {
 		log.WithError(err).Error("stream close error")
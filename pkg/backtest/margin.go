@@ -0,0 +1,147 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// MarginValuationCurrency is the currency for valuing the simulated margin account
+const MarginValuationCurrency = "USDT"
+
+// maxCrossMarginLeverage is the leverage of the simulated cross margin account
+var maxCrossMarginLeverage = fixedpoint.NewFromInt(3)
+
+// MarginExchange wraps the backtest exchange with the simulated cross margin account.
+// It's only used for the backtest sessions configured with margin: true,
+// so the other backtest sessions are still spot exchanges to the strategies.
+type MarginExchange struct {
+	*Exchange
+	types.MarginSettings
+}
+
+func NewMarginExchange(e *Exchange) *MarginExchange {
+	e.account.AccountType = types.AccountTypeMargin
+
+	m := &MarginExchange{Exchange: e}
+	m.UseMargin()
+	return m
+}
+
+// UnwrapExchange returns the backtest exchange of the session exchange, which may be wrapped by MarginExchange
+func UnwrapExchange(ex types.Exchange) (*Exchange, bool) {
+	switch e := ex.(type) {
+	case *Exchange:
+		return e, true
+	case *MarginExchange:
+		return e.Exchange, true
+	}
+
+	return nil, false
+}
+
+// BorrowMarginAsset borrows the asset into the simulated margin account, the borrowed amount is added to the available balance.
+func (e *MarginExchange) BorrowMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	if amount.Sign() <= 0 {
+		return fmt.Errorf("invalid borrow amount %v", amount)
+	}
+
+	maxBorrowable, err := e.QueryMarginAssetMaxBorrowable(ctx, asset)
+	if err != nil {
+		return err
+	}
+
+	if amount.Compare(maxBorrowable) > 0 {
+		return fmt.Errorf("can not borrow %v %s, max borrowable amount is %v", amount, asset, maxBorrowable)
+	}
+
+	balance, _ := e.account.Balance(asset)
+	balance.Currency = asset
+	balance.Available = balance.Available.Add(amount)
+	balance.Borrowed = balance.Borrowed.Add(amount)
+	balance.NetAsset = balance.Net()
+	e.account.UpdateBalances(types.BalanceMap{asset: balance})
+
+	e.emitBalanceUpdate()
+	return nil
+}
+
+// RepayMarginAsset repays the borrowed asset with the available balance, the interest is repaid first.
+func (e *MarginExchange) RepayMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	balance, ok := e.account.Balance(asset)
+	if !ok || balance.Debt().IsZero() {
+		return fmt.Errorf("no %s debt to repay", asset)
+	}
+
+	if amount.Sign() <= 0 || amount.Compare(balance.Debt()) > 0 {
+		return fmt.Errorf("invalid repay amount %v, the %s debt is %v", amount, asset, balance.Debt())
+	}
+
+	if amount.Compare(balance.Available) > 0 {
+		return fmt.Errorf("insufficient %s balance %v for repaying %v", asset, balance.Available, amount)
+	}
+
+	balance.Available = balance.Available.Sub(amount)
+
+	interest := fixedpoint.Min(amount, balance.Interest)
+	balance.Interest = balance.Interest.Sub(interest)
+	balance.Borrowed = balance.Borrowed.Sub(amount.Sub(interest))
+	balance.NetAsset = balance.Net()
+	e.account.UpdateBalances(types.BalanceMap{asset: balance})
+
+	e.emitBalanceUpdate()
+	return nil
+}
+
+// QueryMarginAssetMaxBorrowable returns the max borrowable amount of the asset,
+// which is limited by the net value of the account and the max cross margin leverage.
+func (e *MarginExchange) QueryMarginAssetMaxBorrowable(ctx context.Context, asset string) (fixedpoint.Value, error) {
+	price, ok := e.lastPrice(asset, MarginValuationCurrency)
+	if !ok {
+		return fixedpoint.Zero, fmt.Errorf("%s price in %s not found", asset, MarginValuationCurrency)
+	}
+
+	netValue := fixedpoint.Zero
+	debtValue := fixedpoint.Zero
+	for currency, balance := range e.account.Balances() {
+		p, ok := e.lastPrice(currency, MarginValuationCurrency)
+		if !ok {
+			continue
+		}
+
+		netValue = netValue.Add(balance.Net().Mul(p))
+		debtValue = debtValue.Add(balance.Debt().Mul(p))
+	}
+
+	borrowableValue := netValue.Mul(maxCrossMarginLeverage.Sub(fixedpoint.One)).Sub(debtValue)
+	if borrowableValue.Sign() <= 0 {
+		return fixedpoint.Zero, nil
+	}
+
+	return borrowableValue.Div(price), nil
+}
+
+// lastPrice returns the close price of the last kline of the matching book
+func (e *Exchange) lastPrice(asset, quoteCurrency string) (fixedpoint.Value, bool) {
+	if asset == quoteCurrency {
+		return fixedpoint.One, true
+	}
+
+	if matching, ok := e.matchingBook(asset + quoteCurrency); ok && matching.lastKLine.Close.Sign() > 0 {
+		return matching.lastKLine.Close, true
+	}
+
+	if matching, ok := e.matchingBook(quoteCurrency + asset); ok && matching.lastKLine.Close.Sign() > 0 {
+		return fixedpoint.One.Div(matching.lastKLine.Close), true
+	}
+
+	return fixedpoint.Zero, false
+}
+
+func (e *Exchange) emitBalanceUpdate() {
+	if e.userDataStream != nil {
+		e.userDataStream.EmitBalanceUpdate(e.account.Balances())
+	}
+}
@@ -0,0 +1,70 @@
+package backtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestMarginExchange_BorrowRepay(t *testing.T) {
+	account := &types.Account{AccountType: types.AccountTypeSpot}
+	account.UpdateBalances(types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.One},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromInt(10000)},
+	})
+
+	spot := &Exchange{
+		account: account,
+		matchingBooks: map[string]*SimplePriceMatching{
+			"BTCUSDT": {
+				account:   account,
+				Market:    types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+				lastKLine: types.KLine{Symbol: "BTCUSDT", Close: fixedpoint.NewFromInt(20000)},
+			},
+		},
+	}
+
+	// the backtest exchange is a spot exchange unless it's wrapped
+	var ex types.Exchange = spot
+	_, isMargin := ex.(types.MarginBorrowRepayService)
+	assert.False(t, isMargin)
+
+	e := NewMarginExchange(spot)
+	assert.True(t, e.GetMarginSettings().IsMargin)
+	assert.Equal(t, types.AccountTypeMargin, account.AccountType)
+
+	unwrapped, ok := UnwrapExchange(e)
+	assert.True(t, ok)
+	assert.Equal(t, spot, unwrapped)
+
+	ctx := context.Background()
+
+	// net value 30000 x (3 - 1)
+	maxBorrowable, err := e.QueryMarginAssetMaxBorrowable(ctx, "BTC")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", maxBorrowable.String())
+
+	assert.NoError(t, e.BorrowMarginAsset(ctx, "USDT", fixedpoint.NewFromInt(20000)))
+	assert.Error(t, e.BorrowMarginAsset(ctx, "USDT", fixedpoint.NewFromInt(40001)))
+
+	usdt, _ := account.Balance("USDT")
+	assert.Equal(t, "30000", usdt.Available.String())
+	assert.Equal(t, "20000", usdt.Borrowed.String())
+	assert.Equal(t, "10000", usdt.Net().String())
+
+	maxBorrowable, err = e.QueryMarginAssetMaxBorrowable(ctx, "USDT")
+	assert.NoError(t, err)
+	assert.Equal(t, "40000", maxBorrowable.String())
+
+	assert.Error(t, e.RepayMarginAsset(ctx, "USDT", fixedpoint.NewFromInt(20001)))
+	assert.Error(t, e.RepayMarginAsset(ctx, "BTC", fixedpoint.One))
+	assert.NoError(t, e.RepayMarginAsset(ctx, "USDT", fixedpoint.NewFromInt(15000)))
+
+	usdt, _ = account.Balance("USDT")
+	assert.Equal(t, "15000", usdt.Available.String())
+	assert.Equal(t, "5000", usdt.Borrowed.String())
+}
@@ -0,0 +1,89 @@
+package backtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/c9s/rockhopper"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func prepareMarketTradeService(t *testing.T) *service.MarketTradeService {
+	dialect, err := rockhopper.LoadDialect("sqlite3")
+	require.NoError(t, err)
+
+	db, err := rockhopper.Open("sqlite3", dialect, ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	_, err = db.CurrentVersion()
+	require.NoError(t, err)
+
+	var loader rockhopper.SqlMigrationLoader
+	migrations, err := loader.Load("../../migrations/sqlite3")
+	require.NoError(t, err)
+	require.NoError(t, rockhopper.Up(context.Background(), db, migrations, 0, 0))
+
+	return &service.MarketTradeService{DB: sqlx.NewDb(db.DB, "sqlite3")}
+}
+
+func TestExchange_emitMarketTrades(t *testing.T) {
+	marketTradeService := prepareMarketTradeService(t)
+
+	// one trade every 10 minutes in 3 hours
+	startTime := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 18; i++ {
+		err := marketTradeService.Insert(types.Trade{
+			ID:       uint64(i + 1),
+			Exchange: types.ExchangeBinance,
+			Symbol:   "BTCUSDT",
+			Price:    fixedpoint.NewFromInt(40000),
+			Quantity: fixedpoint.One,
+			Side:     types.SideTypeBuy,
+			Time:     types.Time(startTime.Add(time.Duration(i)*10*time.Minute + 30*time.Second)),
+		})
+		require.NoError(t, err)
+	}
+
+	stream := &types.StandardStream{}
+	e := &Exchange{
+		sourceName:         types.ExchangeBinance,
+		MarketDataStream:   stream,
+		MarketTradeService: marketTradeService,
+		marketTradeBuffers: map[string]*marketTradeBuffer{"BTCUSDT": {}},
+	}
+
+	var emitted []types.Trade
+	var current types.KLine
+	stream.OnMarketTrade(func(trade types.Trade) {
+		assert.False(t, trade.Time.Time().Before(current.StartTime.Time()), "trade %d is emitted late", trade.ID)
+		assert.False(t, trade.Time.Time().After(current.EndTime.Time()), "trade %d is emitted early", trade.ID)
+		emitted = append(emitted, trade)
+	})
+
+	for i := 0; i < 180; i++ {
+		kStartTime := startTime.Add(time.Duration(i) * time.Minute)
+		current = types.KLine{
+			Symbol:    "BTCUSDT",
+			Interval:  types.Interval1m,
+			StartTime: types.Time(kStartTime),
+			EndTime:   types.Time(kStartTime.Add(time.Minute - time.Millisecond)),
+		}
+		e.emitMarketTrades(current)
+	}
+
+	if assert.Len(t, emitted, 18) {
+		for i, trade := range emitted {
+			assert.Equal(t, uint64(i+1), trade.ID)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package bbgo
+
+import (
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// BarBuilder builds the tick, volume, dollar and renko bars from the market trades.
+// The bars are emitted in the kline shape with the pseudo interval of the bar type, e.g., "volume:100",
+// so that the existing indicators can consume them.
+//
+// For the tick, volume and dollar bars, the trade that reaches the threshold is included in the closing bar,
+// the bar is not split by the trade.
+//go:generate callbackgen -type BarBuilder
+type BarBuilder struct {
+	Symbol   string
+	Type     types.BarType
+	Size     fixedpoint.Value
+	Interval types.Interval
+
+	bar *aggregatingKLine
+
+	// brickClose is the close price of the last renko brick
+	brickClose fixedpoint.Value
+
+	barClosedCallbacks []func(k types.KLine)
+}
+
+func NewBarBuilder(symbol string, barType types.BarType, size fixedpoint.Value) *BarBuilder {
+	return &BarBuilder{
+		Symbol:   symbol,
+		Type:     barType,
+		Size:     size,
+		Interval: barType.Interval(size),
+	}
+}
+
+func (b *BarBuilder) BindStream(stream types.Stream) {
+	stream.OnMarketTrade(b.AddTrade)
+}
+
+func (b *BarBuilder) AddTrade(trade types.Trade) {
+	if trade.Symbol != b.Symbol || b.Size.Sign() <= 0 {
+		return
+	}
+
+	if trade.QuoteQuantity.IsZero() {
+		trade.QuoteQuantity = trade.Price.Mul(trade.Quantity)
+	}
+
+	if b.bar == nil {
+		b.bar = &aggregatingKLine{
+			KLine: types.KLine{
+				Exchange:  trade.Exchange,
+				Symbol:    trade.Symbol,
+				Interval:  b.Interval,
+				StartTime: trade.Time,
+				Open:      trade.Price,
+				High:      trade.Price,
+				Low:       trade.Price,
+			},
+		}
+
+		if b.Type == types.BarTypeRenko && b.brickClose.IsZero() {
+			b.brickClose = trade.Price
+		}
+	}
+
+	b.bar.pushTrade(trade)
+
+	switch b.Type {
+	case types.BarTypeTick:
+		if fixedpoint.NewFromInt(int64(b.bar.NumberOfTrades)).Compare(b.Size) >= 0 {
+			b.close()
+		}
+
+	case types.BarTypeVolume:
+		if b.bar.Volume.Compare(b.Size) >= 0 {
+			b.close()
+		}
+
+	case types.BarTypeDollar:
+		if b.bar.QuoteVolume.Compare(b.Size) >= 0 {
+			b.close()
+		}
+
+	case types.BarTypeRenko:
+		b.addBricks(trade.Price)
+	}
+}
+
+func (b *BarBuilder) close() {
+	k := b.bar.KLine
+	k.Closed = true
+	b.bar = nil
+	b.EmitBarClosed(k)
+}
+
+// addBricks closes one brick for every brick size the price moves from the close price of the last brick.
+// The open and the close of the bricks are aligned to the brick size, the volume of the pending bar
+// is attributed to the first brick.
+func (b *BarBuilder) addBricks(price fixedpoint.Value) {
+	for {
+		var brickClose fixedpoint.Value
+		if price.Compare(b.brickClose.Add(b.Size)) >= 0 {
+			brickClose = b.brickClose.Add(b.Size)
+		} else if price.Compare(b.brickClose.Sub(b.Size)) <= 0 {
+			brickClose = b.brickClose.Sub(b.Size)
+		} else {
+			return
+		}
+
+		k := b.bar.KLine
+		k.Open = b.brickClose
+		k.Close = brickClose
+		k.High = fixedpoint.Max(k.Open, k.Close)
+		k.Low = fixedpoint.Min(k.Open, k.Close)
+		k.Closed = true
+		b.EmitBarClosed(k)
+
+		b.brickClose = brickClose
+
+		// the following bricks of the same trade carry no volume
+		b.bar.KLine = types.KLine{
+			Exchange:    k.Exchange,
+			Symbol:      k.Symbol,
+			Interval:    k.Interval,
+			StartTime:   k.EndTime,
+			EndTime:     k.EndTime,
+			Open:        price,
+			High:        price,
+			Low:         price,
+			Close:       price,
+			LastTradeID: k.LastTradeID,
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func buildBars(barType types.BarType, size fixedpoint.Value, prices []int64, quantities []float64) []types.KLine {
+	builder := NewBarBuilder("BTCUSDT", barType, size)
+
+	var bars []types.KLine
+	builder.OnBarClosed(func(k types.KLine) {
+		bars = append(bars, k)
+	})
+
+	startTime := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i, price := range prices {
+		builder.AddTrade(types.Trade{
+			ID:       uint64(i + 1),
+			Symbol:   "BTCUSDT",
+			Price:    fixedpoint.NewFromInt(price),
+			Quantity: fixedpoint.NewFromFloat(quantities[i]),
+			Side:     types.SideTypeBuy,
+			Time:     types.Time(startTime.Add(time.Duration(i) * time.Second)),
+		})
+	}
+
+	return bars
+}
+
+func TestBarBuilder_Tick(t *testing.T) {
+	bars := buildBars(types.BarTypeTick, fixedpoint.NewFromInt(3),
+		[]int64{100, 102, 99, 101, 105, 104, 103},
+		[]float64{1, 1, 1, 1, 1, 1, 1})
+
+	if assert.Len(t, bars, 2) {
+		assert.Equal(t, types.Interval("tick:3"), bars[0].Interval)
+		assert.Equal(t, fixedpoint.NewFromInt(100), bars[0].Open)
+		assert.Equal(t, fixedpoint.NewFromInt(102), bars[0].High)
+		assert.Equal(t, fixedpoint.NewFromInt(99), bars[0].Low)
+		assert.Equal(t, fixedpoint.NewFromInt(99), bars[0].Close)
+		assert.Equal(t, uint64(3), bars[0].NumberOfTrades)
+		assert.True(t, bars[0].Closed)
+
+		assert.Equal(t, fixedpoint.NewFromInt(101), bars[1].Open)
+		assert.Equal(t, fixedpoint.NewFromInt(104), bars[1].Close)
+		assert.Equal(t, uint64(6), bars[1].LastTradeID)
+	}
+}
+
+func TestBarBuilder_Volume(t *testing.T) {
+	bars := buildBars(types.BarTypeVolume, fixedpoint.NewFromInt(2),
+		[]int64{100, 101, 102, 103, 104},
+		[]float64{0.5, 1.0, 0.8, 2.5, 0.1})
+
+	if assert.Len(t, bars, 2) {
+		assert.Equal(t, fixedpoint.MustNewFromString("2.3"), bars[0].Volume)
+		assert.Equal(t, fixedpoint.NewFromInt(102), bars[0].Close)
+		assert.Equal(t, fixedpoint.MustNewFromString("2.5"), bars[1].Volume)
+		assert.Equal(t, fixedpoint.NewFromInt(103), bars[1].Open)
+	}
+}
+
+func TestBarBuilder_Dollar(t *testing.T) {
+	bars := buildBars(types.BarTypeDollar, fixedpoint.NewFromInt(250),
+		[]int64{100, 100, 100, 100, 100},
+		[]float64{1, 1, 1, 1, 1})
+
+	if assert.Len(t, bars, 1) {
+		assert.Equal(t, fixedpoint.NewFromInt(300), bars[0].QuoteVolume)
+		assert.Equal(t, uint64(3), bars[0].NumberOfTrades)
+	}
+}
+
+func TestBarBuilder_Renko(t *testing.T) {
+	bars := buildBars(types.BarTypeRenko, fixedpoint.NewFromInt(10),
+		[]int64{100, 105, 112, 131, 125, 109},
+		[]float64{1, 1, 1, 1, 1, 1})
+
+	var closes []fixedpoint.Value
+	for _, k := range bars {
+		closes = append(closes, k.Close)
+	}
+
+	assert.Equal(t, []fixedpoint.Value{
+		fixedpoint.NewFromInt(110),
+		fixedpoint.NewFromInt(120),
+		fixedpoint.NewFromInt(130),
+		fixedpoint.NewFromInt(120),
+		fixedpoint.NewFromInt(110),
+	}, closes)
+
+	if assert.Len(t, bars, 5) {
+		assert.Equal(t, fixedpoint.NewFromInt(100), bars[0].Open)
+		assert.Equal(t, fixedpoint.NewFromInt(3), bars[0].Volume)
+		assert.Equal(t, fixedpoint.Zero, bars[2].Volume)
+		assert.Equal(t, fixedpoint.NewFromInt(130), bars[3].High)
+		assert.Equal(t, fixedpoint.NewFromInt(120), bars[3].Low)
+		assert.Equal(t, fixedpoint.NewFromInt(2), bars[3].Volume)
+	}
+}
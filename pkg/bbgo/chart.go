@@ -0,0 +1,106 @@
+package bbgo
+
+import (
+	"bytes"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/charting"
+	"github.com/c9s/bbgo/pkg/interact"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// ChartOverlayProvider is implemented by the strategies that draw their indicators on the charts,
+// the overlays are used by the /chart command and the backtest chart exports.
+type ChartOverlayProvider interface {
+	ChartOverlays(symbol string, interval types.Interval) []charting.Overlay
+}
+
+// SessionKLines returns the last klines of the symbol from the market data store of the session,
+// all the stored klines are returned if limit is zero.
+func SessionKLines(session *ExchangeSession, symbol string, interval types.Interval, limit int) []types.KLine {
+	store, ok := session.MarketDataStore(symbol)
+	if !ok {
+		return nil
+	}
+
+	window, ok := store.KLinesOfInterval(interval)
+	if !ok || window == nil {
+		return nil
+	}
+
+	if limit <= 0 {
+		limit = len(*window)
+	}
+
+	return window.Tail(limit)
+}
+
+// NewSessionChart builds the chart of the symbol with the trades and the open orders of the session,
+// the overlays and the positions are collected from the given strategies.
+func NewSessionChart(session *ExchangeSession, symbol string, interval types.Interval, klines []types.KLine, strategies ...interface{}) *charting.Chart {
+	c := charting.New(symbol, interval, klines)
+	c.Title = session.Name + " " + c.Title
+
+	for _, strategy := range strategies {
+		if provider, ok := strategy.(ChartOverlayProvider); ok {
+			c.AddOverlays(provider.ChartOverlays(symbol, interval)...)
+		}
+
+		if reader, ok := strategy.(PositionReader); ok {
+			if position := reader.CurrentPosition(); position != nil && position.Symbol == symbol {
+				c.AddPosition(position)
+			}
+		}
+	}
+
+	if trades, ok := session.Trades[symbol]; ok {
+		c.AddTrades(trades.Copy()...)
+	}
+
+	if store, ok := session.OrderStore(symbol); ok {
+		for _, order := range store.Orders() {
+			if order.Status == types.OrderStatusNew || order.Status == types.OrderStatusPartiallyFilled {
+				c.AddOrders(order)
+			}
+		}
+	}
+
+	return c
+}
+
+// SessionChart builds the chart of the symbol with the strategies running on the session,
+// the cross exchange strategies are included as well.
+func (trader *Trader) SessionChart(session *ExchangeSession, symbol string, interval types.Interval, klines []types.KLine) *charting.Chart {
+	var strategies []interface{}
+	for _, strategy := range trader.exchangeStrategies[session.Name] {
+		strategies = append(strategies, strategy)
+	}
+
+	for _, strategy := range trader.crossExchangeStrategies {
+		strategies = append(strategies, strategy)
+	}
+
+	return NewSessionChart(session, symbol, interval, klines, strategies...)
+}
+
+// ChartRenderer is implemented by the charts of the charting package
+type ChartRenderer interface {
+	RenderBuffer() (*bytes.Buffer, error)
+}
+
+// RegisterChartCommand registers the strategy command that renders the chart and sends it as a photo,
+// the chart is built when the command is called.
+func RegisterChartCommand(command, desc string, newChart func() ChartRenderer) *interact.Command {
+	return RegisterCommand(command, desc, func(reply interact.Reply) {
+		buffer, err := newChart().RenderBuffer()
+		if err != nil {
+			log.WithError(err).Errorf("unable to render the chart of %s", command)
+			reply.Message(fmt.Sprintf("[error] unable to render the chart: %v", err))
+			return
+		}
+
+		SendPhoto(buffer)
+	})
+}
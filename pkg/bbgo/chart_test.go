@@ -0,0 +1,109 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/charting"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testChartStrategy struct {
+	position *types.Position
+	sma      *types.Queue
+}
+
+func (s *testChartStrategy) CurrentPosition() *types.Position {
+	return s.position
+}
+
+func (s *testChartStrategy) ChartOverlays(symbol string, interval types.Interval) []charting.Overlay {
+	return []charting.Overlay{{Name: "sma", Series: s.sma}}
+}
+
+func TestNewSessionChart(t *testing.T) {
+	session, market := newOrderEntryTestSession(t)
+	startTime := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	store, _ := session.MarketDataStore(market.Symbol)
+	sma := types.NewQueue(10)
+	for i := 0; i < 10; i++ {
+		price := fixedpoint.NewFromInt(int64(20000 + i*10))
+		store.AddKLine(types.KLine{
+			Symbol:    market.Symbol,
+			Interval:  types.Interval1h,
+			StartTime: types.Time(startTime.Add(time.Duration(i) * time.Hour)),
+			Open:      price,
+			Close:     price.Add(fixedpoint.NewFromInt(5)),
+			High:      price.Add(fixedpoint.NewFromInt(10)),
+			Low:       price.Sub(fixedpoint.NewFromInt(10)),
+			Closed:    true,
+		})
+		sma.Update(price.Float64())
+	}
+
+	session.Trades[market.Symbol] = &types.TradeSlice{Trades: []types.Trade{{
+		Symbol: market.Symbol,
+		Side:   types.SideTypeBuy,
+		Price:  fixedpoint.NewFromInt(20020),
+		Time:   types.Time(startTime.Add(2 * time.Hour)),
+	}}}
+
+	orderStore := NewOrderStore(market.Symbol)
+	orderStore.Add(
+		types.Order{SubmitOrder: types.SubmitOrder{Symbol: market.Symbol, Side: types.SideTypeSell, Price: fixedpoint.NewFromInt(20200)}, OrderID: 1, Status: types.OrderStatusNew},
+		types.Order{SubmitOrder: types.SubmitOrder{Symbol: market.Symbol, Side: types.SideTypeSell, Price: fixedpoint.NewFromInt(20300)}, OrderID: 2, Status: types.OrderStatusFilled},
+	)
+	session.orderStores[market.Symbol] = orderStore
+
+	strategy := &testChartStrategy{
+		position: &types.Position{Symbol: market.Symbol, BaseCurrency: "BTC", Base: fixedpoint.MustNewFromString("0.1"), AverageCost: fixedpoint.NewFromInt(20020)},
+		sma:      sma,
+	}
+
+	klines := SessionKLines(session, market.Symbol, types.Interval1h, 5)
+	assert.Len(t, klines, 5)
+
+	klines = SessionKLines(session, market.Symbol, types.Interval1h, 0)
+	require.Len(t, klines, 10)
+
+	c := NewSessionChart(session, market.Symbol, types.Interval1h, klines, strategy)
+	assert.Equal(t, "binance BTCUSDT 1h", c.Title)
+
+	canvas, err := c.Canvas()
+	require.NoError(t, err)
+
+	var names []string
+	for _, s := range canvas.Series {
+		names = append(names, s.GetName())
+	}
+	assert.Equal(t, []string{"BTCUSDT", "sma", "trades", "open orders", "position 0.1 BTC @ 20020"}, names)
+
+	_, err = c.RenderBuffer()
+	assert.NoError(t, err)
+}
+
+func Test_parseChartArgs(t *testing.T) {
+	req, err := parseChartArgs([]string{"btcusdt"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, chartRequest{symbol: "BTCUSDT", interval: types.Interval1h, limit: defaultChartLimit}, req)
+	}
+
+	req, err = parseChartArgs([]string{"max:ETHUSDT", "4h", "200"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, chartRequest{session: "max", symbol: "ETHUSDT", interval: types.Interval4h, limit: 200}, req)
+	}
+
+	_, err = parseChartArgs([]string{"BTCUSDT", "7h"})
+	assert.EqualError(t, err, `unsupported interval "7h"`)
+
+	_, err = parseChartArgs([]string{"BTCUSDT", "1h", "5000"})
+	assert.EqualError(t, err, `invalid limit "5000", the limit should be between 2 and 1000`)
+
+	_, err = parseChartArgs([]string{"BTCUSDT", "1h", "100", "extra"})
+	assert.EqualError(t, err, "too many arguments: extra")
+}
@@ -0,0 +1,448 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
+
+	"github.com/c9s/bbgo/pkg/dynamic"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrCircuitBreakerTripped = errors.New("circuit breaker tripped")
+
+type CircuitBreakerAction string
+
+const (
+	// CircuitBreakerActionSuspend suspends the strategies
+	CircuitBreakerActionSuspend CircuitBreakerAction = "suspend"
+
+	// CircuitBreakerActionFlatten closes the positions of the strategies and then suspends the strategies
+	CircuitBreakerActionFlatten CircuitBreakerAction = "flatten"
+)
+
+// CircuitBreakerLimit defines when the circuit breaker trips, the zero limits are disabled.
+type CircuitBreakerLimit struct {
+	// MaxDailyLoss is the max loss of the day (realized + unrealized) in the valuation currency
+	MaxDailyLoss fixedpoint.Value `json:"maxDailyLoss,omitempty" yaml:"maxDailyLoss,omitempty"`
+
+	// MaxDrawdown is the max peak-to-trough drawdown of the equity (accumulated realized + unrealized) in the valuation currency
+	MaxDrawdown fixedpoint.Value `json:"maxDrawdown,omitempty" yaml:"maxDrawdown,omitempty"`
+
+	// Action is the action to take when the circuit breaker trips, default: suspend
+	Action CircuitBreakerAction `json:"action,omitempty" yaml:"action,omitempty"`
+
+	// CoolDown is the period before the circuit breaker is reset automatically,
+	// the tripped circuit breaker can only be reset by /resetbreaker if it's not set.
+	CoolDown types.Duration `json:"coolDown,omitempty" yaml:"coolDown,omitempty"`
+}
+
+type CircuitBreakerConfig struct {
+	// ValuationCurrency is the currency of the loss and the drawdown values, default: USDT
+	ValuationCurrency string `json:"valuationCurrency,omitempty" yaml:"valuationCurrency,omitempty"`
+
+	// Strategy is the limit applied to each strategy
+	Strategy *CircuitBreakerLimit `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+
+	// Strategies are the limits of the strategies by the strategy instance ID, which override the Strategy limit
+	Strategies map[string]CircuitBreakerLimit `json:"strategies,omitempty" yaml:"strategies,omitempty"`
+
+	// Sessions are the limits of the strategies on each session
+	Sessions map[string]CircuitBreakerLimit `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+
+	// Global is the limit of all the strategies
+	Global *CircuitBreakerLimit `json:"global,omitempty" yaml:"global,omitempty"`
+}
+
+type circuitBreakerStrategy struct {
+	session   *ExchangeSession
+	signature string
+	strategy  SingleExchangeStrategy
+
+	// suspended is true when the strategy is suspended by the circuit breaker,
+	// the strategies suspended by the operator are not resumed when the circuit breaker is reset.
+	suspended bool
+}
+
+// circuitBreakerScope tracks the equity of a group of strategies
+type circuitBreakerScope struct {
+	name       string
+	limit      CircuitBreakerLimit
+	strategies []*circuitBreakerStrategy
+
+	initialized    bool
+	day            time.Time
+	dayStartEquity fixedpoint.Value
+	peakEquity     fixedpoint.Value
+
+	tripped   bool
+	trippedAt time.Time
+	reason    string
+}
+
+// CircuitBreaker suspends or flattens the strategies when the daily loss or the drawdown of
+// a strategy, a session or all strategies exceeds the limit.
+// While a scope is tripped, the general order executors of its strategies only submit the orders that reduce the position.
+//go:generate callbackgen -type CircuitBreaker
+type CircuitBreaker struct {
+	Config *CircuitBreakerConfig
+
+	mu sync.Mutex
+
+	// scopes: scope name -> scope
+	scopes map[string]*circuitBreakerScope
+
+	lastEvaluatedAt time.Time
+
+	trippedCallbacks []func(scope, reason string)
+	resetCallbacks   []func(scope string)
+}
+
+func NewCircuitBreaker(config *CircuitBreakerConfig) *CircuitBreaker {
+	if config.ValuationCurrency == "" {
+		config.ValuationCurrency = "USDT"
+	}
+
+	return &CircuitBreaker{
+		Config: config,
+		scopes: make(map[string]*circuitBreakerScope),
+	}
+}
+
+func strategyScopeName(sessionName, signature string) string {
+	return "strategy:" + sessionName + "." + signature
+}
+
+func sessionScopeName(sessionName string) string {
+	return "session:" + sessionName
+}
+
+const globalScopeName = "global"
+
+// AddStrategy adds the strategy into the strategy scope, the session scope and the global scope
+func (b *CircuitBreaker) AddStrategy(session *ExchangeSession, strategy SingleExchangeStrategy) error {
+	signature, err := getStrategySignature(strategy)
+	if err != nil {
+		return err
+	}
+
+	s := &circuitBreakerStrategy{session: session, signature: signature, strategy: strategy}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if limit, ok := b.Config.Strategies[signature]; ok {
+		b.addToScope(strategyScopeName(session.Name, signature), limit, s)
+	} else if b.Config.Strategy != nil {
+		b.addToScope(strategyScopeName(session.Name, signature), *b.Config.Strategy, s)
+	}
+
+	if limit, ok := b.Config.Sessions[session.Name]; ok {
+		b.addToScope(sessionScopeName(session.Name), limit, s)
+	}
+
+	if b.Config.Global != nil {
+		b.addToScope(globalScopeName, *b.Config.Global, s)
+	}
+
+	return nil
+}
+
+func (b *CircuitBreaker) addToScope(name string, limit CircuitBreakerLimit, s *circuitBreakerStrategy) {
+	scope, ok := b.scopes[name]
+	if !ok {
+		if limit.Action == "" {
+			limit.Action = CircuitBreakerActionSuspend
+		}
+
+		scope = &circuitBreakerScope{name: name, limit: limit}
+		b.scopes[name] = scope
+	}
+
+	scope.strategies = append(scope.strategies, s)
+}
+
+// BindSession evaluates the circuit breaker on the closed klines of the session
+func (b *CircuitBreaker) BindSession(ctx context.Context, session *ExchangeSession) {
+	session.MarketDataStream.OnKLineClosed(func(k types.KLine) {
+		now := sessionTime(session)
+
+		b.mu.Lock()
+		if now.Sub(b.lastEvaluatedAt) < time.Minute {
+			b.mu.Unlock()
+			return
+		}
+		b.lastEvaluatedAt = now
+		b.mu.Unlock()
+
+		b.Evaluate(ctx, now)
+	})
+}
+
+// Tripped returns true if any scope of the strategy on the session is tripped
+func (b *CircuitBreaker) Tripped(sessionName, signature string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, name := range []string{strategyScopeName(sessionName, signature), sessionScopeName(sessionName), globalScopeName} {
+		if scope, ok := b.scopes[name]; ok && scope.tripped {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TrippedScopes returns the names of the tripped scopes
+func (b *CircuitBreaker) TrippedScopes() (names []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for name, scope := range b.scopes {
+		if scope.tripped {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Evaluate updates the equity of the scopes and trips the scopes that exceed the limits,
+// the tripped scopes are reset after the cool-down period.
+func (b *CircuitBreaker) Evaluate(ctx context.Context, now time.Time) {
+	var tripped, reset []*circuitBreakerScope
+
+	b.mu.Lock()
+	for _, scope := range b.scopes {
+		equity, todayPnL := b.equity(scope)
+
+		day := now.UTC().Truncate(24 * time.Hour)
+		if !scope.initialized {
+			scope.initialized = true
+			scope.day = day
+			scope.dayStartEquity = equity.Sub(todayPnL)
+			scope.peakEquity = equity
+		}
+
+		if day.After(scope.day) {
+			scope.day = day
+			scope.dayStartEquity = equity
+		}
+
+		scope.peakEquity = fixedpoint.Max(scope.peakEquity, equity)
+
+		if scope.tripped {
+			if coolDown := scope.limit.CoolDown; coolDown > 0 && !now.Before(scope.trippedAt.Add(coolDown.Duration())) {
+				scope.reset(equity)
+				reset = append(reset, scope)
+			}
+			continue
+		}
+
+		if c := scope.limit.MaxDailyLoss; c.Sign() > 0 {
+			if loss := scope.dayStartEquity.Sub(equity); loss.Compare(c) > 0 {
+				scope.trip(now, fmt.Sprintf("daily loss %s exceeds the limit %s %s", loss.String(), c.String(), b.Config.ValuationCurrency))
+				tripped = append(tripped, scope)
+				continue
+			}
+		}
+
+		if c := scope.limit.MaxDrawdown; c.Sign() > 0 {
+			if drawdown := scope.peakEquity.Sub(equity); drawdown.Compare(c) > 0 {
+				scope.trip(now, fmt.Sprintf("drawdown %s exceeds the limit %s %s", drawdown.String(), c.String(), b.Config.ValuationCurrency))
+				tripped = append(tripped, scope)
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	// the actions are taken outside the lock because the orders submitted by the actions check Tripped()
+	for _, scope := range tripped {
+		b.tripScope(ctx, scope)
+	}
+
+	for _, scope := range reset {
+		b.resetScope(scope)
+	}
+}
+
+// Reset resets the tripped scope and resumes the strategies suspended by the circuit breaker
+func (b *CircuitBreaker) Reset(name string) error {
+	b.mu.Lock()
+	scope, ok := b.scopes[name]
+	if !ok {
+		b.mu.Unlock()
+		return fmt.Errorf("circuit breaker scope %s not found", name)
+	}
+
+	if !scope.tripped {
+		b.mu.Unlock()
+		return fmt.Errorf("circuit breaker scope %s is not tripped", name)
+	}
+
+	equity, _ := b.equity(scope)
+	scope.reset(equity)
+	b.mu.Unlock()
+
+	b.resetScope(scope)
+	return nil
+}
+
+func (b *CircuitBreaker) tripScope(ctx context.Context, scope *circuitBreakerScope) {
+	log.Warnf("circuit breaker %s tripped: %s", scope.name, scope.reason)
+	NotifyWithSeverity(SeverityCritical, ":rotating_light: circuit breaker %s tripped: %s, action: %s", scope.name, scope.reason, scope.limit.Action)
+
+	for _, s := range scope.strategies {
+		if scope.limit.Action == CircuitBreakerActionFlatten {
+			if closer, ok := s.strategy.(PositionCloser); ok {
+				if err := closer.ClosePosition(ctx, fixedpoint.One); err != nil {
+					log.WithError(err).Errorf("circuit breaker %s can not close the position of %s", scope.name, s.signature)
+				}
+			}
+		}
+
+		if toggler, ok := s.strategy.(StrategyToggler); ok && toggler.GetStatus() != types.StrategyStatusStopped {
+			if err := toggler.Suspend(); err != nil {
+				log.WithError(err).Errorf("circuit breaker %s can not suspend %s", scope.name, s.signature)
+				continue
+			}
+
+			b.mu.Lock()
+			s.suspended = true
+			b.mu.Unlock()
+		}
+	}
+
+	b.EmitTripped(scope.name, scope.reason)
+}
+
+func (b *CircuitBreaker) resetScope(scope *circuitBreakerScope) {
+	log.Infof("circuit breaker %s is reset", scope.name)
+	Notify(":white_check_mark: circuit breaker %s is reset", scope.name)
+
+	for _, s := range scope.strategies {
+		// the strategy is still held by the other tripped scopes
+		if b.Tripped(s.session.Name, s.signature) {
+			continue
+		}
+
+		b.mu.Lock()
+		suspended := s.suspended
+		s.suspended = false
+		b.mu.Unlock()
+
+		if !suspended {
+			continue
+		}
+
+		if toggler, ok := s.strategy.(StrategyToggler); ok && toggler.GetStatus() == types.StrategyStatusStopped {
+			if err := toggler.Resume(); err != nil {
+				log.WithError(err).Errorf("circuit breaker %s can not resume %s", scope.name, s.signature)
+			}
+		}
+	}
+
+	b.EmitReset(scope.name)
+}
+
+func (scope *circuitBreakerScope) trip(now time.Time, reason string) {
+	scope.tripped = true
+	scope.trippedAt = now
+	scope.reason = reason
+}
+
+func (scope *circuitBreakerScope) reset(equity fixedpoint.Value) {
+	scope.tripped = false
+	scope.reason = ""
+	scope.dayStartEquity = equity
+	scope.peakEquity = equity
+}
+
+// equity returns the equity and today's realized pnl of the scope in the valuation currency
+func (b *CircuitBreaker) equity(scope *circuitBreakerScope) (equity, todayPnL fixedpoint.Value) {
+	for _, s := range scope.strategies {
+		e, t := b.strategyEquity(s)
+		equity = equity.Add(e)
+		todayPnL = todayPnL.Add(t)
+	}
+	return equity, todayPnL
+}
+
+// strategyEquity returns the accumulated pnl + the unrealized pnl and today's realized pnl of the strategy,
+// which are looked up from the *types.ProfitStats and the *types.Position fields of the strategy.
+func (b *CircuitBreaker) strategyEquity(s *circuitBreakerStrategy) (equity, todayPnL fixedpoint.Value) {
+	var position *types.Position
+	var profitStats *types.ProfitStats
+
+	if reader, ok := s.strategy.(PositionReader); ok {
+		position = reader.CurrentPosition()
+	}
+
+	_ = dynamic.IterateFields(s.strategy, func(ft reflect.StructField, fv reflect.Value) error {
+		if fv.Kind() != reflect.Ptr || fv.IsNil() {
+			return nil
+		}
+
+		switch v := fv.Interface().(type) {
+		case *types.Position:
+			if position == nil {
+				position = v
+			}
+		case *types.ProfitStats:
+			if profitStats == nil {
+				profitStats = v
+			}
+		}
+		return nil
+	})
+
+	var quoteCurrency string
+	if profitStats != nil {
+		quoteCurrency = profitStats.QuoteCurrency
+		equity = profitStats.AccumulatedPnL
+		todayPnL = profitStats.TodayPnL
+	}
+
+	if position != nil {
+		quoteCurrency = position.QuoteCurrency
+		if price, ok := s.session.LastPrice(position.Symbol); ok && !position.GetBase().IsZero() {
+			equity = equity.Add(position.UnrealizedProfit(price))
+		}
+	}
+
+	if quoteCurrency == "" {
+		return fixedpoint.Zero, fixedpoint.Zero
+	}
+
+	price, ok := valuationPrice(s.session, quoteCurrency, b.Config.ValuationCurrency)
+	if !ok {
+		return fixedpoint.Zero, fixedpoint.Zero
+	}
+
+	return equity.Mul(price), todayPnL.Mul(price)
+}
+
+// filterReduceOrders returns the orders that reduce the position
+func filterReduceOrders(position *types.Position, orders []types.SubmitOrder) (reduced []types.SubmitOrder, err error) {
+	for _, order := range orders {
+		if order.ReduceOnly || order.ClosePosition ||
+			(position.IsLong() && order.Side == types.SideTypeSell) ||
+			(position.IsShort() && order.Side == types.SideTypeBuy) {
+			reduced = append(reduced, order)
+			continue
+		}
+
+		err = multierr.Append(err, fmt.Errorf("%w: order %s is not submitted", ErrCircuitBreakerTripped, order.String()))
+	}
+
+	return reduced, err
+}
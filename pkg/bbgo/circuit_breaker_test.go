@@ -0,0 +1,193 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type circuitBreakerTestStrategy struct {
+	*StrategyController
+
+	Symbol      string
+	Position    *types.Position
+	ProfitStats *types.ProfitStats
+
+	closed int
+}
+
+func (s *circuitBreakerTestStrategy) ID() string {
+	return "test"
+}
+
+func (s *circuitBreakerTestStrategy) InstanceID() string {
+	return "test:" + s.Symbol
+}
+
+func (s *circuitBreakerTestStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	return nil
+}
+
+func (s *circuitBreakerTestStrategy) ClosePosition(ctx context.Context, percentage fixedpoint.Value) error {
+	s.closed++
+	s.Position.Reset()
+	return nil
+}
+
+func newCircuitBreakerTestStrategy(market types.Market, base, cost string) *circuitBreakerTestStrategy {
+	position := types.NewPositionFromMarket(market)
+	position.Base = fixedpoint.MustNewFromString(base)
+	position.AverageCost = fixedpoint.MustNewFromString(cost)
+
+	return &circuitBreakerTestStrategy{
+		StrategyController: &StrategyController{Status: types.StrategyStatusRunning},
+		Symbol:             market.Symbol,
+		Position:           position,
+		ProfitStats:        types.NewProfitStats(market),
+	}
+}
+
+func TestCircuitBreaker_DailyLoss(t *testing.T) {
+	session := newRiskTestSession(t, "binance")
+	market, _ := session.Market("BTCUSDT")
+	strategy := newCircuitBreakerTestStrategy(market, "1", "20000")
+
+	breaker := NewCircuitBreaker(&CircuitBreakerConfig{
+		Strategy: &CircuitBreakerLimit{
+			MaxDailyLoss: fixedpoint.NewFromInt(1000),
+			CoolDown:     types.Duration(time.Hour),
+		},
+	})
+	assert.NoError(t, breaker.AddStrategy(session, strategy))
+
+	var trippedScope string
+	breaker.OnTripped(func(scope, reason string) {
+		trippedScope = scope
+	})
+
+	t0 := time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC)
+	breaker.Evaluate(context.Background(), t0)
+	assert.False(t, breaker.Tripped("binance", "test:BTCUSDT"))
+
+	session.lastPrices["BTCUSDT"] = fixedpoint.NewFromInt(19500)
+	breaker.Evaluate(context.Background(), t0.Add(time.Minute))
+	assert.False(t, breaker.Tripped("binance", "test:BTCUSDT"))
+
+	// realized loss 600 + unrealized loss 500
+	strategy.ProfitStats.AccumulatedPnL = fixedpoint.NewFromInt(-600)
+	breaker.Evaluate(context.Background(), t0.Add(2*time.Minute))
+	assert.True(t, breaker.Tripped("binance", "test:BTCUSDT"))
+	assert.False(t, breaker.Tripped("binance", "test:ETHBTC"))
+	assert.Equal(t, "strategy:binance.test:BTCUSDT", trippedScope)
+	assert.Equal(t, []string{"strategy:binance.test:BTCUSDT"}, breaker.TrippedScopes())
+	assert.Equal(t, types.StrategyStatusStopped, strategy.GetStatus())
+	assert.Equal(t, 0, strategy.closed)
+
+	// still in the cool-down period
+	breaker.Evaluate(context.Background(), t0.Add(30*time.Minute))
+	assert.True(t, breaker.Tripped("binance", "test:BTCUSDT"))
+
+	breaker.Evaluate(context.Background(), t0.Add(62*time.Minute))
+	assert.False(t, breaker.Tripped("binance", "test:BTCUSDT"))
+	assert.Equal(t, types.StrategyStatusRunning, strategy.GetStatus())
+
+	// the loss before the reset is not counted again
+	breaker.Evaluate(context.Background(), t0.Add(63*time.Minute))
+	assert.False(t, breaker.Tripped("binance", "test:BTCUSDT"))
+}
+
+func TestCircuitBreaker_DrawdownFlatten(t *testing.T) {
+	session := newRiskTestSession(t, "binance")
+	market, _ := session.Market("BTCUSDT")
+	strategy := newCircuitBreakerTestStrategy(market, "1", "20000")
+
+	breaker := NewCircuitBreaker(&CircuitBreakerConfig{
+		Global: &CircuitBreakerLimit{
+			MaxDrawdown: fixedpoint.NewFromInt(2000),
+			Action:      CircuitBreakerActionFlatten,
+		},
+	})
+	assert.NoError(t, breaker.AddStrategy(session, strategy))
+
+	t0 := time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC)
+	breaker.Evaluate(context.Background(), t0)
+
+	// the peak equity is 3000
+	session.lastPrices["BTCUSDT"] = fixedpoint.NewFromInt(23000)
+	breaker.Evaluate(context.Background(), t0.Add(time.Minute))
+
+	// the drawdown is 2500
+	session.lastPrices["BTCUSDT"] = fixedpoint.NewFromInt(20500)
+	breaker.Evaluate(context.Background(), t0.Add(2*time.Minute))
+	assert.True(t, breaker.Tripped("binance", "test:BTCUSDT"))
+	assert.Equal(t, 1, strategy.closed)
+	assert.Equal(t, types.StrategyStatusStopped, strategy.GetStatus())
+
+	// no cool-down, manual reset only
+	breaker.Evaluate(context.Background(), t0.Add(24*time.Hour))
+	assert.True(t, breaker.Tripped("binance", "test:BTCUSDT"))
+
+	assert.Error(t, breaker.Reset("session:binance"))
+	assert.NoError(t, breaker.Reset(globalScopeName))
+	assert.False(t, breaker.Tripped("binance", "test:BTCUSDT"))
+	assert.Equal(t, types.StrategyStatusRunning, strategy.GetStatus())
+	assert.Error(t, breaker.Reset(globalScopeName))
+}
+
+func TestCircuitBreaker_ResetKeepsManualSuspension(t *testing.T) {
+	session := newRiskTestSession(t, "binance")
+	market, _ := session.Market("BTCUSDT")
+	running := newCircuitBreakerTestStrategy(market, "1", "20000")
+	suspended := newCircuitBreakerTestStrategy(market, "1", "20000")
+	suspended.Symbol = "BTCUSDT2"
+
+	breaker := NewCircuitBreaker(&CircuitBreakerConfig{
+		Global: &CircuitBreakerLimit{MaxDrawdown: fixedpoint.NewFromInt(1000)},
+	})
+	assert.NoError(t, breaker.AddStrategy(session, running))
+	assert.NoError(t, breaker.AddStrategy(session, suspended))
+
+	// suspended by the operator with /suspend
+	assert.NoError(t, suspended.Suspend())
+
+	t0 := time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC)
+	breaker.Evaluate(context.Background(), t0)
+
+	session.lastPrices["BTCUSDT"] = fixedpoint.NewFromInt(19000)
+	breaker.Evaluate(context.Background(), t0.Add(time.Minute))
+	assert.True(t, breaker.Tripped("binance", "test:BTCUSDT"))
+	assert.Equal(t, types.StrategyStatusStopped, running.GetStatus())
+
+	assert.NoError(t, breaker.Reset(globalScopeName))
+	assert.Equal(t, types.StrategyStatusRunning, running.GetStatus())
+	assert.Equal(t, types.StrategyStatusStopped, suspended.GetStatus(), "the manual suspension is kept")
+}
+
+func TestFilterReduceOrders(t *testing.T) {
+	position := types.NewPositionFromMarket(types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"})
+	position.Base = fixedpoint.One
+
+	orders, err := filterReduceOrders(position, []types.SubmitOrder{
+		newRiskTestOrder("BTCUSDT", types.SideTypeBuy, "0.1", "20000"),
+		newRiskTestOrder("BTCUSDT", types.SideTypeSell, "0.1", "21000"),
+	})
+	assert.True(t, errors.Is(err, ErrCircuitBreakerTripped))
+	if assert.Len(t, orders, 1) {
+		assert.Equal(t, types.SideTypeSell, orders[0].Side)
+	}
+}
+
+func TestCircuitBreakerLimit_CoolDown(t *testing.T) {
+	var limit CircuitBreakerLimit
+	err := yaml.Unmarshal([]byte("maxDailyLoss: 500\ncoolDown: 30m\n"), &limit)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 30*time.Minute, limit.CoolDown.Duration())
+	}
+}
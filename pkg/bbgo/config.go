@@ -15,6 +15,7 @@ import (
 	"github.com/c9s/bbgo/pkg/datatype"
 	"github.com/c9s/bbgo/pkg/dynamic"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/interact"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/types"
 )
@@ -76,15 +77,67 @@ type TelegramNotification struct {
 	Broadcast bool `json:"broadcast" yaml:"broadcast"`
 }
 
+// WebhookNotification posts the notifications to the HTTP endpoint,
+// the environment variables in the url and the secret are expanded, e.g., ${WEBHOOK_SECRET}
+type WebhookNotification struct {
+	URL    string `json:"url" yaml:"url"`
+	Secret string `json:"secret,omitempty" yaml:"secret,omitempty"`
+
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+
+	// Fields are the text/template strings of the JSON payload fields, the default payload is sent if it's empty
+	Fields map[string]string `json:"fields,omitempty" yaml:"fields,omitempty"`
+
+	// Channels routes the notification channels to the URLs
+	Channels map[string]string `json:"channels,omitempty" yaml:"channels,omitempty"`
+
+	MaxRetries *int `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty"`
+}
+
+// DiscordNotification sends the notifications through the discord channel webhooks,
+// the webhook url is read from DISCORD_WEBHOOK_URL if it's not set.
+type DiscordNotification struct {
+	WebhookURL string `json:"webhookURL,omitempty" yaml:"webhookURL,omitempty"`
+	Username   string `json:"username,omitempty" yaml:"username,omitempty"`
+
+	// Channels routes the notification channels to the channel webhook URLs
+	Channels map[string]string `json:"channels,omitempty" yaml:"channels,omitempty"`
+}
+
 type NotificationConfig struct {
 	Slack *SlackNotification `json:"slack,omitempty" yaml:"slack,omitempty"`
 
 	Telegram *TelegramNotification `json:"telegram,omitempty" yaml:"telegram,omitempty"`
 
+	Webhooks []WebhookNotification `json:"webhooks,omitempty" yaml:"webhooks,omitempty"`
+
+	Discord *DiscordNotification `json:"discord,omitempty" yaml:"discord,omitempty"`
+
 	SymbolChannels  map[string]string `json:"symbolChannels,omitempty" yaml:"symbolChannels,omitempty"`
 	SessionChannels map[string]string `json:"sessionChannels,omitempty" yaml:"sessionChannels,omitempty"`
 
 	Routing *SlackNotificationRouting `json:"routing,omitempty" yaml:"routing,omitempty"`
+
+	// MinSeverity filters out the notifications below the severity, the errors are warn and the others are info by default
+	MinSeverity NotificationSeverity `json:"minSeverity,omitempty" yaml:"minSeverity,omitempty"`
+
+	// RateLimit is the default rate limit of a channel, the critical notifications are not limited
+	RateLimit *NotificationRateLimit `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
+
+	ChannelRateLimits map[string]NotificationRateLimit `json:"channelRateLimits,omitempty" yaml:"channelRateLimits,omitempty"`
+
+	Digest *NotificationDigest `json:"digest,omitempty" yaml:"digest,omitempty"`
+
+	// DedupWindow suppresses the repeated text messages and errors in the window
+	DedupWindow types.Duration `json:"dedupWindow,omitempty" yaml:"dedupWindow,omitempty"`
+
+	QuietHours *QuietHours `json:"quietHours,omitempty" yaml:"quietHours,omitempty"`
+}
+
+// HasThrottle returns true if any of the throttle settings is configured
+func (c *NotificationConfig) HasThrottle() bool {
+	return c.MinSeverity > SeverityDebug || c.RateLimit != nil || len(c.ChannelRateLimits) > 0 ||
+		c.Digest != nil || c.DedupWindow > 0 || c.QuietHours != nil
 }
 
 type Session struct {
@@ -201,6 +254,26 @@ func (m BacktestAccountBalanceMap) BalanceMap() types.BalanceMap {
 	return balances
 }
 
+// InteractionToken is an additional authentication token of the interactive commands,
+// the users authorized by this token get the given role.
+type InteractionToken struct {
+	Token string        `json:"token" yaml:"token"`
+	Role  interact.Role `json:"role" yaml:"role"`
+}
+
+// InteractionConfig configures the role-based authorization of the interactive commands
+type InteractionConfig struct {
+	// Tokens are the additional authentication tokens mapped to the roles,
+	// the token from TELEGRAM_BOT_AUTH_TOKEN (or the one-time password) always gets the admin role.
+	Tokens []InteractionToken `json:"tokens,omitempty" yaml:"tokens,omitempty"`
+
+	// CommandRoles overrides the minimal roles required to execute the commands
+	CommandRoles map[string]interact.Role `json:"commandRoles,omitempty" yaml:"commandRoles,omitempty"`
+
+	// Confirmations overrides whether the commands need the confirmation before executing them
+	Confirmations map[string]bool `json:"confirmations,omitempty" yaml:"confirmations,omitempty"`
+}
+
 type PersistenceConfig struct {
 	Redis *service.RedisPersistenceConfig `json:"redis,omitempty" yaml:"redis,omitempty"`
 	Json  *service.JsonPersistenceConfig  `json:"json,omitempty" yaml:"json,omitempty"`
@@ -300,6 +373,125 @@ type SyncConfig struct {
 		Trades       bool `json:"trades,omitempty" yaml:"trades,omitempty"`
 		FilledOrders bool `json:"filledOrders,omitempty" yaml:"filledOrders,omitempty"`
 	} `json:"userDataStream,omitempty" yaml:"userDataStream,omitempty"`
+
+	// MarketDataStream is for real-time sync with websocket market data stream,
+	// the stored market trades are used to build the trade-based bars in backtest.
+	MarketDataStream *struct {
+		MarketTrades bool `json:"marketTrades,omitempty" yaml:"marketTrades,omitempty"`
+	} `json:"marketDataStream,omitempty" yaml:"marketDataStream,omitempty"`
+}
+
+// RecordConfig is the config of the market data recorder (bbgo record)
+type RecordConfig struct {
+	// Directory is where the record files are written, default: data/record
+	Directory string `json:"directory,omitempty" yaml:"directory,omitempty"`
+
+	// Symbols is the list of session:symbol pair to record
+	// Valid formats are: {session}:{symbol} or in YAML object form {symbol: "BTCUSDT", session:"max" }
+	Symbols []SyncSymbol `json:"symbols" yaml:"symbols"`
+
+	// Channels are the market data channels to record: trade, bookticker and book, default: all
+	Channels []types.Channel `json:"channels,omitempty" yaml:"channels,omitempty"`
+
+	// Depth is the depth of the book channel
+	Depth types.Depth `json:"depth,omitempty" yaml:"depth,omitempty"`
+
+	// Rotation is the time span of each record file, default: 1h
+	Rotation types.Interval `json:"rotation,omitempty" yaml:"rotation,omitempty"`
+
+	// Database inserts the market trades into the database too, so that they can be replayed in backtest
+	Database bool `json:"database,omitempty" yaml:"database,omitempty"`
+}
+
+// SessionSymbols returns the symbols to record of each session,
+// the symbols without the session name are recorded on all the given sessions.
+func (c *RecordConfig) SessionSymbols(sessionNames []string) map[string][]string {
+	sessionSymbols, rest := categorizeSyncSymbol(c.Symbols)
+	if len(rest) == 0 {
+		return sessionSymbols
+	}
+
+	for _, sessionName := range sessionNames {
+		sessionSymbols[sessionName] = append(sessionSymbols[sessionName], rest...)
+	}
+
+	return sessionSymbols
+}
+
+type AlertCondition string
+
+const (
+	// AlertConditionCross triggers when the price crosses the price level in either direction
+	AlertConditionCross AlertCondition = "cross"
+	// AlertConditionAbove triggers when the price is above or equal to the price level
+	AlertConditionAbove AlertCondition = "above"
+	// AlertConditionBelow triggers when the price is below or equal to the price level
+	AlertConditionBelow AlertCondition = "below"
+	// AlertConditionMove triggers when the price moves by the percentage within the window
+	AlertConditionMove AlertCondition = "move"
+	// AlertConditionVolume triggers when the volume of the last kline exceeds the average volume times the multiplier
+	AlertConditionVolume AlertCondition = "volume"
+	// AlertConditionIndicator triggers when the indicator expression is true on the closed kline
+	AlertConditionIndicator AlertCondition = "indicator"
+	// AlertConditionFunding triggers when the funding rate reaches the threshold
+	AlertConditionFunding AlertCondition = "funding"
+)
+
+// AlertRule defines the condition of an alert
+type AlertRule struct {
+	// ID is the identifier of the alert, generated if it's not given
+	ID string `json:"id,omitempty" yaml:"id,omitempty"`
+
+	// Session is the exchange session for querying the market data, default: the session of the alert config
+	Session string `json:"session,omitempty" yaml:"session,omitempty"`
+
+	Symbol string `json:"symbol" yaml:"symbol"`
+
+	Condition AlertCondition `json:"condition" yaml:"condition"`
+
+	// Price is the price level of the cross, above and below conditions
+	Price fixedpoint.Value `json:"price,omitempty" yaml:"price,omitempty"`
+
+	// Percentage is the minimal price change of the move condition, e.g. 5%
+	Percentage fixedpoint.Value `json:"percentage,omitempty" yaml:"percentage,omitempty"`
+
+	// Interval is the kline interval of the move, volume and indicator conditions, default: 1h
+	Interval types.Interval `json:"interval,omitempty" yaml:"interval,omitempty"`
+
+	// Window is the number of klines of the move condition (default: 1) and the volume condition (default: 20)
+	Window int `json:"window,omitempty" yaml:"window,omitempty"`
+
+	// Multiplier is the volume multiplier of the volume condition, default: 3
+	Multiplier fixedpoint.Value `json:"multiplier,omitempty" yaml:"multiplier,omitempty"`
+
+	// Expression is the condition of the indicator alert, e.g. "rsi(close, 14) > 70"
+	Expression string `json:"expression,omitempty" yaml:"expression,omitempty"`
+
+	// FundingRate is the funding rate threshold, a negative threshold triggers when the funding rate is lower than it
+	FundingRate fixedpoint.Value `json:"fundingRate,omitempty" yaml:"fundingRate,omitempty"`
+
+	// Recurring alerts are not disabled after they are triggered
+	Recurring bool `json:"recurring,omitempty" yaml:"recurring,omitempty"`
+
+	// Cooldown is the minimal duration between two notifications of a recurring alert, default: 15m
+	Cooldown types.Duration `json:"cooldown,omitempty" yaml:"cooldown,omitempty"`
+
+	// Channel is the notification channel, default: the channel of the alert config or the symbol channel
+	Channel string `json:"channel,omitempty" yaml:"channel,omitempty"`
+}
+
+// AlertConfig is the config of the alert engine, which runs independently of the strategies
+type AlertConfig struct {
+	// Session is the default exchange session of the alerts, default: the first session
+	Session string `json:"session,omitempty" yaml:"session,omitempty"`
+
+	// CheckInterval is how often the alert conditions are checked, default: 1m
+	CheckInterval types.Duration `json:"checkInterval,omitempty" yaml:"checkInterval,omitempty"`
+
+	// Channel is the default notification channel of the alerts
+	Channel string `json:"channel,omitempty" yaml:"channel,omitempty"`
+
+	Rules []AlertRule `json:"rules,omitempty" yaml:"rules,omitempty"`
 }
 
 type Config struct {
@@ -313,8 +505,12 @@ type Config struct {
 
 	Sync *SyncConfig `json:"sync,omitempty" yaml:"sync,omitempty"`
 
+	Record *RecordConfig `json:"record,omitempty" yaml:"record,omitempty"`
+
 	Notifications *NotificationConfig `json:"notifications,omitempty" yaml:"notifications,omitempty"`
 
+	Interaction *InteractionConfig `json:"interaction,omitempty" yaml:"interaction,omitempty"`
+
 	Persistence *PersistenceConfig `json:"persistence,omitempty" yaml:"persistence,omitempty"`
 
 	Sessions map[string]*ExchangeSession `json:"sessions,omitempty" yaml:"sessions,omitempty"`
@@ -325,6 +521,10 @@ type Config struct {
 	CrossExchangeStrategies []CrossExchangeStrategy `json:"-" yaml:"-"`
 
 	PnLReporters []PnLReporterConfig `json:"reportPnL,omitempty" yaml:"reportPnL,omitempty"`
+
+	Reports []ScheduledReportConfig `json:"reports,omitempty" yaml:"reports,omitempty"`
+
+	Alerts *AlertConfig `json:"alerts,omitempty" yaml:"alerts,omitempty"`
 }
 
 func (c *Config) Map() (map[string]interface{}, error) {
@@ -57,6 +57,19 @@ func TestLoadConfig(t *testing.T) {
 				assert.NotNil(t, config.Notifications.Routing)
 				assert.Equal(t, "#dev-bbgo", config.Notifications.Slack.DefaultChannel)
 				assert.Equal(t, "#error", config.Notifications.Slack.ErrorChannel)
+
+				if assert.Len(t, config.Notifications.Webhooks, 1) {
+					webhook := config.Notifications.Webhooks[0]
+					assert.Equal(t, "${WEBHOOK_SECRET}", webhook.Secret)
+					assert.Equal(t, "[{{ .Channel }}] {{ .Text }}", webhook.Fields["summary"])
+					assert.Equal(t, "https://oncall.example.com/hooks/pnl", webhook.Channels["#bbgo-pnl"])
+					assert.Equal(t, 5, *webhook.MaxRetries)
+				}
+
+				if assert.NotNil(t, config.Notifications.Discord) {
+					assert.Equal(t, "bbgo", config.Notifications.Discord.Username)
+					assert.Equal(t, "https://discord.com/api/webhooks/1/btc", config.Notifications.Discord.Channels["#btc"])
+				}
 			},
 		},
 
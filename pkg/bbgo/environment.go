@@ -24,8 +24,10 @@ import (
 	"github.com/c9s/bbgo/pkg/exchange"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/interact"
+	"github.com/c9s/bbgo/pkg/notifier/discordnotifier"
 	"github.com/c9s/bbgo/pkg/notifier/slacknotifier"
 	"github.com/c9s/bbgo/pkg/notifier/telegramnotifier"
+	"github.com/c9s/bbgo/pkg/notifier/webhooknotifier"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/slack/slacklog"
 	"github.com/c9s/bbgo/pkg/types"
@@ -66,6 +68,9 @@ func RegisterStrategy(key string, s interface{}) {
 	if loaded == 0 {
 		panic(fmt.Errorf("%T does not implement SingleExchangeStrategy or CrossExchangeStrategy", s))
 	}
+
+	// decode the strategy of the synced orders from the client order IDs
+	types.RegisterClientOrderIDStrategy(key)
 }
 
 var emptyTime time.Time
@@ -93,6 +98,9 @@ type Environment struct {
 	WithdrawService *service.WithdrawService
 	DepositService  *service.DepositService
 
+	MarketTradeService *service.MarketTradeService
+	AuditService       *service.AuditService
+
 	// startTime is the time of start point (which is used in the backtest)
 	startTime time.Time
 
@@ -187,6 +195,8 @@ func (environ *Environment) ConfigureDatabaseDriver(ctx context.Context, driver
 	environ.MarginService = &service.MarginService{DB: db}
 	environ.WithdrawService = &service.WithdrawService{DB: db}
 	environ.DepositService = &service.DepositService{DB: db}
+	environ.MarketTradeService = &service.MarketTradeService{DB: db}
+	environ.AuditService = &service.AuditService{DB: db}
 	environ.SyncService = &service.SyncService{
 		TradeService:    environ.TradeService,
 		OrderService:    environ.OrderService,
@@ -475,12 +485,26 @@ func (environ *Environment) BindSync(config *SyncConfig) {
 		return
 	}
 
-	if config == nil || config.UserDataStream == nil {
+	if config == nil {
 		return
 	}
 
 	environ.syncConfig = config
 
+	if config.MarketDataStream != nil && config.MarketDataStream.MarketTrades {
+		for _, session := range environ.sessions {
+			session.MarketDataStream.OnMarketTrade(func(trade types.Trade) {
+				if err := environ.MarketTradeService.Insert(trade); err != nil {
+					log.WithError(err).Errorf("market trade insert error: %+v", trade)
+				}
+			})
+		}
+	}
+
+	if config.UserDataStream == nil {
+		return
+	}
+
 	tradeWriterCreator := func(session *ExchangeSession) func(trade types.Trade) {
 		return func(trade types.Trade) {
 			trade.IsMargin = session.Margin
@@ -787,7 +811,7 @@ func (environ *Environment) ConfigureNotificationSystem(userConfig *Config) erro
 
 	var persistence = PersistenceServiceFacade.Get()
 
-	err := environ.setupInteraction(persistence)
+	err := environ.setupInteraction(userConfig, persistence)
 	if err != nil {
 		return err
 	}
@@ -806,10 +830,31 @@ func (environ *Environment) ConfigureNotificationSystem(userConfig *Config) erro
 		}
 	}
 
+	// the matrix interactive messenger
+	matrixAccessToken := viper.GetString("matrix-access-token")
+	if len(matrixAccessToken) > 0 {
+		environ.setupMatrix(viper.GetString("matrix-homeserver-url"), matrixAccessToken, persistence)
+	}
+
+	if err := environ.setupWebhooks(userConfig); err != nil {
+		return err
+	}
+
+	environ.setupDiscord(userConfig)
+
 	if userConfig.Notifications != nil {
 		if err := environ.ConfigureNotificationRouting(userConfig.Notifications); err != nil {
 			return err
 		}
+
+		if userConfig.Notifications.HasThrottle() {
+			throttle, err := NewNotificationThrottle(userConfig.Notifications)
+			if err != nil {
+				return err
+			}
+
+			Notification.SetThrottle(throttle)
+		}
 	}
 
 	return nil
@@ -834,7 +879,7 @@ func getAuthStoreID() string {
 	return "default"
 }
 
-func (environ *Environment) setupInteraction(persistence service.PersistenceService) error {
+func (environ *Environment) setupInteraction(userConfig *Config, persistence service.PersistenceService) error {
 	var otpQRCodeImagePath = "otp.png"
 	var key *otp.Key
 	var keyURL string
@@ -903,16 +948,65 @@ func (environ *Environment) setupInteraction(persistence service.PersistenceServ
 		printAuthTokenGuide(authToken)
 	}
 
+	tokenRoles, err := environ.setupInteractionRoles(userConfig.Interaction)
+	if err != nil {
+		return err
+	}
+
 	interact.AddCustomInteraction(&interact.AuthInteract{
 		Strict: authStrict,
 		Mode:   authMode,
 		Token:  authToken, // can be empty string here
 		// pragma: allowlist nextline secret
 		OneTimePasswordKey: key, // can be nil here
+		TokenRoles:         tokenRoles,
 	})
 	return nil
 }
 
+// setupInteractionRoles applies the command roles and the confirmations, and returns the additional tokens mapped to the roles
+func (environ *Environment) setupInteractionRoles(conf *InteractionConfig) (map[string]interact.Role, error) {
+	if environ.AuditService != nil {
+		interact.Default().SetAuditLogger(environ.AuditService)
+	}
+
+	if conf == nil {
+		return nil, nil
+	}
+
+	var tokenRoles = make(map[string]interact.Role)
+	for _, token := range conf.Tokens {
+		role, err := interact.ParseRole(string(token.Role))
+		if err != nil {
+			return nil, err
+		}
+
+		// pragma: allowlist nextline secret
+		secret := os.ExpandEnv(token.Token)
+		if len(secret) == 0 {
+			log.Warnf("the %s interaction token is empty, skipping", role)
+			continue
+		}
+
+		tokenRoles[secret] = role
+	}
+
+	for command, r := range conf.CommandRoles {
+		role, err := interact.ParseRole(string(r))
+		if err != nil {
+			return nil, err
+		}
+
+		interact.Default().SetCommandRole(command, role)
+	}
+
+	for command, confirm := range conf.Confirmations {
+		interact.Default().SetCommandConfirmation(command, confirm)
+	}
+
+	return tokenRoles, nil
+}
+
 func (environ *Environment) getAuthStore(persistence service.PersistenceService) service.Store {
 	id := getAuthStoreID()
 	return persistence.NewStore("bbgo", "auth", id)
@@ -987,6 +1081,84 @@ func (environ *Environment) setupSlack(userConfig *Config, slackToken string, pe
 	interact.AddMessenger(messenger)
 }
 
+func (environ *Environment) setupMatrix(homeserverURL, accessToken string, persistence service.PersistenceService) {
+	if len(homeserverURL) == 0 {
+		log.Error("MATRIX_HOMESERVER_URL is required for the matrix messenger")
+		return
+	}
+
+	var messenger = interact.NewMatrix(homeserverURL, accessToken)
+
+	var sessions = interact.MatrixSessionMap{}
+	var sessionStore = persistence.NewStore("bbgo", "matrix")
+	if err := sessionStore.Load(&sessions); err != nil {
+		if err != service.ErrPersistenceNotExists {
+			log.WithError(err).Errorf("unexpected persistence error")
+		}
+	} else {
+		messenger.RestoreSessions(sessions)
+	}
+
+	messenger.OnAuthorized(func(userSession *interact.MatrixSession) {
+		log.Infof("user session %s got authorized, saving matrix sessions...", userSession.ID())
+		if err := sessionStore.Save(messenger.Sessions()); err != nil {
+			log.WithError(err).Errorf("matrix session save error")
+		}
+	})
+
+	interact.AddMessenger(messenger)
+}
+
+func (environ *Environment) setupWebhooks(userConfig *Config) error {
+	for _, conf := range userConfig.Notifications.Webhooks {
+		url := os.ExpandEnv(conf.URL)
+		if len(url) == 0 {
+			return fmt.Errorf("webhook notification url is not set")
+		}
+
+		opts := []webhooknotifier.Option{
+			webhooknotifier.WithSecret(os.ExpandEnv(conf.Secret)),
+			webhooknotifier.WithHeaders(conf.Headers),
+			webhooknotifier.WithChannels(conf.Channels),
+		}
+
+		if conf.MaxRetries != nil {
+			opts = append(opts, webhooknotifier.WithMaxRetries(*conf.MaxRetries))
+		}
+
+		notifier, err := webhooknotifier.New(url, conf.Fields, opts...)
+		if err != nil {
+			return err
+		}
+
+		Notification.AddNotifier(notifier)
+	}
+
+	return nil
+}
+
+func (environ *Environment) setupDiscord(userConfig *Config) {
+	conf := userConfig.Notifications.Discord
+	if conf == nil {
+		return
+	}
+
+	webhookURL := conf.WebhookURL
+	if len(webhookURL) == 0 {
+		webhookURL = os.Getenv("DISCORD_WEBHOOK_URL")
+	}
+
+	if len(webhookURL) == 0 && len(conf.Channels) == 0 {
+		log.Warnf("discord notification is configured without the webhook url")
+		return
+	}
+
+	var notifier = discordnotifier.New(webhookURL,
+		discordnotifier.WithUsername(conf.Username),
+		discordnotifier.WithChannels(conf.Channels))
+	Notification.AddNotifier(notifier)
+}
+
 func (environ *Environment) setupTelegram(userConfig *Config, telegramBotToken string, persistence service.PersistenceService) error {
 	tt := strings.Split(telegramBotToken, ":")
 	telegramID := tt[0]
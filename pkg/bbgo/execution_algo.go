@@ -0,0 +1,450 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultAlgoUpdateInterval = 10 * time.Second
+
+// ExecutionAlgo schedules the child orders of a parent order
+type ExecutionAlgo interface {
+	ID() string
+
+	// Bind subscribes the market data that the algo needs before the stream is connected,
+	// the start time is the start time of the parent order execution.
+	Bind(symbol string, stream types.Stream, startTime time.Time)
+
+	// Target returns the cumulative quantity that should be executed at the given time
+	Target(now time.Time, executed, quantity fixedpoint.Value) fixedpoint.Value
+}
+
+// ExecutionReport is the implementation shortfall report of a parent order
+type ExecutionReport struct {
+	Algo     string           `json:"algo"`
+	Symbol   string           `json:"symbol"`
+	Side     types.SideType   `json:"side"`
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+
+	// ArrivalPrice is the mid price when the execution starts, it's the benchmark of the shortfall
+	ArrivalPrice fixedpoint.Value `json:"arrivalPrice"`
+
+	// LastPrice is the mid price when the execution ends
+	LastPrice fixedpoint.Value `json:"lastPrice"`
+
+	Executed     fixedpoint.Value            `json:"executed"`
+	AveragePrice fixedpoint.Value            `json:"averagePrice"`
+	Fees         map[string]fixedpoint.Value `json:"fees"`
+
+	NumOfChildOrders int `json:"numOfChildOrders"`
+}
+
+func (r *ExecutionReport) sign() fixedpoint.Value {
+	if r.Side == types.SideTypeSell {
+		return fixedpoint.NegOne
+	}
+	return fixedpoint.One
+}
+
+// Shortfall is the execution cost of the executed quantity against the arrival price in the quote currency, the fees are excluded
+func (r *ExecutionReport) Shortfall() fixedpoint.Value {
+	if r.Executed.IsZero() || r.ArrivalPrice.IsZero() {
+		return fixedpoint.Zero
+	}
+	return r.AveragePrice.Sub(r.ArrivalPrice).Mul(r.Executed).Mul(r.sign())
+}
+
+// ShortfallBps is the execution cost in basis points of the arrival price
+func (r *ExecutionReport) ShortfallBps() fixedpoint.Value {
+	if r.Executed.IsZero() || r.ArrivalPrice.IsZero() {
+		return fixedpoint.Zero
+	}
+	return r.AveragePrice.Sub(r.ArrivalPrice).Div(r.ArrivalPrice).Mul(r.sign()).Mul(fixedpoint.NewFromInt(10000))
+}
+
+// OpportunityCost is the cost of the unfilled quantity from the arrival price to the last price
+func (r *ExecutionReport) OpportunityCost() fixedpoint.Value {
+	unfilled := r.Quantity.Sub(r.Executed)
+	if unfilled.Sign() <= 0 || r.ArrivalPrice.IsZero() || r.LastPrice.IsZero() {
+		return fixedpoint.Zero
+	}
+	return r.LastPrice.Sub(r.ArrivalPrice).Mul(unfilled).Mul(r.sign())
+}
+
+func (r *ExecutionReport) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %s %s %v execution report:\n", r.Algo, r.Symbol, r.Side, r.Quantity))
+	sb.WriteString(fmt.Sprintf("- executed: %v @ %v (%d child orders)\n", r.Executed, r.AveragePrice, r.NumOfChildOrders))
+	sb.WriteString(fmt.Sprintf("- arrival price: %v, last price: %v\n", r.ArrivalPrice, r.LastPrice))
+	sb.WriteString(fmt.Sprintf("- shortfall: %v (%v bps)\n", r.Shortfall(), r.ShortfallBps().Round(2, fixedpoint.HalfUp)))
+	sb.WriteString(fmt.Sprintf("- opportunity cost: %v", r.OpportunityCost()))
+	for currency, fee := range r.Fees {
+		sb.WriteString(fmt.Sprintf("\n- fee: %v %s", fee, currency))
+	}
+	return sb.String()
+}
+
+// AlgoExecution executes a parent order by the child orders scheduled by the execution algo.
+// There is at most one active child order, it's re-placed when the best price moves or the schedule requires more quantity.
+//go:generate callbackgen -type AlgoExecution
+type AlgoExecution struct {
+	Session  *ExchangeSession
+	Symbol   string
+	Side     types.SideType
+	Quantity fixedpoint.Value
+	Algo     ExecutionAlgo
+
+	// LimitPrice bounds the price of the child orders
+	LimitPrice fixedpoint.Value
+
+	// Aggressive takes the liquidity by IOC orders on the opposite best price,
+	// the child orders join the best price on the same side by default.
+	Aggressive bool
+
+	// UpdateInterval is the interval of the schedule update, default: 10s
+	UpdateInterval time.Duration
+
+	// DeadlineTime is the time to execute the remaining quantity by a market order
+	DeadlineTime time.Time
+
+	market           types.Market
+	marketDataStream types.Stream
+	userDataStream   types.Stream
+	orderBook        *types.StreamOrderBook
+	orderStore       *OrderStore
+	activeOrders     *ActiveOrderBook
+	tradeCollector   *TradeCollector
+	position         *types.Position
+
+	report   ExecutionReport
+	notional fixedpoint.Value
+
+	executionCtx    context.Context
+	cancelExecution context.CancelFunc
+	doneC           chan struct{}
+
+	mu sync.Mutex
+
+	childOrderCallbacks []func(order types.Order)
+	doneCallbacks       []func(report ExecutionReport)
+}
+
+// Run starts the execution in the background, use Done to wait for the execution
+func (e *AlgoExecution) Run(ctx context.Context) error {
+	if e.Algo == nil {
+		return fmt.Errorf("execution algo is not set")
+	}
+
+	if e.Quantity.Sign() <= 0 {
+		return fmt.Errorf("invalid quantity %v", e.Quantity)
+	}
+
+	market, ok := e.Session.Market(e.Symbol)
+	if !ok {
+		return fmt.Errorf("market %s not found", e.Symbol)
+	}
+
+	if e.UpdateInterval == 0 {
+		e.UpdateInterval = defaultAlgoUpdateInterval
+	}
+
+	now := time.Now()
+	e.setup(market, now)
+
+	e.mu.Lock()
+	e.executionCtx, e.cancelExecution = context.WithCancel(ctx)
+	e.doneC = make(chan struct{})
+	e.mu.Unlock()
+
+	e.marketDataStream = e.Session.Exchange.NewStream()
+	e.marketDataStream.SetPublicOnly()
+	e.marketDataStream.Subscribe(types.BookChannel, e.Symbol, types.SubscribeOptions{})
+	e.orderBook.BindStream(e.marketDataStream)
+	e.Algo.Bind(e.Symbol, e.marketDataStream, now)
+
+	e.userDataStream = e.Session.Exchange.NewStream()
+	e.orderStore.BindStream(e.userDataStream)
+	e.activeOrders.BindStream(e.userDataStream)
+	e.tradeCollector.BindStream(e.userDataStream)
+
+	if err := e.marketDataStream.Connect(e.executionCtx); err != nil {
+		e.abort()
+		return err
+	}
+
+	if err := e.userDataStream.Connect(e.executionCtx); err != nil {
+		_ = e.marketDataStream.Close()
+		e.abort()
+		return err
+	}
+
+	go e.run(e.executionCtx)
+	return nil
+}
+
+// abort stops the execution that failed to start, so that Done and Shutdown don't block
+func (e *AlgoExecution) abort() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.cancelExecution()
+	close(e.doneC)
+}
+
+func (e *AlgoExecution) setup(market types.Market, now time.Time) {
+	e.market = market
+	e.orderBook = types.NewStreamBook(e.Symbol)
+	e.orderStore = NewOrderStore(e.Symbol)
+	e.activeOrders = NewActiveOrderBook(e.Symbol)
+	e.position = types.NewPositionFromMarket(market)
+	e.tradeCollector = NewTradeCollector(e.Symbol, e.position, e.orderStore)
+	e.tradeCollector.OnTrade(func(trade types.Trade, profit, netProfit fixedpoint.Value) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		e.notional = e.notional.Add(trade.Price.Mul(trade.Quantity))
+		e.report.Executed = e.report.Executed.Add(trade.Quantity)
+		e.report.AveragePrice = e.notional.Div(e.report.Executed)
+		if trade.FeeCurrency != "" {
+			e.report.Fees[trade.FeeCurrency] = e.report.Fees[trade.FeeCurrency].Add(trade.Fee)
+		}
+	})
+
+	e.report = ExecutionReport{
+		Algo:      e.Algo.ID(),
+		Symbol:    e.Symbol,
+		Side:      e.Side,
+		Quantity:  e.Quantity,
+		StartTime: now,
+		Fees:      make(map[string]fixedpoint.Value),
+	}
+}
+
+func (e *AlgoExecution) run(ctx context.Context) {
+	ticker := time.NewTicker(e.UpdateInterval)
+	defer ticker.Stop()
+
+	defer func() {
+		e.cancelActiveOrders()
+		e.finish()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			done, err := e.step(ctx, time.Now())
+			if err != nil {
+				log.WithError(err).Errorf("%s %s execution error", e.Algo.ID(), e.Symbol)
+			}
+
+			if done {
+				return
+			}
+		}
+	}
+}
+
+// step updates the child order by the schedule, it returns true if the execution is done
+func (e *AlgoExecution) step(ctx context.Context, now time.Time) (bool, error) {
+	e.tradeCollector.Process()
+
+	executed := e.executed()
+	remaining := e.Quantity.Sub(executed)
+	if remaining.Compare(e.market.MinQuantity) < 0 || remaining.Sign() <= 0 {
+		return true, nil
+	}
+
+	bid, ask, ok := e.orderBook.BestBidAndAsk()
+	if !ok {
+		return false, nil
+	}
+
+	e.mu.Lock()
+	if e.report.ArrivalPrice.IsZero() {
+		e.report.ArrivalPrice = bid.Price.Add(ask.Price).Div(fixedpoint.Two)
+	}
+	e.mu.Unlock()
+
+	if !e.DeadlineTime.IsZero() && !now.Before(e.DeadlineTime) {
+		// the child order can be filled before it's canceled, recompute the remaining quantity after the cancel
+		e.cancelActiveOrders()
+		e.tradeCollector.Process()
+
+		remaining = e.Quantity.Sub(e.executed())
+		if remaining.Compare(e.market.MinQuantity) < 0 || remaining.Sign() <= 0 {
+			return true, nil
+		}
+
+		_, err := e.submitChildOrder(ctx, types.SubmitOrder{
+			Symbol:   e.Symbol,
+			Side:     e.Side,
+			Type:     types.OrderTypeMarket,
+			Quantity: e.market.TruncateQuantity(remaining),
+			Market:   e.market,
+		})
+		return true, err
+	}
+
+	price := e.childOrderPrice(bid.Price, ask.Price)
+	if !e.LimitPrice.IsZero() {
+		if e.Side == types.SideTypeBuy && price.Compare(e.LimitPrice) > 0 || e.Side == types.SideTypeSell && price.Compare(e.LimitPrice) < 0 {
+			// wait for the price to come back
+			e.cancelActiveOrders()
+			return false, nil
+		}
+	}
+
+	target := fixedpoint.Min(e.Algo.Target(now, executed, e.Quantity), e.Quantity)
+	quantity := e.market.TruncateQuantity(target.Sub(executed))
+
+	// keep the active child order if it's on the price and covers the scheduled quantity
+	if orders := e.activeOrders.Orders(); len(orders) > 0 {
+		order := orders[0]
+		open := order.Quantity.Sub(order.ExecutedQuantity)
+		if order.Price.Compare(price) == 0 && open.Compare(quantity) >= 0 {
+			return false, nil
+		}
+
+		e.cancelActiveOrders()
+		e.tradeCollector.Process()
+		executed = e.executed()
+		quantity = e.market.TruncateQuantity(target.Sub(executed))
+	}
+
+	if e.market.IsDustQuantity(quantity, price) {
+		return false, nil
+	}
+
+	childOrder := types.SubmitOrder{
+		Symbol:   e.Symbol,
+		Side:     e.Side,
+		Type:     types.OrderTypeLimitMaker,
+		Quantity: quantity,
+		Price:    price,
+		Market:   e.market,
+	}
+
+	if e.Aggressive {
+		childOrder.Type = types.OrderTypeLimit
+		childOrder.TimeInForce = types.TimeInForceIOC
+	}
+
+	_, err := e.submitChildOrder(ctx, childOrder)
+	return false, err
+}
+
+// childOrderPrice joins the best price on the same side, or takes the best price on the opposite side
+func (e *AlgoExecution) childOrderPrice(bid, ask fixedpoint.Value) fixedpoint.Value {
+	buy := e.Side == types.SideTypeBuy
+	if buy != e.Aggressive {
+		if buy {
+			return bid
+		}
+		return ask
+	}
+
+	if buy {
+		return ask
+	}
+	return bid
+}
+
+func (e *AlgoExecution) submitChildOrder(ctx context.Context, childOrder types.SubmitOrder) (types.OrderSlice, error) {
+	createdOrders, err := submitOrdersToSession(ctx, e.Session, childOrder)
+
+	e.orderStore.Add(createdOrders...)
+	e.activeOrders.Add(createdOrders...)
+
+	e.mu.Lock()
+	e.report.NumOfChildOrders += len(createdOrders)
+	e.mu.Unlock()
+
+	for _, order := range createdOrders {
+		e.EmitChildOrder(order)
+	}
+
+	e.tradeCollector.Process()
+	return createdOrders, err
+}
+
+func (e *AlgoExecution) executed() fixedpoint.Value {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.report.Executed
+}
+
+func (e *AlgoExecution) cancelActiveOrders() {
+	if e.activeOrders.NumOfOrders() == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := e.activeOrders.GracefulCancel(ctx, e.Session.Exchange); err != nil {
+		log.WithError(err).Errorf("%s %s can not cancel the child orders", e.Algo.ID(), e.Symbol)
+	}
+}
+
+func (e *AlgoExecution) finish() {
+	e.mu.Lock()
+	e.report.EndTime = time.Now()
+	if bid, ask, ok := e.orderBook.BestBidAndAsk(); ok {
+		e.report.LastPrice = bid.Price.Add(ask.Price).Div(fixedpoint.Two)
+	}
+	report := e.report
+	close(e.doneC)
+	e.mu.Unlock()
+
+	log.Info(report.String())
+	e.EmitDone(report)
+}
+
+// Report returns the current execution report
+func (e *AlgoExecution) Report() ExecutionReport {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.report
+}
+
+func (e *AlgoExecution) Done() <-chan struct{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// not started
+	if e.doneC == nil {
+		c := make(chan struct{})
+		close(c)
+		return c
+	}
+
+	return e.doneC
+}
+
+// Shutdown stops the execution and cancels the active child order
+func (e *AlgoExecution) Shutdown(shutdownCtx context.Context) {
+	e.mu.Lock()
+	if e.cancelExecution != nil {
+		e.cancelExecution()
+	}
+	e.mu.Unlock()
+
+	select {
+	case <-shutdownCtx.Done():
+	case <-e.Done():
+	}
+}
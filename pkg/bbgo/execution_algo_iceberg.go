@@ -0,0 +1,60 @@
+package bbgo
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// IcebergAlgo shows only a clip of the parent order, the next clip is placed after the current clip is filled.
+// The clip quantity is randomized to hide the pattern of the child orders.
+type IcebergAlgo struct {
+	ClipQuantity fixedpoint.Value
+
+	// ClipVariance randomizes the clip quantity in [clip x (1 - variance), clip x (1 + variance)]
+	ClipVariance fixedpoint.Value
+
+	// Rand is the random source, the global source is used if it's nil
+	Rand *rand.Rand
+
+	mu           sync.Mutex
+	clip         fixedpoint.Value
+	lastExecuted fixedpoint.Value
+}
+
+func (a *IcebergAlgo) ID() string {
+	return "iceberg"
+}
+
+func (a *IcebergAlgo) Bind(symbol string, stream types.Stream, startTime time.Time) {}
+
+func (a *IcebergAlgo) Target(now time.Time, executed, quantity fixedpoint.Value) fixedpoint.Value {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// the clip is kept until the executed quantity changes
+	if a.clip.IsZero() || executed.Compare(a.lastExecuted) != 0 {
+		a.clip = a.randomClip()
+		a.lastExecuted = executed
+	}
+
+	return executed.Add(a.clip)
+}
+
+func (a *IcebergAlgo) randomClip() fixedpoint.Value {
+	if a.ClipVariance.Sign() <= 0 {
+		return a.ClipQuantity
+	}
+
+	r := rand.Float64()
+	if a.Rand != nil {
+		r = a.Rand.Float64()
+	}
+
+	// [-1, 1) x variance
+	factor := fixedpoint.One.Add(a.ClipVariance.Mul(fixedpoint.NewFromFloat(r*2 - 1)))
+	return a.ClipQuantity.Mul(factor)
+}
@@ -0,0 +1,53 @@
+package bbgo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// POVAlgo executes a percentage of the market volume from the market trade stream since the start time
+type POVAlgo struct {
+	// ParticipationRate is the target ratio of the market volume, for example, 0.1 for 10%
+	ParticipationRate fixedpoint.Value
+
+	mu           sync.Mutex
+	startTime    time.Time
+	marketVolume fixedpoint.Value
+}
+
+func (a *POVAlgo) ID() string {
+	return "pov"
+}
+
+func (a *POVAlgo) Bind(symbol string, stream types.Stream, startTime time.Time) {
+	a.startTime = startTime
+
+	stream.Subscribe(types.MarketTradeChannel, symbol, types.SubscribeOptions{})
+	stream.OnMarketTrade(func(trade types.Trade) {
+		if trade.Symbol != symbol {
+			return
+		}
+
+		a.AddMarketTrade(trade)
+	})
+}
+
+// AddMarketTrade adds the market trade volume after the start time
+func (a *POVAlgo) AddMarketTrade(trade types.Trade) {
+	if trade.Time.Time().Before(a.startTime) {
+		return
+	}
+
+	a.mu.Lock()
+	a.marketVolume = a.marketVolume.Add(trade.Quantity)
+	a.mu.Unlock()
+}
+
+func (a *POVAlgo) Target(now time.Time, executed, quantity fixedpoint.Value) fixedpoint.Value {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.marketVolume.Mul(a.ParticipationRate)
+}
@@ -0,0 +1,278 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func TestVolumeProfile(t *testing.T) {
+	day1 := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+
+	var klines []types.KLine
+	for _, day := range []time.Time{day1, day2} {
+		for h := 0; h < 24; h++ {
+			volume := fixedpoint.NewFromInt(10)
+			// the busy hours
+			if h >= 12 && h < 14 {
+				volume = fixedpoint.NewFromInt(30)
+			}
+
+			klines = append(klines, types.KLine{
+				StartTime: types.Time(day.Add(time.Duration(h) * time.Hour)),
+				Interval:  types.Interval1h,
+				Volume:    volume,
+			})
+		}
+	}
+
+	profile := NewVolumeProfile(klines, types.Interval1h)
+	assert.Len(t, profile.Volumes, 24)
+	assert.Equal(t, "10", profile.Volumes[0].String())
+	assert.Equal(t, "30", profile.Volumes[12].String())
+
+	start := day2.Add(11 * time.Hour)
+	assert.Equal(t, "70", profile.Between(start, start.Add(3*time.Hour)).String())
+	assert.Equal(t, "20", profile.Between(start.Add(30*time.Minute), start.Add(90*time.Minute)).String())
+
+	algo := &VWAPAlgo{Profile: profile, Duration: 3 * time.Hour}
+	algo.Bind("BTCUSDT", nil, start)
+
+	quantity := fixedpoint.NewFromInt(7)
+	assert.Equal(t, "1", algo.Target(start.Add(time.Hour), fixedpoint.Zero, quantity).String())
+	assert.Equal(t, "4", algo.Target(start.Add(2*time.Hour), fixedpoint.Zero, quantity).String())
+	assert.Equal(t, "7", algo.Target(start.Add(4*time.Hour), fixedpoint.Zero, quantity).String())
+
+	// linear schedule without the profile volume
+	algo = &VWAPAlgo{Profile: &VolumeProfile{Interval: types.Interval1h, Volumes: make([]fixedpoint.Value, 24)}, Duration: 2 * time.Hour}
+	algo.Bind("BTCUSDT", nil, start)
+	assert.Equal(t, "3.5", algo.Target(start.Add(time.Hour), fixedpoint.Zero, quantity).String())
+}
+
+func TestPOVAlgo(t *testing.T) {
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	algo := &POVAlgo{ParticipationRate: fixedpoint.NewFromFloat(0.1)}
+	algo.Bind("BTCUSDT", &types.StandardStream{}, start)
+
+	algo.AddMarketTrade(types.Trade{Symbol: "BTCUSDT", Quantity: fixedpoint.NewFromInt(5), Time: types.Time(start.Add(-time.Second))})
+	algo.AddMarketTrade(types.Trade{Symbol: "BTCUSDT", Quantity: fixedpoint.NewFromInt(20), Time: types.Time(start.Add(time.Second))})
+	algo.AddMarketTrade(types.Trade{Symbol: "BTCUSDT", Quantity: fixedpoint.NewFromInt(10), Time: types.Time(start.Add(2 * time.Second))})
+	assert.Equal(t, "3", algo.Target(start.Add(time.Minute), fixedpoint.Zero, fixedpoint.NewFromInt(10)).String())
+}
+
+func TestIcebergAlgo(t *testing.T) {
+	algo := &IcebergAlgo{
+		ClipQuantity: fixedpoint.One,
+		ClipVariance: fixedpoint.NewFromFloat(0.2),
+		Rand:         rand.New(rand.NewSource(1)),
+	}
+
+	now := time.Now()
+	quantity := fixedpoint.NewFromInt(10)
+	target := algo.Target(now, fixedpoint.Zero, quantity)
+	assert.True(t, target.Compare(fixedpoint.NewFromFloat(0.8)) >= 0 && target.Compare(fixedpoint.NewFromFloat(1.2)) <= 0, target.String())
+
+	// the clip is kept until the clip is executed
+	assert.Equal(t, target, algo.Target(now, fixedpoint.Zero, quantity))
+
+	executed := fixedpoint.NewFromFloat(0.5)
+	clip := algo.Target(now, executed, quantity).Sub(executed)
+	assert.True(t, clip.Compare(fixedpoint.NewFromFloat(0.8)) >= 0 && clip.Compare(fixedpoint.NewFromFloat(1.2)) <= 0, clip.String())
+}
+
+func TestExecutionReport(t *testing.T) {
+	report := ExecutionReport{
+		Side:         types.SideTypeBuy,
+		Quantity:     fixedpoint.NewFromInt(2),
+		ArrivalPrice: fixedpoint.NewFromInt(20000),
+		LastPrice:    fixedpoint.NewFromInt(20100),
+		Executed:     fixedpoint.One,
+		AveragePrice: fixedpoint.NewFromInt(20010),
+	}
+	assert.Equal(t, "10", report.Shortfall().String())
+	assert.Equal(t, "5", report.ShortfallBps().String())
+	assert.Equal(t, "100", report.OpportunityCost().String())
+
+	report.Side = types.SideTypeSell
+	assert.Equal(t, "-10", report.Shortfall().String())
+	assert.Equal(t, "-100", report.OpportunityCost().String())
+}
+
+func TestAlgoExecution_Step(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	session := NewExchangeSession("binance", mockEx)
+	market := types.Market{
+		Symbol:          "BTCUSDT",
+		BaseCurrency:    "BTC",
+		QuoteCurrency:   "USDT",
+		PricePrecision:  2,
+		VolumePrecision: 4,
+		StepSize:        fixedpoint.NewFromFloat(0.0001),
+		TickSize:        fixedpoint.NewFromFloat(0.01),
+	}
+	session.markets = map[string]types.Market{"BTCUSDT": market}
+
+	var submitted []types.SubmitOrder
+	mockEx.EXPECT().SubmitOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+		submitted = append(submitted, order)
+		return &types.Order{SubmitOrder: order, OrderID: uint64(len(submitted)), Status: types.OrderStatusNew}, nil
+	}).Times(3)
+
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	e := &AlgoExecution{
+		Session:      session,
+		Symbol:       "BTCUSDT",
+		Side:         types.SideTypeBuy,
+		Quantity:     fixedpoint.NewFromInt(3),
+		Algo:         &IcebergAlgo{ClipQuantity: fixedpoint.One},
+		DeadlineTime: start.Add(time.Hour),
+	}
+	e.setup(market, start)
+	e.orderBook.Load(types.SliceOrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   types.PriceVolumeSlice{pv("19990", "5")},
+		Asks:   types.PriceVolumeSlice{pv("20010", "5")},
+	})
+
+	ctx := context.Background()
+	done, err := e.step(ctx, start)
+	assert.NoError(t, err)
+	assert.False(t, done)
+	if assert.Len(t, submitted, 1) {
+		assert.Equal(t, types.OrderTypeLimitMaker, submitted[0].Type)
+		assert.Equal(t, "19990", submitted[0].Price.String())
+		assert.Equal(t, "1", submitted[0].Quantity.String())
+	}
+	assert.Equal(t, "20000", e.Report().ArrivalPrice.String())
+
+	// the child order is on the best price
+	_, err = e.step(ctx, start.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, submitted, 1)
+
+	// the first clip is filled
+	e.activeOrders.Remove(types.Order{SubmitOrder: submitted[0], OrderID: 1})
+	e.tradeCollector.ProcessTrade(types.Trade{
+		ID:            1,
+		OrderID:       1,
+		Symbol:        "BTCUSDT",
+		Side:          types.SideTypeBuy,
+		IsBuyer:       true,
+		Price:         fixedpoint.NewFromInt(19990),
+		Quantity:      fixedpoint.One,
+		QuoteQuantity: fixedpoint.NewFromInt(19990),
+		Fee:           fixedpoint.NewFromFloat(0.001),
+		FeeCurrency:   "BTC",
+	})
+
+	_, err = e.step(ctx, start.Add(2*time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, submitted, 2)
+
+	// the rest is executed by the market order after the deadline,
+	// the second clip is partially filled before it's canceled
+	mockEx.EXPECT().CancelOrders(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, orders ...types.Order) error {
+		// the order update and the trade from the user data stream
+		for _, o := range orders {
+			e.activeOrders.Remove(o)
+		}
+
+		e.tradeCollector.ProcessTrade(types.Trade{
+			ID:            2,
+			OrderID:       2,
+			Symbol:        "BTCUSDT",
+			Side:          types.SideTypeBuy,
+			IsBuyer:       true,
+			Price:         fixedpoint.NewFromInt(19990),
+			Quantity:      fixedpoint.NewFromFloat(0.5),
+			QuoteQuantity: fixedpoint.NewFromInt(9995),
+		})
+		return nil
+	}).Times(1)
+	done, err = e.step(ctx, start.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.True(t, done)
+	if assert.Len(t, submitted, 3) {
+		assert.Equal(t, types.OrderTypeMarket, submitted[2].Type)
+		assert.Equal(t, "1.5", submitted[2].Quantity.String())
+	}
+
+	report := e.Report()
+	assert.Equal(t, "1.5", report.Executed.String())
+	assert.Equal(t, "19990", report.AveragePrice.String())
+	assert.Equal(t, "0.001", report.Fees["BTC"].String())
+	assert.Equal(t, 3, report.NumOfChildOrders)
+	assert.Equal(t, "-15", report.Shortfall().String())
+}
+
+// connectTestStream is a stream that fails to connect if connectErr is set
+type connectTestStream struct {
+	types.StandardStream
+
+	connectErr error
+	closed     bool
+}
+
+func (s *connectTestStream) Connect(ctx context.Context) error {
+	return s.connectErr
+}
+
+func (s *connectTestStream) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestAlgoExecution_RunConnectError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	session := NewExchangeSession("binance", mockEx)
+	session.markets = map[string]types.Market{"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}}
+
+	marketDataStream := &connectTestStream{StandardStream: types.NewStandardStream()}
+	userDataStream := &connectTestStream{StandardStream: types.NewStandardStream(), connectErr: errors.New("connection refused")}
+	mockEx.EXPECT().NewStream().Return(marketDataStream)
+	mockEx.EXPECT().NewStream().Return(userDataStream)
+
+	e := &AlgoExecution{
+		Session:  session,
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Quantity: fixedpoint.One,
+		Algo:     &IcebergAlgo{ClipQuantity: fixedpoint.One},
+	}
+
+	assert.Error(t, e.Run(context.Background()))
+	assert.True(t, marketDataStream.closed)
+	assert.Error(t, e.executionCtx.Err(), "the execution context is canceled")
+
+	select {
+	case <-e.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the execution is not done after the connect error")
+	}
+
+	// shutdown returns immediately
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	e.Shutdown(shutdownCtx)
+	assert.NoError(t, shutdownCtx.Err())
+}
@@ -0,0 +1,103 @@
+package bbgo
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const dayDuration = 24 * time.Hour
+
+// VolumeProfile is the average traded volume of the time buckets in a day (UTC)
+type VolumeProfile struct {
+	Interval types.Interval
+	Volumes  []fixedpoint.Value
+}
+
+// NewVolumeProfile builds the intraday volume profile from the klines of the interval,
+// the volume of a bucket is the average volume of the klines in the bucket.
+func NewVolumeProfile(klines []types.KLine, interval types.Interval) *VolumeProfile {
+	numOfBuckets := int(dayDuration / interval.Duration())
+	if numOfBuckets <= 0 {
+		numOfBuckets = 1
+	}
+
+	sums := make([]fixedpoint.Value, numOfBuckets)
+	counts := make([]int64, numOfBuckets)
+	for _, k := range klines {
+		i := bucketOf(k.StartTime.Time(), interval.Duration(), numOfBuckets)
+		sums[i] = sums[i].Add(k.Volume)
+		counts[i]++
+	}
+
+	profile := &VolumeProfile{Interval: interval, Volumes: make([]fixedpoint.Value, numOfBuckets)}
+	for i := range sums {
+		if counts[i] > 0 {
+			profile.Volumes[i] = sums[i].Div(fixedpoint.NewFromInt(counts[i]))
+		}
+	}
+	return profile
+}
+
+func bucketOf(t time.Time, bucketDuration time.Duration, numOfBuckets int) int {
+	t = t.UTC()
+	sinceMidnight := t.Sub(t.Truncate(dayDuration))
+	return int(sinceMidnight/bucketDuration) % numOfBuckets
+}
+
+// Between returns the expected volume in the time range, the partial buckets are pro-rated
+func (p *VolumeProfile) Between(from, to time.Time) (volume fixedpoint.Value) {
+	bucketDuration := p.Interval.Duration()
+	numOfBuckets := len(p.Volumes)
+	if numOfBuckets == 0 || bucketDuration <= 0 {
+		return volume
+	}
+
+	for t := from; t.Before(to); {
+		next := t.Truncate(bucketDuration).Add(bucketDuration)
+		if next.After(to) {
+			next = to
+		}
+
+		ratio := fixedpoint.NewFromFloat(float64(next.Sub(t)) / float64(bucketDuration))
+		volume = volume.Add(p.Volumes[bucketOf(t, bucketDuration, numOfBuckets)].Mul(ratio))
+		t = next
+	}
+
+	return volume
+}
+
+// VWAPAlgo follows the intraday volume profile from the start time to the end of the duration,
+// the schedule falls back to the linear time schedule if the profile has no volume in the period.
+type VWAPAlgo struct {
+	Profile  *VolumeProfile
+	Duration time.Duration
+
+	startTime time.Time
+	endTime   time.Time
+	total     fixedpoint.Value
+}
+
+func (a *VWAPAlgo) ID() string {
+	return "vwap"
+}
+
+func (a *VWAPAlgo) Bind(symbol string, stream types.Stream, startTime time.Time) {
+	a.startTime = startTime
+	a.endTime = startTime.Add(a.Duration)
+	a.total = a.Profile.Between(a.startTime, a.endTime)
+}
+
+func (a *VWAPAlgo) Target(now time.Time, executed, quantity fixedpoint.Value) fixedpoint.Value {
+	if !now.Before(a.endTime) {
+		return quantity
+	}
+
+	if a.total.Sign() <= 0 {
+		ratio := float64(now.Sub(a.startTime)) / float64(a.Duration)
+		return quantity.Mul(fixedpoint.NewFromFloat(ratio))
+	}
+
+	return quantity.Mul(a.Profile.Between(a.startTime, now)).Div(a.total)
+}
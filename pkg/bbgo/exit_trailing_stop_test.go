@@ -1,6 +1,8 @@
 package bbgo
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -27,6 +29,25 @@ func getTestMarket() types.Market {
 	return market
 }
 
+// submitOrderWithoutClientOrderID matches the submit order except the generated client order ID
+type submitOrderWithoutClientOrderID struct {
+	order types.SubmitOrder
+}
+
+func (m submitOrderWithoutClientOrderID) Matches(x interface{}) bool {
+	order, ok := x.(types.SubmitOrder)
+	if !ok || order.ClientOrderID == "" {
+		return false
+	}
+
+	order.ClientOrderID = m.order.ClientOrderID
+	return reflect.DeepEqual(order, m.order)
+}
+
+func (m submitOrderWithoutClientOrderID) String() string {
+	return fmt.Sprintf("is equal to %v with a client order ID", m.order)
+}
+
 func TestTrailingStop_ShortPosition(t *testing.T) {
 	market := getTestMarket()
 
@@ -35,7 +56,7 @@ func TestTrailingStop_ShortPosition(t *testing.T) {
 
 	mockEx := mocks.NewMockExchange(mockCtrl)
 	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
-	mockEx.EXPECT().SubmitOrder(gomock.Any(), types.SubmitOrder{
+	mockEx.EXPECT().SubmitOrder(gomock.Any(), submitOrderWithoutClientOrderID{types.SubmitOrder{
 		Symbol:           "BTCUSDT",
 		Side:             types.SideTypeBuy,
 		Type:             types.OrderTypeMarket,
@@ -43,7 +64,7 @@ func TestTrailingStop_ShortPosition(t *testing.T) {
 		Quantity:         fixedpoint.NewFromFloat(1.0),
 		Tag:              "trailingStop",
 		MarginSideEffect: types.SideEffectTypeAutoRepay,
-	})
+	}})
 
 	session := NewExchangeSession("test", mockEx)
 	assert.NotNil(t, session)
@@ -113,7 +134,7 @@ func TestTrailingStop_LongPosition(t *testing.T) {
 
 	mockEx := mocks.NewMockExchange(mockCtrl)
 	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
-	mockEx.EXPECT().SubmitOrder(gomock.Any(), types.SubmitOrder{
+	mockEx.EXPECT().SubmitOrder(gomock.Any(), submitOrderWithoutClientOrderID{types.SubmitOrder{
 		Symbol:           "BTCUSDT",
 		Side:             types.SideTypeSell,
 		Type:             types.OrderTypeMarket,
@@ -121,7 +142,7 @@ func TestTrailingStop_LongPosition(t *testing.T) {
 		Quantity:         fixedpoint.NewFromFloat(1.0),
 		Tag:              "trailingStop",
 		MarginSideEffect: types.SideEffectTypeAutoRepay,
-	})
+	}})
 
 	session := NewExchangeSession("test", mockEx)
 	assert.NotNil(t, session)
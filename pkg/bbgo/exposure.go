@@ -0,0 +1,230 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+const DefaultExposureValuationCurrency = "USDT"
+
+// AssetExposure is the exposure of an asset netted across the sessions
+type AssetExposure struct {
+	Asset string `json:"asset"`
+
+	// Balance is the total spot and margin balance (available + locked)
+	Balance fixedpoint.Value `json:"balance"`
+
+	// Debt is the borrowed amount and the interest of the margin sessions
+	Debt fixedpoint.Value `json:"debt"`
+
+	// Futures is the base quantity of the futures positions, negative for the short positions
+	Futures fixedpoint.Value `json:"futures"`
+
+	// Net = Balance - Debt + Futures
+	Net fixedpoint.Value `json:"net"`
+
+	// Price is the price in the valuation currency, zero if the price is not found
+	Price    fixedpoint.Value `json:"price"`
+	NetValue fixedpoint.Value `json:"netValue"`
+
+	// Sessions is the net quantity of each session
+	Sessions map[string]fixedpoint.Value `json:"sessions"`
+}
+
+// Exposure is the consolidated exposure of all the sessions in the environment
+type Exposure struct {
+	Time              time.Time        `json:"time"`
+	ValuationCurrency string           `json:"valuationCurrency"`
+	Assets            []AssetExposure  `json:"assets"`
+	NetValue          fixedpoint.Value `json:"netValue"`
+	GrossValue        fixedpoint.Value `json:"grossValue"`
+}
+
+func (e *Exposure) Asset(asset string) (AssetExposure, bool) {
+	for _, a := range e.Assets {
+		if a.Asset == asset {
+			return a, true
+		}
+	}
+
+	return AssetExposure{}, false
+}
+
+func (e *Exposure) PlainText() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Exposure in %s: net = %v, gross = %v\n", e.ValuationCurrency, e.NetValue, e.GrossValue))
+	for _, a := range e.Assets {
+		sb.WriteString(fmt.Sprintf("- %s: net = %v", a.Asset, a.Net))
+		if a.Debt.Sign() > 0 {
+			sb.WriteString(fmt.Sprintf(", debt = %v", a.Debt))
+		}
+		if !a.Futures.IsZero() {
+			sb.WriteString(fmt.Sprintf(", futures = %v", a.Futures))
+		}
+
+		if a.Price.IsZero() {
+			sb.WriteString(", no price\n")
+		} else {
+			sb.WriteString(fmt.Sprintf(", value = %v %s\n", a.NetValue, e.ValuationCurrency))
+		}
+	}
+
+	return sb.String()
+}
+
+// Exposure nets the balances, the margin debts and the futures positions of all the sessions per asset,
+// and values them with the ticker prices of the AccountValueCalculator of each session.
+func (environ *Environment) Exposure(ctx context.Context, valuationCurrency string) (*Exposure, error) {
+	if valuationCurrency == "" {
+		valuationCurrency = DefaultExposureValuationCurrency
+	}
+
+	calculators := make(map[string]*AccountValueCalculator)
+	for name, session := range environ.Sessions() {
+		calculator := NewAccountValueCalculator(session, valuationCurrency)
+		if !environ.IsBackTesting() {
+			if err := calculator.UpdatePrices(ctx); err != nil {
+				log.WithError(err).Warnf("[%s] can not update the prices for the exposure, using the last prices", name)
+			}
+		}
+
+		calculators[name] = calculator
+	}
+
+	exposure := consolidateExposure(environ.Sessions(), calculators, valuationCurrency)
+	exposure.Time = time.Now()
+	updateExposureMetrics(exposure)
+	return exposure, nil
+}
+
+// RunExposureMetrics updates the exposure metrics periodically until the context is done
+func (environ *Environment) RunExposureMetrics(ctx context.Context, valuationCurrency string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := environ.Exposure(ctx, valuationCurrency); err != nil {
+			log.WithError(err).Error("can not update the exposure metrics")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func consolidateExposure(sessions map[string]*ExchangeSession, calculators map[string]*AccountValueCalculator, valuationCurrency string) *Exposure {
+	assets := make(map[string]*AssetExposure)
+	get := func(asset string) *AssetExposure {
+		a, ok := assets[asset]
+		if !ok {
+			a = &AssetExposure{Asset: asset, Sessions: make(map[string]fixedpoint.Value)}
+			assets[asset] = a
+		}
+		return a
+	}
+
+	for name, session := range sessions {
+		for currency, balance := range session.GetAccount().Balances() {
+			if balance.Total().IsZero() && balance.Debt().IsZero() {
+				continue
+			}
+
+			a := get(currency)
+			a.Balance = a.Balance.Add(balance.Total())
+			a.Debt = a.Debt.Add(balance.Debt())
+			a.Sessions[name] = a.Sessions[name].Add(balance.Total().Sub(balance.Debt()))
+		}
+
+		if !session.Futures && !session.IsolatedFutures {
+			continue
+		}
+
+		if info := session.GetAccount().FuturesInfo; info != nil {
+			for _, position := range info.Positions {
+				if position.Base.IsZero() {
+					continue
+				}
+
+				a := get(position.BaseCurrency)
+				a.Futures = a.Futures.Add(position.Base)
+				a.Sessions[name] = a.Sessions[name].Add(position.Base)
+			}
+		} else {
+			// the futures account info is not available, use the positions of the session instead
+			for _, position := range session.Positions() {
+				if position.Base.IsZero() {
+					continue
+				}
+
+				a := get(position.Market.BaseCurrency)
+				a.Futures = a.Futures.Add(position.Base)
+				a.Sessions[name] = a.Sessions[name].Add(position.Base)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(sessions))
+	for name := range sessions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	exposure := &Exposure{ValuationCurrency: valuationCurrency}
+	for _, a := range assets {
+		a.Net = a.Balance.Sub(a.Debt).Add(a.Futures)
+
+		for _, name := range names {
+			calculator, ok := calculators[name]
+			if !ok {
+				continue
+			}
+
+			if price, ok := calculator.Price(a.Asset); ok {
+				a.Price = price
+				break
+			}
+		}
+
+		a.NetValue = a.Net.Mul(a.Price)
+		exposure.NetValue = exposure.NetValue.Add(a.NetValue)
+		exposure.GrossValue = exposure.GrossValue.Add(a.NetValue.Abs())
+		exposure.Assets = append(exposure.Assets, *a)
+	}
+
+	sort.Slice(exposure.Assets, func(i, j int) bool {
+		vi, vj := exposure.Assets[i].NetValue.Abs(), exposure.Assets[j].NetValue.Abs()
+		if vi.Compare(vj) == 0 {
+			return exposure.Assets[i].Asset < exposure.Assets[j].Asset
+		}
+		return vi.Compare(vj) > 0
+	})
+
+	return exposure
+}
+
+func updateExposureMetrics(exposure *Exposure) {
+	// reset the asset gauges, so that the assets no longer held are not reported with the stale values
+	metricsExposureNet.Reset()
+	metricsExposureNetValue.Reset()
+
+	for _, a := range exposure.Assets {
+		metricsExposureNet.With(prometheus.Labels{"asset": a.Asset}).Set(a.Net.Float64())
+		if !a.Price.IsZero() {
+			metricsExposureNetValue.With(prometheus.Labels{"asset": a.Asset, "valuation": exposure.ValuationCurrency}).Set(a.NetValue.Float64())
+		}
+	}
+
+	metricsExposureTotalValue.With(prometheus.Labels{"type": "net", "valuation": exposure.ValuationCurrency}).Set(exposure.NetValue.Float64())
+	metricsExposureTotalValue.With(prometheus.Labels{"type": "gross", "valuation": exposure.ValuationCurrency}).Set(exposure.GrossValue.Float64())
+}
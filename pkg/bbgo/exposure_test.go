@@ -0,0 +1,98 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestConsolidateExposure(t *testing.T) {
+	spot := newRiskTestSession(t, "binance")
+
+	margin := newRiskTestSession(t, "max")
+	margin.Margin = true
+	margin.Account.UpdateBalances(types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.5), Borrowed: fixedpoint.NewFromInt(2), Interest: fixedpoint.NewFromFloat(0.1)},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromInt(1000)},
+		"DOGE": {Currency: "DOGE", Available: fixedpoint.NewFromInt(100)},
+	})
+
+	futures := newRiskTestSession(t, "binance-futures")
+	futures.Futures = true
+	futures.Account.UpdateBalances(types.BalanceMap{
+		"BTC":  {Currency: "BTC"},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromInt(5000)},
+	})
+	futures.Account.FuturesInfo = &types.FuturesAccountInfo{
+		Positions: types.FuturesPositionMap{
+			"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", Base: fixedpoint.NewFromFloat(-0.4)},
+		},
+	}
+
+	sessions := map[string]*ExchangeSession{
+		spot.Name:    spot,
+		margin.Name:  margin,
+		futures.Name: futures,
+	}
+
+	calculators := map[string]*AccountValueCalculator{}
+	for name, session := range sessions {
+		calculators[name] = NewAccountValueCalculator(session, "USDT")
+	}
+
+	// the ticker price takes precedence over the last price of the session
+	calculators["binance"].prices["BTCUSDT"] = fixedpoint.NewFromInt(21000)
+
+	exposure := consolidateExposure(sessions, calculators, "USDT")
+
+	btc, ok := exposure.Asset("BTC")
+	if assert.True(t, ok) {
+		assert.Equal(t, "1.5", btc.Balance.String())
+		assert.Equal(t, "2.1", btc.Debt.String())
+		assert.Equal(t, "-0.4", btc.Futures.String())
+		assert.Equal(t, "-1", btc.Net.String())
+		assert.Equal(t, "21000", btc.Price.String())
+		assert.Equal(t, "-21000", btc.NetValue.String())
+		assert.Equal(t, "1", btc.Sessions["binance"].String())
+		assert.Equal(t, "-1.6", btc.Sessions["max"].String())
+		assert.Equal(t, "-0.4", btc.Sessions["binance-futures"].String())
+	}
+
+	usdt, ok := exposure.Asset("USDT")
+	if assert.True(t, ok) {
+		assert.Equal(t, "106000", usdt.Net.String())
+	}
+
+	doge, ok := exposure.Asset("DOGE")
+	if assert.True(t, ok) {
+		assert.Equal(t, "100", doge.Net.String())
+		assert.True(t, doge.Price.IsZero())
+	}
+
+	assert.Equal(t, "85000", exposure.NetValue.String())
+	assert.Equal(t, "127000", exposure.GrossValue.String())
+	assert.Equal(t, "USDT", exposure.Assets[0].Asset)
+	assert.Contains(t, exposure.PlainText(), "DOGE: net = 100, no price")
+}
+
+func TestUpdateExposureMetrics(t *testing.T) {
+	updateExposureMetrics(&Exposure{
+		ValuationCurrency: "USDT",
+		Assets: []AssetExposure{
+			{Asset: "BTC", Net: fixedpoint.One},
+			{Asset: "ETH", Net: fixedpoint.NewFromInt(10)},
+		},
+	})
+	assert.Equal(t, 2, testutil.CollectAndCount(metricsExposureNet))
+
+	// the assets no longer held are removed
+	updateExposureMetrics(&Exposure{
+		ValuationCurrency: "USDT",
+		Assets:            []AssetExposure{{Asset: "BTC", Net: fixedpoint.One}},
+	})
+	assert.Equal(t, 1, testutil.CollectAndCount(metricsExposureNet))
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/c9s/bbgo/pkg/dynamic"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/interact"
+	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/types"
 )
 
@@ -46,6 +47,8 @@ type CoreInteraction struct {
 	exchangeStrategies    map[string]SingleExchangeStrategy
 	closePositionContext  closePositionContext
 	modifyPositionContext modifyPositionContext
+	orderEntryContext     orderEntryContext
+	openOrdersContext     openOrdersContext
 }
 
 func NewCoreInteraction(environment *Environment, trader *Trader) *CoreInteraction {
@@ -92,7 +95,7 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 
 		reply.Message(message)
 		return nil
-	})
+	}).RequireRole(interact.RoleViewer)
 
 	i.PrivateCommand("/balances", "Show balances", func(reply interact.Reply) error {
 		reply.Message("Please select an exchange session")
@@ -119,7 +122,23 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 
 		reply.Message(message)
 		return nil
-	})
+	}).RequireRole(interact.RoleViewer)
+
+	i.PrivateCommand("/exposure", "Show the consolidated exposure of all sessions", func(reply interact.Reply) error {
+		exposure, err := it.environment.Exposure(context.Background(), DefaultExposureValuationCurrency)
+		if err != nil {
+			reply.Message(fmt.Sprintf("Failed to calculate the exposure, %s", err.Error()))
+			return err
+		}
+
+		if len(exposure.Assets) == 0 {
+			reply.Message("No exposure")
+			return nil
+		}
+
+		reply.Message(exposure.PlainText())
+		return nil
+	}).RequireRole(interact.RoleViewer)
 
 	i.PrivateCommand("/position", "Show Position", func(reply interact.Reply) error {
 		// it.trader.exchangeStrategies
@@ -158,7 +177,7 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 		}
 
 		return nil
-	})
+	}).RequireRole(interact.RoleViewer)
 
 	i.PrivateCommand("/resetposition", "Reset position", func(reply interact.Reply) error {
 		strategies, err := filterStrategies(it.exchangeStrategies, func(s SingleExchangeStrategy) bool {
@@ -201,7 +220,7 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 		}
 
 		return err
-	})
+	}).RequireRole(interact.RoleOperator).Confirm()
 
 	i.PrivateCommand("/closeposition", "Close position", func(reply interact.Reply) error {
 		// it.trader.exchangeStrategies
@@ -270,7 +289,7 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 
 		reply.Message("Done")
 		return nil
-	})
+	}).RequireRole(interact.RoleOperator).Confirm()
 
 	i.PrivateCommand("/status", "Strategy Status", func(reply interact.Reply) error {
 		// it.trader.exchangeStrategies
@@ -310,7 +329,7 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 		}
 
 		return nil
-	})
+	}).RequireRole(interact.RoleViewer)
 
 	i.PrivateCommand("/suspend", "Suspend Strategy", func(reply interact.Reply) error {
 		// it.trader.exchangeStrategies
@@ -354,7 +373,7 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 
 		reply.Message(fmt.Sprintf("Strategy %s is now suspended.", signature))
 		return nil
-	})
+	}).RequireRole(interact.RoleOperator)
 
 	i.PrivateCommand("/resume", "Resume Strategy", func(reply interact.Reply) error {
 		// it.trader.exchangeStrategies
@@ -398,7 +417,7 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 
 		reply.Message(fmt.Sprintf("Strategy %s is now resumed.", signature))
 		return nil
-	})
+	}).RequireRole(interact.RoleOperator)
 
 	i.PrivateCommand("/emergencystop", "Emergency Stop", func(reply interact.Reply) error {
 		// it.trader.exchangeStrategies
@@ -434,7 +453,45 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 
 		reply.Message(fmt.Sprintf("Strategy %s stopped and the position closed.", signature))
 		return nil
-	})
+	}).RequireRole(interact.RoleAdmin).Confirm()
+
+	i.PrivateCommand("/resetbreaker", "Reset Circuit Breaker", func(reply interact.Reply) error {
+		breaker := it.trader.CircuitBreaker()
+		if breaker == nil {
+			reply.Message("Circuit breaker is not configured")
+			return nil
+		}
+
+		scopes := breaker.TrippedScopes()
+		if len(scopes) == 0 {
+			reply.Message("No circuit breaker is tripped")
+			return nil
+		}
+
+		for _, scope := range scopes {
+			reply.AddButton(scope, "scope", scope)
+		}
+		reply.Message("Please choose the circuit breaker to reset")
+		return nil
+	}).Next(func(scope string, reply interact.Reply) error {
+		if kc, ok := reply.(interact.KeyboardController); ok {
+			kc.RemoveKeyboard()
+		}
+
+		breaker := it.trader.CircuitBreaker()
+		if breaker == nil {
+			reply.Message("Circuit breaker is not configured")
+			return fmt.Errorf("circuit breaker is not configured")
+		}
+
+		if err := breaker.Reset(scope); err != nil {
+			reply.Message(fmt.Sprintf("Failed to reset the circuit breaker, %s", err.Error()))
+			return err
+		}
+
+		reply.Message(fmt.Sprintf("Circuit breaker %s is reset.", scope))
+		return nil
+	}).RequireRole(interact.RoleAdmin)
 
 	// Position updater
 	i.PrivateCommand("/modifyposition", "Modify Strategy Position", func(reply interact.Reply) error {
@@ -508,21 +565,47 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 
 		reply.Message(fmt.Sprintf("Position of strategy %s modified.", it.modifyPositionContext.signature))
 		return nil
-	})
+	}).RequireRole(interact.RoleAdmin)
+
+	i.PrivateCommand("/audit", "Show the audit log of the commands", func(reply interact.Reply) error {
+		if it.environment.AuditService == nil {
+			reply.Message("Audit log requires the database, please configure the database first")
+			return nil
+		}
+
+		logs, err := it.environment.AuditService.Query(context.Background(), service.QueryAuditLogsOptions{Limit: 20})
+		if err != nil {
+			reply.Message(fmt.Sprintf("Failed to query the audit log: %v", err))
+			return err
+		}
+
+		if len(logs) == 0 {
+			reply.Message("No audit log found")
+			return nil
+		}
+
+		message := "Recent commands:\n"
+		for _, entry := range logs {
+			message += entry.String() + "\n"
+		}
+
+		reply.Message(message)
+		return nil
+	}).RequireRole(interact.RoleAdmin)
+
+	it.orderCommands(i)
+	it.chartCommands(i)
 }
 
 func (it *CoreInteraction) Initialize() error {
 	// re-map exchange strategies into the signature-object map
-	for sessionID, strategies := range it.trader.exchangeStrategies {
-		for _, strategy := range strategies {
-			signature, err := getStrategySignature(strategy)
-			if err != nil {
-				return err
-			}
+	strategies, err := it.trader.StrategiesBySignature()
+	if err != nil {
+		return err
+	}
 
-			key := sessionID + "." + signature
-			it.exchangeStrategies[key] = strategy
-		}
+	for key, strategy := range strategies {
+		it.exchangeStrategies[key] = strategy
 	}
 	return nil
 }
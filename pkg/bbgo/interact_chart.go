@@ -0,0 +1,183 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/interact"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	defaultChartLimit = 120
+	maxChartLimit     = 1000
+)
+
+var defaultChartInterval = types.Interval1h
+
+const chartUsage = `Usage: /chart [session:]SYMBOL [INTERVAL] [LIMIT]
+
+e.g. /chart BTCUSDT 4h 200
+
+The chart is rendered with the indicators, the trades, the open orders and the positions of the running strategies.`
+
+// chartRequest is the parsed arguments of the /chart command
+type chartRequest struct {
+	session  string
+	symbol   string
+	interval types.Interval
+	limit    int
+}
+
+// chartCommands registers the /chart command
+func (it *CoreInteraction) chartCommands(i *interact.Interact) {
+	i.PrivateCommand("/chart", "Render the candlestick chart of a symbol, e.g. /chart BTCUSDT 1h", func(reply interact.Reply, args ...string) error {
+		if len(args) == 0 {
+			reply.Message(chartUsage)
+			return nil
+		}
+
+		req, err := parseChartArgs(args)
+		if err != nil {
+			reply.Message(fmt.Sprintf("%s\n\n%s", err.Error(), chartUsage))
+			return err
+		}
+
+		session, err := it.chartSession(req)
+		if err != nil {
+			reply.Message(err.Error())
+			return err
+		}
+
+		klines, err := queryChartKLines(context.Background(), session, req)
+		if err != nil {
+			reply.Message(fmt.Sprintf("Unable to query the %s %s klines: %s", req.symbol, req.interval, err.Error()))
+			return err
+		}
+
+		c := NewSessionChart(session, req.symbol, req.interval, klines)
+		if it.trader != nil {
+			c = it.trader.SessionChart(session, req.symbol, req.interval, klines)
+		}
+
+		buffer, err := c.RenderBuffer()
+		if err != nil {
+			reply.Message(fmt.Sprintf("Unable to render the chart: %s", err.Error()))
+			return err
+		}
+
+		SendPhoto(buffer)
+		reply.Message(fmt.Sprintf("Rendered the %s %s chart of %s with %d klines", req.symbol, req.interval, session.Name, len(klines)))
+		return nil
+	}).RequireRole(interact.RoleViewer)
+}
+
+func parseChartArgs(args []string) (req chartRequest, err error) {
+	req.interval = defaultChartInterval
+	req.limit = defaultChartLimit
+
+	req.symbol = args[0]
+	if parts := strings.SplitN(req.symbol, ":", 2); len(parts) == 2 {
+		req.session = parts[0]
+		req.symbol = parts[1]
+	}
+	req.symbol = strings.ToUpper(req.symbol)
+
+	if len(args) > 1 {
+		req.interval = types.Interval(args[1])
+		if _, ok := types.SupportedIntervals[req.interval]; !ok {
+			return req, fmt.Errorf("unsupported interval %q", args[1])
+		}
+	}
+
+	if len(args) > 2 {
+		req.limit, err = strconv.Atoi(args[2])
+		if err != nil || req.limit < 2 || req.limit > maxChartLimit {
+			return req, fmt.Errorf("invalid limit %q, the limit should be between 2 and %d", args[2], maxChartLimit)
+		}
+	}
+
+	if len(args) > 3 {
+		return req, fmt.Errorf("too many arguments: %s", strings.Join(args[3:], " "))
+	}
+
+	return req, nil
+}
+
+// chartSession returns the session of the chart, if the session is not specified,
+// the session that stores the klines of the symbol is preferred.
+func (it *CoreInteraction) chartSession(req chartRequest) (*ExchangeSession, error) {
+	if len(req.session) > 0 {
+		session, ok := it.environment.Session(req.session)
+		if !ok {
+			return nil, fmt.Errorf("session %s not found", req.session)
+		}
+
+		return session, nil
+	}
+
+	var names []string
+	for name := range it.environment.Sessions() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var candidate *ExchangeSession
+	for _, name := range names {
+		session, _ := it.environment.Session(name)
+		if store, ok := session.marketDataStores[req.symbol]; ok {
+			if _, ok := store.KLinesOfInterval(req.interval); ok {
+				return session, nil
+			}
+		}
+
+		if _, ok := session.Market(req.symbol); ok && candidate == nil {
+			candidate = session
+		}
+	}
+
+	if candidate == nil {
+		return nil, fmt.Errorf("symbol %s not found in the sessions", req.symbol)
+	}
+
+	return candidate, nil
+}
+
+// queryChartKLines returns the klines from the market data store,
+// the klines are queried from the exchange when the store does not have enough klines.
+func queryChartKLines(ctx context.Context, session *ExchangeSession, req chartRequest) ([]types.KLine, error) {
+	var klines []types.KLine
+	if store, ok := session.marketDataStores[req.symbol]; ok {
+		if window, ok := store.KLinesOfInterval(req.interval); ok {
+			klines = window.Tail(req.limit)
+		}
+	}
+
+	if len(klines) >= req.limit {
+		return klines, nil
+	}
+
+	endTime := time.Now()
+	queried, err := session.Exchange.QueryKLines(ctx, req.symbol, req.interval, types.KLineQueryOptions{
+		Limit:   req.limit,
+		EndTime: &endTime,
+	})
+	if err != nil {
+		// fall back to the stored klines
+		if len(klines) >= 2 {
+			return klines, nil
+		}
+
+		return nil, err
+	}
+
+	if len(queried) > req.limit {
+		queried = queried[len(queried)-req.limit:]
+	}
+
+	return queried, nil
+}
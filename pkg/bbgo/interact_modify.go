@@ -62,5 +62,5 @@ func RegisterModifier(s interface{}) {
 			newVal = string(e)
 		}
 		reply.Message(fmt.Sprintf("update to %v successfully", newVal))
-	})
+	}).RequireRole(interact.RoleAdmin)
 }
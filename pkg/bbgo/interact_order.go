@@ -0,0 +1,433 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/interact"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// orderEntryContext keeps the inputs of the /buy and /sell commands between the steps
+type orderEntryContext struct {
+	session   *ExchangeSession
+	market    types.Market
+	side      types.SideType
+	lastPrice fixedpoint.Value
+	quantity  fixedpoint.Value
+	order     types.SubmitOrder
+}
+
+// openOrdersContext keeps the open orders listed by the /cancel command
+type openOrdersContext struct {
+	session *ExchangeSession
+	symbol  string
+	orders  []types.Order
+}
+
+// orderCommands registers the guided order entry commands: /buy, /sell, /orders and /cancel
+func (it *CoreInteraction) orderCommands(i *interact.Interact) {
+	it.orderEntryCommand(i, "/buy", "Place a buy order", types.SideTypeBuy)
+	it.orderEntryCommand(i, "/sell", "Place a sell order", types.SideTypeSell)
+
+	i.PrivateCommand("/orders", "List the open orders", func(reply interact.Reply) error {
+		return it.replySessionButtons(reply)
+	}).Next(func(sessionName string, reply interact.Reply) error {
+		return it.selectOrderSession(sessionName, reply)
+	}).Next(func(symbol string, reply interact.Reply) error {
+		if kc, ok := reply.(interact.KeyboardController); ok {
+			kc.RemoveKeyboard()
+		}
+
+		orders, err := it.queryOpenOrders(symbol)
+		if err != nil {
+			reply.Message(fmt.Sprintf("Failed to query the open orders, %s", err.Error()))
+			return err
+		}
+
+		if len(orders) == 0 {
+			reply.Message(fmt.Sprintf("No open order of %s", it.openOrdersContext.symbol))
+			return nil
+		}
+
+		message := fmt.Sprintf("Open orders of %s:\n", it.openOrdersContext.symbol)
+		for _, order := range orders {
+			message += "- " + formatOpenOrder(order) + "\n"
+		}
+
+		reply.Message(message)
+		return nil
+	}).RequireRole(interact.RoleViewer)
+
+	i.PrivateCommand("/cancel", "Cancel the open orders", func(reply interact.Reply) error {
+		return it.replySessionButtons(reply)
+	}).Next(func(sessionName string, reply interact.Reply) error {
+		return it.selectOrderSession(sessionName, reply)
+	}).Next(func(symbol string, reply interact.Reply) error {
+		orders, err := it.queryOpenOrders(symbol)
+		if err != nil {
+			reply.Message(fmt.Sprintf("Failed to query the open orders, %s", err.Error()))
+			return err
+		}
+
+		if len(orders) == 0 {
+			reply.Message(fmt.Sprintf("No open order of %s, please choose another symbol", it.openOrdersContext.symbol))
+			return fmt.Errorf("no open order of %s", it.openOrdersContext.symbol)
+		}
+
+		reply.Message("Please choose the order to cancel")
+		for _, order := range orders {
+			reply.AddButton(formatOpenOrder(order), "order", strconv.FormatUint(order.OrderID, 10))
+		}
+		reply.AddButton("All orders", "order", "all")
+		return nil
+	}).Next(func(orderID string, reply interact.Reply) error {
+		var orders []types.Order
+		for _, order := range it.openOrdersContext.orders {
+			if orderID == "all" || strconv.FormatUint(order.OrderID, 10) == orderID {
+				orders = append(orders, order)
+			}
+		}
+
+		if len(orders) == 0 {
+			reply.Message(fmt.Sprintf("Order %s not found", orderID))
+			return fmt.Errorf("order %s not found", orderID)
+		}
+
+		if kc, ok := reply.(interact.KeyboardController); ok {
+			kc.RemoveKeyboard()
+		}
+
+		executor := it.sessionOrderExecutor(it.openOrdersContext.session)
+		if err := executor.CancelOrders(context.Background(), orders...); err != nil {
+			reply.Message(fmt.Sprintf("Failed to cancel the orders, %s", err.Error()))
+			return err
+		}
+
+		reply.Message(fmt.Sprintf("%d order(s) of %s cancelled", len(orders), it.openOrdersContext.symbol))
+		return nil
+	}).RequireRole(interact.RoleOperator)
+}
+
+func (it *CoreInteraction) orderEntryCommand(i *interact.Interact, command, desc string, side types.SideType) {
+	i.PrivateCommand(command, desc, func(reply interact.Reply) error {
+		it.orderEntryContext = orderEntryContext{side: side}
+		return it.replySessionButtons(reply)
+	}).Next(func(sessionName string, reply interact.Reply) error {
+		session, ok := it.environment.Session(sessionName)
+		if !ok {
+			reply.Message(fmt.Sprintf("Session %s not found", sessionName))
+			return fmt.Errorf("session %s not found", sessionName)
+		}
+
+		it.orderEntryContext.session = session
+		replySymbolButtons(session, reply)
+		return nil
+	}).Next(func(symbol string, reply interact.Reply) error {
+		session := it.orderEntryContext.session
+		market, ok := session.Market(strings.ToUpper(symbol))
+		if !ok {
+			reply.Message(fmt.Sprintf("Market %s not found in session %s", symbol, session.Name))
+			return fmt.Errorf("market %s not found", symbol)
+		}
+
+		lastPrice, err := queryLastPrice(session, market.Symbol)
+		if err != nil {
+			reply.Message(fmt.Sprintf("Failed to query the last price of %s, %s", market.Symbol, err.Error()))
+			return err
+		}
+
+		it.orderEntryContext.market = market
+		it.orderEntryContext.lastPrice = lastPrice
+
+		if kc, ok := reply.(interact.KeyboardController); ok {
+			kc.RemoveKeyboard()
+		}
+
+		reply.Message(fmt.Sprintf("The last price of %s is %s, please enter the quantity in %s (min %s)",
+			market.Symbol,
+			market.FormatPrice(lastPrice),
+			market.BaseCurrency,
+			market.MinQuantity.String()))
+		return nil
+	}).Next(func(quantityStr string, reply interact.Reply) error {
+		quantity, err := fixedpoint.NewFromString(quantityStr)
+		if err != nil || quantity.Sign() <= 0 {
+			reply.Message(fmt.Sprintf("%q is not a valid quantity", quantityStr))
+			return fmt.Errorf("invalid quantity %q", quantityStr)
+		}
+
+		it.orderEntryContext.quantity = quantity
+
+		reply.Message("Please enter the limit price, or choose market to place a market order")
+		reply.AddButton("Market", "price", "market")
+		reply.AddButton(it.orderEntryContext.market.FormatPrice(it.orderEntryContext.lastPrice), "price",
+			it.orderEntryContext.market.FormatPrice(it.orderEntryContext.lastPrice))
+		return nil
+	}).Next(func(priceStr string, reply interact.Reply) error {
+		c := &it.orderEntryContext
+		order, preview, err := buildOrderPreview(c.session, c.market, c.side, c.quantity, priceStr, c.lastPrice)
+		if err != nil {
+			reply.Message(err.Error())
+			return err
+		}
+
+		c.order = order
+
+		reply.Message(preview + "\nReply yes to submit the order.")
+		reply.AddButton("Yes", "confirm", "yes")
+		reply.AddButton("No", "confirm", "no")
+		return nil
+	}).Next(func(answer string, reply interact.Reply) error {
+		if kc, ok := reply.(interact.KeyboardController); ok {
+			kc.RemoveKeyboard()
+		}
+
+		if a := strings.ToLower(answer); a != "yes" && a != "y" {
+			reply.Message("Order is cancelled")
+			return nil
+		}
+
+		c := &it.orderEntryContext
+		executor := it.sessionOrderExecutor(c.session)
+		createdOrders, err := executor.SubmitOrders(context.Background(), c.order)
+		if err != nil {
+			reply.Message(fmt.Sprintf("Failed to submit the order, %s", err.Error()))
+			return err
+		}
+
+		// the flow ends here, so the rejected order is not submitted again by another reply
+		if len(createdOrders) == 0 {
+			reply.Message("The order is rejected by the risk controls")
+			return nil
+		}
+
+		message := "Order submitted:\n"
+		for _, order := range createdOrders {
+			message += "- " + formatOpenOrder(order) + "\n"
+		}
+
+		reply.Message(message)
+		return nil
+	}).RequireRole(interact.RoleOperator)
+}
+
+func (it *CoreInteraction) replySessionButtons(reply interact.Reply) error {
+	var names []string
+	for name := range it.environment.Sessions() {
+		names = append(names, name)
+	}
+
+	if len(names) == 0 {
+		reply.Message("No exchange session")
+		return fmt.Errorf("no exchange session")
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		reply.AddButton(name, "session", name)
+	}
+
+	reply.Message("Please select an exchange session")
+	return nil
+}
+
+func (it *CoreInteraction) selectOrderSession(sessionName string, reply interact.Reply) error {
+	session, ok := it.environment.Session(sessionName)
+	if !ok {
+		reply.Message(fmt.Sprintf("Session %s not found", sessionName))
+		return fmt.Errorf("session %s not found", sessionName)
+	}
+
+	it.openOrdersContext = openOrdersContext{session: session}
+	replySymbolButtons(session, reply)
+	return nil
+}
+
+func (it *CoreInteraction) queryOpenOrders(symbol string) ([]types.Order, error) {
+	session := it.openOrdersContext.session
+	symbol = strings.ToUpper(symbol)
+	orders, err := session.Exchange.QueryOpenOrders(context.Background(), symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	it.openOrdersContext.symbol = symbol
+	it.openOrdersContext.orders = orders
+	return orders, nil
+}
+
+// sessionOrderExecutor returns the order executor of the session,
+// the orders are routed through the session risk controls if they are configured.
+func (it *CoreInteraction) sessionOrderExecutor(session *ExchangeSession) OrderExecutor {
+	if it.trader != nil {
+		return it.trader.getSessionOrderExecutor(session.Name)
+	}
+
+	return session.OrderExecutor
+}
+
+// replySymbolButtons adds the subscribed symbols of the session as the buttons, the other symbols can be entered directly
+func replySymbolButtons(session *ExchangeSession, reply interact.Reply) {
+	symbols := make(map[string]struct{})
+	for sub := range session.Subscriptions {
+		if len(sub.Symbol) > 0 {
+			symbols[sub.Symbol] = struct{}{}
+		}
+	}
+
+	var sorted []string
+	for symbol := range symbols {
+		sorted = append(sorted, symbol)
+	}
+	sort.Strings(sorted)
+
+	for _, symbol := range sorted {
+		reply.AddButton(symbol, "symbol", symbol)
+	}
+
+	reply.Message("Please choose or enter the symbol")
+}
+
+func queryLastPrice(session *ExchangeSession, symbol string) (fixedpoint.Value, error) {
+	if price, ok := session.LastPrice(symbol); ok && price.Sign() > 0 {
+		return price, nil
+	}
+
+	ticker, err := session.Exchange.QueryTicker(context.Background(), symbol)
+	if err != nil {
+		return fixedpoint.Zero, err
+	}
+
+	return ticker.Last, nil
+}
+
+// buildOrderPreview validates the order against the market and the balances,
+// and returns the submit order with the preview text of the estimated cost and fee.
+func buildOrderPreview(session *ExchangeSession, market types.Market, side types.SideType, quantity fixedpoint.Value, priceStr string, lastPrice fixedpoint.Value) (types.SubmitOrder, string, error) {
+	order := types.SubmitOrder{
+		Symbol:   market.Symbol,
+		Side:     side,
+		Type:     types.OrderTypeMarket,
+		Quantity: quantity,
+		Market:   market,
+	}
+
+	price := lastPrice
+	feeRate := session.TakerFeeRate
+	if !strings.EqualFold(priceStr, "market") {
+		var err error
+		price, err = fixedpoint.NewFromString(priceStr)
+		if err != nil || price.Sign() <= 0 {
+			return order, "", fmt.Errorf("%q is not a valid price", priceStr)
+		}
+
+		order.Type = types.OrderTypeLimit
+		order.Price = price
+		order.TimeInForce = types.TimeInForceGTC
+		feeRate = session.MakerFeeRate
+	}
+
+	if err := validateOrder(market, order, price); err != nil {
+		return order, "", err
+	}
+
+	notional := quantity.Mul(price)
+	fee := notional.Mul(feeRate)
+
+	account := session.GetAccount()
+	switch side {
+	case types.SideTypeBuy:
+		if balance, ok := account.Balance(market.QuoteCurrency); !ok || balance.Available.Compare(notional.Add(fee)) < 0 {
+			return order, "", fmt.Errorf("insufficient %s balance, available %s, required %s",
+				market.QuoteCurrency, balance.Available.String(), notional.Add(fee).String())
+		}
+
+	case types.SideTypeSell:
+		if balance, ok := account.Balance(market.BaseCurrency); !ok || balance.Available.Compare(quantity) < 0 {
+			return order, "", fmt.Errorf("insufficient %s balance, available %s, required %s",
+				market.BaseCurrency, balance.Available.String(), quantity.String())
+		}
+	}
+
+	priceText := market.FormatPrice(price)
+	if order.Type == types.OrderTypeMarket {
+		priceText = "market price (last " + priceText + ")"
+	}
+
+	action := "Buy"
+	if side == types.SideTypeSell {
+		action = "Sell"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %s %s with %s order at %s on %s\n",
+		action, market.FormatQuantity(quantity), market.BaseCurrency,
+		strings.ToLower(string(order.Type)), priceText, session.Name))
+
+	switch side {
+	case types.SideTypeBuy:
+		sb.WriteString(fmt.Sprintf("Estimated cost: %s %s\n", market.FormatPrice(notional.Add(fee)), market.QuoteCurrency))
+	case types.SideTypeSell:
+		sb.WriteString(fmt.Sprintf("Estimated proceeds: %s %s\n", market.FormatPrice(notional.Sub(fee)), market.QuoteCurrency))
+	}
+
+	sb.WriteString(fmt.Sprintf("Estimated fee: %s %s (%s)\n", market.FormatPrice(fee), market.QuoteCurrency, feeRate.Percentage()))
+	return order, sb.String(), nil
+}
+
+// validateOrder checks the quantity and the price of the order against the market filters
+func validateOrder(market types.Market, order types.SubmitOrder, price fixedpoint.Value) error {
+	quantity := order.Quantity
+	if quantity.Compare(market.MinQuantity) < 0 {
+		return fmt.Errorf("quantity %s is less than the min quantity %s", quantity.String(), market.MinQuantity.String())
+	}
+
+	if market.MaxQuantity.Sign() > 0 && quantity.Compare(market.MaxQuantity) > 0 {
+		return fmt.Errorf("quantity %s is greater than the max quantity %s", quantity.String(), market.MaxQuantity.String())
+	}
+
+	if market.StepSize.Sign() > 0 && market.TruncateQuantity(quantity).Compare(quantity) != 0 {
+		return fmt.Errorf("quantity %s does not match the step size %s", quantity.String(), market.StepSize.String())
+	}
+
+	if order.Type == types.OrderTypeLimit {
+		if market.MinPrice.Sign() > 0 && price.Compare(market.MinPrice) < 0 {
+			return fmt.Errorf("price %s is less than the min price %s", price.String(), market.MinPrice.String())
+		}
+
+		if market.MaxPrice.Sign() > 0 && price.Compare(market.MaxPrice) > 0 {
+			return fmt.Errorf("price %s is greater than the max price %s", price.String(), market.MaxPrice.String())
+		}
+
+		if market.TickSize.Sign() > 0 && market.TruncatePrice(price).Compare(price) != 0 {
+			return fmt.Errorf("price %s does not match the tick size %s", price.String(), market.TickSize.String())
+		}
+	}
+
+	notional := quantity.Mul(price)
+	if notional.Compare(market.MinNotional) < 0 {
+		return fmt.Errorf("order amount %s is less than the min notional %s", notional.String(), market.MinNotional.String())
+	}
+
+	if notional.Compare(market.MinAmount) < 0 {
+		return fmt.Errorf("order amount %s is less than the min amount %s", notional.String(), market.MinAmount.String())
+	}
+
+	return nil
+}
+
+func formatOpenOrder(order types.Order) string {
+	price := order.Market.FormatPrice(order.Price)
+	if len(order.Market.Symbol) == 0 {
+		price = order.Price.String()
+	}
+
+	return fmt.Sprintf("#%d %s %s %s %s @ %s, executed %s",
+		order.OrderID, order.Symbol, order.Side, order.Type,
+		order.Quantity.String(), price, order.ExecutedQuantity.String())
+}
@@ -0,0 +1,104 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func newOrderEntryTestSession(t *testing.T) (*ExchangeSession, types.Market) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	market := types.Market{
+		Symbol:        "BTCUSDT",
+		BaseCurrency:  "BTC",
+		QuoteCurrency: "USDT",
+		MinNotional:   fixedpoint.NewFromInt(10),
+		MinQuantity:   fixedpoint.MustNewFromString("0.0001"),
+		StepSize:      fixedpoint.MustNewFromString("0.0001"),
+		TickSize:      fixedpoint.MustNewFromString("0.01"),
+		MinPrice:      fixedpoint.MustNewFromString("0.01"),
+	}
+
+	session := NewExchangeSession("binance", mockEx)
+	session.MakerFeeRate = fixedpoint.MustNewFromString("0.001")
+	session.TakerFeeRate = fixedpoint.MustNewFromString("0.002")
+	session.markets[market.Symbol] = market
+	session.Account.UpdateBalances(types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.MustNewFromString("0.5")},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromInt(10000)},
+	})
+
+	return session, market
+}
+
+func Test_buildOrderPreview(t *testing.T) {
+	session, market := newOrderEntryTestSession(t)
+	lastPrice := fixedpoint.NewFromInt(20000)
+
+	order, preview, err := buildOrderPreview(session, market, types.SideTypeBuy, fixedpoint.MustNewFromString("0.1"), "19000", lastPrice)
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.OrderTypeLimit, order.Type)
+		assert.Equal(t, types.TimeInForceGTC, order.TimeInForce)
+		assert.Equal(t, "19000", order.Price.String())
+		assert.Equal(t, "Buy 0.1000 BTC with limit order at 19000.00 on binance\n"+
+			"Estimated cost: 1901.90 USDT\n"+
+			"Estimated fee: 1.90 USDT (0.1%)\n", preview)
+	}
+
+	order, preview, err = buildOrderPreview(session, market, types.SideTypeSell, fixedpoint.MustNewFromString("0.1"), "market", lastPrice)
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.OrderTypeMarket, order.Type)
+		assert.True(t, order.Price.IsZero())
+		assert.Equal(t, "Sell 0.1000 BTC with market order at market price (last 20000.00) on binance\n"+
+			"Estimated proceeds: 1996.00 USDT\n"+
+			"Estimated fee: 4.00 USDT (0.2%)\n", preview)
+	}
+
+	tests := []struct {
+		side     types.SideType
+		quantity string
+		price    string
+		err      string
+	}{
+		{types.SideTypeBuy, "0.00001", "market", "quantity 0.00001 is less than the min quantity 0.0001"},
+		{types.SideTypeBuy, "0.00015", "market", "quantity 0.00015 does not match the step size 0.0001"},
+		{types.SideTypeBuy, "0.1", "19000.123", "price 19000.123 does not match the tick size 0.01"},
+		{types.SideTypeBuy, "0.1", "abc", `"abc" is not a valid price`},
+		{types.SideTypeBuy, "0.0001", "market", "order amount 2 is less than the min notional 10"},
+		{types.SideTypeBuy, "1", "market", "insufficient USDT balance, available 10000, required 20040"},
+		{types.SideTypeSell, "1", "market", "insufficient BTC balance, available 0.5, required 1"},
+	}
+
+	for _, test := range tests {
+		_, _, err := buildOrderPreview(session, market, test.side, fixedpoint.MustNewFromString(test.quantity), test.price, lastPrice)
+		assert.EqualError(t, err, test.err)
+	}
+}
+
+func Test_formatOpenOrder(t *testing.T) {
+	_, market := newOrderEntryTestSession(t)
+	order := types.Order{
+		SubmitOrder: types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeBuy,
+			Type:     types.OrderTypeLimit,
+			Quantity: fixedpoint.MustNewFromString("0.1"),
+			Price:    fixedpoint.NewFromInt(19000),
+			Market:   market,
+		},
+		OrderID:          123,
+		ExecutedQuantity: fixedpoint.MustNewFromString("0.05"),
+	}
+
+	assert.Equal(t, "#123 BTCUSDT BUY LIMIT 0.1 @ 19000.00, executed 0.05", formatOpenOrder(order))
+}
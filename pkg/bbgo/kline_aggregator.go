@@ -0,0 +1,165 @@
+package bbgo
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type aggregatingKLine struct {
+	types.KLine
+
+	// partial is true when the aggregation didn't start from the beginning of the interval
+	partial bool
+}
+
+// KLineAggregator builds the klines of the higher intervals from the closed klines of the base interval,
+// or from the market trades when the base interval is empty. A kline of the higher interval is closed when
+// the base kline reaches the boundary of the interval, or when the first trade after the boundary arrives.
+// The klines that are not aggregated from the beginning of the interval are dropped unless EmitPartial is set.
+//go:generate callbackgen -type KLineAggregator
+type KLineAggregator struct {
+	Symbol       string
+	BaseInterval types.Interval
+
+	// Intervals are the intervals to be aggregated, the closed klines are emitted in this order
+	Intervals []types.Interval
+
+	// EmitPartial emits the first kline even if it's not aggregated from the beginning of the interval
+	EmitPartial bool
+
+	klines map[types.Interval]*aggregatingKLine
+
+	// closed records the intervals that have closed at least one kline
+	closed map[types.Interval]bool
+
+	kLineClosedCallbacks []func(k types.KLine)
+}
+
+func NewKLineAggregator(symbol string, baseInterval types.Interval) *KLineAggregator {
+	return &KLineAggregator{
+		Symbol:       symbol,
+		BaseInterval: baseInterval,
+		klines:       make(map[types.Interval]*aggregatingKLine),
+		closed:       make(map[types.Interval]bool),
+	}
+}
+
+// Subscribe adds the interval to the aggregation list,
+// the interval must be a multiple of the base interval.
+func (a *KLineAggregator) Subscribe(interval types.Interval) {
+	for _, i := range a.Intervals {
+		if i == interval {
+			return
+		}
+	}
+
+	a.Intervals = append(a.Intervals, interval)
+}
+
+// BindStream aggregates the closed klines of the base interval from the stream
+func (a *KLineAggregator) BindStream(stream types.Stream) {
+	stream.OnKLineClosed(types.KLineWith(a.Symbol, a.BaseInterval, a.AddKLine))
+}
+
+// BindMarketTradeStream aggregates the market trades from the stream
+func (a *KLineAggregator) BindMarketTradeStream(stream types.Stream) {
+	stream.OnMarketTrade(a.AddTrade)
+}
+
+func (a *KLineAggregator) AddKLine(k types.KLine) {
+	if k.Symbol != a.Symbol || k.Interval != a.BaseInterval {
+		return
+	}
+
+	endTime := k.StartTime.Time().Add(k.Interval.Duration())
+	for _, interval := range a.Intervals {
+		if interval == a.BaseInterval {
+			a.EmitKLineClosed(k)
+			continue
+		}
+
+		pending, ok := a.klines[interval]
+		if !ok {
+			startTime := interval.Truncate(k.StartTime.Time())
+			pending = &aggregatingKLine{KLine: k, partial: !startTime.Equal(k.StartTime.Time())}
+			pending.Interval = interval
+			pending.StartTime = types.Time(startTime)
+			a.klines[interval] = pending
+		} else {
+			pending.Merge(&k)
+		}
+
+		pending.Closed = false
+		if interval.Truncate(endTime).Equal(endTime) {
+			a.close(interval, endTime)
+		}
+	}
+}
+
+func (a *KLineAggregator) AddTrade(trade types.Trade) {
+	if trade.Symbol != a.Symbol {
+		return
+	}
+
+	tradeTime := trade.Time.Time()
+	for _, interval := range a.Intervals {
+		startTime := interval.Truncate(tradeTime)
+
+		pending, ok := a.klines[interval]
+		if ok && pending.StartTime.Time().Before(startTime) {
+			a.close(interval, pending.StartTime.Time().Add(interval.Duration()))
+			pending, ok = nil, false
+		}
+
+		if !ok {
+			pending = &aggregatingKLine{
+				KLine: types.KLine{
+					Exchange:  trade.Exchange,
+					Symbol:    trade.Symbol,
+					Interval:  interval,
+					StartTime: types.Time(startTime),
+					Open:      trade.Price,
+					High:      trade.Price,
+					Low:       trade.Price,
+				},
+				// we can not tell whether the trades of this interval are missing before the first boundary
+				partial: !a.closed[interval],
+			}
+			a.klines[interval] = pending
+		}
+
+		pending.pushTrade(trade)
+	}
+}
+
+func (k *aggregatingKLine) pushTrade(trade types.Trade) {
+	k.High = fixedpoint.Max(k.High, trade.Price)
+	k.Low = fixedpoint.Min(k.Low, trade.Price)
+	k.Close = trade.Price
+	k.Volume = k.Volume.Add(trade.Quantity)
+	k.QuoteVolume = k.QuoteVolume.Add(trade.QuoteQuantity)
+	if trade.Side == types.SideTypeBuy {
+		k.TakerBuyBaseAssetVolume = k.TakerBuyBaseAssetVolume.Add(trade.Quantity)
+		k.TakerBuyQuoteAssetVolume = k.TakerBuyQuoteAssetVolume.Add(trade.QuoteQuantity)
+	}
+	k.LastTradeID = trade.ID
+	k.NumberOfTrades++
+	k.EndTime = trade.Time
+}
+
+func (a *KLineAggregator) close(interval types.Interval, endTime time.Time) {
+	pending := a.klines[interval]
+	delete(a.klines, interval)
+	a.closed[interval] = true
+
+	if pending.partial && !a.EmitPartial {
+		return
+	}
+
+	k := pending.KLine
+	k.EndTime = types.Time(endTime.Add(-time.Millisecond))
+	k.Closed = true
+	a.EmitKLineClosed(k)
+}
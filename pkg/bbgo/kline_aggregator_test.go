@@ -0,0 +1,117 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestKLineAggregator_AddKLine(t *testing.T) {
+	aggregator := NewKLineAggregator("BTCUSDT", types.Interval1m)
+	aggregator.Subscribe(types.Interval1m)
+	aggregator.Subscribe(types.Interval5m)
+
+	var closed []types.KLine
+	aggregator.OnKLineClosed(func(k types.KLine) {
+		closed = append(closed, k)
+	})
+
+	// starts from 00:03, the first 5m kline is partial and should be dropped
+	startTime := time.Date(2022, time.January, 1, 0, 3, 0, 0, time.UTC)
+	for i := 0; i < 7; i++ {
+		aggregator.AddKLine(types.KLine{
+			Symbol:    "BTCUSDT",
+			Interval:  types.Interval1m,
+			StartTime: types.Time(startTime.Add(time.Duration(i) * time.Minute)),
+			Open:      fixedpoint.NewFromInt(int64(100 + i)),
+			High:      fixedpoint.NewFromInt(int64(110 + i)),
+			Low:       fixedpoint.NewFromInt(int64(90 + i)),
+			Close:     fixedpoint.NewFromInt(int64(101 + i)),
+			Volume:    fixedpoint.One,
+			Closed:    true,
+		})
+	}
+
+	var klines5m []types.KLine
+	for _, k := range closed {
+		if k.Interval == types.Interval5m {
+			klines5m = append(klines5m, k)
+		}
+	}
+
+	assert.Len(t, closed, 8)
+	if assert.Len(t, klines5m, 1) {
+		k := klines5m[0]
+		assert.Equal(t, time.Date(2022, time.January, 1, 0, 5, 0, 0, time.UTC), k.StartTime.Time())
+		assert.Equal(t, time.Date(2022, time.January, 1, 0, 9, 59, 999000000, time.UTC), k.EndTime.Time())
+		assert.Equal(t, fixedpoint.NewFromInt(102), k.Open)
+		assert.Equal(t, fixedpoint.NewFromInt(116), k.High)
+		assert.Equal(t, fixedpoint.NewFromInt(92), k.Low)
+		assert.Equal(t, fixedpoint.NewFromInt(107), k.Close)
+		assert.Equal(t, fixedpoint.NewFromInt(5), k.Volume)
+		assert.True(t, k.Closed)
+	}
+}
+
+func TestKLineAggregator_AddTrade(t *testing.T) {
+	aggregator := NewKLineAggregator("BTCUSDT", types.Interval1m)
+	aggregator.Subscribe(types.Interval1m)
+
+	var closed []types.KLine
+	aggregator.OnKLineClosed(func(k types.KLine) {
+		closed = append(closed, k)
+	})
+
+	startTime := time.Date(2022, time.January, 1, 0, 0, 30, 0, time.UTC)
+	prices := []int64{100, 105, 95, 102, 103, 104}
+	for i, price := range prices {
+		aggregator.AddTrade(types.Trade{
+			ID:            uint64(i),
+			Symbol:        "BTCUSDT",
+			Price:         fixedpoint.NewFromInt(price),
+			Quantity:      fixedpoint.One,
+			QuoteQuantity: fixedpoint.NewFromInt(price),
+			Side:          types.SideTypeBuy,
+			Time:          types.Time(startTime.Add(time.Duration(i) * 20 * time.Second)),
+		})
+	}
+
+	// 00:00 is partial, 00:01 is closed by the trade at 00:02:10
+	if assert.Len(t, closed, 1) {
+		k := closed[0]
+		assert.Equal(t, time.Date(2022, time.January, 1, 0, 1, 0, 0, time.UTC), k.StartTime.Time())
+		assert.Equal(t, fixedpoint.NewFromInt(95), k.Open)
+		assert.Equal(t, fixedpoint.NewFromInt(103), k.High)
+		assert.Equal(t, fixedpoint.NewFromInt(95), k.Low)
+		assert.Equal(t, fixedpoint.NewFromInt(103), k.Close)
+		assert.Equal(t, fixedpoint.NewFromInt(3), k.Volume)
+		assert.Equal(t, uint64(3), k.NumberOfTrades)
+	}
+}
+
+func TestSerialMarketDataStore_PartialKLine(t *testing.T) {
+	store := NewSerialMarketDataStore("BTCUSDT")
+	store.Subscribe(types.Interval5m)
+
+	// starts from 00:03, the first 5m kline is partial but still added
+	startTime := time.Date(2022, time.January, 1, 0, 3, 0, 0, time.UTC)
+	for i := 0; i < 7; i++ {
+		store.AddKLine(types.KLine{
+			Symbol:    "BTCUSDT",
+			Interval:  types.Interval1m,
+			StartTime: types.Time(startTime.Add(time.Duration(i) * time.Minute)),
+			Close:     fixedpoint.NewFromInt(int64(101 + i)),
+			Closed:    true,
+		})
+	}
+
+	klines, ok := store.KLinesOfInterval(types.Interval5m)
+	if assert.True(t, ok) && assert.Len(t, *klines, 2) {
+		assert.Equal(t, fixedpoint.NewFromInt(102), (*klines)[0].Close)
+		assert.Equal(t, fixedpoint.NewFromInt(107), (*klines)[1].Close)
+	}
+}
@@ -0,0 +1,421 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type DeleverageAction string
+
+const (
+	// DeleverageActionRepay repays the debt of the asset with the available balance of the asset
+	DeleverageActionRepay DeleverageAction = "repay"
+
+	// DeleverageActionReduce reduces the position of the symbol with a market order and repays the debt with the proceeds
+	DeleverageActionReduce DeleverageAction = "reduce"
+)
+
+type DeleverageStep struct {
+	Action DeleverageAction `json:"action" yaml:"action"`
+
+	// Asset is the asset to repay, for the repay action
+	Asset string `json:"asset,omitempty" yaml:"asset,omitempty"`
+
+	// Symbol is the market to reduce, for the reduce action
+	Symbol string `json:"symbol,omitempty" yaml:"symbol,omitempty"`
+}
+
+// MarginHealthConfig configures the margin health monitor of a cross margin session
+//
+//   marginHealth:
+//     interval: 1m
+//     warningLevels: [1.5, 1.3]
+//     criticalLevel: 1.15
+//     targetLevel: 1.5
+//     deleverage:
+//     - action: repay
+//       asset: USDT
+//     - action: reduce
+//       symbol: BTCUSDT
+type MarginHealthConfig struct {
+	// Interval is the polling interval of the margin level, default: 1m
+	Interval types.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+
+	// ValuationCurrency is the currency for valuing the assets and the debts, default: USDT
+	ValuationCurrency string `json:"valuationCurrency,omitempty" yaml:"valuationCurrency,omitempty"`
+
+	// WarningLevels emit a warning once when the margin level drops below each level
+	WarningLevels []fixedpoint.Value `json:"warningLevels,omitempty" yaml:"warningLevels,omitempty"`
+
+	// CriticalLevel triggers the deleverage when the margin level drops below it
+	CriticalLevel fixedpoint.Value `json:"criticalLevel" yaml:"criticalLevel"`
+
+	// TargetLevel is the margin level to restore by the deleverage, default: the highest warning level or 1.5 x critical level
+	TargetLevel fixedpoint.Value `json:"targetLevel,omitempty" yaml:"targetLevel,omitempty"`
+
+	// KeepOpenOrders keeps the open orders when the deleverage is triggered
+	KeepOpenOrders bool `json:"keepOpenOrders,omitempty" yaml:"keepOpenOrders,omitempty"`
+
+	// Deleverage is the deleverage steps in the priority order
+	Deleverage []DeleverageStep `json:"deleverage,omitempty" yaml:"deleverage,omitempty"`
+}
+
+func (c *MarginHealthConfig) Validate() error {
+	if c.CriticalLevel.Compare(fixedpoint.One) <= 0 {
+		return fmt.Errorf("marginHealth: criticalLevel %v must be greater than 1", c.CriticalLevel)
+	}
+
+	for _, step := range c.Deleverage {
+		switch step.Action {
+		case DeleverageActionRepay:
+			if step.Asset == "" {
+				return fmt.Errorf("marginHealth: asset of the repay step is required")
+			}
+
+		case DeleverageActionReduce:
+			if step.Symbol == "" {
+				return fmt.Errorf("marginHealth: symbol of the reduce step is required")
+			}
+
+		default:
+			return fmt.Errorf("marginHealth: unsupported deleverage action %q", step.Action)
+		}
+	}
+
+	return nil
+}
+
+// MarginHealthMonitor polls the margin level of a cross margin session, emits the warnings at the warning levels,
+// and deleverages the account to the target level when the margin level drops below the critical level.
+//go:generate callbackgen -type MarginHealthMonitor
+type MarginHealthMonitor struct {
+	Config *MarginHealthConfig
+
+	session     *ExchangeSession
+	calculator  *AccountValueCalculator
+	borrowRepay types.MarginBorrowRepayService
+
+	mu            sync.Mutex
+	lastCheckedAt time.Time
+	warned        map[string]bool
+
+	warningCallbacks     []func(marginLevel, warningLevel fixedpoint.Value)
+	criticalCallbacks    []func(marginLevel fixedpoint.Value)
+	deleveragedCallbacks []func(before, after fixedpoint.Value)
+}
+
+func NewMarginHealthMonitor(session *ExchangeSession, config *MarginHealthConfig) (*MarginHealthMonitor, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	borrowRepay, ok := session.Exchange.(types.MarginBorrowRepayService)
+	if !ok {
+		return nil, fmt.Errorf("exchange %s does not support margin borrow and repay", session.ExchangeName)
+	}
+
+	if config.Interval == 0 {
+		config.Interval = types.Duration(time.Minute)
+	}
+
+	if config.ValuationCurrency == "" {
+		config.ValuationCurrency = "USDT"
+	}
+
+	sort.Slice(config.WarningLevels, func(i, j int) bool {
+		return config.WarningLevels[i].Compare(config.WarningLevels[j]) > 0
+	})
+
+	if config.TargetLevel.IsZero() {
+		if len(config.WarningLevels) > 0 {
+			config.TargetLevel = config.WarningLevels[0]
+		} else {
+			config.TargetLevel = config.CriticalLevel.Mul(fixedpoint.NewFromFloat(1.5))
+		}
+	}
+
+	if config.TargetLevel.Compare(config.CriticalLevel) <= 0 {
+		return nil, fmt.Errorf("marginHealth: targetLevel %v must be greater than criticalLevel %v", config.TargetLevel, config.CriticalLevel)
+	}
+
+	return &MarginHealthMonitor{
+		Config:      config,
+		session:     session,
+		calculator:  NewAccountValueCalculator(session, config.ValuationCurrency),
+		borrowRepay: borrowRepay,
+		warned:      make(map[string]bool),
+	}, nil
+}
+
+// Bind checks the margin level on the closed klines of the session, throttled by the interval.
+func (m *MarginHealthMonitor) Bind(ctx context.Context) {
+	m.session.MarketDataStream.OnKLineClosed(func(k types.KLine) {
+		now := sessionTime(m.session)
+
+		m.mu.Lock()
+		if now.Sub(m.lastCheckedAt) < m.Config.Interval.Duration() {
+			m.mu.Unlock()
+			return
+		}
+		m.lastCheckedAt = now
+		m.mu.Unlock()
+
+		if err := m.Check(ctx); err != nil {
+			log.WithError(err).Errorf("[%s] margin health check error", m.session.Name)
+		}
+	})
+}
+
+// MarginLevel returns the margin level of the account, which is zero if the account has no debt.
+func (m *MarginHealthMonitor) MarginLevel(ctx context.Context) (fixedpoint.Value, error) {
+	if err := m.calculator.UpdatePrices(ctx); err != nil {
+		return fixedpoint.Zero, err
+	}
+
+	return m.marginLevel(ctx)
+}
+
+func (m *MarginHealthMonitor) marginLevel(ctx context.Context) (fixedpoint.Value, error) {
+	debtValue, err := m.calculator.DebtValue(ctx)
+	if err != nil || debtValue.IsZero() {
+		return fixedpoint.Zero, err
+	}
+
+	return m.calculator.MarginLevel(ctx)
+}
+
+// Check polls the margin level, emits the warnings and deleverages the account if the margin level is critical
+func (m *MarginHealthMonitor) Check(ctx context.Context) error {
+	marginLevel, err := m.MarginLevel(ctx)
+	if err != nil {
+		return err
+	}
+
+	// no debt
+	if marginLevel.IsZero() {
+		m.mu.Lock()
+		m.warned = make(map[string]bool)
+		m.mu.Unlock()
+		return nil
+	}
+
+	var warnings []fixedpoint.Value
+	m.mu.Lock()
+	for _, level := range m.Config.WarningLevels {
+		key := level.String()
+		if marginLevel.Compare(level) >= 0 {
+			delete(m.warned, key)
+			continue
+		}
+
+		if !m.warned[key] {
+			m.warned[key] = true
+			warnings = append(warnings, level)
+		}
+	}
+	m.mu.Unlock()
+
+	// only the lowest crossed level is reported
+	if len(warnings) > 0 {
+		warningLevel := warnings[len(warnings)-1]
+		NotifyWithSeverity(SeverityWarn, "[%s] margin level %v is below the warning level %v", m.session.Name, marginLevel, warningLevel)
+		m.EmitWarning(marginLevel, warningLevel)
+	}
+
+	if marginLevel.Compare(m.Config.CriticalLevel) >= 0 {
+		return nil
+	}
+
+	NotifyWithSeverity(SeverityCritical, "[%s] margin level %v is below the critical level %v, deleveraging to %v", m.session.Name, marginLevel, m.Config.CriticalLevel, m.Config.TargetLevel)
+	m.EmitCritical(marginLevel)
+
+	after, err := m.Deleverage(ctx)
+	NotifyWithSeverity(SeverityWarn, "[%s] deleveraged, margin level %v -> %v", m.session.Name, marginLevel, after)
+	m.EmitDeleveraged(marginLevel, after)
+	return err
+}
+
+// Deleverage cancels the open orders and runs the deleverage steps in order until the target level is restored,
+// it returns the margin level after the deleverage.
+func (m *MarginHealthMonitor) Deleverage(ctx context.Context) (fixedpoint.Value, error) {
+	var errs error
+
+	if !m.Config.KeepOpenOrders {
+		if err := m.cancelOpenOrders(ctx); err != nil {
+			errs = multierr.Append(errs, err)
+		}
+	}
+
+	for _, step := range m.Config.Deleverage {
+		if err := m.refreshAccount(ctx); err != nil {
+			return fixedpoint.Zero, multierr.Append(errs, err)
+		}
+
+		required, err := m.requiredRepayValue(ctx)
+		if err != nil {
+			return fixedpoint.Zero, multierr.Append(errs, err)
+		}
+
+		if required.Sign() <= 0 {
+			break
+		}
+
+		switch step.Action {
+		case DeleverageActionRepay:
+			err = m.repay(ctx, step.Asset, required)
+		case DeleverageActionReduce:
+			err = m.reduce(ctx, step.Symbol, required)
+		}
+
+		if err != nil {
+			log.WithError(err).Errorf("[%s] deleverage step %s %s%s error", m.session.Name, step.Action, step.Asset, step.Symbol)
+			errs = multierr.Append(errs, err)
+		}
+	}
+
+	if err := m.refreshAccount(ctx); err != nil {
+		return fixedpoint.Zero, multierr.Append(errs, err)
+	}
+
+	marginLevel, err := m.marginLevel(ctx)
+	return marginLevel, multierr.Append(errs, err)
+}
+
+// requiredRepayValue returns the debt value to repay for restoring the target level,
+// repaying x reduces both the market value and the debt value: (MV - x) / (DV - x) = target
+func (m *MarginHealthMonitor) requiredRepayValue(ctx context.Context) (fixedpoint.Value, error) {
+	marketValue, err := m.calculator.MarketValue(ctx)
+	if err != nil {
+		return fixedpoint.Zero, err
+	}
+
+	debtValue, err := m.calculator.DebtValue(ctx)
+	if err != nil || debtValue.IsZero() {
+		return fixedpoint.Zero, err
+	}
+
+	target := m.Config.TargetLevel
+	return target.Mul(debtValue).Sub(marketValue).Div(target.Sub(fixedpoint.One)), nil
+}
+
+func (m *MarginHealthMonitor) repay(ctx context.Context, asset string, requiredValue fixedpoint.Value) error {
+	balance, ok := m.session.GetAccount().Balance(asset)
+	if !ok {
+		return nil
+	}
+
+	amount := fixedpoint.Min(balance.Available, balance.Debt())
+	if amount.Sign() <= 0 {
+		return nil
+	}
+
+	if price, ok := m.calculator.Price(asset); ok {
+		amount = fixedpoint.Min(amount, requiredValue.Div(price))
+	}
+
+	log.Infof("[%s] deleverage: repaying %v %s", m.session.Name, amount, asset)
+	return m.borrowRepay.RepayMarginAsset(ctx, asset, amount)
+}
+
+func (m *MarginHealthMonitor) reduce(ctx context.Context, symbol string, requiredValue fixedpoint.Value) error {
+	market, ok := m.session.Market(symbol)
+	if !ok {
+		return fmt.Errorf("market %s not found", symbol)
+	}
+
+	price, ok := m.session.LastPrice(symbol)
+	if !ok || price.Sign() <= 0 {
+		return fmt.Errorf("last price of %s not found", symbol)
+	}
+
+	quotePrice, ok := m.calculator.Price(market.QuoteCurrency)
+	if !ok {
+		return fmt.Errorf("%s price in %s not found", market.QuoteCurrency, m.Config.ValuationCurrency)
+	}
+
+	// the required value in the quote currency
+	requiredQuote := requiredValue.Div(quotePrice)
+
+	account := m.session.GetAccount()
+	base, _ := account.Balance(market.BaseCurrency)
+	quote, _ := account.Balance(market.QuoteCurrency)
+
+	var side types.SideType
+	var quantity fixedpoint.Value
+	var repayAsset string
+
+	switch {
+	case quote.Debt().Sign() > 0 && base.Available.Sign() > 0:
+		// the long position is financed by the quote debt, sell the base
+		side = types.SideTypeSell
+		quantity = fixedpoint.Min(base.Available, fixedpoint.Min(quote.Debt(), requiredQuote).Div(price))
+		repayAsset = market.QuoteCurrency
+
+	case base.Debt().Sign() > 0 && quote.Available.Sign() > 0:
+		// the short position, buy back the base
+		side = types.SideTypeBuy
+		quantity = fixedpoint.Min(base.Debt().Sub(base.Available), requiredQuote.Div(price))
+		quantity = fixedpoint.Min(quantity, quote.Available.Div(price))
+		repayAsset = market.BaseCurrency
+
+	default:
+		return nil
+	}
+
+	quantity = market.TruncateQuantity(quantity)
+	if quantity.Sign() > 0 && !market.IsDustQuantity(quantity, price) {
+		log.Infof("[%s] deleverage: reducing %s with %s %v", m.session.Name, symbol, side, quantity)
+		if _, err := m.session.Exchange.SubmitOrder(ctx, types.SubmitOrder{
+			Symbol:   symbol,
+			Side:     side,
+			Type:     types.OrderTypeMarket,
+			Quantity: quantity,
+			Market:   market,
+			Tag:      "deleverage",
+		}); err != nil {
+			return err
+		}
+
+		if err := m.refreshAccount(ctx); err != nil {
+			return err
+		}
+	}
+
+	return m.repay(ctx, repayAsset, requiredValue)
+}
+
+func (m *MarginHealthMonitor) cancelOpenOrders(ctx context.Context) error {
+	// the order stores may keep the filled and the canceled orders
+	var orders []types.Order
+	for _, store := range m.session.OrderStores() {
+		for _, order := range store.Orders() {
+			switch order.Status {
+			case types.OrderStatusNew, types.OrderStatusPartiallyFilled:
+				orders = append(orders, order)
+			}
+		}
+	}
+
+	if len(orders) == 0 {
+		return nil
+	}
+
+	log.Warnf("[%s] deleverage: canceling %d open orders", m.session.Name, len(orders))
+	return m.session.Exchange.CancelOrders(ctx, orders...)
+}
+
+func (m *MarginHealthMonitor) refreshAccount(ctx context.Context) error {
+	if _, err := m.session.UpdateAccount(ctx); err != nil {
+		return err
+	}
+	return nil
+}
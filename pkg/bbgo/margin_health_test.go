@@ -0,0 +1,147 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+// marginTestExchange repays the debt from the session account
+type marginTestExchange struct {
+	*mocks.MockExchange
+
+	account *types.Account
+}
+
+func (e *marginTestExchange) RepayMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	balance, _ := e.account.Balance(asset)
+	balance.Available = balance.Available.Sub(amount)
+	balance.Borrowed = balance.Borrowed.Sub(amount)
+	e.account.UpdateBalances(types.BalanceMap{asset: balance})
+	return nil
+}
+
+func (e *marginTestExchange) BorrowMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	return nil
+}
+
+func (e *marginTestExchange) QueryMarginAssetMaxBorrowable(ctx context.Context, asset string) (fixedpoint.Value, error) {
+	return fixedpoint.Zero, nil
+}
+
+func TestMarginHealthMonitor_Deleverage(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	ex := &marginTestExchange{MockExchange: mockEx}
+	session := NewExchangeSession("binance", ex)
+	session.Margin = true
+	session.markets = map[string]types.Market{
+		"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", StepSize: fixedpoint.NewFromFloat(0.0001), VolumePrecision: 4},
+	}
+	session.lastPrices["BTCUSDT"] = fixedpoint.NewFromInt(20000)
+
+	ex.account = session.Account
+	ex.account.UpdateBalances(types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.One},
+		"USDT": {Currency: "USDT", Borrowed: fixedpoint.NewFromInt(18000)},
+	})
+
+	mockEx.EXPECT().QueryTickers(gomock.Any(), gomock.Any()).Return(map[string]types.Ticker{
+		"BTCUSDT": {Last: fixedpoint.NewFromInt(20000)},
+	}, nil).AnyTimes()
+	mockEx.EXPECT().QueryAccount(gomock.Any()).Return(ex.account, nil).AnyTimes()
+	mockEx.EXPECT().SubmitOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+		assert.Equal(t, types.SideTypeSell, order.Side)
+		assert.Equal(t, types.OrderTypeMarket, order.Type)
+		assert.Equal(t, "0.7", order.Quantity.String())
+
+		ex.account.AddBalance("BTC", order.Quantity.Neg())
+		ex.account.AddBalance("USDT", order.Quantity.Mul(fixedpoint.NewFromInt(20000)))
+		return &types.Order{SubmitOrder: order, Status: types.OrderStatusFilled}, nil
+	}).Times(1)
+
+	monitor, err := NewMarginHealthMonitor(session, &MarginHealthConfig{
+		WarningLevels: []fixedpoint.Value{fixedpoint.NewFromFloat(1.3), fixedpoint.NewFromFloat(1.5)},
+		CriticalLevel: fixedpoint.NewFromFloat(1.15),
+		Deleverage: []DeleverageStep{
+			{Action: DeleverageActionRepay, Asset: "USDT"},
+			{Action: DeleverageActionReduce, Symbol: "BTCUSDT"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "1.5", monitor.Config.TargetLevel.String())
+
+	var warningLevels []string
+	monitor.OnWarning(func(marginLevel, warningLevel fixedpoint.Value) {
+		warningLevels = append(warningLevels, warningLevel.String())
+	})
+
+	var after fixedpoint.Value
+	monitor.OnDeleveraged(func(before, a fixedpoint.Value) {
+		after = a
+	})
+
+	ctx := context.Background()
+	assert.NoError(t, monitor.Check(ctx))
+	assert.Equal(t, []string{"1.3"}, warningLevels)
+	assert.Equal(t, "1.5", after.String())
+
+	usdt, _ := session.Account.Balance("USDT")
+	assert.Equal(t, "4000", usdt.Borrowed.String())
+
+	// healthy again
+	assert.NoError(t, monitor.Check(ctx))
+	assert.Equal(t, []string{"1.3"}, warningLevels)
+}
+
+func TestMarginHealthConfig_Validate(t *testing.T) {
+	assert.Error(t, (&MarginHealthConfig{CriticalLevel: fixedpoint.One}).Validate())
+	assert.Error(t, (&MarginHealthConfig{
+		CriticalLevel: fixedpoint.NewFromFloat(1.1),
+		Deleverage:    []DeleverageStep{{Action: DeleverageActionReduce}},
+	}).Validate())
+	assert.NoError(t, (&MarginHealthConfig{
+		CriticalLevel: fixedpoint.NewFromFloat(1.1),
+		Deleverage:    []DeleverageStep{{Action: DeleverageActionRepay, Asset: "USDT"}},
+	}).Validate())
+}
+
+func TestMarginHealthMonitor_cancelOpenOrders(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	session := NewExchangeSession("binance", mockEx)
+	store := NewOrderStore("BTCUSDT")
+	store.Add(
+		types.Order{OrderID: 1, Status: types.OrderStatusNew},
+		types.Order{OrderID: 2, Status: types.OrderStatusPartiallyFilled},
+		types.Order{OrderID: 3, Status: types.OrderStatusFilled},
+		types.Order{OrderID: 4, Status: types.OrderStatusCanceled},
+	)
+	session.orderStores["BTCUSDT"] = store
+
+	mockEx.EXPECT().CancelOrders(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, orders ...types.Order) error {
+		var orderIDs []uint64
+		for _, order := range orders {
+			orderIDs = append(orderIDs, order.OrderID)
+		}
+		assert.ElementsMatch(t, []uint64{1, 2}, orderIDs)
+		return nil
+	}).Times(1)
+
+	monitor := &MarginHealthMonitor{session: session}
+	assert.NoError(t, monitor.cancelOpenOrders(context.Background()))
+}
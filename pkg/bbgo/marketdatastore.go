@@ -1,6 +1,9 @@
 package bbgo
 
-import "github.com/c9s/bbgo/pkg/types"
+import (
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/types"
+)
 
 const MaxNumOfKLines = 5_000
 const MaxNumOfKLinesTruncate = 100
@@ -40,6 +43,18 @@ func (store *MarketDataStore) BindStream(stream types.Stream) {
 	stream.OnKLineClosed(store.handleKLineClosed)
 }
 
+// BindKLinePusher replays the stored klines of the given interval through the pusher,
+// and then keeps the pusher updated with the klines added to the store afterward.
+func (store *MarketDataStore) BindKLinePusher(interval types.Interval, pusher indicator.KLinePusher) {
+	if klines, ok := store.KLinesOfInterval(interval); ok {
+		for _, k := range *klines {
+			pusher.PushK(k)
+		}
+	}
+
+	store.OnKLineClosed(types.KLineWith(store.Symbol, interval, pusher.PushK))
+}
+
 func (store *MarketDataStore) handleKLineClosed(kline types.KLine) {
 	if kline.Symbol != store.Symbol {
 		return
@@ -97,6 +97,37 @@ var (
 			"currency",  // for balance
 		},
 	)
+	metricsExposureNet = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bbgo_exposure_net",
+			Help: "bbgo consolidated net exposure of the asset across all sessions",
+		},
+		[]string{
+			"asset",
+		},
+	)
+
+	metricsExposureNetValue = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bbgo_exposure_net_value",
+			Help: "bbgo consolidated net exposure value of the asset across all sessions",
+		},
+		[]string{
+			"asset",
+			"valuation", // valuation currency
+		},
+	)
+
+	metricsExposureTotalValue = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bbgo_exposure_total_value",
+			Help: "bbgo consolidated total exposure value across all sessions",
+		},
+		[]string{
+			"type",      // type: net or gross
+			"valuation", // valuation currency
+		},
+	)
 )
 
 func init() {
@@ -108,5 +139,8 @@ func init() {
 		metricsTradesTotal,
 		metricsTradingVolume,
 		metricsLastUpdateTimeBalance,
+		metricsExposureNet,
+		metricsExposureNetValue,
+		metricsExposureTotalValue,
 	)
 }
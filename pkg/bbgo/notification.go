@@ -2,6 +2,8 @@ package bbgo
 
 import (
 	"bytes"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -22,6 +24,15 @@ func NotifyTo(channel string, obj interface{}, args ...interface{}) {
 	Notification.NotifyTo(channel, obj, args...)
 }
 
+// NotifyWithSeverity sends the notification with the severity, the notifications are filtered by the severity settings
+func NotifyWithSeverity(severity NotificationSeverity, obj interface{}, args ...interface{}) {
+	Notification.NotifyWithSeverity(severity, obj, args...)
+}
+
+func NotifyToWithSeverity(channel string, severity NotificationSeverity, obj interface{}, args ...interface{}) {
+	Notification.NotifyToWithSeverity(channel, severity, obj, args...)
+}
+
 func SendPhoto(buffer *bytes.Buffer) {
 	Notification.SendPhoto(buffer)
 }
@@ -52,6 +63,9 @@ type Notifiability struct {
 	SessionChannelRouter *PatternChannelRouter `json:"-"`
 	SymbolChannelRouter  *PatternChannelRouter `json:"-"`
 	ObjectChannelRouter  *ObjectChannelRouter  `json:"-"`
+
+	throttle *NotificationThrottle
+	mu       sync.Mutex
 }
 
 // RouteSymbol routes symbol name to channel
@@ -83,18 +97,83 @@ func (m *Notifiability) AddNotifier(notifier Notifier) {
 	m.notifiers = append(m.notifiers, notifier)
 }
 
+// SetThrottle applies the throttle to the notifications of all notifiers,
+// the messages generated by the throttle (the digests and the summaries) are flushed every second.
+func (m *Notifiability) SetThrottle(throttle *NotificationThrottle) {
+	m.mu.Lock()
+	m.throttle = throttle
+	m.mu.Unlock()
+
+	if throttle != nil {
+		go m.flushThrottle(throttle)
+	}
+}
+
+func (m *Notifiability) getThrottle() *NotificationThrottle {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.throttle
+}
+
+func (m *Notifiability) flushThrottle(throttle *NotificationThrottle) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		// the throttle is replaced
+		if m.getThrottle() != throttle {
+			return
+		}
+
+		m.sendMessages(throttle.Flush(now))
+	}
+}
+
+func (m *Notifiability) sendMessages(messages []NotificationMessage) {
+	for _, message := range messages {
+		for _, n := range m.notifiers {
+			if message.Channel == "" {
+				n.Notify("%s", message.Text)
+			} else {
+				n.NotifyTo(message.Channel, "%s", message.Text)
+			}
+		}
+	}
+}
+
+func (m *Notifiability) allow(channel string, severity NotificationSeverity, obj interface{}, args []interface{}) bool {
+	throttle := m.getThrottle()
+	return throttle == nil || throttle.Allow(time.Now(), channel, severity, obj, args...)
+}
+
 func (m *Notifiability) Notify(obj interface{}, args ...interface{}) {
+	m.NotifyWithSeverity(severityOf(obj), obj, args...)
+}
+
+func (m *Notifiability) NotifyWithSeverity(severity NotificationSeverity, obj interface{}, args ...interface{}) {
 	if str, ok := obj.(string); ok {
 		simpleArgs := util.FilterSimpleArgs(args)
 		logrus.Infof(str, simpleArgs...)
 	}
 
+	if !m.allow("", severity, obj, args) {
+		return
+	}
+
 	for _, n := range m.notifiers {
 		n.Notify(obj, args...)
 	}
 }
 
 func (m *Notifiability) NotifyTo(channel string, obj interface{}, args ...interface{}) {
+	m.NotifyToWithSeverity(channel, severityOf(obj), obj, args...)
+}
+
+func (m *Notifiability) NotifyToWithSeverity(channel string, severity NotificationSeverity, obj interface{}, args ...interface{}) {
+	if !m.allow(channel, severity, obj, args) {
+		return
+	}
+
 	for _, n := range m.notifiers {
 		n.NotifyTo(channel, obj, args...)
 	}
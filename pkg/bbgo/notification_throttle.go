@@ -0,0 +1,475 @@
+package bbgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+type NotificationSeverity int
+
+const (
+	SeverityDebug NotificationSeverity = iota
+	SeverityInfo
+	SeverityWarn
+	SeverityCritical
+)
+
+var severityNames = map[NotificationSeverity]string{
+	SeverityDebug:    "debug",
+	SeverityInfo:     "info",
+	SeverityWarn:     "warn",
+	SeverityCritical: "critical",
+}
+
+func (s NotificationSeverity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+func ParseNotificationSeverity(s string) (NotificationSeverity, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return SeverityDebug, nil
+	case "info", "":
+		return SeverityInfo, nil
+	case "warn", "warning":
+		return SeverityWarn, nil
+	case "critical", "error":
+		return SeverityCritical, nil
+	}
+
+	return SeverityInfo, fmt.Errorf("invalid notification severity: %q", s)
+}
+
+func (s *NotificationSeverity) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+
+	severity, err := ParseNotificationSeverity(str)
+	if err != nil {
+		return err
+	}
+
+	*s = severity
+	return nil
+}
+
+func (s *NotificationSeverity) UnmarshalYAML(unmarshal func(a interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+
+	severity, err := ParseNotificationSeverity(str)
+	if err != nil {
+		return err
+	}
+
+	*s = severity
+	return nil
+}
+
+func (s NotificationSeverity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// severityOf returns the default severity of the notified object
+func severityOf(obj interface{}) NotificationSeverity {
+	if _, ok := obj.(error); ok {
+		return SeverityWarn
+	}
+
+	return SeverityInfo
+}
+
+type NotificationRateLimit struct {
+	// Interval is the interval of the notifications
+	Interval types.Duration `json:"interval" yaml:"interval"`
+
+	// Burst is the max number of the notifications sent at once
+	Burst int `json:"burst" yaml:"burst"`
+}
+
+type NotificationDigest struct {
+	Interval types.Duration `json:"interval" yaml:"interval"`
+
+	// Trades batches the trade notifications into one summary per symbol and channel
+	Trades bool `json:"trades" yaml:"trades"`
+
+	// Orders batches the order notifications into one summary per symbol and channel
+	Orders bool `json:"orders" yaml:"orders"`
+}
+
+// QuietHours suppresses the notifications below the min severity in the time range, e.g., 23:00 - 07:00
+type QuietHours struct {
+	Start    string `json:"start" yaml:"start"`
+	End      string `json:"end" yaml:"end"`
+	TimeZone string `json:"timeZone,omitempty" yaml:"timeZone,omitempty"`
+
+	// MinSeverity is the min severity sent in the quiet hours, default to critical
+	MinSeverity *NotificationSeverity `json:"minSeverity,omitempty" yaml:"minSeverity,omitempty"`
+
+	start, end time.Duration
+	location   *time.Location
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid clock time %q, expecting HH:MM: %w", s, err)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func (q *QuietHours) init() (err error) {
+	if q.start, err = parseClock(q.Start); err != nil {
+		return err
+	}
+
+	if q.end, err = parseClock(q.End); err != nil {
+		return err
+	}
+
+	q.location = time.Local
+	if q.TimeZone != "" {
+		if q.location, err = time.LoadLocation(q.TimeZone); err != nil {
+			return err
+		}
+	}
+
+	if q.MinSeverity == nil {
+		severity := SeverityCritical
+		q.MinSeverity = &severity
+	}
+
+	return nil
+}
+
+// Contains returns true if the time is in the quiet hours
+func (q *QuietHours) Contains(t time.Time) bool {
+	t = t.In(q.location)
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+
+	if q.start <= q.end {
+		return clock >= q.start && clock < q.end
+	}
+
+	// the range crosses the midnight
+	return clock >= q.start || clock < q.end
+}
+
+type notificationDigestKey struct {
+	Channel string
+	Symbol  string
+}
+
+type notificationDigestEntry struct {
+	StartTime time.Time
+
+	NumOfTrades int
+	BuyVolume   fixedpoint.Value
+	BuyQuote    fixedpoint.Value
+	SellVolume  fixedpoint.Value
+	SellQuote   fixedpoint.Value
+
+	OrderStatus map[types.OrderStatus]int
+}
+
+func (e *notificationDigestEntry) addTrade(trade types.Trade) {
+	e.NumOfTrades++
+	if trade.Side == types.SideTypeBuy {
+		e.BuyVolume = e.BuyVolume.Add(trade.Quantity)
+		e.BuyQuote = e.BuyQuote.Add(trade.QuoteQuantity)
+	} else {
+		e.SellVolume = e.SellVolume.Add(trade.Quantity)
+		e.SellQuote = e.SellQuote.Add(trade.QuoteQuantity)
+	}
+}
+
+func (e *notificationDigestEntry) addOrder(order types.Order) {
+	if e.OrderStatus == nil {
+		e.OrderStatus = make(map[types.OrderStatus]int)
+	}
+
+	e.OrderStatus[order.Status]++
+}
+
+func (e *notificationDigestEntry) text(symbol string, now time.Time) string {
+	var parts []string
+	if e.NumOfTrades > 0 {
+		s := fmt.Sprintf("%d trades", e.NumOfTrades)
+		if e.BuyVolume.Sign() > 0 {
+			s += fmt.Sprintf(", bought %s at avg %s", e.BuyVolume.String(), e.BuyQuote.Div(e.BuyVolume).Round(8, fixedpoint.HalfUp).String())
+		}
+		if e.SellVolume.Sign() > 0 {
+			s += fmt.Sprintf(", sold %s at avg %s", e.SellVolume.String(), e.SellQuote.Div(e.SellVolume).Round(8, fixedpoint.HalfUp).String())
+		}
+		parts = append(parts, s)
+	}
+
+	if len(e.OrderStatus) > 0 {
+		var total int
+		var statuses []string
+		for status, count := range e.OrderStatus {
+			total += count
+			statuses = append(statuses, fmt.Sprintf("%d %s", count, status))
+		}
+
+		sort.Strings(statuses)
+		parts = append(parts, fmt.Sprintf("%d order updates (%s)", total, strings.Join(statuses, ", ")))
+	}
+
+	return fmt.Sprintf("%s digest of the last %s: %s", symbol, now.Sub(e.StartTime).Round(time.Second), strings.Join(parts, ", "))
+}
+
+type notificationDedupEntry struct {
+	Text      string
+	FirstTime time.Time
+	Count     int
+}
+
+// NotificationMessage is the message generated by the throttle, e.g., the digest summaries
+type NotificationMessage struct {
+	Channel string
+	Text    string
+}
+
+// NotificationThrottle filters the notifications by the severity, the quiet hours, the deduplication window
+// and the channel rate limits, the trade and order notifications can be batched into the digests.
+type NotificationThrottle struct {
+	MinSeverity       NotificationSeverity
+	RateLimit         *NotificationRateLimit
+	ChannelRateLimits map[string]NotificationRateLimit
+	Digest            *NotificationDigest
+	DedupWindow       time.Duration
+	QuietHours        *QuietHours
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+	dropped  map[string]int
+	digests  map[notificationDigestKey]*notificationDigestEntry
+	dedups   map[string]*notificationDedupEntry
+}
+
+func NewNotificationThrottle(conf *NotificationConfig) (*NotificationThrottle, error) {
+	t := &NotificationThrottle{
+		MinSeverity:       conf.MinSeverity,
+		RateLimit:         conf.RateLimit,
+		ChannelRateLimits: conf.ChannelRateLimits,
+		Digest:            conf.Digest,
+		DedupWindow:       conf.DedupWindow.Duration(),
+		QuietHours:        conf.QuietHours,
+		limiters:          make(map[string]*rate.Limiter),
+		dropped:           make(map[string]int),
+		digests:           make(map[notificationDigestKey]*notificationDigestEntry),
+		dedups:            make(map[string]*notificationDedupEntry),
+	}
+
+	if t.QuietHours != nil {
+		if err := t.QuietHours.init(); err != nil {
+			return nil, err
+		}
+	}
+
+	if t.Digest != nil && t.Digest.Interval == 0 {
+		t.Digest.Interval = types.Duration(time.Minute)
+	}
+
+	return t, nil
+}
+
+func (t *NotificationThrottle) limiter(channel string) *rate.Limiter {
+	if limiter, ok := t.limiters[channel]; ok {
+		return limiter
+	}
+
+	conf := t.RateLimit
+	if c, ok := t.ChannelRateLimits[channel]; ok {
+		conf = &c
+	}
+
+	if conf == nil || conf.Interval <= 0 {
+		t.limiters[channel] = nil
+		return nil
+	}
+
+	burst := conf.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+
+	limiter := rate.NewLimiter(rate.Every(conf.Interval.Duration()), burst)
+	t.limiters[channel] = limiter
+	return limiter
+}
+
+// Allow returns true if the notification should be sent to the notifiers now
+func (t *NotificationThrottle) Allow(now time.Time, channel string, severity NotificationSeverity, obj interface{}, args ...interface{}) bool {
+	if severity < t.MinSeverity {
+		return false
+	}
+
+	if t.QuietHours != nil && t.QuietHours.Contains(now) && severity < *t.QuietHours.MinSeverity {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if severity < SeverityCritical && t.addDigest(now, channel, obj, args) {
+		return false
+	}
+
+	if t.DedupWindow > 0 {
+		if text, ok := notificationText(obj, args); ok {
+			key := channel + "\x00" + text
+			if entry, ok := t.dedups[key]; ok && now.Sub(entry.FirstTime) < t.DedupWindow {
+				entry.Count++
+				return false
+			}
+
+			t.dedups[key] = &notificationDedupEntry{Text: text, FirstTime: now}
+		}
+	}
+
+	if severity < SeverityCritical {
+		if limiter := t.limiter(channel); limiter != nil && !limiter.AllowN(now, 1) {
+			t.dropped[channel]++
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t *NotificationThrottle) addDigest(now time.Time, channel string, obj interface{}, args []interface{}) bool {
+	if t.Digest == nil {
+		return false
+	}
+
+	for _, o := range append([]interface{}{obj}, args...) {
+		switch v := o.(type) {
+		case types.Trade:
+			if t.Digest.Trades {
+				t.digestEntry(now, channel, v.Symbol).addTrade(v)
+				return true
+			}
+
+		case *types.Trade:
+			if t.Digest.Trades {
+				t.digestEntry(now, channel, v.Symbol).addTrade(*v)
+				return true
+			}
+
+		case types.Order:
+			if t.Digest.Orders {
+				t.digestEntry(now, channel, v.Symbol).addOrder(v)
+				return true
+			}
+
+		case *types.Order:
+			if t.Digest.Orders {
+				t.digestEntry(now, channel, v.Symbol).addOrder(*v)
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (t *NotificationThrottle) digestEntry(now time.Time, channel, symbol string) *notificationDigestEntry {
+	key := notificationDigestKey{Channel: channel, Symbol: symbol}
+	entry, ok := t.digests[key]
+	if !ok {
+		entry = &notificationDigestEntry{StartTime: now}
+		t.digests[key] = entry
+	}
+	return entry
+}
+
+// Flush returns the due digests, the summaries of the repeated messages and the rate limited notifications.
+// The digests are kept in the quiet hours.
+func (t *NotificationThrottle) Flush(now time.Time) (messages []NotificationMessage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	quiet := t.QuietHours != nil && t.QuietHours.Contains(now)
+
+	if t.Digest != nil && !quiet {
+		for key, entry := range t.digests {
+			if now.Sub(entry.StartTime) < t.Digest.Interval.Duration() {
+				continue
+			}
+
+			messages = append(messages, NotificationMessage{Channel: key.Channel, Text: entry.text(key.Symbol, now)})
+			delete(t.digests, key)
+		}
+	}
+
+	for key, entry := range t.dedups {
+		if now.Sub(entry.FirstTime) < t.DedupWindow {
+			continue
+		}
+
+		if entry.Count > 0 && !quiet {
+			channel := strings.SplitN(key, "\x00", 2)[0]
+			messages = append(messages, NotificationMessage{
+				Channel: channel,
+				Text:    fmt.Sprintf("%s (repeated %d times in %s)", entry.Text, entry.Count, t.DedupWindow),
+			})
+		}
+
+		delete(t.dedups, key)
+	}
+
+	if !quiet {
+		for channel, count := range t.dropped {
+			if limiter := t.limiter(channel); limiter != nil && !limiter.AllowN(now, 1) {
+				continue
+			}
+
+			messages = append(messages, NotificationMessage{
+				Channel: channel,
+				Text:    fmt.Sprintf("%d notifications were dropped by the rate limit", count),
+			})
+			delete(t.dropped, channel)
+		}
+	}
+
+	sort.Slice(messages, func(i, j int) bool {
+		if messages[i].Channel == messages[j].Channel {
+			return messages[i].Text < messages[j].Text
+		}
+		return messages[i].Channel < messages[j].Channel
+	})
+
+	return messages
+}
+
+// notificationText returns the text of the text messages and the errors for the deduplication
+func notificationText(obj interface{}, args []interface{}) (string, bool) {
+	switch v := obj.(type) {
+	case string:
+		return fmt.Sprintf(v, util.FilterSimpleArgs(args)...), true
+	case error:
+		return v.Error(), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,182 @@
+package bbgo
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type recordNotifier struct {
+	messages []interface{}
+	channels []string
+	photos   []*bytes.Buffer
+}
+
+func (n *recordNotifier) NotifyTo(channel string, obj interface{}, args ...interface{}) {
+	n.messages = append(n.messages, obj)
+	n.channels = append(n.channels, channel)
+}
+
+func (n *recordNotifier) Notify(obj interface{}, args ...interface{}) {
+	n.messages = append(n.messages, obj)
+}
+
+func (n *recordNotifier) SendPhotoTo(channel string, buffer *bytes.Buffer) {
+	n.photos = append(n.photos, buffer)
+}
+
+func (n *recordNotifier) SendPhoto(buffer *bytes.Buffer) {}
+
+func TestNotificationConfig_Throttle(t *testing.T) {
+	var conf NotificationConfig
+	err := yaml.Unmarshal([]byte(`
+minSeverity: info
+rateLimit:
+  interval: 1s
+  burst: 3
+channelRateLimits:
+  "#trades":
+    interval: 10s
+    burst: 1
+digest:
+  interval: 1m
+  trades: true
+dedupWindow: 5m
+quietHours:
+  start: "23:00"
+  end: "07:00"
+  timeZone: "Asia/Taipei"
+  minSeverity: warn
+`), &conf)
+	require.NoError(t, err)
+	assert.True(t, conf.HasThrottle())
+	assert.Equal(t, SeverityInfo, conf.MinSeverity)
+	assert.Equal(t, types.Duration(time.Second), conf.RateLimit.Interval)
+	assert.Equal(t, 1, conf.ChannelRateLimits["#trades"].Burst)
+	assert.Equal(t, types.Duration(time.Minute), conf.Digest.Interval)
+	assert.Equal(t, types.Duration(5*time.Minute), conf.DedupWindow)
+	assert.Equal(t, SeverityWarn, *conf.QuietHours.MinSeverity)
+
+	_, err = NewNotificationThrottle(&conf)
+	assert.NoError(t, err)
+
+	assert.False(t, (&NotificationConfig{}).HasThrottle())
+
+	_, err = ParseNotificationSeverity("fatal")
+	assert.Error(t, err)
+}
+
+func TestNotificationThrottle_Severity(t *testing.T) {
+	throttle, err := NewNotificationThrottle(&NotificationConfig{
+		MinSeverity: SeverityInfo,
+		QuietHours:  &QuietHours{Start: "23:00", End: "07:00", TimeZone: "UTC"},
+	})
+	require.NoError(t, err)
+
+	day := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	assert.False(t, throttle.Allow(day, "", SeverityDebug, "debug"))
+	assert.True(t, throttle.Allow(day, "", SeverityInfo, "info"))
+
+	night := time.Date(2022, 6, 1, 2, 0, 0, 0, time.UTC)
+	assert.False(t, throttle.Allow(night, "", SeverityWarn, "warn"))
+	assert.True(t, throttle.Allow(night, "", SeverityCritical, "critical"))
+
+	assert.True(t, throttle.QuietHours.Contains(time.Date(2022, 6, 1, 23, 30, 0, 0, time.UTC)))
+	assert.False(t, throttle.QuietHours.Contains(time.Date(2022, 6, 1, 7, 0, 0, 0, time.UTC)))
+}
+
+func TestNotificationThrottle_Digest(t *testing.T) {
+	throttle, err := NewNotificationThrottle(&NotificationConfig{
+		Digest: &NotificationDigest{Trades: true, Orders: true},
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	assert.False(t, throttle.Allow(now, "#btc", SeverityInfo, &types.Trade{
+		Symbol: "BTCUSDT", Side: types.SideTypeBuy, Quantity: fixedpoint.One, QuoteQuantity: fixedpoint.NewFromInt(20000),
+	}))
+	assert.False(t, throttle.Allow(now.Add(10*time.Second), "#btc", SeverityInfo, types.Trade{
+		Symbol: "BTCUSDT", Side: types.SideTypeBuy, Quantity: fixedpoint.One, QuoteQuantity: fixedpoint.NewFromInt(20100),
+	}))
+	assert.False(t, throttle.Allow(now.Add(20*time.Second), "#btc", SeverityInfo, "order update", &types.Order{
+		SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT"}, Status: types.OrderStatusFilled,
+	}))
+
+	// not due yet
+	assert.Empty(t, throttle.Flush(now.Add(30*time.Second)))
+
+	messages := throttle.Flush(now.Add(time.Minute))
+	assert.Equal(t, []NotificationMessage{{
+		Channel: "#btc",
+		Text:    "BTCUSDT digest of the last 1m0s: 2 trades, bought 2 at avg 20050, 1 order updates (1 FILLED)",
+	}}, messages)
+	assert.Empty(t, throttle.Flush(now.Add(2*time.Minute)))
+}
+
+func TestNotificationThrottle_Dedup(t *testing.T) {
+	throttle, err := NewNotificationThrottle(&NotificationConfig{
+		DedupWindow: types.Duration(time.Minute),
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	assert.True(t, throttle.Allow(now, "", SeverityWarn, errors.New("connection reset")))
+	assert.False(t, throttle.Allow(now.Add(time.Second), "", SeverityWarn, errors.New("connection reset")))
+	assert.False(t, throttle.Allow(now.Add(2*time.Second), "", SeverityWarn, errors.New("connection reset")))
+	assert.True(t, throttle.Allow(now.Add(2*time.Second), "", SeverityWarn, "%s stream disconnected", "binance"))
+
+	messages := throttle.Flush(now.Add(time.Minute))
+	assert.Equal(t, []NotificationMessage{{Text: "connection reset (repeated 2 times in 1m0s)"}}, messages)
+
+	assert.True(t, throttle.Allow(now.Add(time.Minute), "", SeverityWarn, errors.New("connection reset")))
+}
+
+func TestNotificationThrottle_RateLimit(t *testing.T) {
+	throttle, err := NewNotificationThrottle(&NotificationConfig{
+		RateLimit: &NotificationRateLimit{Interval: types.Duration(time.Second), Burst: 2},
+		ChannelRateLimits: map[string]NotificationRateLimit{
+			"#trades": {Interval: types.Duration(time.Minute), Burst: 1},
+		},
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	assert.True(t, throttle.Allow(now, "", SeverityInfo, "1"))
+	assert.True(t, throttle.Allow(now, "", SeverityInfo, "2"))
+	assert.False(t, throttle.Allow(now, "", SeverityInfo, "3"))
+	assert.True(t, throttle.Allow(now, "", SeverityCritical, "4"))
+
+	assert.True(t, throttle.Allow(now, "#trades", SeverityInfo, "1"))
+	assert.False(t, throttle.Allow(now.Add(time.Second), "#trades", SeverityInfo, "2"))
+
+	messages := throttle.Flush(now.Add(time.Second))
+	assert.Equal(t, []NotificationMessage{{Text: "1 notifications were dropped by the rate limit"}}, messages)
+
+	messages = throttle.Flush(now.Add(time.Minute))
+	assert.Equal(t, []NotificationMessage{{Channel: "#trades", Text: "1 notifications were dropped by the rate limit"}}, messages)
+}
+
+func TestNotifiability_Throttle(t *testing.T) {
+	notifier := &recordNotifier{}
+	notifiability := &Notifiability{}
+	notifiability.AddNotifier(notifier)
+
+	throttle, err := NewNotificationThrottle(&NotificationConfig{MinSeverity: SeverityWarn})
+	require.NoError(t, err)
+	notifiability.SetThrottle(throttle)
+	defer notifiability.SetThrottle(nil)
+
+	notifiability.Notify("info")
+	notifiability.NotifyTo("#channel", "info")
+	notifiability.Notify(errors.New("error"))
+	notifiability.NotifyToWithSeverity("#channel", SeverityCritical, "critical")
+	assert.Equal(t, []interface{}{errors.New("error"), "critical"}, notifier.messages)
+}
@@ -38,13 +38,25 @@ func (e *ExchangeOrderExecutionRouter) SubmitOrdersTo(ctx context.Context, sessi
 		return nil, fmt.Errorf("exchange session %s not found", session)
 	}
 
+	return submitOrdersToSession(ctx, es, orders...)
+}
+
+// submitOrdersToSession submits the orders to the session exchange with the risk checks
+func submitOrdersToSession(ctx context.Context, es *ExchangeSession, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	// format the orders first, so that the limits are checked against the orders that are actually submitted
 	formattedOrders, err := es.FormatOrders(orders)
 	if err != nil {
 		return nil, err
 	}
 
+	formattedOrders, riskErr := es.checkRisk(formattedOrders)
+	if len(formattedOrders) == 0 {
+		return nil, riskErr
+	}
+
 	createdOrders, _, err := BatchPlaceOrder(ctx, es.Exchange, formattedOrders...)
-	return createdOrders, err
+	es.addRiskOrders(createdOrders)
+	return createdOrders, multierr.Append(riskErr, err)
 }
 
 func BatchRetryPlaceOrder(ctx context.Context, exchange types.Exchange, errIdx []int, submitOrders ...types.SubmitOrder) (types.OrderSlice, error) {
@@ -127,6 +139,11 @@ func (e *ExchangeOrderExecutor) SubmitOrders(ctx context.Context, orders ...type
 		return nil, err
 	}
 
+	formattedOrders, riskErr := e.Session.checkRisk(formattedOrders)
+	if len(formattedOrders) == 0 {
+		return nil, riskErr
+	}
+
 	for _, order := range formattedOrders {
 		// pass submit order as an interface object.
 		channel, ok := e.RouteObject(&order)
@@ -142,7 +159,8 @@ func (e *ExchangeOrderExecutor) SubmitOrders(ctx context.Context, orders ...type
 	e.notifySubmitOrders(formattedOrders...)
 
 	createdOrders, _, err := BatchPlaceOrder(ctx, e.Session.Exchange, formattedOrders...)
-	return createdOrders, err
+	e.Session.addRiskOrders(createdOrders)
+	return createdOrders, multierr.Append(riskErr, err)
 }
 
 func (e *ExchangeOrderExecutor) CancelOrders(ctx context.Context, orders ...types.Order) error {
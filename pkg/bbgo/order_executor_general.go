@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -27,6 +28,13 @@ type GeneralOrderExecutor struct {
 	activeMakerOrders  *ActiveOrderBook
 	orderStore         *OrderStore
 	tradeCollector     *TradeCollector
+	clientOrderIDs     *types.ClientOrderIDGenerator
+
+	// tradeMu serializes the trade processing of the user data stream with the reconciliation
+	tradeMu sync.Mutex
+
+	// reconciler is set when the reconciliation is bound
+	reconciler *PositionReconciler
 
 	marginBaseMaxBorrowable, marginQuoteMaxBorrowable fixedpoint.Value
 }
@@ -47,6 +55,7 @@ func NewGeneralOrderExecutor(session *ExchangeSession, symbol, strategy, strateg
 		activeMakerOrders:  NewActiveOrderBook(symbol),
 		orderStore:         orderStore,
 		tradeCollector:     NewTradeCollector(symbol, position, orderStore),
+		clientOrderIDs:     types.NewClientOrderIDGenerator(strategy, strategyInstanceID, uint64(sessionTime(session).UnixMilli())),
 	}
 
 	if session.Margin {
@@ -148,7 +157,41 @@ func (e *GeneralOrderExecutor) Bind() {
 		Notify(position)
 	})
 
-	e.tradeCollector.BindStream(e.session.UserDataStream)
+	e.session.UserDataStream.OnTradeUpdate(func(trade types.Trade) {
+		e.tradeMu.Lock()
+		e.tradeCollector.ProcessTrade(trade)
+		e.tradeMu.Unlock()
+	})
+
+	e.session.addOrderExecutor(e)
+}
+
+// BindReconciler reconciles the position and the active orders with the reconcile config of the session,
+// and then reconciles periodically if the interval is set. It does nothing if the session has no reconcile config
+// or the reconciler is already bound. The startup reconciliation runs synchronously with the given context.
+// The trader binds the reconcilers of the bound executors after the strategies are started,
+// strategies call it in Run only when they need the reconciliation before submitting the first orders.
+func (e *GeneralOrderExecutor) BindReconciler(ctx context.Context) (*ReconcileReport, error) {
+	if e.session.Reconcile == nil || e.reconciler != nil {
+		return nil, nil
+	}
+
+	e.reconciler = e.newReconciler(e.session.Reconcile)
+	e.reconciler.Bind(ctx)
+	return e.reconciler.Reconcile(ctx)
+}
+
+// Reconcile runs the position reconciliation with the given config
+func (e *GeneralOrderExecutor) Reconcile(ctx context.Context, config *ReconcileConfig) (*ReconcileReport, error) {
+	return e.newReconciler(config).Reconcile(ctx)
+}
+
+func (e *GeneralOrderExecutor) newReconciler(config *ReconcileConfig) *PositionReconciler {
+	reconciler := NewPositionReconciler(config, e.session, e.position, e.orderStore, e.activeMakerOrders, e.tradeCollector)
+	reconciler.GroupID = util.FNV32(e.strategyInstanceID)
+	reconciler.ClientOrderIDs = e.clientOrderIDs
+	reconciler.Locker = &e.tradeMu
+	return reconciler
 }
 
 // CancelOrders cancels the given order objects directly
@@ -161,11 +204,41 @@ func (e *GeneralOrderExecutor) CancelOrders(ctx context.Context, orders ...types
 }
 
 func (e *GeneralOrderExecutor) SubmitOrders(ctx context.Context, submitOrders ...types.SubmitOrder) (types.OrderSlice, error) {
+	var breakerErr error
+	if breaker := e.session.circuitBreaker; breaker != nil && breaker.Tripped(e.session.Name, e.strategyInstanceID) {
+		submitOrders, breakerErr = filterReduceOrders(e.position, submitOrders)
+	}
+
+	if len(submitOrders) == 0 {
+		return nil, breakerErr
+	}
+
+	// format the orders first, so that the limits are checked against the orders that are actually submitted
 	formattedOrders, err := e.session.FormatOrders(submitOrders)
 	if err != nil {
 		return nil, err
 	}
 
+	formattedOrders, riskErr := e.session.checkRisk(formattedOrders)
+	riskErr = multierr.Append(breakerErr, riskErr)
+	if len(formattedOrders) == 0 {
+		return nil, riskErr
+	}
+
+	// the client order IDs attribute the orders to the strategy instance after restart,
+	// the group ID is only set for the reconciler when the reconciliation is enabled on the session
+	reconcile := e.session.Reconcile != nil
+	groupID := util.FNV32(e.strategyInstanceID)
+	for i := range formattedOrders {
+		if formattedOrders[i].ClientOrderID == "" {
+			formattedOrders[i].ClientOrderID = e.clientOrderIDs.Next()
+		}
+
+		if reconcile && formattedOrders[i].GroupID == 0 {
+			formattedOrders[i].GroupID = groupID
+		}
+	}
+
 	createdOrders, errIdx, err := BatchPlaceOrder(ctx, e.session.Exchange, formattedOrders...)
 	if len(errIdx) > 0 {
 		createdOrders2, err2 := BatchRetryPlaceOrder(ctx, e.session.Exchange, errIdx, formattedOrders...)
@@ -176,10 +249,11 @@ func (e *GeneralOrderExecutor) SubmitOrders(ctx context.Context, submitOrders ..
 		}
 	}
 
+	e.session.addRiskOrders(createdOrders)
 	e.orderStore.Add(createdOrders...)
 	e.activeMakerOrders.Add(createdOrders...)
 	e.tradeCollector.Process()
-	return createdOrders, err
+	return createdOrders, multierr.Append(riskErr, err)
 }
 
 type OpenPositionOptions struct {
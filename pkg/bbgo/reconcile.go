@@ -0,0 +1,394 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type ReconcilePolicy string
+
+const (
+	// ReconcilePolicyReport only reports the discrepancies
+	ReconcilePolicyReport ReconcilePolicy = "report"
+
+	// ReconcilePolicyFix replays the missed trades and fixes the orphan and the stale orders
+	ReconcilePolicyFix ReconcilePolicy = "fix"
+)
+
+type OrphanOrderAction string
+
+const (
+	// OrphanOrderActionAdopt adds the orphan orders back to the active order book
+	OrphanOrderActionAdopt OrphanOrderAction = "adopt"
+
+	// OrphanOrderActionCancel cancels the orphan orders
+	OrphanOrderActionCancel OrphanOrderAction = "cancel"
+)
+
+// ReconcileConfig configures the position reconciliation of the strategies on the session
+//
+//	reconcile:
+//	  policy: fix
+//	  orphanOrders: cancel
+//	  interval: 30m
+//
+// The strategies opt in to the reconciliation by calling GeneralOrderExecutor.BindReconciler.
+type ReconcileConfig struct {
+	// Policy is the reconciliation policy, default: report
+	Policy ReconcilePolicy `json:"policy,omitempty" yaml:"policy,omitempty"`
+
+	// OrphanOrders is the action for the orphan orders with the fix policy, default: adopt
+	OrphanOrders OrphanOrderAction `json:"orphanOrders,omitempty" yaml:"orphanOrders,omitempty"`
+
+	// Interval is the periodic reconciliation interval, the reconciliation only runs on startup if it's not set
+	Interval types.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+
+	// TradeLookback is the period of the trades to query when the position has never changed, default: 24h
+	TradeLookback types.Duration `json:"tradeLookback,omitempty" yaml:"tradeLookback,omitempty"`
+}
+
+func (c *ReconcileConfig) Defaults() {
+	if c.Policy == "" {
+		c.Policy = ReconcilePolicyReport
+	}
+
+	if c.OrphanOrders == "" {
+		c.OrphanOrders = OrphanOrderActionAdopt
+	}
+
+	if c.TradeLookback == 0 {
+		c.TradeLookback = types.Duration(24 * time.Hour)
+	}
+}
+
+// ReconcileReport is the result of a reconciliation
+type ReconcileReport struct {
+	Session string    `json:"session"`
+	Symbol  string    `json:"symbol"`
+	Time    time.Time `json:"time"`
+
+	// MissedTrades are the trades of the strategy orders that are not applied to the position
+	MissedTrades []types.Trade `json:"missedTrades,omitempty"`
+
+	// OrphanOrders are the open orders of the strategy on the exchange that are not in the active order book
+	OrphanOrders []types.Order `json:"orphanOrders,omitempty"`
+
+	// StaleOrders are the orders in the active order book that are not open on the exchange
+	StaleOrders []types.Order `json:"staleOrders,omitempty"`
+
+	// BalanceShortfall is the base quantity of the long spot position that is not covered by the exchange balance
+	BalanceShortfall fixedpoint.Value `json:"balanceShortfall,omitempty"`
+
+	// Fixed is true if the discrepancies are fixed by the fix policy
+	Fixed bool `json:"fixed"`
+}
+
+func (r *ReconcileReport) HasDiscrepancy() bool {
+	return len(r.MissedTrades) > 0 || len(r.OrphanOrders) > 0 || len(r.StaleOrders) > 0 || r.BalanceShortfall.Sign() > 0
+}
+
+func (r *ReconcileReport) String() string {
+	if !r.HasDiscrepancy() {
+		return fmt.Sprintf("[%s] %s position is reconciled", r.Session, r.Symbol)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("[%s] %s position reconciliation", r.Session, r.Symbol))
+	if r.Fixed {
+		sb.WriteString(" (fixed)")
+	}
+	sb.WriteString(":")
+
+	if n := len(r.MissedTrades); n > 0 {
+		sb.WriteString(fmt.Sprintf("\n- %d missed trades", n))
+	}
+
+	if n := len(r.OrphanOrders); n > 0 {
+		sb.WriteString(fmt.Sprintf("\n- %d orphan orders", n))
+	}
+
+	if n := len(r.StaleOrders); n > 0 {
+		sb.WriteString(fmt.Sprintf("\n- %d stale orders", n))
+	}
+
+	if r.BalanceShortfall.Sign() > 0 {
+		sb.WriteString(fmt.Sprintf("\n- position base exceeds the balance by %v", r.BalanceShortfall))
+	}
+
+	return sb.String()
+}
+
+// PositionReconciler compares the persisted position and the active orders of a strategy with the exchange,
+// the orders are attributed to the strategy by the order store, the group ID or the client order ID.
+type PositionReconciler struct {
+	Config *ReconcileConfig
+
+	// GroupID is the group ID of the strategy orders, zero to disable
+	GroupID uint32
+
+	// ClientOrderIDs is the client order ID generator of the strategy orders, nil to disable
+	ClientOrderIDs *types.ClientOrderIDGenerator
+
+	// Locker serializes the reconciliation with the trade processing of the strategy, optional
+	Locker sync.Locker
+
+	session        *ExchangeSession
+	symbol         string
+	position       *types.Position
+	orderStore     *OrderStore
+	activeOrders   *ActiveOrderBook
+	tradeCollector *TradeCollector
+
+	mu               sync.Mutex
+	lastReconciledAt time.Time
+}
+
+func NewPositionReconciler(config *ReconcileConfig, session *ExchangeSession, position *types.Position, orderStore *OrderStore, activeOrders *ActiveOrderBook, tradeCollector *TradeCollector) *PositionReconciler {
+	// the config can be shared by the strategies of the session, the defaults are applied to a copy
+	c := *config
+	c.Defaults()
+	return &PositionReconciler{
+		Config:         &c,
+		session:        session,
+		symbol:         position.Symbol,
+		position:       position,
+		orderStore:     orderStore,
+		activeOrders:   activeOrders,
+		tradeCollector: tradeCollector,
+	}
+}
+
+// Bind reconciles periodically on the closed klines of the session if the interval is set
+func (r *PositionReconciler) Bind(ctx context.Context) {
+	if r.Config.Interval == 0 {
+		return
+	}
+
+	r.session.MarketDataStream.OnKLineClosed(func(k types.KLine) {
+		now := sessionTime(r.session)
+
+		r.mu.Lock()
+		if now.Sub(r.lastReconciledAt) < r.Config.Interval.Duration() {
+			r.mu.Unlock()
+			return
+		}
+		r.lastReconciledAt = now
+		r.mu.Unlock()
+
+		if _, err := r.Reconcile(ctx); err != nil {
+			log.WithError(err).Errorf("[%s] %s position reconciliation error", r.session.Name, r.symbol)
+		}
+	})
+}
+
+// Reconcile compares the position and the orders with the exchange, and fixes the discrepancies with the fix policy
+func (r *PositionReconciler) Reconcile(ctx context.Context) (*ReconcileReport, error) {
+	if r.Locker != nil {
+		r.Locker.Lock()
+		defer r.Locker.Unlock()
+	}
+
+	now := sessionTime(r.session)
+	r.mu.Lock()
+	r.lastReconciledAt = now
+	r.mu.Unlock()
+
+	report := &ReconcileReport{
+		Session: r.session.Name,
+		Symbol:  r.symbol,
+		Time:    now,
+	}
+
+	fix := r.Config.Policy == ReconcilePolicyFix
+
+	var errs error
+	if err := r.reconcileOrders(ctx, report, fix); err != nil {
+		errs = multierr.Append(errs, err)
+	}
+
+	if err := r.reconcileTrades(ctx, report, now, fix); err != nil {
+		errs = multierr.Append(errs, err)
+	}
+
+	r.reconcileBalance(report)
+
+	report.Fixed = fix && errs == nil && (len(report.MissedTrades) > 0 || len(report.OrphanOrders) > 0 || len(report.StaleOrders) > 0)
+
+	if report.HasDiscrepancy() {
+		log.Warn(report.String())
+		NotifyWithSeverity(SeverityWarn, report.String())
+	}
+
+	return report, errs
+}
+
+func (r *PositionReconciler) owns(order types.Order) bool {
+	if r.orderStore.Exists(order.OrderID) {
+		return true
+	}
+
+	if r.GroupID != 0 && order.GroupID == r.GroupID {
+		return true
+	}
+
+	return r.ClientOrderIDs != nil && r.ClientOrderIDs.Owns(order.ClientOrderID)
+}
+
+func (r *PositionReconciler) reconcileOrders(ctx context.Context, report *ReconcileReport, fix bool) error {
+	openOrders, err := r.session.Exchange.QueryOpenOrders(ctx, r.symbol)
+	if err != nil {
+		return err
+	}
+
+	open := make(map[uint64]struct{})
+	for _, order := range openOrders {
+		if !r.owns(order) {
+			continue
+		}
+
+		open[order.OrderID] = struct{}{}
+		if !r.activeOrders.Exists(order) {
+			report.OrphanOrders = append(report.OrphanOrders, order)
+		}
+	}
+
+	for _, order := range r.activeOrders.Orders() {
+		if _, ok := open[order.OrderID]; !ok {
+			report.StaleOrders = append(report.StaleOrders, order)
+		}
+	}
+
+	if !fix {
+		return nil
+	}
+
+	var errs error
+	if len(report.OrphanOrders) > 0 {
+		switch r.Config.OrphanOrders {
+		case OrphanOrderActionCancel:
+			if err := r.session.Exchange.CancelOrders(ctx, report.OrphanOrders...); err != nil {
+				errs = multierr.Append(errs, err)
+			}
+
+		default:
+			r.orderStore.Add(report.OrphanOrders...)
+			r.activeOrders.Add(report.OrphanOrders...)
+		}
+	}
+
+	queryService, hasQueryService := r.session.Exchange.(types.ExchangeOrderQueryService)
+	for _, order := range report.StaleOrders {
+		r.activeOrders.Remove(order)
+
+		if !hasQueryService {
+			continue
+		}
+
+		updated, err := queryService.QueryOrder(ctx, types.OrderQuery{
+			Symbol:  order.Symbol,
+			OrderID: strconv.FormatUint(order.OrderID, 10),
+		})
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+
+		if updated != nil {
+			r.orderStore.Update(*updated)
+		}
+	}
+
+	return errs
+}
+
+func (r *PositionReconciler) reconcileTrades(ctx context.Context, report *ReconcileReport, now time.Time, fix bool) error {
+	history, ok := r.session.Exchange.(types.ExchangeTradeHistoryService)
+	if !ok {
+		return nil
+	}
+
+	changedAt := r.position.ChangedAt
+	since := changedAt
+	if since.IsZero() {
+		since = now.Add(-r.Config.TradeLookback.Duration())
+	}
+
+	trades, err := history.QueryTrades(ctx, r.symbol, &types.TradeQueryOptions{StartTime: &since})
+	if err != nil {
+		return err
+	}
+
+	queryService, hasQueryService := r.session.Exchange.(types.ExchangeOrderQueryService)
+
+	// the orders of the trades that are not in the order store
+	orders := make(map[uint64]*types.Order)
+
+	var errs error
+	for _, trade := range types.SortTradesAscending(trades) {
+		// the trades at the change time are already applied to the position
+		if !changedAt.IsZero() && !trade.Time.Time().After(changedAt) {
+			continue
+		}
+
+		if !r.orderStore.Exists(trade.OrderID) {
+			order, queried := orders[trade.OrderID]
+			if !queried && hasQueryService {
+				order, err = queryService.QueryOrder(ctx, types.OrderQuery{
+					Symbol:  trade.Symbol,
+					OrderID: strconv.FormatUint(trade.OrderID, 10),
+				})
+				if err != nil {
+					errs = multierr.Append(errs, err)
+				}
+				orders[trade.OrderID] = order
+			}
+
+			if order == nil || !r.owns(*order) {
+				continue
+			}
+
+			if fix {
+				r.orderStore.Add(*order)
+			}
+		}
+
+		if fix {
+			// the trades processed by the collector are skipped
+			if r.tradeCollector.ProcessTrade(trade) {
+				report.MissedTrades = append(report.MissedTrades, trade)
+			}
+		} else {
+			report.MissedTrades = append(report.MissedTrades, trade)
+		}
+	}
+
+	return errs
+}
+
+// reconcileBalance checks that the long spot position is covered by the exchange balance,
+// the balance can be more than the position since it's shared by the strategies.
+func (r *PositionReconciler) reconcileBalance(report *ReconcileReport) {
+	if r.session.Margin || r.session.Futures || r.session.IsolatedFutures {
+		return
+	}
+
+	base := r.position.GetBase()
+	if base.Sign() <= 0 {
+		return
+	}
+
+	balance, _ := r.session.GetAccount().Balance(r.position.Market.BaseCurrency)
+	if shortfall := base.Sub(balance.Total()); shortfall.Sign() > 0 {
+		report.BalanceShortfall = shortfall
+	}
+}
@@ -0,0 +1,246 @@
+package bbgo
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+// reconcileTestExchange serves the trade history and the order query from memory
+type reconcileTestExchange struct {
+	*mocks.MockExchange
+
+	trades []types.Trade
+	orders map[uint64]types.Order
+}
+
+func (e *reconcileTestExchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) ([]types.Trade, error) {
+	var trades []types.Trade
+	for _, trade := range e.trades {
+		if options.StartTime != nil && trade.Time.Time().Before(*options.StartTime) {
+			continue
+		}
+		trades = append(trades, trade)
+	}
+	return trades, nil
+}
+
+func (e *reconcileTestExchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) ([]types.Order, error) {
+	return nil, nil
+}
+
+func (e *reconcileTestExchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	orderID, err := strconv.ParseUint(q.OrderID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	if order, ok := e.orders[orderID]; ok {
+		return &order, nil
+	}
+	return nil, nil
+}
+
+func (e *reconcileTestExchange) QueryOrderTrades(ctx context.Context, q types.OrderQuery) ([]types.Trade, error) {
+	return nil, nil
+}
+
+func newReconcileTestOrder(orderID uint64, groupID uint32, status types.OrderStatus) types.Order {
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeBuy,
+			Type:     types.OrderTypeLimit,
+			Quantity: fixedpoint.One,
+			Price:    fixedpoint.NewFromInt(20000),
+			GroupID:  groupID,
+		},
+		OrderID: orderID,
+		Status:  status,
+	}
+}
+
+func newReconcileTestTrade(id, orderID uint64, quantity string, tradeTime time.Time) types.Trade {
+	q := fixedpoint.MustNewFromString(quantity)
+	price := fixedpoint.NewFromInt(20000)
+	return types.Trade{
+		ID:            id,
+		OrderID:       orderID,
+		Exchange:      types.ExchangeBinance,
+		Symbol:        "BTCUSDT",
+		Side:          types.SideTypeBuy,
+		IsBuyer:       true,
+		Price:         price,
+		Quantity:      q,
+		QuoteQuantity: q.Mul(price),
+		FeeCurrency:   "BNB",
+		Time:          types.Time(tradeTime),
+	}
+}
+
+func TestPositionReconciler_Reconcile(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	groupID := util.FNV32("test")
+	clientOrderIDs := types.NewClientOrderIDGenerator("test", "test", 0)
+
+	// the order without group ID is attributed by the client order ID
+	order7 := newReconcileTestOrder(7, 0, types.OrderStatusNew)
+	order7.ClientOrderID = "x-NSUYEBKM" + clientOrderIDs.Next()
+	t0 := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	ex := &reconcileTestExchange{
+		MockExchange: mockEx,
+		trades: []types.Trade{
+			// applied before the crash
+			newReconcileTestTrade(1, 1, "0.1", t0),
+			// the fill of the stale order
+			newReconcileTestTrade(2, 2, "0.5", t0.Add(time.Minute)),
+			// the fill of the order submitted right before the crash
+			newReconcileTestTrade(3, 5, "0.2", t0.Add(2*time.Minute)),
+			// the fill of the other strategy
+			newReconcileTestTrade(4, 6, "0.3", t0.Add(3*time.Minute)),
+		},
+		orders: map[uint64]types.Order{
+			2: newReconcileTestOrder(2, groupID, types.OrderStatusFilled),
+			5: newReconcileTestOrder(5, groupID, types.OrderStatusFilled),
+			6: newReconcileTestOrder(6, 0, types.OrderStatusFilled),
+		},
+	}
+	mockEx.EXPECT().QueryOpenOrders(gomock.Any(), "BTCUSDT").Return([]types.Order{
+		newReconcileTestOrder(3, groupID, types.OrderStatusNew),
+		newReconcileTestOrder(4, 0, types.OrderStatusNew),
+		order7,
+	}, nil).AnyTimes()
+
+	session := NewExchangeSession("binance", ex)
+	session.Account.UpdateBalances(types.BalanceMap{
+		"BTC": {Currency: "BTC", Available: fixedpoint.One},
+	})
+
+	market := types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}
+	position := types.NewPositionFromMarket(market)
+	position.ChangedAt = t0
+
+	orderStore := NewOrderStore("BTCUSDT")
+	orderStore.Add(newReconcileTestOrder(1, groupID, types.OrderStatusFilled))
+
+	activeOrders := NewActiveOrderBook("BTCUSDT")
+	activeOrders.Add(newReconcileTestOrder(2, groupID, types.OrderStatusNew))
+
+	tradeCollector := NewTradeCollector("BTCUSDT", position, orderStore)
+
+	config := &ReconcileConfig{}
+	reconciler := NewPositionReconciler(config, session, position, orderStore, activeOrders, tradeCollector)
+	reconciler.GroupID = groupID
+	reconciler.ClientOrderIDs = clientOrderIDs
+	assert.Equal(t, ReconcilePolicyReport, reconciler.Config.Policy)
+	assert.Equal(t, 24*time.Hour, reconciler.Config.TradeLookback.Duration())
+
+	// the shared config is not modified
+	assert.Equal(t, ReconcilePolicy(""), config.Policy)
+
+	ctx := context.Background()
+	report, err := reconciler.Reconcile(ctx)
+	assert.NoError(t, err)
+	assert.True(t, report.HasDiscrepancy())
+	assert.False(t, report.Fixed)
+	if assert.Len(t, report.MissedTrades, 2) {
+		assert.Equal(t, uint64(2), report.MissedTrades[0].ID)
+		assert.Equal(t, uint64(3), report.MissedTrades[1].ID)
+	}
+	if assert.Len(t, report.OrphanOrders, 2) {
+		assert.Equal(t, uint64(3), report.OrphanOrders[0].OrderID)
+		assert.Equal(t, uint64(7), report.OrphanOrders[1].OrderID)
+	}
+	if assert.Len(t, report.StaleOrders, 1) {
+		assert.Equal(t, uint64(2), report.StaleOrders[0].OrderID)
+	}
+
+	// nothing is changed with the report policy
+	assert.Equal(t, "0", position.GetBase().String())
+	assert.Equal(t, 1, activeOrders.NumOfOrders())
+
+	reconciler.Config.Policy = ReconcilePolicyFix
+	report, err = reconciler.Reconcile(ctx)
+	assert.NoError(t, err)
+	assert.True(t, report.Fixed)
+	assert.Len(t, report.MissedTrades, 2)
+	assert.Equal(t, "0.7", position.GetBase().String())
+	assert.Equal(t, t0.Add(2*time.Minute), position.ChangedAt)
+
+	assert.True(t, activeOrders.Exists(newReconcileTestOrder(3, groupID, types.OrderStatusNew)))
+	assert.True(t, activeOrders.Exists(order7))
+	assert.False(t, activeOrders.Exists(newReconcileTestOrder(2, groupID, types.OrderStatusNew)))
+	assert.True(t, orderStore.Exists(5))
+	assert.False(t, orderStore.Exists(6))
+
+	// reconciled
+	report, err = reconciler.Reconcile(ctx)
+	assert.NoError(t, err)
+	assert.False(t, report.HasDiscrepancy())
+
+	// the long position is not covered by the balance
+	session.Account.UpdateBalances(types.BalanceMap{
+		"BTC": {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.5)},
+	})
+	report, err = reconciler.Reconcile(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.2", report.BalanceShortfall.String())
+}
+
+func TestExchangeSession_bindReconcilers(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+	mockEx.EXPECT().QueryOpenOrders(gomock.Any(), "BTCUSDT").Return(nil, nil).Times(1)
+
+	session := NewExchangeSession("binance", &reconcileTestExchange{MockExchange: mockEx})
+	market := getTestMarket()
+	session.markets[market.Symbol] = market
+
+	position := types.NewPositionFromMarket(market)
+	executor := NewGeneralOrderExecutor(session, "BTCUSDT", "test", "test-01", position)
+	executor.Bind()
+
+	// nothing is reconciled without the reconcile config
+	session.bindReconcilers(context.Background())
+	assert.Nil(t, executor.reconciler)
+
+	session.Reconcile = &ReconcileConfig{}
+	session.bindReconcilers(context.Background())
+	assert.NotNil(t, executor.reconciler)
+
+	// the executor is only reconciled once on startup
+	_, err := executor.BindReconciler(context.Background())
+	assert.NoError(t, err)
+
+	// the orders are attributed by the group ID when the reconciliation is enabled
+	mockEx.EXPECT().SubmitOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+		assert.Equal(t, util.FNV32("test-01"), order.GroupID)
+		return &types.Order{SubmitOrder: order, OrderID: 1, Status: types.OrderStatusNew}, nil
+	})
+
+	_, err = executor.SubmitOrders(context.Background(), types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.One,
+	})
+	assert.NoError(t, err)
+}
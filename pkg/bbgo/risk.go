@@ -63,6 +63,24 @@ func (c *AccountValueCalculator) UpdatePrices(ctx context.Context) error {
 	return nil
 }
 
+// Price returns the price of the currency in the quote currency of the calculator,
+// the last prices of the session are used if the ticker price is not found.
+func (c *AccountValueCalculator) Price(currency string) (fixedpoint.Value, bool) {
+	if currency == c.quoteCurrency {
+		return fixedpoint.One, true
+	}
+
+	if price, ok := c.prices[currency+c.quoteCurrency]; ok && price.Sign() > 0 {
+		return price, true
+	}
+
+	if price, ok := c.prices[c.quoteCurrency+currency]; ok && price.Sign() > 0 {
+		return fixedpoint.One.Div(price), true
+	}
+
+	return valuationPrice(c.session, currency, c.quoteCurrency)
+}
+
 func (c *AccountValueCalculator) DebtValue(ctx context.Context) (fixedpoint.Value, error) {
 	debtValue := fixedpoint.Zero
 
@@ -74,6 +92,11 @@ func (c *AccountValueCalculator) DebtValue(ctx context.Context) (fixedpoint.Valu
 
 	balances := c.session.Account.Balances()
 	for _, b := range balances {
+		if b.Currency == c.quoteCurrency {
+			debtValue = debtValue.Add(b.Debt())
+			continue
+		}
+
 		symbol := b.Currency + c.quoteCurrency
 		price, ok := c.prices[symbol]
 		if !ok {
@@ -71,4 +71,10 @@ func groupSubmitOrdersBySymbol(orders []types.SubmitOrder) map[string][]types.Su
 
 type RiskControls struct {
 	SessionBasedRiskControl map[string]*SessionBasedRiskControl `json:"sessionBased,omitempty" yaml:"sessionBased,omitempty"`
+
+	// Engine is the portfolio-level risk engine across the strategies and the sessions
+	Engine *RiskEngineConfig `json:"engine,omitempty" yaml:"engine,omitempty"`
+
+	// CircuitBreaker suspends or flattens the strategies on the daily loss and the drawdown
+	CircuitBreaker *CircuitBreakerConfig `json:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty"`
 }
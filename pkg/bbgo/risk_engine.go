@@ -0,0 +1,538 @@
+package bbgo
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// ErrRiskRejected is the base error of the orders rejected by the risk engine,
+// use errors.Is(err, ErrRiskRejected) to check if the order is rejected by the risk engine.
+var ErrRiskRejected = errors.New("order rejected by risk engine")
+
+type RiskRejectReason string
+
+const (
+	RiskRejectNoPrice              RiskRejectReason = "NoPrice"
+	RiskRejectFatFingerNotional    RiskRejectReason = "FatFingerNotional"
+	RiskRejectFatFingerQuantity    RiskRejectReason = "FatFingerQuantity"
+	RiskRejectPriceBand            RiskRejectReason = "PriceBand"
+	RiskRejectMaxOpenOrders        RiskRejectReason = "MaxOpenOrders"
+	RiskRejectMaxNotionalPerMinute RiskRejectReason = "MaxNotionalPerMinute"
+	RiskRejectMaxGrossExposure     RiskRejectReason = "MaxGrossExposure"
+	RiskRejectMaxNetExposure       RiskRejectReason = "MaxNetExposure"
+)
+
+// RiskRejectionError is returned when the submit order is rejected by the risk engine
+type RiskRejectionError struct {
+	Reason  RiskRejectReason
+	Session string
+
+	// Scope is the scope of the limit, e.g., "global", "session binance", "asset BTC"
+	Scope string
+	Order types.SubmitOrder
+
+	// Value is the value that exceeds the limit
+	Value fixedpoint.Value
+	Limit fixedpoint.Value
+}
+
+func (e *RiskRejectionError) Error() string {
+	return fmt.Sprintf("%s: %s %s %s %s order rejected, %s %s exceeds the limit %s (%s)",
+		ErrRiskRejected.Error(), e.Session, e.Order.Symbol, e.Order.Side, e.Order.Type,
+		e.Scope, e.Value.String(), e.Limit.String(), e.Reason)
+}
+
+func (e *RiskRejectionError) Is(target error) bool {
+	return target == ErrRiskRejected
+}
+
+// ExposureLimit limits the exposure in the valuation currency
+type ExposureLimit struct {
+	MaxGrossExposure fixedpoint.Value `json:"maxGrossExposure,omitempty" yaml:"maxGrossExposure,omitempty"`
+	MaxNetExposure   fixedpoint.Value `json:"maxNetExposure,omitempty" yaml:"maxNetExposure,omitempty"`
+}
+
+type AssetRiskLimit struct {
+	ExposureLimit `yaml:",inline"`
+
+	// MaxOrderQuantity is the fat-finger guard of the order quantity of the asset
+	MaxOrderQuantity fixedpoint.Value `json:"maxOrderQuantity,omitempty" yaml:"maxOrderQuantity,omitempty"`
+}
+
+type SessionRiskLimit struct {
+	ExposureLimit `yaml:",inline"`
+
+	MaxOpenOrders        int              `json:"maxOpenOrders,omitempty" yaml:"maxOpenOrders,omitempty"`
+	MaxNotionalPerMinute fixedpoint.Value `json:"maxNotionalPerMinute,omitempty" yaml:"maxNotionalPerMinute,omitempty"`
+}
+
+// RiskEngineConfig is the config of the portfolio-level risk engine,
+// the zero limits are disabled.
+type RiskEngineConfig struct {
+	// ValuationCurrency is the currency of the notional and the exposure values, default: USDT
+	ValuationCurrency string `json:"valuationCurrency,omitempty" yaml:"valuationCurrency,omitempty"`
+
+	// MaxOpenOrders is the max number of the open orders across all sessions
+	MaxOpenOrders int `json:"maxOpenOrders,omitempty" yaml:"maxOpenOrders,omitempty"`
+
+	// MaxNotionalPerMinute is the max submitted notional in the last minute across all sessions
+	MaxNotionalPerMinute fixedpoint.Value `json:"maxNotionalPerMinute,omitempty" yaml:"maxNotionalPerMinute,omitempty"`
+
+	// MaxOrderNotional is the fat-finger guard of the order notional
+	MaxOrderNotional fixedpoint.Value `json:"maxOrderNotional,omitempty" yaml:"maxOrderNotional,omitempty"`
+
+	// PriceBand is the max deviation ratio of the limit price from the last price, e.g., 0.05 = 5%
+	PriceBand fixedpoint.Value `json:"priceBand,omitempty" yaml:"priceBand,omitempty"`
+
+	// Assets are the limits of the assets across all sessions
+	Assets map[string]AssetRiskLimit `json:"assets,omitempty" yaml:"assets,omitempty"`
+
+	// Sessions are the limits of each session
+	Sessions map[string]SessionRiskLimit `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+}
+
+type riskNotional struct {
+	session string
+	time    time.Time
+	value   fixedpoint.Value
+}
+
+// riskExposure is the exposure of an asset in the valuation currency
+type riskExposure struct {
+	net, gross fixedpoint.Value
+}
+
+// apply returns the exposure after the signed value is added.
+// The gross exposure increases when the absolute net exposure increases, otherwise it decreases.
+func (e riskExposure) apply(value fixedpoint.Value) riskExposure {
+	net := e.net.Add(value)
+	if net.Abs().Compare(e.net.Abs()) > 0 {
+		return riskExposure{net: net, gross: e.gross.Add(value.Abs())}
+	}
+
+	return riskExposure{net: net, gross: fixedpoint.Max(net.Abs(), e.gross.Sub(value.Abs()))}
+}
+
+const maxNumOfClosedOrders = 1000
+
+// exchangeClock is implemented by the exchanges that have their own clock, e.g., the backtest exchange
+type exchangeClock interface {
+	CurrentTime() time.Time
+}
+
+// RiskEngine runs the pre-trade checks of the orders across the strategies and the sessions.
+// It's consulted by the order executors of the sessions before the orders are submitted,
+// the rejected orders are removed from the batch and the rejections are returned as *RiskRejectionError.
+type RiskEngine struct {
+	Config *RiskEngineConfig
+
+	mu sync.Mutex
+
+	sessions map[string]*ExchangeSession
+
+	// openOrders: session -> order id -> order
+	openOrders map[string]map[uint64]types.Order
+
+	// closedOrders records the closed orders, so that the orders closed before AddOrders are not added back
+	// map: session -> order id
+	closedOrders map[string]map[uint64]struct{}
+
+	notionals []riskNotional
+}
+
+func NewRiskEngine(config *RiskEngineConfig) *RiskEngine {
+	if config.ValuationCurrency == "" {
+		config.ValuationCurrency = "USDT"
+	}
+
+	return &RiskEngine{
+		Config:       config,
+		sessions:     make(map[string]*ExchangeSession),
+		openOrders:   make(map[string]map[uint64]types.Order),
+		closedOrders: make(map[string]map[uint64]struct{}),
+	}
+}
+
+// BindSession tracks the open orders of the session from the user data stream
+func (r *RiskEngine) BindSession(session *ExchangeSession) {
+	r.mu.Lock()
+	r.sessions[session.Name] = session
+	r.openOrders[session.Name] = make(map[uint64]types.Order)
+	r.closedOrders[session.Name] = make(map[uint64]struct{})
+	r.mu.Unlock()
+
+	session.UserDataStream.OnOrderUpdate(func(order types.Order) {
+		r.updateOrder(session.Name, order)
+	})
+}
+
+func (r *RiskEngine) updateOrder(sessionName string, order types.Order) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	orders, ok := r.openOrders[sessionName]
+	if !ok {
+		return
+	}
+
+	closed := r.closedOrders[sessionName]
+	if _, ok := closed[order.OrderID]; ok {
+		return
+	}
+
+	switch order.Status {
+	case types.OrderStatusNew, types.OrderStatusPartiallyFilled:
+		orders[order.OrderID] = order
+	default:
+		delete(orders, order.OrderID)
+
+		// keep the recent closed orders only
+		if len(closed) >= maxNumOfClosedOrders {
+			closed = make(map[uint64]struct{})
+			r.closedOrders[sessionName] = closed
+		}
+		closed[order.OrderID] = struct{}{}
+	}
+}
+
+// AddOrders adds the created orders to the open orders before the order updates are received.
+// The notional of the created orders is counted into the notional per minute,
+// so that the orders failed to be placed are not counted.
+func (r *RiskEngine) AddOrders(session *ExchangeSession, orders ...types.Order) {
+	now := sessionTime(session)
+	for _, order := range orders {
+		if order.Status == "" {
+			order.Status = types.OrderStatusNew
+		}
+		r.updateOrder(session.Name, order)
+
+		notional, _, rejection := r.orderNotional(session, order.SubmitOrder)
+		if rejection != nil {
+			log.WithError(rejection).Warnf("risk engine can not calculate the notional of order: %s", order.String())
+			continue
+		}
+
+		r.mu.Lock()
+		r.notionals = append(r.notionals, riskNotional{session: session.Name, time: now, value: notional})
+		r.mu.Unlock()
+	}
+}
+
+// sessionTime returns the current time of the session, which is the kline time in the back-test
+func sessionTime(session *ExchangeSession) time.Time {
+	if clock, ok := session.Exchange.(exchangeClock); ok {
+		return clock.CurrentTime()
+	}
+	return time.Now()
+}
+
+// CheckOrders runs the pre-trade checks on the orders of the session, the orders are checked in sequence and
+// the accepted orders are counted into the limits of the following orders.
+// It returns the accepted orders and the rejections.
+func (r *RiskEngine) CheckOrders(session *ExchangeSession, orders ...types.SubmitOrder) (accepted []types.SubmitOrder, err error) {
+	accepted, rejections := r.checkOrders(session, orders)
+
+	// notify after the lock is released, so that the notifiers don't block the other checks
+	for _, rejection := range rejections {
+		log.WithError(rejection).Warnf("risk engine rejected order: %s", rejection.Order.String())
+		NotifyWithSeverity(SeverityWarn, ":no_entry: %s", rejection.Error())
+		err = multierr.Append(err, rejection)
+	}
+
+	return accepted, err
+}
+
+func (r *RiskEngine) checkOrders(session *ExchangeSession, orders []types.SubmitOrder) (accepted []types.SubmitOrder, rejections []*RiskRejectionError) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := sessionTime(session)
+	r.truncateNotionals(now)
+
+	// the accepted orders of the batch are counted as open orders and into the notional per minute
+	// until they are added by AddOrders after they are created
+	numOfPending := 0
+	pendingNotional := fixedpoint.Zero
+
+	exposures := r.exposures()
+	for _, order := range orders {
+		value, rejection := r.checkOrder(session, order, exposures, numOfPending, pendingNotional)
+		if rejection != nil {
+			rejection.Session = session.Name
+			rejection.Order = order
+			rejections = append(rejections, rejection)
+			continue
+		}
+
+		accepted = append(accepted, order)
+		numOfPending++
+		pendingNotional = pendingNotional.Add(value.Abs())
+	}
+
+	return accepted, rejections
+}
+
+func (r *RiskEngine) truncateNotionals(now time.Time) {
+	since := now.Add(-time.Minute)
+	i := 0
+	for ; i < len(r.notionals); i++ {
+		if r.notionals[i].time.After(since) {
+			break
+		}
+	}
+	r.notionals = r.notionals[i:]
+}
+
+// orderNotional returns the notional value of the order in the valuation currency,
+// the last price is used for the market orders.
+func (r *RiskEngine) orderNotional(session *ExchangeSession, order types.SubmitOrder) (fixedpoint.Value, types.Market, *RiskRejectionError) {
+	market, ok := session.Market(order.Symbol)
+	if !ok {
+		market = order.Market
+	}
+
+	price := order.Price
+	if order.Type == types.OrderTypeMarket || price.IsZero() {
+		price, _ = session.LastPrice(order.Symbol)
+	}
+
+	if price.IsZero() {
+		return fixedpoint.Zero, market, &RiskRejectionError{Reason: RiskRejectNoPrice, Scope: "price"}
+	}
+
+	quoteValue, ok := r.assetPrice(session, market.QuoteCurrency)
+	if !ok {
+		return fixedpoint.Zero, market, &RiskRejectionError{Reason: RiskRejectNoPrice, Scope: "asset " + market.QuoteCurrency}
+	}
+
+	return order.Quantity.Mul(price).Mul(quoteValue), market, nil
+}
+
+// checkOrder checks the order and updates the exposures if the order is accepted,
+// it returns the signed notional value of the order in the valuation currency.
+// numOfPending and pendingNotional are the accepted orders of the batch that are not created yet.
+func (r *RiskEngine) checkOrder(session *ExchangeSession, order types.SubmitOrder, exposures map[string]map[string]riskExposure, numOfPending int, pendingNotional fixedpoint.Value) (fixedpoint.Value, *RiskRejectionError) {
+	notional, market, rejection := r.orderNotional(session, order)
+	if rejection != nil {
+		return fixedpoint.Zero, rejection
+	}
+
+	lastPrice, hasLastPrice := session.LastPrice(order.Symbol)
+	signed := notional
+	if order.Side == types.SideTypeSell {
+		signed = signed.Neg()
+	}
+
+	// the reduce-only orders only reduce the exposure, so they are not guarded by the fat-finger guards and the price band
+	reduceOnly := order.ReduceOnly || order.ClosePosition
+
+	// fat-finger guards
+	if c := r.Config.MaxOrderNotional; c.Sign() > 0 && !reduceOnly && notional.Compare(c) > 0 {
+		return signed, &RiskRejectionError{Reason: RiskRejectFatFingerNotional, Scope: "order notional", Value: notional, Limit: c}
+	}
+
+	if limit, ok := r.Config.Assets[market.BaseCurrency]; ok && limit.MaxOrderQuantity.Sign() > 0 && !reduceOnly && order.Quantity.Compare(limit.MaxOrderQuantity) > 0 {
+		return signed, &RiskRejectionError{Reason: RiskRejectFatFingerQuantity, Scope: "order quantity", Value: order.Quantity, Limit: limit.MaxOrderQuantity}
+	}
+
+	// price band
+	if c := r.Config.PriceBand; c.Sign() > 0 && !reduceOnly && hasLastPrice && lastPrice.Sign() > 0 && order.Type != types.OrderTypeMarket {
+		deviation := order.Price.Sub(lastPrice).Abs().Div(lastPrice)
+		if deviation.Compare(c) > 0 {
+			return signed, &RiskRejectionError{Reason: RiskRejectPriceBand, Scope: "price deviation", Value: deviation, Limit: c}
+		}
+	}
+
+	// open orders
+	if c := r.Config.MaxOpenOrders; c > 0 {
+		if n := r.numOfOpenOrders("") + numOfPending; n+1 > c {
+			return signed, &RiskRejectionError{Reason: RiskRejectMaxOpenOrders, Scope: "global open orders", Value: fixedpoint.NewFromInt(int64(n + 1)), Limit: fixedpoint.NewFromInt(int64(c))}
+		}
+	}
+
+	sessionLimit, hasSessionLimit := r.Config.Sessions[session.Name]
+	if c := sessionLimit.MaxOpenOrders; hasSessionLimit && c > 0 {
+		if n := r.numOfOpenOrders(session.Name) + numOfPending; n+1 > c {
+			return signed, &RiskRejectionError{Reason: RiskRejectMaxOpenOrders, Scope: "session open orders", Value: fixedpoint.NewFromInt(int64(n + 1)), Limit: fixedpoint.NewFromInt(int64(c))}
+		}
+	}
+
+	// notional per minute
+	if c := r.Config.MaxNotionalPerMinute; c.Sign() > 0 {
+		if v := r.notionalInLastMinute("").Add(pendingNotional).Add(notional); v.Compare(c) > 0 {
+			return signed, &RiskRejectionError{Reason: RiskRejectMaxNotionalPerMinute, Scope: "global notional per minute", Value: v, Limit: c}
+		}
+	}
+
+	if c := sessionLimit.MaxNotionalPerMinute; hasSessionLimit && c.Sign() > 0 {
+		if v := r.notionalInLastMinute(session.Name).Add(pendingNotional).Add(notional); v.Compare(c) > 0 {
+			return signed, &RiskRejectionError{Reason: RiskRejectMaxNotionalPerMinute, Scope: "session notional per minute", Value: v, Limit: c}
+		}
+	}
+
+	// exposures, only the orders that increase the exposure are rejected
+	baseExposure := exposures[session.Name][market.BaseCurrency]
+	newBaseExposure := baseExposure.apply(signed)
+
+	if limit, ok := r.Config.Assets[market.BaseCurrency]; ok {
+		current := sumExposures(exposures, "", market.BaseCurrency)
+		projected := riskExposure{
+			net:   current.net.Sub(baseExposure.net).Add(newBaseExposure.net),
+			gross: current.gross.Sub(baseExposure.gross).Add(newBaseExposure.gross),
+		}
+
+		if rejection := checkExposureLimit(limit.ExposureLimit, current, projected, "asset "+market.BaseCurrency); rejection != nil {
+			return signed, rejection
+		}
+	}
+
+	if hasSessionLimit {
+		current := sumExposures(exposures, session.Name, "")
+		projected := riskExposure{
+			net:   current.net.Sub(baseExposure.net).Add(newBaseExposure.net),
+			gross: current.gross.Sub(baseExposure.gross).Add(newBaseExposure.gross),
+		}
+
+		if rejection := checkExposureLimit(sessionLimit.ExposureLimit, current, projected, "session "+session.Name); rejection != nil {
+			return signed, rejection
+		}
+	}
+
+	if _, ok := exposures[session.Name]; !ok {
+		exposures[session.Name] = make(map[string]riskExposure)
+	}
+	exposures[session.Name][market.BaseCurrency] = newBaseExposure
+	return signed, nil
+}
+
+func checkExposureLimit(limit ExposureLimit, current, projected riskExposure, scope string) *RiskRejectionError {
+	if c := limit.MaxNetExposure; c.Sign() > 0 {
+		if v := projected.net.Abs(); v.Compare(c) > 0 && v.Compare(current.net.Abs()) > 0 {
+			return &RiskRejectionError{Reason: RiskRejectMaxNetExposure, Scope: scope + " net exposure", Value: v, Limit: c}
+		}
+	}
+
+	if c := limit.MaxGrossExposure; c.Sign() > 0 {
+		if v := projected.gross; v.Compare(c) > 0 && v.Compare(current.gross) > 0 {
+			return &RiskRejectionError{Reason: RiskRejectMaxGrossExposure, Scope: scope + " gross exposure", Value: v, Limit: c}
+		}
+	}
+
+	return nil
+}
+
+// sumExposures sums the exposures of the given session and asset, the empty filter matches all.
+func sumExposures(exposures map[string]map[string]riskExposure, sessionName, asset string) (sum riskExposure) {
+	for name, assets := range exposures {
+		if sessionName != "" && name != sessionName {
+			continue
+		}
+
+		for currency, exposure := range assets {
+			if asset != "" && currency != asset {
+				continue
+			}
+
+			sum.net = sum.net.Add(exposure.net)
+			sum.gross = sum.gross.Add(exposure.gross)
+		}
+	}
+
+	return sum
+}
+
+func (r *RiskEngine) numOfOpenOrders(sessionName string) (n int) {
+	for name, orders := range r.openOrders {
+		if sessionName == "" || name == sessionName {
+			n += len(orders)
+		}
+	}
+	return n
+}
+
+func (r *RiskEngine) notionalInLastMinute(sessionName string) (sum fixedpoint.Value) {
+	for _, n := range r.notionals {
+		if sessionName == "" || n.session == sessionName {
+			sum = sum.Add(n.value)
+		}
+	}
+	return sum
+}
+
+// assetPrice returns the price of the asset in the valuation currency
+func (r *RiskEngine) assetPrice(session *ExchangeSession, asset string) (fixedpoint.Value, bool) {
+	return valuationPrice(session, asset, r.Config.ValuationCurrency)
+}
+
+// valuationPrice returns the price of the asset in the valuation currency from the last prices of the session
+func valuationPrice(session *ExchangeSession, asset, valuationCurrency string) (fixedpoint.Value, bool) {
+	if asset == valuationCurrency {
+		return fixedpoint.One, true
+	}
+
+	if price, ok := session.LastPrice(asset + valuationCurrency); ok && price.Sign() > 0 {
+		return price, true
+	}
+
+	if price, ok := session.LastPrice(valuationCurrency + asset); ok && price.Sign() > 0 {
+		return fixedpoint.One.Div(price), true
+	}
+
+	return fixedpoint.Zero, false
+}
+
+// exposures returns the exposures of the non-valuation assets of each session in the valuation currency,
+// which include the balances and the remaining quantity of the open orders.
+// The assets without prices are ignored.
+func (r *RiskEngine) exposures() map[string]map[string]riskExposure {
+	exposures := make(map[string]map[string]riskExposure)
+	for name, session := range r.sessions {
+		assets := make(map[string]riskExposure)
+		exposures[name] = assets
+
+		for currency, balance := range session.GetAccount().Balances() {
+			if currency == r.Config.ValuationCurrency {
+				continue
+			}
+
+			price, ok := r.assetPrice(session, currency)
+			if !ok {
+				continue
+			}
+
+			assets[currency] = riskExposure{
+				net:   balance.Net().Mul(price),
+				gross: balance.Total().Add(balance.Debt()).Mul(price),
+			}
+		}
+
+		for _, order := range r.openOrders[name] {
+			market, ok := session.Market(order.Symbol)
+			if !ok || market.BaseCurrency == r.Config.ValuationCurrency {
+				continue
+			}
+
+			price, ok := r.assetPrice(session, market.BaseCurrency)
+			if !ok {
+				continue
+			}
+
+			value := order.Quantity.Sub(order.ExecutedQuantity).Mul(price)
+			if order.Side == types.SideTypeSell {
+				value = value.Neg()
+			}
+
+			assets[market.BaseCurrency] = assets[market.BaseCurrency].apply(value)
+		}
+	}
+
+	return exposures
+}
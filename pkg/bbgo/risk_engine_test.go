@@ -0,0 +1,233 @@
+package bbgo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func newRiskTestSession(t *testing.T, name string) *ExchangeSession {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	session := NewExchangeSession(name, mockEx)
+	session.markets = map[string]types.Market{
+		"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+		"ETHBTC":  {Symbol: "ETHBTC", BaseCurrency: "ETH", QuoteCurrency: "BTC"},
+	}
+	session.lastPrices["BTCUSDT"] = fixedpoint.NewFromInt(20000)
+	session.lastPrices["ETHBTC"] = fixedpoint.MustNewFromString("0.05")
+	session.Account.UpdateBalances(types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.One},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromInt(100000)},
+	})
+	return session
+}
+
+func newRiskTestOrder(symbol string, side types.SideType, quantity, price string) types.SubmitOrder {
+	return types.SubmitOrder{
+		Symbol:   symbol,
+		Side:     side,
+		Type:     types.OrderTypeLimit,
+		Quantity: fixedpoint.MustNewFromString(quantity),
+		Price:    fixedpoint.MustNewFromString(price),
+	}
+}
+
+func assertRiskRejected(t *testing.T, err error, reason RiskRejectReason) {
+	assert.True(t, errors.Is(err, ErrRiskRejected))
+
+	var rejection *RiskRejectionError
+	if assert.True(t, errors.As(err, &rejection)) {
+		assert.Equal(t, reason, rejection.Reason)
+	}
+}
+
+func TestRiskEngine_FatFinger(t *testing.T) {
+	session := newRiskTestSession(t, "binance")
+	engine := NewRiskEngine(&RiskEngineConfig{
+		MaxOrderNotional: fixedpoint.NewFromInt(10000),
+		Assets: map[string]AssetRiskLimit{
+			"ETH": {MaxOrderQuantity: fixedpoint.NewFromInt(5)},
+		},
+	})
+	session.SetRiskEngine(engine)
+
+	accepted, err := engine.CheckOrders(session, newRiskTestOrder("BTCUSDT", types.SideTypeBuy, "0.4", "20000"))
+	assert.NoError(t, err)
+	assert.Len(t, accepted, 1)
+
+	accepted, err = engine.CheckOrders(session, newRiskTestOrder("BTCUSDT", types.SideTypeBuy, "0.6", "20000"))
+	assert.Len(t, accepted, 0)
+	assertRiskRejected(t, err, RiskRejectFatFingerNotional)
+
+	// 6 ETH = 0.3 BTC = 6000 USDT
+	accepted, err = engine.CheckOrders(session, newRiskTestOrder("ETHBTC", types.SideTypeBuy, "6", "0.05"))
+	assert.Len(t, accepted, 0)
+	assertRiskRejected(t, err, RiskRejectFatFingerQuantity)
+
+	// reduce-only orders are not guarded by the fat-finger guards
+	order := newRiskTestOrder("BTCUSDT", types.SideTypeSell, "0.6", "20000")
+	order.ReduceOnly = true
+	accepted, err = engine.CheckOrders(session, order)
+	assert.NoError(t, err)
+	assert.Len(t, accepted, 1)
+}
+
+func TestRiskEngine_PriceBand(t *testing.T) {
+	session := newRiskTestSession(t, "binance")
+	engine := NewRiskEngine(&RiskEngineConfig{PriceBand: fixedpoint.MustNewFromString("0.05")})
+	session.SetRiskEngine(engine)
+
+	_, err := engine.CheckOrders(session, newRiskTestOrder("BTCUSDT", types.SideTypeBuy, "0.01", "19500"))
+	assert.NoError(t, err)
+
+	_, err = engine.CheckOrders(session, newRiskTestOrder("BTCUSDT", types.SideTypeSell, "0.01", "22000"))
+	assertRiskRejected(t, err, RiskRejectPriceBand)
+}
+
+func TestRiskEngine_MaxOpenOrders(t *testing.T) {
+	session := newRiskTestSession(t, "binance")
+	engine := NewRiskEngine(&RiskEngineConfig{
+		Sessions: map[string]SessionRiskLimit{
+			"binance": {MaxOpenOrders: 2},
+		},
+	})
+	session.SetRiskEngine(engine)
+
+	accepted, err := engine.CheckOrders(session,
+		newRiskTestOrder("BTCUSDT", types.SideTypeBuy, "0.01", "19000"),
+		newRiskTestOrder("BTCUSDT", types.SideTypeBuy, "0.01", "18000"),
+		newRiskTestOrder("BTCUSDT", types.SideTypeBuy, "0.01", "17000"))
+	assert.Len(t, accepted, 2)
+	assertRiskRejected(t, err, RiskRejectMaxOpenOrders)
+
+	engine.AddOrders(session, types.Order{
+		SubmitOrder: accepted[0],
+		OrderID:     1,
+		Status:      types.OrderStatusNew,
+	})
+
+	accepted, err = engine.CheckOrders(session, newRiskTestOrder("BTCUSDT", types.SideTypeBuy, "0.01", "19000"))
+	assert.NoError(t, err)
+	assert.Len(t, accepted, 1)
+
+	engine.AddOrders(session, types.Order{
+		SubmitOrder: accepted[0],
+		OrderID:     2,
+		Status:      types.OrderStatusNew,
+	})
+
+	_, err = engine.CheckOrders(session, newRiskTestOrder("BTCUSDT", types.SideTypeBuy, "0.01", "19000"))
+	assertRiskRejected(t, err, RiskRejectMaxOpenOrders)
+
+	// the filled order is removed from the open orders
+	engine.updateOrder(session.Name, types.Order{
+		SubmitOrder: accepted[0],
+		OrderID:     2,
+		Status:      types.OrderStatusFilled,
+	})
+
+	_, err = engine.CheckOrders(session, newRiskTestOrder("BTCUSDT", types.SideTypeBuy, "0.01", "19000"))
+	assert.NoError(t, err)
+}
+
+func TestRiskEngine_MaxNotionalPerMinute(t *testing.T) {
+	session := newRiskTestSession(t, "binance")
+	engine := NewRiskEngine(&RiskEngineConfig{MaxNotionalPerMinute: fixedpoint.NewFromInt(5000)})
+	session.SetRiskEngine(engine)
+
+	order := newRiskTestOrder("BTCUSDT", types.SideTypeBuy, "0.1", "20000")
+
+	// the accepted orders of the same batch are counted
+	accepted, err := engine.CheckOrders(session, order, order, order)
+	assertRiskRejected(t, err, RiskRejectMaxNotionalPerMinute)
+	assert.Len(t, accepted, 2)
+
+	// the orders failed to be placed are not counted
+	_, err = engine.CheckOrders(session, order)
+	assert.NoError(t, err)
+
+	engine.AddOrders(session, types.Order{SubmitOrder: order, OrderID: 1})
+	_, err = engine.CheckOrders(session, order)
+	assert.NoError(t, err)
+
+	engine.AddOrders(session, types.Order{SubmitOrder: order, OrderID: 2})
+	_, err = engine.CheckOrders(session, order)
+	assertRiskRejected(t, err, RiskRejectMaxNotionalPerMinute)
+}
+
+func TestRiskEngine_Exposure(t *testing.T) {
+	binance := newRiskTestSession(t, "binance")
+	max := newRiskTestSession(t, "max")
+
+	// 1 BTC on each session = 40000 USDT net exposure
+	engine := NewRiskEngine(&RiskEngineConfig{
+		Assets: map[string]AssetRiskLimit{
+			"BTC": {ExposureLimit: ExposureLimit{MaxNetExposure: fixedpoint.NewFromInt(45000)}},
+		},
+		Sessions: map[string]SessionRiskLimit{
+			"max": {ExposureLimit: ExposureLimit{MaxGrossExposure: fixedpoint.NewFromInt(23000)}},
+		},
+	})
+	binance.SetRiskEngine(engine)
+	max.SetRiskEngine(engine)
+
+	_, err := engine.CheckOrders(binance, newRiskTestOrder("BTCUSDT", types.SideTypeBuy, "0.2", "20000"))
+	assert.NoError(t, err)
+
+	_, err = engine.CheckOrders(binance, newRiskTestOrder("BTCUSDT", types.SideTypeBuy, "0.3", "20000"))
+	assertRiskRejected(t, err, RiskRejectMaxNetExposure)
+
+	// the orders that reduce the exposure are accepted
+	_, err = engine.CheckOrders(binance, newRiskTestOrder("BTCUSDT", types.SideTypeSell, "0.5", "20000"))
+	assert.NoError(t, err)
+
+	_, err = engine.CheckOrders(max, newRiskTestOrder("BTCUSDT", types.SideTypeBuy, "0.1", "20000"))
+	assert.NoError(t, err)
+
+	_, err = engine.CheckOrders(max, newRiskTestOrder("BTCUSDT", types.SideTypeBuy, "0.2", "20000"))
+	assertRiskRejected(t, err, RiskRejectMaxGrossExposure)
+}
+
+// reentrantNotifier runs the risk check again when it's notified
+type reentrantNotifier struct {
+	recordNotifier
+	onNotify func()
+}
+
+func (n *reentrantNotifier) Notify(obj interface{}, args ...interface{}) {
+	n.recordNotifier.Notify(obj, args...)
+	n.onNotify()
+}
+
+func TestRiskEngine_NotifyWithoutLock(t *testing.T) {
+	session := newRiskTestSession(t, "binance")
+	engine := NewRiskEngine(&RiskEngineConfig{MaxOrderNotional: fixedpoint.NewFromInt(10000)})
+	session.SetRiskEngine(engine)
+
+	order := newRiskTestOrder("BTCUSDT", types.SideTypeBuy, "0.6", "20000")
+
+	// the notifier would dead lock if it's called with the risk engine lock held
+	notifier := &reentrantNotifier{}
+	notifier.onNotify = func() {
+		_, _ = engine.checkOrders(session, []types.SubmitOrder{order})
+	}
+
+	defer func(notification *Notifiability) { Notification = notification }(Notification)
+	Notification = &Notifiability{}
+	Notification.AddNotifier(notifier)
+
+	_, err := engine.CheckOrders(session, order)
+	assertRiskRejected(t, err, RiskRejectFatFingerNotional)
+	assert.Len(t, notifier.messages, 1)
+}
@@ -0,0 +1,490 @@
+package bbgo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/wcharczuk/go-chart/v2"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/dynamic"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/style"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+const defaultReportSampleInterval = types.Duration(time.Hour)
+
+// ScheduledReportConfig is the config of a scheduled report, e.g.,
+//
+//   reports:
+//   - name: Daily Report
+//     when: [ "@daily" ]
+//     channel: "#reports"
+//     chart: true
+type ScheduledReportConfig struct {
+	Name string `json:"name" yaml:"name"`
+
+	// When is the list of the cron specs, e.g., "@daily", "0 8 * * 1" (every monday 08:00)
+	When datatype.StringSlice `json:"when" yaml:"when"`
+
+	// Channel is the notification channel of the report, the default channel is used if it's empty
+	Channel string `json:"channel,omitempty" yaml:"channel,omitempty"`
+
+	// Sessions filters the sessions, all sessions are included if it's empty
+	Sessions []string `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+
+	// Strategies filters the strategies by the signature, e.g., "bollmaker:ETHUSDT"
+	Strategies []string `json:"strategies,omitempty" yaml:"strategies,omitempty"`
+
+	// ValuationCurrency is the currency of the account net value, defaults to USDT
+	ValuationCurrency string `json:"valuationCurrency,omitempty" yaml:"valuationCurrency,omitempty"`
+
+	// Chart attaches the chart of the account net value in the report period
+	Chart bool `json:"chart,omitempty" yaml:"chart,omitempty"`
+
+	// SampleInterval is the sampling interval of the net value chart, defaults to 1h
+	SampleInterval types.Duration `json:"sampleInterval,omitempty" yaml:"sampleInterval,omitempty"`
+}
+
+// StrategyReport is the profit and trade stats of a strategy in the report
+type StrategyReport struct {
+	Session     string             `json:"session"`
+	Signature   string             `json:"signature"`
+	ProfitStats *types.ProfitStats `json:"profitStats,omitempty"`
+	TradeStats  *types.TradeStats  `json:"tradeStats,omitempty"`
+}
+
+// BalanceChange is the net balance change of a currency since the last report
+type BalanceChange struct {
+	Session  string           `json:"session"`
+	Currency string           `json:"currency"`
+	Previous fixedpoint.Value `json:"previous"`
+	Current  fixedpoint.Value `json:"current"`
+}
+
+func (c BalanceChange) Change() fixedpoint.Value {
+	return c.Current.Sub(c.Previous)
+}
+
+// ScheduledReport is the rendered digest of the PnL, the account net value and the balance changes
+type ScheduledReport struct {
+	Name              string           `json:"name"`
+	Since             time.Time        `json:"since,omitempty"`
+	Time              time.Time        `json:"time"`
+	ValuationCurrency string           `json:"valuationCurrency"`
+	NetValue          fixedpoint.Value `json:"netValue"`
+
+	// PreviousNetValue is the net value of the last report, zero if this is the first report
+	PreviousNetValue fixedpoint.Value `json:"previousNetValue"`
+
+	Strategies     []StrategyReport `json:"strategies,omitempty"`
+	BalanceChanges []BalanceChange  `json:"balanceChanges,omitempty"`
+}
+
+func (r *ScheduledReport) title() string {
+	if r.Since.IsZero() {
+		return fmt.Sprintf("%s (%s)", r.Name, r.Time.Format("2006-01-02 15:04"))
+	}
+
+	return fmt.Sprintf("%s (%s ~ %s)", r.Name, r.Since.Format("2006-01-02 15:04"), r.Time.Format("2006-01-02 15:04"))
+}
+
+func (r *ScheduledReport) netValueString() string {
+	o := fmt.Sprintf("%s %s", r.NetValue.String(), r.ValuationCurrency)
+	if r.PreviousNetValue.IsZero() {
+		return o
+	}
+
+	change := r.NetValue.Sub(r.PreviousNetValue)
+	return o + fmt.Sprintf(" (%s, %s)", style.PnLSignString(change), change.Div(r.PreviousNetValue).FormatPercentage(2))
+}
+
+func (s StrategyReport) String() string {
+	var parts []string
+	if p := s.ProfitStats; p != nil {
+		parts = append(parts,
+			fmt.Sprintf("today PnL %s %s", style.PnLSignString(p.TodayPnL), p.QuoteCurrency),
+			fmt.Sprintf("accumulated PnL %s %s", style.PnLSignString(p.AccumulatedPnL), p.QuoteCurrency),
+			fmt.Sprintf("volume %s %s", p.AccumulatedVolume.String(), p.BaseCurrency),
+		)
+	}
+
+	if t := s.TradeStats; t != nil {
+		parts = append(parts,
+			fmt.Sprintf("%d wins / %d losses", t.NumOfProfitTrade, t.NumOfLossTrade),
+			fmt.Sprintf("winning ratio %s", t.WinningRatio.String()),
+			fmt.Sprintf("profit factor %s", t.ProfitFactor.String()),
+		)
+	}
+
+	return fmt.Sprintf("%s %s: %s", s.Session, s.Signature, strings.Join(parts, ", "))
+}
+
+func (c BalanceChange) String() string {
+	return fmt.Sprintf("%s %s: %s -> %s (%s)", c.Session, c.Currency, c.Previous.String(), c.Current.String(), style.PnLSignString(c.Change()))
+}
+
+func (r *ScheduledReport) PlainText() string {
+	var sb strings.Builder
+	sb.WriteString(r.title() + "\n")
+	sb.WriteString("Net Value: " + r.netValueString() + "\n")
+
+	if len(r.Strategies) > 0 {
+		sb.WriteString("Strategies:\n")
+		for _, s := range r.Strategies {
+			sb.WriteString("- " + s.String() + "\n")
+		}
+	}
+
+	if len(r.BalanceChanges) > 0 {
+		sb.WriteString("Balance Changes:\n")
+		for _, c := range r.BalanceChanges {
+			sb.WriteString("- " + c.String() + "\n")
+		}
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func (r *ScheduledReport) SlackAttachment() slack.Attachment {
+	var fields []slack.AttachmentField
+	for _, s := range r.Strategies {
+		fields = append(fields, slack.AttachmentField{
+			Title: s.Session + " " + s.Signature,
+			Value: strings.TrimPrefix(s.String(), s.Session+" "+s.Signature+": "),
+		})
+	}
+
+	for _, c := range r.BalanceChanges {
+		fields = append(fields, slack.AttachmentField{
+			Title: c.Session + " " + c.Currency,
+			Value: fmt.Sprintf("%s -> %s (%s)", c.Previous.String(), c.Current.String(), style.PnLSignString(c.Change())),
+			Short: true,
+		})
+	}
+
+	return slack.Attachment{
+		Color:  style.PnLColor(r.NetValue.Sub(r.PreviousNetValue)),
+		Title:  r.title(),
+		Text:   "Net Value: " + r.netValueString(),
+		Fields: fields,
+	}
+}
+
+type netValueSample struct {
+	Time     time.Time        `json:"time"`
+	NetValue fixedpoint.Value `json:"netValue"`
+}
+
+// reportSnapshot is the state of the last report, it's saved in the persistence service
+// so that the balance changes can be calculated after restarting.
+type reportSnapshot struct {
+	Time     time.Time                              `json:"time"`
+	NetValue fixedpoint.Value                       `json:"netValue"`
+	Balances map[string]map[string]fixedpoint.Value `json:"balances"`
+
+	// Samples are the net value samples since the last report, they are used for rendering the chart
+	Samples []netValueSample `json:"samples,omitempty"`
+}
+
+// ScheduledReporter composes the scheduled report of the strategies and the sessions,
+// and sends it to the notification channel.
+type ScheduledReporter struct {
+	Config ScheduledReportConfig
+
+	sessions   map[string]*ExchangeSession
+	strategies map[string][]SingleExchangeStrategy
+	notifier   Notifier
+	store      service.Store
+	cron       *cron.Cron
+
+	mu       sync.Mutex
+	snapshot reportSnapshot
+}
+
+// NewScheduledReporter creates the reporter, the strategies are indexed by the session name.
+func NewScheduledReporter(config ScheduledReportConfig, sessions map[string]*ExchangeSession, strategies map[string][]SingleExchangeStrategy, notifier Notifier) *ScheduledReporter {
+	if config.ValuationCurrency == "" {
+		config.ValuationCurrency = "USDT"
+	}
+
+	if config.SampleInterval == 0 {
+		config.SampleInterval = defaultReportSampleInterval
+	}
+
+	return &ScheduledReporter{
+		Config:     config,
+		sessions:   sessions,
+		strategies: strategies,
+		notifier:   notifier,
+		cron:       cron.New(),
+	}
+}
+
+// SetStore sets the store of the last report snapshot
+func (r *ScheduledReporter) SetStore(store service.Store) {
+	r.store = store
+
+	var snapshot reportSnapshot
+	if err := store.Load(&snapshot); err != nil {
+		if err != service.ErrPersistenceNotExists {
+			log.WithError(err).Errorf("[report] can not load the snapshot of %s", r.Config.Name)
+		}
+		return
+	}
+
+	r.mu.Lock()
+	r.snapshot = snapshot
+	r.mu.Unlock()
+}
+
+// Start adds the cron jobs of the report and starts the scheduler until the context is done
+func (r *ScheduledReporter) Start(ctx context.Context) error {
+	if len(r.Config.When) == 0 {
+		return fmt.Errorf("report %s: schedule (when) is not defined", r.Config.Name)
+	}
+
+	for _, spec := range r.Config.When {
+		if _, err := r.cron.AddFunc(spec, func() {
+			if _, err := r.Report(ctx, time.Now()); err != nil {
+				log.WithError(err).Errorf("[report] can not send the report %s", r.Config.Name)
+			}
+		}); err != nil {
+			return fmt.Errorf("report %s: invalid schedule %q: %w", r.Config.Name, spec, err)
+		}
+	}
+
+	if r.Config.Chart {
+		spec := fmt.Sprintf("@every %s", r.Config.SampleInterval.Duration())
+		if _, err := r.cron.AddFunc(spec, func() {
+			if err := r.Sample(ctx, time.Now()); err != nil {
+				log.WithError(err).Errorf("[report] can not sample the net value of %s", r.Config.Name)
+			}
+		}); err != nil {
+			return err
+		}
+	}
+
+	r.cron.Start()
+	go func() {
+		<-ctx.Done()
+		r.cron.Stop()
+	}()
+	return nil
+}
+
+func (r *ScheduledReporter) selectedSessions() map[string]*ExchangeSession {
+	if len(r.Config.Sessions) == 0 {
+		return r.sessions
+	}
+
+	sessions := make(map[string]*ExchangeSession)
+	for _, name := range r.Config.Sessions {
+		if session, ok := r.sessions[name]; ok {
+			sessions[name] = session
+		}
+	}
+	return sessions
+}
+
+func (r *ScheduledReporter) netValue(ctx context.Context) (fixedpoint.Value, error) {
+	netValue := fixedpoint.Zero
+	for _, session := range r.selectedSessions() {
+		calculator := NewAccountValueCalculator(session, r.Config.ValuationCurrency)
+		value, err := calculator.NetValue(ctx)
+		if err != nil {
+			return netValue, err
+		}
+
+		netValue = netValue.Add(value)
+	}
+
+	return netValue, nil
+}
+
+// Sample records the net value for the chart
+func (r *ScheduledReporter) Sample(ctx context.Context, now time.Time) error {
+	netValue, err := r.netValue(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.snapshot.Samples = append(r.snapshot.Samples, netValueSample{Time: now, NetValue: netValue})
+	r.mu.Unlock()
+
+	r.save()
+	return nil
+}
+
+func (r *ScheduledReporter) strategyReports() (reports []StrategyReport) {
+	for sessionName := range r.selectedSessions() {
+		for _, strategy := range r.strategies[sessionName] {
+			signature, err := getStrategySignature(strategy)
+			if err != nil {
+				continue
+			}
+
+			if len(r.Config.Strategies) > 0 && !util.StringSliceContains(r.Config.Strategies, signature) {
+				continue
+			}
+
+			report := StrategyReport{Session: sessionName, Signature: signature}
+			_ = dynamic.IterateFields(strategy, func(ft reflect.StructField, fv reflect.Value) error {
+				if fv.Kind() != reflect.Ptr || fv.IsNil() {
+					return nil
+				}
+
+				switch v := fv.Interface().(type) {
+				case *types.ProfitStats:
+					if report.ProfitStats == nil {
+						report.ProfitStats = v
+					}
+				case *types.TradeStats:
+					if report.TradeStats == nil {
+						report.TradeStats = v
+					}
+				}
+				return nil
+			})
+
+			if report.ProfitStats == nil && report.TradeStats == nil {
+				continue
+			}
+
+			reports = append(reports, report)
+		}
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Session != reports[j].Session {
+			return reports[i].Session < reports[j].Session
+		}
+		return reports[i].Signature < reports[j].Signature
+	})
+	return reports
+}
+
+func (r *ScheduledReporter) balances() map[string]map[string]fixedpoint.Value {
+	balances := make(map[string]map[string]fixedpoint.Value)
+	for name, session := range r.selectedSessions() {
+		currencies := make(map[string]fixedpoint.Value)
+		for currency, balance := range session.GetAccount().Balances() {
+			currencies[currency] = balance.Net()
+		}
+		balances[name] = currencies
+	}
+	return balances
+}
+
+func balanceChanges(previous, current map[string]map[string]fixedpoint.Value) (changes []BalanceChange) {
+	for session, currencies := range current {
+		for currency, balance := range currencies {
+			prev := previous[session][currency]
+			if prev.Compare(balance) != 0 {
+				changes = append(changes, BalanceChange{Session: session, Currency: currency, Previous: prev, Current: balance})
+			}
+		}
+	}
+
+	for session, currencies := range previous {
+		for currency, prev := range currencies {
+			if _, ok := current[session][currency]; !ok && !prev.IsZero() {
+				changes = append(changes, BalanceChange{Session: session, Currency: currency, Previous: prev, Current: fixedpoint.Zero})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Session != changes[j].Session {
+			return changes[i].Session < changes[j].Session
+		}
+		return changes[i].Currency < changes[j].Currency
+	})
+	return changes
+}
+
+// Report composes the report, sends it to the channel, and saves the snapshot for the next report
+func (r *ScheduledReporter) Report(ctx context.Context, now time.Time) (*ScheduledReport, error) {
+	netValue, err := r.netValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := r.balances()
+
+	r.mu.Lock()
+	last := r.snapshot
+	r.snapshot = reportSnapshot{Time: now, NetValue: netValue, Balances: balances}
+	r.mu.Unlock()
+
+	report := &ScheduledReport{
+		Name:              r.Config.Name,
+		Since:             last.Time,
+		Time:              now,
+		ValuationCurrency: r.Config.ValuationCurrency,
+		NetValue:          netValue,
+		PreviousNetValue:  last.NetValue,
+		Strategies:        r.strategyReports(),
+	}
+
+	if last.Balances != nil {
+		report.BalanceChanges = balanceChanges(last.Balances, balances)
+	}
+
+	r.notifier.NotifyTo(r.Config.Channel, report)
+
+	if r.Config.Chart {
+		samples := append(last.Samples, netValueSample{Time: now, NetValue: netValue})
+		if len(samples) > 1 {
+			var buffer bytes.Buffer
+			if err := renderNetValueChart(report.title(), r.Config.ValuationCurrency, samples, &buffer); err != nil {
+				log.WithError(err).Errorf("[report] can not render the net value chart")
+			} else {
+				r.notifier.SendPhotoTo(r.Config.Channel, &buffer)
+			}
+		}
+	}
+
+	r.save()
+	return report, nil
+}
+
+func (r *ScheduledReporter) save() {
+	if r.store == nil {
+		return
+	}
+
+	r.mu.Lock()
+	snapshot := r.snapshot
+	r.mu.Unlock()
+
+	if err := r.store.Save(snapshot); err != nil {
+		log.WithError(err).Errorf("[report] can not save the snapshot of %s", r.Config.Name)
+	}
+}
+
+func renderNetValueChart(title, currency string, samples []netValueSample, buffer *bytes.Buffer) error {
+	series := chart.TimeSeries{Name: "net value (" + currency + ")"}
+	for _, sample := range samples {
+		series.XValues = append(series.XValues, sample.Time)
+		series.YValues = append(series.YValues, sample.NetValue.Float64())
+	}
+
+	canvas := types.NewCanvas(title)
+	canvas.XAxis.ValueFormatter = chart.TimeHourValueFormatter
+	canvas.Series = append(canvas.Series, series)
+	return canvas.Render(chart.PNG, buffer)
+}
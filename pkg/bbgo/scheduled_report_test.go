@@ -0,0 +1,126 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+type reportTestStrategy struct {
+	Symbol      string
+	ProfitStats *types.ProfitStats
+	TradeStats  *types.TradeStats
+}
+
+func (s *reportTestStrategy) ID() string {
+	return "test"
+}
+
+func (s *reportTestStrategy) InstanceID() string {
+	return "test:" + s.Symbol
+}
+
+func (s *reportTestStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	return nil
+}
+
+func TestScheduledReporter_Report(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+	mockEx.EXPECT().QueryTickers(gomock.Any(), gomock.Any()).Return(map[string]types.Ticker{
+		"BTCUSDT": {Last: fixedpoint.NewFromInt(20000)},
+	}, nil).AnyTimes()
+
+	session := NewExchangeSession("binance", mockEx)
+	session.Account.UpdateBalances(types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.One},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromInt(10000)},
+	})
+
+	market := types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}
+	strategy := &reportTestStrategy{
+		Symbol:      "BTCUSDT",
+		ProfitStats: types.NewProfitStats(market),
+		TradeStats:  types.NewTradeStats("BTCUSDT"),
+	}
+	strategy.ProfitStats.AddProfit(types.Profit{Profit: fixedpoint.NewFromInt(100), QuoteCurrency: "USDT"})
+	strategy.TradeStats.Add(&types.Profit{Symbol: "BTCUSDT", Profit: fixedpoint.NewFromInt(100), OrderID: 1})
+
+	notifier := &recordNotifier{}
+	store := service.NewMemoryService().NewStore("bbgo", "report", "daily")
+	reporter := NewScheduledReporter(ScheduledReportConfig{
+		Name:    "Daily Report",
+		When:    []string{"@daily"},
+		Channel: "#reports",
+		Chart:   true,
+	}, map[string]*ExchangeSession{"binance": session}, map[string][]SingleExchangeStrategy{
+		"binance": {strategy, &reportTestStrategy{Symbol: "ETHUSDT"}},
+	}, notifier)
+	reporter.SetStore(store)
+
+	ctx := context.Background()
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.Local)
+	report, err := reporter.Report(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, "30000", report.NetValue.String())
+	assert.Empty(t, report.BalanceChanges)
+	if assert.Len(t, report.Strategies, 1) {
+		assert.Equal(t, "test:BTCUSDT", report.Strategies[0].Signature)
+	}
+
+	// only one sample, no chart
+	assert.Empty(t, notifier.photos)
+
+	session.Account.AddBalance("BTC", fixedpoint.NewFromFloat(0.5))
+	require.NoError(t, reporter.Sample(ctx, now.Add(12*time.Hour)))
+
+	// the snapshot is restored from the store
+	restored := NewScheduledReporter(reporter.Config, reporter.sessions, reporter.strategies, notifier)
+	restored.SetStore(store)
+
+	report, err = restored.Report(ctx, now.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "40000", report.NetValue.String())
+	assert.Equal(t, []BalanceChange{{
+		Session: "binance", Currency: "BTC", Previous: fixedpoint.One, Current: fixedpoint.NewFromFloat(1.5),
+	}}, report.BalanceChanges)
+
+	assert.Equal(t, "Daily Report (2022-06-01 00:00 ~ 2022-06-02 00:00)\n"+
+		"Net Value: 40000 USDT (+10000, 33.33%)\n"+
+		"Strategies:\n"+
+		"- binance test:BTCUSDT: today PnL +100 USDT, accumulated PnL +100 USDT, volume 0 BTC, 1 wins / 0 losses, winning ratio 1, profit factor inf\n"+
+		"Balance Changes:\n"+
+		"- binance BTC: 1 -> 1.5 (+0.5)", report.PlainText())
+
+	assert.Equal(t, []string{"#reports", "#reports"}, notifier.channels)
+	if assert.Len(t, notifier.photos, 1) {
+		assert.NotZero(t, notifier.photos[0].Len())
+	}
+}
+
+func TestScheduledReporter_Start(t *testing.T) {
+	reporter := NewScheduledReporter(ScheduledReportConfig{Name: "weekly"}, nil, nil, &recordNotifier{})
+	assert.Error(t, reporter.Start(context.Background()))
+
+	reporter = NewScheduledReporter(ScheduledReportConfig{Name: "weekly", When: []string{"every monday"}}, nil, nil, &recordNotifier{})
+	assert.Error(t, reporter.Start(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reporter = NewScheduledReporter(ScheduledReportConfig{Name: "weekly", When: []string{"0 8 * * 1"}, Chart: true}, nil, nil, &recordNotifier{})
+	assert.NoError(t, reporter.Start(ctx))
+	assert.Len(t, reporter.cron.Entries(), 2)
+}
@@ -1,23 +1,28 @@
 package bbgo
 
 import (
-	"time"
-
 	"github.com/c9s/bbgo/pkg/types"
 )
 
+// SerialMarketDataStore aggregates the 1m klines into the subscribed intervals,
+// the klines closed at the same time are added in the order of the subscription.
+// The first kline of each interval is added even if it's partial, as the store always did.
 type SerialMarketDataStore struct {
 	*MarketDataStore
-	KLines       map[types.Interval]*types.KLine
 	Subscription []types.Interval
+
+	aggregator *KLineAggregator
 }
 
 func NewSerialMarketDataStore(symbol string) *SerialMarketDataStore {
-	return &SerialMarketDataStore{
+	store := &SerialMarketDataStore{
 		MarketDataStore: NewMarketDataStore(symbol),
-		KLines:          make(map[types.Interval]*types.KLine),
 		Subscription:    []types.Interval{},
+		aggregator:      NewKLineAggregator(symbol, types.Interval1m),
 	}
+	store.aggregator.EmitPartial = true
+	store.aggregator.OnKLineClosed(store.MarketDataStore.AddKLine)
+	return store
 }
 
 func (store *SerialMarketDataStore) Subscribe(interval types.Interval) {
@@ -28,6 +33,7 @@ func (store *SerialMarketDataStore) Subscribe(interval types.Interval) {
 		}
 	}
 	store.Subscription = append(store.Subscription, interval)
+	store.aggregator.Subscribe(interval)
 }
 
 func (store *SerialMarketDataStore) BindStream(stream types.Stream) {
@@ -39,31 +45,6 @@ func (store *SerialMarketDataStore) handleKLineClosed(kline types.KLine) {
 }
 
 func (store *SerialMarketDataStore) AddKLine(kline types.KLine) {
-	if kline.Symbol != store.Symbol {
-		return
-	}
 	// only consumes kline1m
-	if kline.Interval != types.Interval1m {
-		return
-	}
-	// endtime in minutes
-	timestamp := kline.StartTime.Time().Add(time.Minute)
-	for _, val := range store.Subscription {
-		k, ok := store.KLines[val]
-		if !ok {
-			k = &types.KLine{}
-			k.Set(&kline)
-			k.Interval = val
-			k.Closed = false
-			store.KLines[val] = k
-		} else {
-			k.Merge(&kline)
-			k.Closed = false
-		}
-		if timestamp.Truncate(val.Duration()) == timestamp {
-			k.Closed = true
-			store.MarketDataStore.AddKLine(*k)
-			delete(store.KLines, val)
-		}
-	}
+	store.aggregator.AddKLine(kline)
 }
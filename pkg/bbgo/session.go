@@ -56,6 +56,12 @@ type ExchangeSession struct {
 	IsolatedFutures       bool   `json:"isolatedFutures,omitempty" yaml:"isolatedFutures,omitempty"`
 	IsolatedFuturesSymbol string `json:"isolatedFuturesSymbol,omitempty" yaml:"isolatedFuturesSymbol,omitempty"`
 
+	// MarginHealth configures the margin health monitor of the cross margin session
+	MarginHealth *MarginHealthConfig `json:"marginHealth,omitempty" yaml:"marginHealth,omitempty"`
+
+	// Reconcile configures the position reconciliation of the order executors on the session
+	Reconcile *ReconcileConfig `json:"reconcile,omitempty" yaml:"reconcile,omitempty"`
+
 	// ---------------------------
 	// Runtime fields
 	// ---------------------------
@@ -109,6 +115,31 @@ type ExchangeSession struct {
 	usedSymbols        map[string]struct{}
 	initializedSymbols map[string]struct{}
 
+	// kLineAggregators builds the kline intervals that are not supported by the exchange
+	// map: symbol -> base interval -> aggregator
+	kLineAggregators map[string]map[types.Interval]*KLineAggregator
+
+	// barBuilders builds the bars of the bar channel subscriptions from the market trades
+	// map: symbol -> bar interval -> builder
+	barBuilders map[string]map[types.Interval]*BarBuilder
+
+	// riskEngine runs the pre-trade checks before the orders are submitted by the order executors
+	riskEngine *RiskEngine
+
+	// circuitBreaker blocks the orders that increase the positions of the tripped strategies
+	circuitBreaker *CircuitBreaker
+
+	// marginHealthMonitor deleverages the cross margin account when the margin level is critical
+	marginHealthMonitor *MarginHealthMonitor
+
+	// orderExecutors are the general order executors bound on the session, which are reconciled
+	// when the reconcile config is set
+	orderExecutors []*GeneralOrderExecutor
+
+	// warmUpWindows stores the kline history limits declared by the strategies
+	// map: symbol -> interval -> window
+	warmUpWindows map[string]map[types.Interval]int
+
 	logger *log.Entry
 }
 
@@ -136,6 +167,9 @@ func NewExchangeSession(name string, exchange types.Exchange) *ExchangeSession {
 		orderStores:           make(map[string]*OrderStore),
 		usedSymbols:           make(map[string]struct{}),
 		initializedSymbols:    make(map[string]struct{}),
+		warmUpWindows:         make(map[string]map[types.Interval]int),
+		kLineAggregators:      make(map[string]map[types.Interval]*KLineAggregator),
+		barBuilders:           make(map[string]map[types.Interval]*BarBuilder),
 		logger:                log.WithField("session", name),
 	}
 
@@ -388,7 +422,7 @@ func (session *ExchangeSession) initSymbol(ctx context.Context, environ *Environ
 	marketDataStore := session.marketDataStores[symbol]
 
 	if _, ok := session.standardIndicatorSets[symbol]; !ok {
-		standardIndicatorSet := NewStandardIndicatorSet(symbol, session.MarketDataStream, marketDataStore)
+		standardIndicatorSet := NewStandardIndicatorSet(symbol, marketDataStore)
 		session.standardIndicatorSets[symbol] = standardIndicatorSet
 	}
 
@@ -417,40 +451,71 @@ func (session *ExchangeSession) initSymbol(ctx context.Context, environ *Environ
 		}
 	}
 
+	// load the kline history of the intervals declared for warming up
+	for interval := range session.warmUpWindows[symbol] {
+		// the bars can not be loaded from the kline history
+		if types.IsBarInterval(interval) {
+			continue
+		}
+
+		if baseInterval, ok := session.aggregationBaseInterval(interval); ok {
+			session.kLineAggregator(symbol, baseInterval).Subscribe(interval)
+			if baseInterval == "" {
+				log.Warnf("%s %s is aggregated from the market trades, the kline history can not be loaded for warming up", symbol, interval)
+				continue
+			}
+
+			interval = baseInterval
+		}
+
+		klineSubscriptions[interval] = struct{}{}
+	}
+
+	// bind the aggregators before loading the history, so that the aggregated klines of the history are stored
+	for _, aggregator := range session.kLineAggregators[symbol] {
+		session.bindKLineAggregator(aggregator, marketDataStore)
+	}
+
 	for interval := range klineSubscriptions {
 		// avoid querying the last unclosed kline
 		endTime := environ.startTime
-		var i int64
-		for i = 0; i < KLinePreloadLimit; i += 1000 {
-			var duration time.Duration = time.Duration(-i * int64(interval.Duration()))
-			e := endTime.Add(duration)
-
-			kLines, err := session.Exchange.QueryKLines(ctx, symbol, interval, types.KLineQueryOptions{
-				EndTime: &e,
-				Limit:   1000, // indicators need at least 100
-			})
-			if err != nil {
-				return err
-			}
+		limit := session.kLineHistoryLimit(symbol, interval)
 
-			if len(kLines) == 0 {
-				log.Warnf("no kline data for %s %s (end time <= %s)", symbol, interval, e)
-				continue
-			}
+		kLines, err := session.queryKLineHistory(ctx, environ, symbol, interval, endTime, limit)
+		if err != nil {
+			return err
+		}
 
-			// update last prices by the given kline
-			lastKLine := kLines[len(kLines)-1]
-			if interval == types.Interval1m {
-				session.lastPrices[symbol] = lastKLine.Close
-			}
+		if len(kLines) == 0 {
+			log.Warnf("no kline data for %s %s (end time <= %s)", symbol, interval, endTime)
+			continue
+		}
+
+		if _, declared := session.warmUpWindows[symbol][interval]; declared && len(kLines) < limit {
+			log.Warnf("%s %s: only %d klines are loaded, %d klines are required for warming up", symbol, interval, len(kLines), limit)
+		}
 
-			for _, k := range kLines {
-				// let market data store trigger the update, so that the indicator could be updated too.
-				marketDataStore.AddKLine(k)
+		// update last prices by the given kline
+		lastKLine := kLines[len(kLines)-1]
+		if interval == types.Interval1m {
+			session.lastPrices[symbol] = lastKLine.Close
+		}
+
+		aggregator, hasAggregator := session.kLineAggregators[symbol][interval]
+		for _, k := range kLines {
+			// let market data store trigger the update, so that the indicator could be updated too.
+			marketDataStore.AddKLine(k)
+
+			if hasAggregator {
+				aggregator.AddKLine(k)
 			}
 		}
 	}
 
+	for _, builder := range session.barBuilders[symbol] {
+		session.bindBarBuilder(builder, marketDataStore)
+	}
+
 	log.Infof("%s last price: %v", symbol, session.lastPrices[symbol])
 
 	session.initializedSymbols[symbol] = struct{}{}
@@ -464,7 +529,7 @@ func (session *ExchangeSession) StandardIndicatorSet(symbol string) *StandardInd
 	}
 
 	store, _ := session.MarketDataStore(symbol)
-	set = NewStandardIndicatorSet(symbol, session.MarketDataStream, store)
+	set = NewStandardIndicatorSet(symbol, store)
 	session.standardIndicatorSets[symbol] = set
 	return set
 }
@@ -576,6 +641,43 @@ func (session *ExchangeSession) Subscribe(channel types.Channel, symbol string,
 		panic("subscription interval for kline can not be empty")
 	}
 
+	// the kline subscription of the bar interval, e.g., "volume:100", is the bar subscription
+	if channel == types.KLineChannel && types.IsBarInterval(options.Interval) {
+		barType, barSize, ok := types.ParseBarInterval(options.Interval)
+		if !ok {
+			panic(fmt.Sprintf("invalid bar interval: %q", options.Interval))
+		}
+
+		channel = types.BarChannel
+		options = types.SubscribeOptions{BarType: barType, BarSize: barSize}
+	}
+
+	// subscribe the base interval instead if the exchange does not support the given interval,
+	// the klines of the given interval will be aggregated from the klines of the base interval,
+	// or from the market trades if none of the supported intervals can be the base interval.
+	if channel == types.KLineChannel {
+		if baseInterval, ok := session.aggregationBaseInterval(options.Interval); ok {
+			session.kLineAggregator(symbol, baseInterval).Subscribe(options.Interval)
+			if baseInterval == "" {
+				channel = types.MarketTradeChannel
+				options = types.SubscribeOptions{}
+			} else {
+				options.Interval = baseInterval
+			}
+		}
+	}
+
+	// the bars are built from the market trades, so we subscribe the market trades instead
+	if channel == types.BarChannel {
+		if !options.BarType.IsSupported() || options.BarSize.Sign() <= 0 {
+			panic(fmt.Sprintf("invalid bar subscription: type %q size %v", options.BarType, options.BarSize))
+		}
+
+		session.barBuilder(symbol, options.BarType, options.BarSize)
+		channel = types.MarketTradeChannel
+		options = types.SubscribeOptions{}
+	}
+
 	sub := types.Subscription{
 		Channel: channel,
 		Symbol:  symbol,
@@ -588,6 +690,188 @@ func (session *ExchangeSession) Subscribe(channel types.Channel, symbol string,
 	return session
 }
 
+// aggregationBaseInterval returns the largest interval supported by the exchange that the given interval can be aggregated from.
+// ok is false when the exchange supports the given interval.
+// The base interval is empty when none of the supported intervals fits, the klines are aggregated from the market trades then.
+func (session *ExchangeSession) aggregationBaseInterval(interval types.Interval) (baseInterval types.Interval, ok bool) {
+	provider, isProvider := session.Exchange.(types.CustomIntervalProvider)
+	if !isProvider || provider.IsSupportedInterval(interval) || types.IsBarInterval(interval) {
+		return "", false
+	}
+
+	for supportedInterval := range provider.SupportedInterval() {
+		d := supportedInterval.Duration()
+		if d < interval.Duration() && interval.Duration()%d == 0 && (baseInterval == "" || d > baseInterval.Duration()) {
+			baseInterval = supportedInterval
+		}
+	}
+
+	return baseInterval, true
+}
+
+func (session *ExchangeSession) kLineAggregator(symbol string, baseInterval types.Interval) *KLineAggregator {
+	aggregators, ok := session.kLineAggregators[symbol]
+	if !ok {
+		aggregators = make(map[types.Interval]*KLineAggregator)
+		session.kLineAggregators[symbol] = aggregators
+	}
+
+	aggregator, ok := aggregators[baseInterval]
+	if !ok {
+		aggregator = NewKLineAggregator(symbol, baseInterval)
+		aggregators[baseInterval] = aggregator
+	}
+
+	return aggregator
+}
+
+// bindKLineAggregator binds the aggregator on the market data stream before the kline history is loaded.
+// The aggregated klines of the history are added to the market data store directly,
+// and the aggregated klines of the live stream are emitted from the market data stream,
+// so that the market data store and the other stream listeners receive them as the exchange supported intervals.
+func (session *ExchangeSession) bindKLineAggregator(aggregator *KLineAggregator, store *MarketDataStore) {
+	aggregator.OnKLineClosed(func(k types.KLine) {
+		if _, ok := session.initializedSymbols[k.Symbol]; !ok {
+			store.AddKLine(k)
+			return
+		}
+
+		if emitter, ok := session.MarketDataStream.(types.StandardStreamEmitter); ok {
+			emitter.EmitKLineClosed(k)
+		} else {
+			store.AddKLine(k)
+		}
+	})
+
+	if aggregator.BaseInterval == "" {
+		aggregator.BindMarketTradeStream(session.MarketDataStream)
+	} else {
+		aggregator.BindStream(session.MarketDataStream)
+	}
+}
+
+func (session *ExchangeSession) barBuilder(symbol string, barType types.BarType, size fixedpoint.Value) *BarBuilder {
+	builders, ok := session.barBuilders[symbol]
+	if !ok {
+		builders = make(map[types.Interval]*BarBuilder)
+		session.barBuilders[symbol] = builders
+	}
+
+	interval := barType.Interval(size)
+	builder, ok := builders[interval]
+	if !ok {
+		builder = NewBarBuilder(symbol, barType, size)
+		builders[interval] = builder
+	}
+
+	return builder
+}
+
+// bindBarBuilder emits the bars as the closed klines of the market data stream,
+// so that the market data store, the indicators and the kline callbacks of the strategies receive them.
+func (session *ExchangeSession) bindBarBuilder(builder *BarBuilder, store *MarketDataStore) {
+	builder.OnBarClosed(func(k types.KLine) {
+		if emitter, ok := session.MarketDataStream.(types.StandardStreamEmitter); ok {
+			emitter.EmitKLineClosed(k)
+		} else {
+			store.AddKLine(k)
+		}
+	})
+
+	builder.BindStream(session.MarketDataStream)
+}
+
+// SetRiskEngine sets the risk engine that is consulted before the orders are submitted
+func (session *ExchangeSession) SetRiskEngine(engine *RiskEngine) {
+	session.riskEngine = engine
+	engine.BindSession(session)
+}
+
+func (session *ExchangeSession) RiskEngine() *RiskEngine {
+	return session.riskEngine
+}
+
+// SetCircuitBreaker sets the circuit breaker that is consulted by the general order executors
+func (session *ExchangeSession) SetCircuitBreaker(breaker *CircuitBreaker) {
+	session.circuitBreaker = breaker
+}
+
+func (session *ExchangeSession) CircuitBreaker() *CircuitBreaker {
+	return session.circuitBreaker
+}
+
+func (session *ExchangeSession) MarginHealthMonitor() *MarginHealthMonitor {
+	return session.marginHealthMonitor
+}
+
+// bindMarginHealthMonitor creates the margin health monitor from the session config
+func (session *ExchangeSession) bindMarginHealthMonitor(ctx context.Context) error {
+	if session.MarginHealth == nil || session.marginHealthMonitor != nil {
+		return nil
+	}
+
+	if !session.Margin || session.IsolatedMargin {
+		return fmt.Errorf("session %s: marginHealth requires the cross margin mode", session.Name)
+	}
+
+	monitor, err := NewMarginHealthMonitor(session, session.MarginHealth)
+	if err != nil {
+		return err
+	}
+
+	monitor.Bind(ctx)
+	session.marginHealthMonitor = monitor
+	return nil
+}
+
+func (session *ExchangeSession) addOrderExecutor(executor *GeneralOrderExecutor) {
+	for _, e := range session.orderExecutors {
+		if e == executor {
+			return
+		}
+	}
+
+	session.orderExecutors = append(session.orderExecutors, executor)
+}
+
+// bindReconcilers binds the reconcilers of the general order executors on the session if the reconcile config is set,
+// the executors that are already reconciled by the strategies are skipped.
+func (session *ExchangeSession) bindReconcilers(ctx context.Context) {
+	if session.Reconcile == nil {
+		return
+	}
+
+	if len(session.orderExecutors) == 0 {
+		log.Warnf("session %s: reconcile is configured but no strategy binds a general order executor on the session", session.Name)
+		return
+	}
+
+	for _, executor := range session.orderExecutors {
+		if _, err := executor.BindReconciler(ctx); err != nil {
+			log.WithError(err).Errorf("session %s: %s position reconciliation error", session.Name, executor.strategyInstanceID)
+		}
+	}
+}
+
+// checkRisk runs the pre-trade checks of the risk engine,
+// it returns the accepted orders and the rejections if the risk engine is set.
+func (session *ExchangeSession) checkRisk(orders []types.SubmitOrder) ([]types.SubmitOrder, error) {
+	if session.riskEngine == nil {
+		return orders, nil
+	}
+
+	return session.riskEngine.CheckOrders(session, orders...)
+}
+
+// addRiskOrders adds the created orders to the open orders of the risk engine
+func (session *ExchangeSession) addRiskOrders(orders types.OrderSlice) {
+	if session.riskEngine == nil {
+		return
+	}
+
+	session.riskEngine.AddOrders(session, orders...)
+}
+
 func (session *ExchangeSession) FormatOrder(order types.SubmitOrder) (types.SubmitOrder, error) {
 	market, ok := session.Market(order.Symbol)
 	if !ok {
@@ -862,7 +1146,7 @@ func (session *ExchangeSession) bindUserDataStreamMetrics(stream types.Stream) {
 
 func (session *ExchangeSession) bindConnectionStatusNotification(stream types.Stream, streamName string) {
 	stream.OnDisconnect(func() {
-		Notify("session %s %s stream disconnected", session.Name, streamName)
+		NotifyWithSeverity(SeverityWarn, "session %s %s stream disconnected", session.Name, streamName)
 	})
 	stream.OnConnect(func() {
 		Notify("session %s %s stream connected", session.Name, streamName)
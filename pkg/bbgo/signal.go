@@ -0,0 +1,107 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/dynamic"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type ExternalSignalType string
+
+const (
+	// ExternalSignalTypeTargetPosition asks the strategy to move its position to the given base quantity
+	ExternalSignalTypeTargetPosition ExternalSignalType = "targetPosition"
+
+	// ExternalSignalTypeOrderIntent asks the strategy to submit the given order
+	ExternalSignalTypeOrderIntent ExternalSignalType = "orderIntent"
+)
+
+// ExternalSignal is a trading signal produced outside of bbgo, for example by a research process,
+// and submitted through the gRPC SignalService or the HTTP API.
+type ExternalSignal struct {
+	// ID is the unique signal id set by the producer, used for de-duplication
+	ID string `json:"id"`
+
+	// Strategy is the instance id of the receiver strategy, e.g. "externalsignal:BTCUSDT".
+	// It can be omitted when there is only one receiver.
+	Strategy string `json:"strategy,omitempty"`
+
+	Symbol string             `json:"symbol"`
+	Type   ExternalSignalType `json:"type"`
+
+	// TargetPosition is the target base position of the target position signal, negative for short
+	TargetPosition fixedpoint.Value `json:"targetPosition,omitempty"`
+
+	// Side and Quantity are used by the order intent signal
+	Side     types.SideType   `json:"side,omitempty"`
+	Quantity fixedpoint.Value `json:"quantity,omitempty"`
+
+	// OrderType is the order type used to execute the signal, market order by default
+	OrderType types.OrderType  `json:"orderType,omitempty"`
+	Price     fixedpoint.Value `json:"price,omitempty"`
+
+	// Time is the time when the signal is produced, used for the staleness check
+	Time types.MillisecondTimestamp `json:"time"`
+}
+
+func (s ExternalSignal) String() string {
+	switch s.Type {
+	case ExternalSignalTypeTargetPosition:
+		return fmt.Sprintf("signal %s %s target position %s", s.ID, s.Symbol, s.TargetPosition.String())
+	default:
+		return fmt.Sprintf("signal %s %s %s %s %s", s.ID, s.Symbol, s.Type, s.Side, s.Quantity.String())
+	}
+}
+
+// ErrInvalidSignalAuthToken is returned by the receiver when the auth token of the signal producer is invalid
+var ErrInvalidSignalAuthToken = errors.New("invalid signal auth token")
+
+// ExternalSignalReceiver is implemented by the strategies that execute external signals.
+// The auth token is the token presented by the signal producer, the receiver is responsible for verifying it.
+type ExternalSignalReceiver interface {
+	ReceiveExternalSignal(ctx context.Context, signal ExternalSignal, authToken string) (types.OrderSlice, error)
+}
+
+// DispatchExternalSignal routes the signal to the receiver strategy selected by signal.Strategy,
+// which can be either the strategy instance id or the strategy signature, e.g. "binance.externalsignal:BTCUSDT".
+func (trader *Trader) DispatchExternalSignal(ctx context.Context, signal ExternalSignal, authToken string) (types.OrderSlice, error) {
+	strategies, err := trader.StrategiesBySignature()
+	if err != nil {
+		return nil, err
+	}
+
+	var signatures []string
+	for signature, strategy := range strategies {
+		if _, ok := strategy.(ExternalSignalReceiver); !ok {
+			continue
+		}
+
+		if len(signal.Strategy) > 0 && signal.Strategy != signature && signal.Strategy != dynamic.CallID(strategy) {
+			continue
+		}
+
+		signatures = append(signatures, signature)
+	}
+
+	// the auth token is verified by the receiver, so the error returned before that does not name the receivers
+	switch len(signatures) {
+	case 0:
+		return nil, fmt.Errorf("external signal receiver not found")
+
+	case 1:
+		receiver := strategies[signatures[0]].(ExternalSignalReceiver)
+		return receiver.ReceiveExternalSignal(ctx, signal, authToken)
+
+	default:
+		sort.Strings(signatures)
+		log.Warnf("found multiple external signal receivers %v for %s", signatures, signal)
+		return nil, fmt.Errorf("found multiple external signal receivers, please specify the strategy of the signal")
+	}
+}
@@ -0,0 +1,412 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	defaultRouterMaxRounds   = 3
+	defaultRouterFillTimeout = 5 * time.Second
+	routerPollInterval       = 200 * time.Millisecond
+
+	// routerCancelQueryRetries is the max number of the order queries after the routed order is canceled
+	routerCancelQueryRetries = 10
+)
+
+// RouteAllocation is the quantity of the order routed to a session
+type RouteAllocation struct {
+	Session  string           `json:"session"`
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// Price is the worst price of the allocated price levels, it's the limit price of the IOC order
+	Price fixedpoint.Value `json:"price"`
+
+	// AveragePrice is the volume weighted price of the allocated price levels
+	AveragePrice fixedpoint.Value `json:"averagePrice"`
+
+	// Fee is the taker fee in the quote currency
+	Fee fixedpoint.Value `json:"fee"`
+}
+
+// Route is the allocation of an order across the sessions
+type Route struct {
+	Symbol   string           `json:"symbol"`
+	Side     types.SideType   `json:"side"`
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	Allocations []RouteAllocation `json:"allocations"`
+
+	// Unallocated is the quantity that is not covered by the order book depth and the available balances
+	Unallocated fixedpoint.Value `json:"unallocated"`
+}
+
+// Cost returns the quote amount paid for a buy order or received for a sell order, the fees are included
+func (r *Route) Cost() (cost fixedpoint.Value) {
+	for _, a := range r.Allocations {
+		amount := a.Quantity.Mul(a.AveragePrice)
+		if r.Side == types.SideTypeBuy {
+			cost = cost.Add(amount.Add(a.Fee))
+		} else {
+			cost = cost.Add(amount.Sub(a.Fee))
+		}
+	}
+	return cost
+}
+
+func (r *Route) String() string {
+	s := fmt.Sprintf("%s %s %v routed, unallocated %v:", r.Symbol, r.Side, r.Quantity, r.Unallocated)
+	for _, a := range r.Allocations {
+		s += fmt.Sprintf("\n- %s: %v @ %v (avg %v, fee %v)", a.Session, a.Quantity, a.Price, a.AveragePrice, a.Fee)
+	}
+	return s
+}
+
+// RouteResult is the execution result of the smart order router
+type RouteResult struct {
+	Orders types.OrderSlice `json:"orders"`
+
+	Executed  fixedpoint.Value `json:"executed"`
+	Remaining fixedpoint.Value `json:"remaining"`
+
+	// Rounds is the number of the routing rounds
+	Rounds int `json:"rounds"`
+}
+
+// SmartOrderRouter splits an order across the sessions by their order books, taker fee rates and available balances
+// to minimize the execution cost. The routed orders are IOC limit orders at the worst allocated price, and the
+// unfilled quantity is re-routed with the updated order books.
+type SmartOrderRouter struct {
+	// MaxRounds is the max number of the routing rounds, default: 3
+	MaxRounds int
+
+	// FillTimeout is the time to wait for the routed orders to be closed, default: 5s
+	FillTimeout time.Duration
+
+	sessions []*ExchangeSession
+}
+
+func NewSmartOrderRouter(sessions ...*ExchangeSession) *SmartOrderRouter {
+	return &SmartOrderRouter{
+		MaxRounds:   defaultRouterMaxRounds,
+		FillTimeout: defaultRouterFillTimeout,
+		sessions:    sessions,
+	}
+}
+
+type routeLevel struct {
+	session   int
+	price     fixedpoint.Value
+	volume    fixedpoint.Value
+	effective fixedpoint.Value
+}
+
+// Route allocates the order quantity to the price levels of the sessions from the best effective price,
+// the price of a limit order bounds the allocated price levels.
+func (r *SmartOrderRouter) Route(order types.SubmitOrder) (*Route, error) {
+	if order.Side != types.SideTypeBuy && order.Side != types.SideTypeSell {
+		return nil, fmt.Errorf("invalid order side %q", order.Side)
+	}
+
+	if order.Quantity.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid order quantity %v", order.Quantity)
+	}
+
+	buy := order.Side == types.SideTypeBuy
+
+	// the buy order takes the asks and the sell order takes the bids
+	bookSide := types.SideTypeSell
+	if !buy {
+		bookSide = types.SideTypeBuy
+	}
+
+	var levels []routeLevel
+	for i, session := range r.sessions {
+		if _, ok := session.Market(order.Symbol); !ok {
+			continue
+		}
+
+		book, ok := session.OrderBook(order.Symbol)
+		if !ok {
+			continue
+		}
+
+		fee := session.TakerFeeRate
+		for _, pv := range book.Copy().SideBook(bookSide) {
+			if order.Type == types.OrderTypeLimit && order.Price.Sign() > 0 {
+				if buy && pv.Price.Compare(order.Price) > 0 || !buy && pv.Price.Compare(order.Price) < 0 {
+					break
+				}
+			}
+
+			effective := pv.Price.Mul(fixedpoint.One.Add(fee))
+			if !buy {
+				effective = pv.Price.Mul(fixedpoint.One.Sub(fee))
+			}
+
+			levels = append(levels, routeLevel{session: i, price: pv.Price, volume: pv.Volume, effective: effective})
+		}
+	}
+
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("no order book of %s found in the sessions", order.Symbol)
+	}
+
+	sort.SliceStable(levels, func(i, j int) bool {
+		if buy {
+			return levels[i].effective.Compare(levels[j].effective) < 0
+		}
+		return levels[i].effective.Compare(levels[j].effective) > 0
+	})
+
+	// the remaining quote balance for buy, and the remaining base balance for sell
+	budgets := make([]fixedpoint.Value, len(r.sessions))
+	for i, session := range r.sessions {
+		market, ok := session.Market(order.Symbol)
+		if !ok {
+			continue
+		}
+
+		currency := market.BaseCurrency
+		if buy {
+			currency = market.QuoteCurrency
+		}
+
+		if balance, ok := session.GetAccount().Balance(currency); ok {
+			budgets[i] = balance.Available
+		}
+	}
+
+	quantities := make([]fixedpoint.Value, len(r.sessions))
+	amounts := make([]fixedpoint.Value, len(r.sessions))
+	prices := make([]fixedpoint.Value, len(r.sessions))
+
+	remaining := order.Quantity
+	for _, level := range levels {
+		if remaining.Sign() <= 0 {
+			break
+		}
+
+		budget := budgets[level.session]
+		if buy {
+			budget = budget.Div(level.effective)
+		}
+
+		q := fixedpoint.Min(fixedpoint.Min(level.volume, remaining), budget)
+		if q.Sign() <= 0 {
+			continue
+		}
+
+		if buy {
+			budgets[level.session] = budgets[level.session].Sub(q.Mul(level.effective))
+		} else {
+			budgets[level.session] = budgets[level.session].Sub(q)
+		}
+
+		quantities[level.session] = quantities[level.session].Add(q)
+		amounts[level.session] = amounts[level.session].Add(q.Mul(level.price))
+		prices[level.session] = level.price
+		remaining = remaining.Sub(q)
+	}
+
+	route := &Route{
+		Symbol:   order.Symbol,
+		Side:     order.Side,
+		Quantity: order.Quantity,
+	}
+
+	allocated := fixedpoint.Zero
+	for i, session := range r.sessions {
+		if quantities[i].Sign() <= 0 {
+			continue
+		}
+
+		market, _ := session.Market(order.Symbol)
+		quantity := market.TruncateQuantity(quantities[i])
+		if market.IsDustQuantity(quantity, prices[i]) {
+			continue
+		}
+
+		averagePrice := amounts[i].Div(quantities[i])
+		route.Allocations = append(route.Allocations, RouteAllocation{
+			Session:      session.Name,
+			Quantity:     quantity,
+			Price:        prices[i],
+			AveragePrice: averagePrice,
+			Fee:          quantity.Mul(averagePrice).Mul(session.TakerFeeRate),
+		})
+		allocated = allocated.Add(quantity)
+	}
+
+	route.Unallocated = order.Quantity.Sub(allocated)
+	return route, nil
+}
+
+// Execute routes the order and submits the IOC orders to the sessions, the unfilled quantity is re-routed until
+// the order is filled, the max rounds is reached or nothing can be routed.
+func (r *SmartOrderRouter) Execute(ctx context.Context, order types.SubmitOrder) (*RouteResult, error) {
+	result := &RouteResult{Remaining: order.Quantity}
+
+	sessions := make(map[string]*ExchangeSession, len(r.sessions))
+	for _, session := range r.sessions {
+		sessions[session.Name] = session
+	}
+
+	var errs error
+	for result.Rounds < r.MaxRounds && result.Remaining.Sign() > 0 {
+		next := order
+		next.Quantity = result.Remaining
+
+		route, err := r.Route(next)
+		if err != nil {
+			return result, multierr.Append(errs, err)
+		}
+
+		if len(route.Allocations) == 0 {
+			break
+		}
+
+		result.Rounds++
+		log.Infof("smart order router round %d: %s", result.Rounds, route)
+
+		executed := fixedpoint.Zero
+		uncertain := false
+		for _, allocation := range route.Allocations {
+			session := sessions[allocation.Session]
+			market, _ := session.Market(order.Symbol)
+
+			submitOrder := order
+			submitOrder.Market = market
+			submitOrder.Type = types.OrderTypeLimit
+			submitOrder.TimeInForce = types.TimeInForceIOC
+			submitOrder.Quantity = allocation.Quantity
+			submitOrder.Price = allocation.Price
+
+			createdOrders, err := submitOrdersToSession(ctx, session, submitOrder)
+			if err != nil {
+				errs = multierr.Append(errs, err)
+			}
+
+			for _, createdOrder := range createdOrders {
+				closedOrder, err := r.waitOrderClosed(ctx, session, createdOrder)
+				if err != nil {
+					// the executed quantity of the order is unknown, re-routing could overfill the order
+					errs = multierr.Append(errs, err)
+					uncertain = true
+				}
+
+				result.Orders = append(result.Orders, closedOrder)
+				executed = executed.Add(closedOrder.ExecutedQuantity)
+			}
+		}
+
+		result.Executed = result.Executed.Add(executed)
+		result.Remaining = order.Quantity.Sub(result.Executed)
+
+		// nothing is filled in this round, the books will not be better by re-routing immediately
+		if executed.Sign() <= 0 || uncertain {
+			break
+		}
+	}
+
+	return result, errs
+}
+
+// waitOrderClosed waits for the IOC order to be filled or canceled, the order is canceled if it's still open after the fill timeout.
+// An error is returned if the order can not be confirmed closed, since its executed quantity is not final.
+func (r *SmartOrderRouter) waitOrderClosed(ctx context.Context, session *ExchangeSession, order types.Order) (types.Order, error) {
+	if isClosedOrderStatus(order.Status) {
+		return order, nil
+	}
+
+	service, ok := session.Exchange.(types.ExchangeOrderQueryService)
+	if !ok {
+		return order, nil
+	}
+
+	query := types.OrderQuery{Symbol: order.Symbol, OrderID: strconv.FormatUint(order.OrderID, 10)}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, r.FillTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(routerPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-timeoutCtx.Done():
+			if err := session.Exchange.CancelOrders(ctx, order); err != nil {
+				return order, err
+			}
+
+			// the order can be filled before it's canceled, query the final executed quantity
+			return queryCanceledOrder(ctx, service, query, order)
+
+		case <-ticker.C:
+			updated, err := service.QueryOrder(ctx, query)
+			if err != nil {
+				log.WithError(err).Warnf("can not query the routed order %d", order.OrderID)
+				continue
+			}
+
+			if updated != nil {
+				order = *updated
+			}
+
+			if isClosedOrderStatus(order.Status) {
+				return order, nil
+			}
+		}
+	}
+}
+
+// queryCanceledOrder queries the canceled order until its status is closed
+func queryCanceledOrder(ctx context.Context, service types.ExchangeOrderQueryService, query types.OrderQuery, order types.Order) (types.Order, error) {
+	var lastErr error
+	for i := 0; i < routerCancelQueryRetries; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return order, ctx.Err()
+			case <-time.After(routerPollInterval):
+			}
+		}
+
+		updated, err := service.QueryOrder(ctx, query)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if updated != nil {
+			order = *updated
+		}
+
+		if isClosedOrderStatus(order.Status) {
+			return order, nil
+		}
+	}
+
+	if lastErr != nil {
+		return order, fmt.Errorf("can not query the canceled order %d: %w", order.OrderID, lastErr)
+	}
+
+	return order, fmt.Errorf("the canceled order %d is still %s", order.OrderID, order.Status)
+}
+
+func isClosedOrderStatus(status types.OrderStatus) bool {
+	switch status {
+	case types.OrderStatusFilled, types.OrderStatusCanceled, types.OrderStatusRejected:
+		return true
+	}
+	return false
+}
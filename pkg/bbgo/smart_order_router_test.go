@@ -0,0 +1,171 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func newRouterTestSession(mockCtrl *gomock.Controller, name, takerFeeRate string, asks, bids types.PriceVolumeSlice) (*ExchangeSession, *mocks.MockExchange) {
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	session := NewExchangeSession(name, mockEx)
+	session.TakerFeeRate = fixedpoint.MustNewFromString(takerFeeRate)
+	session.markets = map[string]types.Market{
+		"BTCUSDT": {
+			Symbol:          "BTCUSDT",
+			BaseCurrency:    "BTC",
+			QuoteCurrency:   "USDT",
+			PricePrecision:  2,
+			VolumePrecision: 4,
+			StepSize:        fixedpoint.NewFromFloat(0.0001),
+			TickSize:        fixedpoint.NewFromFloat(0.01),
+		},
+	}
+	session.Account.UpdateBalances(types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromInt(10)},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromInt(1000000)},
+	})
+
+	book := types.NewStreamBook("BTCUSDT")
+	book.Load(types.SliceOrderBook{Symbol: "BTCUSDT", Asks: asks, Bids: bids})
+	session.orderBooks["BTCUSDT"] = book
+	return session, mockEx
+}
+
+func pv(price, volume string) types.PriceVolume {
+	return types.PriceVolume{Price: fixedpoint.MustNewFromString(price), Volume: fixedpoint.MustNewFromString(volume)}
+}
+
+func TestSmartOrderRouter_Route(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	binance, _ := newRouterTestSession(mockCtrl, "binance", "0.001",
+		types.PriceVolumeSlice{pv("20000", "1"), pv("20010", "2")},
+		types.PriceVolumeSlice{pv("19990", "1"), pv("19980", "3")})
+	max, _ := newRouterTestSession(mockCtrl, "max", "0.0005",
+		types.PriceVolumeSlice{pv("20005", "1"), pv("20020", "5")},
+		types.PriceVolumeSlice{pv("19995", "1"), pv("19985", "1")})
+
+	router := NewSmartOrderRouter(binance, max)
+
+	t.Run("buy by the effective prices", func(t *testing.T) {
+		route, err := router.Route(types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Quantity: fixedpoint.NewFromInt(3)})
+		assert.NoError(t, err)
+		assert.Equal(t, "0", route.Unallocated.String())
+		if assert.Len(t, route.Allocations, 2) {
+			assert.Equal(t, "binance", route.Allocations[0].Session)
+			assert.Equal(t, "2", route.Allocations[0].Quantity.String())
+			assert.Equal(t, "20010", route.Allocations[0].Price.String())
+			assert.Equal(t, "20005", route.Allocations[0].AveragePrice.String())
+			assert.Equal(t, "40.01", route.Allocations[0].Fee.String())
+
+			assert.Equal(t, "max", route.Allocations[1].Session)
+			assert.Equal(t, "1", route.Allocations[1].Quantity.String())
+			assert.Equal(t, "20005", route.Allocations[1].Price.String())
+		}
+		assert.Equal(t, "60065.0125", route.Cost().String())
+	})
+
+	t.Run("limit price", func(t *testing.T) {
+		route, err := router.Route(types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: fixedpoint.NewFromInt(20005), Quantity: fixedpoint.NewFromInt(3)})
+		assert.NoError(t, err)
+		assert.Len(t, route.Allocations, 2)
+		assert.Equal(t, "1", route.Unallocated.String())
+	})
+
+	t.Run("sell with the available balance", func(t *testing.T) {
+		binance.Account.UpdateBalances(types.BalanceMap{
+			"BTC": {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.5)},
+		})
+
+		route, err := router.Route(types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Quantity: fixedpoint.NewFromInt(3)})
+		assert.NoError(t, err)
+		assert.Equal(t, "0.5", route.Unallocated.String())
+		if assert.Len(t, route.Allocations, 2) {
+			assert.Equal(t, "binance", route.Allocations[0].Session)
+			assert.Equal(t, "0.5", route.Allocations[0].Quantity.String())
+			assert.Equal(t, "max", route.Allocations[1].Session)
+			assert.Equal(t, "2", route.Allocations[1].Quantity.String())
+			assert.Equal(t, "19985", route.Allocations[1].Price.String())
+		}
+	})
+}
+
+func TestSmartOrderRouter_Execute(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	binance, binanceEx := newRouterTestSession(mockCtrl, "binance", "0.001",
+		types.PriceVolumeSlice{pv("20000", "1"), pv("20010", "2")}, nil)
+	max, maxEx := newRouterTestSession(mockCtrl, "max", "0.0005",
+		types.PriceVolumeSlice{pv("20005", "1"), pv("20020", "5")}, nil)
+
+	// the binance IOC order is partially filled
+	binanceEx.EXPECT().SubmitOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+		assert.Equal(t, types.TimeInForceIOC, order.TimeInForce)
+		assert.Equal(t, "20010", order.Price.String())
+		return &types.Order{SubmitOrder: order, OrderID: 1, Status: types.OrderStatusCanceled, ExecutedQuantity: fixedpoint.NewFromFloat(1.5)}, nil
+	}).Times(1)
+
+	var maxQuantities []string
+	maxEx.EXPECT().SubmitOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+		maxQuantities = append(maxQuantities, order.Quantity.String())
+		return &types.Order{SubmitOrder: order, OrderID: 2, Status: types.OrderStatusFilled, ExecutedQuantity: order.Quantity}, nil
+	}).Times(2)
+
+	router := NewSmartOrderRouter(binance, max)
+	result, err := router.Execute(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Quantity: fixedpoint.NewFromInt(3)})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Rounds)
+	assert.Equal(t, "3", result.Executed.String())
+	assert.Equal(t, "0", result.Remaining.String())
+	assert.Len(t, result.Orders, 3)
+
+	// the unfilled quantity is re-routed to the best effective price
+	assert.Equal(t, []string{"1", "0.5"}, maxQuantities)
+}
+
+func TestSmartOrderRouter_Execute_fillTimeout(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session, mockEx := newRouterTestSession(mockCtrl, "binance", "0.001",
+		types.PriceVolumeSlice{pv("20000", "1"), pv("20010", "2")}, nil)
+
+	// the order is still open when the fill timeout is reached, and partially filled before it's canceled
+	ex := &reconcileTestExchange{MockExchange: mockEx, orders: map[uint64]types.Order{}}
+	session.Exchange = ex
+
+	mockEx.EXPECT().SubmitOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+		created := types.Order{SubmitOrder: order, OrderID: 1, Status: types.OrderStatusNew}
+		ex.orders[1] = created
+		return &created, nil
+	}).Times(1)
+
+	mockEx.EXPECT().CancelOrders(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, orders ...types.Order) error {
+		canceled := ex.orders[1]
+		canceled.Status = types.OrderStatusCanceled
+		canceled.ExecutedQuantity = fixedpoint.NewFromFloat(0.8)
+		ex.orders[1] = canceled
+		return nil
+	}).Times(1)
+
+	router := NewSmartOrderRouter(session)
+	router.FillTimeout = time.Millisecond
+	router.MaxRounds = 1
+
+	result, err := router.Execute(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Quantity: fixedpoint.NewFromInt(3)})
+	assert.NoError(t, err)
+	assert.Equal(t, "0.8", result.Executed.String())
+	assert.Equal(t, "2.2", result.Remaining.String())
+}
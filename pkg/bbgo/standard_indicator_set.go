@@ -31,8 +31,7 @@ type StandardIndicatorSet struct {
 	iwIndicators   map[indicatorKey]indicator.KLinePusher
 	macdIndicators map[indicator.MACDConfig]*indicator.MACD
 
-	stream types.Stream
-	store  *MarketDataStore
+	store *MarketDataStore
 }
 
 type indicatorKey struct {
@@ -40,25 +39,20 @@ type indicatorKey struct {
 	id string
 }
 
-func NewStandardIndicatorSet(symbol string, stream types.Stream, store *MarketDataStore) *StandardIndicatorSet {
+func NewStandardIndicatorSet(symbol string, store *MarketDataStore) *StandardIndicatorSet {
 	return &StandardIndicatorSet{
 		Symbol:         symbol,
 		store:          store,
-		stream:         stream,
 		iwIndicators:   make(map[indicatorKey]indicator.KLinePusher),
 		iwbIndicators:  make(map[types.IntervalWindowBandWidth]*indicator.BOLL),
 		macdIndicators: make(map[indicator.MACDConfig]*indicator.MACD),
 	}
 }
 
+// initAndBind binds the indicator to the market data store instead of the stream,
+// so that indicators allocated before the session loads the kline history are warmed up too.
 func (s *StandardIndicatorSet) initAndBind(inc indicator.KLinePusher, interval types.Interval) {
-	if klines, ok := s.store.KLinesOfInterval(interval); ok {
-		for _, k := range *klines {
-			inc.PushK(k)
-		}
-	}
-
-	s.stream.OnKLineClosed(types.KLineWith(s.Symbol, interval, inc.PushK))
+	s.store.BindKLinePusher(interval, inc)
 }
 
 func (s *StandardIndicatorSet) allocateSimpleIndicator(t indicator.KLinePusher, iw types.IntervalWindow, id string) indicator.KLinePusher {
@@ -95,6 +89,18 @@ func (s *StandardIndicatorSet) VWMA(iw types.IntervalWindow) *indicator.VWMA {
 	return inc.(*indicator.VWMA)
 }
 
+// RSI is a helper function that returns the relative strength index indicator of the given interval and the window size.
+func (s *StandardIndicatorSet) RSI(iw types.IntervalWindow) *indicator.RSI {
+	inc := s.allocateSimpleIndicator(&indicator.RSI{IntervalWindow: iw}, iw, "rsi")
+	return inc.(*indicator.RSI)
+}
+
+// StdDev is a helper function that returns the standard deviation indicator of the given interval and the window size.
+func (s *StandardIndicatorSet) StdDev(iw types.IntervalWindow) *indicator.StdDev {
+	inc := s.allocateSimpleIndicator(&indicator.StdDev{IntervalWindow: iw}, iw, "stddev")
+	return inc.(*indicator.StdDev)
+}
+
 func (s *StandardIndicatorSet) PivotHigh(iw types.IntervalWindow) *indicator.PivotHigh {
 	inc := s.allocateSimpleIndicator(&indicator.PivotHigh{IntervalWindow: iw}, iw, "pivothigh")
 	return inc.(*indicator.PivotHigh)
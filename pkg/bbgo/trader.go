@@ -85,6 +85,10 @@ type Trader struct {
 
 	riskControls *RiskControls
 
+	circuitBreaker *CircuitBreaker
+
+	reporters []*ScheduledReporter
+
 	crossExchangeStrategies []CrossExchangeStrategy
 	exchangeStrategies      map[string][]SingleExchangeStrategy
 
@@ -112,6 +116,12 @@ func (trader *Trader) Configure(userConfig *Config) error {
 		trader.SetRiskControls(userConfig.RiskControls)
 	}
 
+	for _, conf := range userConfig.Reports {
+		reporter := NewScheduledReporter(conf, trader.environment.sessions, trader.exchangeStrategies, Notification)
+		reporter.SetStore(PersistenceServiceFacade.Get().NewStore("bbgo", "report", conf.Name))
+		trader.reporters = append(trader.reporters, reporter)
+	}
+
 	for _, entry := range userConfig.ExchangeStrategies {
 		for _, mount := range entry.Mounts {
 			log.Infof("attaching strategy %T on %s...", entry.Strategy, mount)
@@ -151,6 +161,25 @@ func (trader *Trader) AttachStrategyOn(session string, strategies ...SingleExcha
 	return nil
 }
 
+// StrategiesBySignature returns the attached single exchange strategies keyed by
+// the session name and the strategy signature, e.g. "binance.grid.BTCUSDT".
+// The same keys are used by the interact commands and the gRPC strategy service.
+func (trader *Trader) StrategiesBySignature() (map[string]SingleExchangeStrategy, error) {
+	strategies := make(map[string]SingleExchangeStrategy)
+	for sessionName, sessionStrategies := range trader.exchangeStrategies {
+		for _, strategy := range sessionStrategies {
+			signature, err := getStrategySignature(strategy)
+			if err != nil {
+				return nil, err
+			}
+
+			strategies[sessionName+"."+signature] = strategy
+		}
+	}
+
+	return strategies, nil
+}
+
 // AttachCrossExchangeStrategy attaches the cross exchange strategy
 func (trader *Trader) AttachCrossExchangeStrategy(strategy CrossExchangeStrategy) *Trader {
 	trader.crossExchangeStrategies = append(trader.crossExchangeStrategies, strategy)
@@ -162,6 +191,45 @@ func (trader *Trader) AttachCrossExchangeStrategy(strategy CrossExchangeStrategy
 // TODO: provide a more DSL way to configure risk controls
 func (trader *Trader) SetRiskControls(riskControls *RiskControls) {
 	trader.riskControls = riskControls
+
+	if riskControls.Engine != nil {
+		engine := NewRiskEngine(riskControls.Engine)
+		for _, session := range trader.environment.sessions {
+			session.SetRiskEngine(engine)
+		}
+	}
+
+	if riskControls.CircuitBreaker != nil {
+		trader.circuitBreaker = NewCircuitBreaker(riskControls.CircuitBreaker)
+		for _, session := range trader.environment.sessions {
+			session.SetCircuitBreaker(trader.circuitBreaker)
+		}
+	}
+}
+
+// CircuitBreaker returns the circuit breaker configured by the risk controls, nil if it's not configured
+func (trader *Trader) CircuitBreaker() *CircuitBreaker {
+	return trader.circuitBreaker
+}
+
+// bindCircuitBreaker adds the running strategies into the circuit breaker
+func (trader *Trader) bindCircuitBreaker(ctx context.Context) error {
+	if trader.circuitBreaker == nil {
+		return nil
+	}
+
+	for sessionName, strategies := range trader.exchangeStrategies {
+		session := trader.environment.sessions[sessionName]
+		for _, strategy := range strategies {
+			if err := trader.circuitBreaker.AddStrategy(session, strategy); err != nil {
+				return err
+			}
+		}
+
+		trader.circuitBreaker.BindSession(ctx, session)
+	}
+
+	return nil
 }
 
 func (trader *Trader) Subscribe() {
@@ -352,6 +420,22 @@ func (trader *Trader) Run(ctx context.Context) error {
 		return err
 	}
 
+	if err := trader.bindCircuitBreaker(ctx); err != nil {
+		return err
+	}
+
+	for _, session := range trader.environment.sessions {
+		if err := session.bindMarginHealthMonitor(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, reporter := range trader.reporters {
+		if err := reporter.Start(ctx); err != nil {
+			return err
+		}
+	}
+
 	router := &ExchangeOrderExecutionRouter{
 		sessions:  trader.environment.sessions,
 		executors: make(map[string]OrderExecutor),
@@ -367,6 +451,10 @@ func (trader *Trader) Run(ctx context.Context) error {
 		}
 	}
 
+	for _, session := range trader.environment.sessions {
+		session.bindReconcilers(ctx)
+	}
+
 	return trader.environment.Connect(ctx)
 }
 
@@ -0,0 +1,101 @@
+package bbgo
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/exchange/batch"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// maxKLinesPerQuery is the max number of klines returned by a single QueryKLines call
+const maxKLinesPerQuery = 1000
+
+// WarmUp declares the kline history that the given symbol needs before the strategies run.
+// It should be called in the Subscribe method of the strategy, the session loads at least
+// iw.Window klines of iw.Interval when the symbol is initialized, and replays them through
+// the market data store, so that all the indicators bound to the store are warmed up.
+func (session *ExchangeSession) WarmUp(symbol string, iws ...types.IntervalWindow) *ExchangeSession {
+	windows, ok := session.warmUpWindows[symbol]
+	if !ok {
+		windows = make(map[types.Interval]int)
+		session.warmUpWindows[symbol] = windows
+	}
+
+	for _, iw := range iws {
+		if iw.Window > MaxNumOfKLines {
+			log.Warnf("%s warm-up window %d of %s exceeds the max number of klines %d, the history will be truncated",
+				symbol, iw.Window, iw.Interval, MaxNumOfKLines)
+		}
+
+		if iw.Window > windows[iw.Interval] {
+			windows[iw.Interval] = iw.Window
+		}
+	}
+
+	// add to the loaded symbol table
+	session.usedSymbols[symbol] = struct{}{}
+	return session
+}
+
+// WarmUpWindows returns the kline history limits declared for the given symbol
+func (session *ExchangeSession) WarmUpWindows(symbol string) map[types.Interval]int {
+	return session.warmUpWindows[symbol]
+}
+
+// kLineHistoryLimit returns the number of klines that should be preloaded for the given symbol and interval
+func (session *ExchangeSession) kLineHistoryLimit(symbol string, interval types.Interval) int {
+	limit := int(KLinePreloadLimit)
+	if window, ok := session.warmUpWindows[symbol][interval]; ok && window > limit {
+		limit = window
+	}
+
+	// the base interval needs more klines to build the history of the aggregated intervals
+	if aggregator, ok := session.kLineAggregators[symbol][interval]; ok {
+		for _, aggregatedInterval := range aggregator.Intervals {
+			ratio := aggregatedInterval.Minutes() / interval.Minutes()
+			if n := session.kLineHistoryLimit(symbol, aggregatedInterval) * ratio; n > limit {
+				limit = n
+			}
+		}
+	}
+
+	return limit
+}
+
+// queryKLineHistory queries the last N klines closed before the endTime in ascending order.
+// In backtest, the klines are queried from the backtest database directly,
+// otherwise the klines are queried from the exchange in batches.
+func (session *ExchangeSession) queryKLineHistory(ctx context.Context, environ *Environment, symbol string, interval types.Interval, endTime time.Time, limit int) ([]types.KLine, error) {
+	if environ.BacktestService != nil {
+		return environ.BacktestService.QueryKLinesBackward(session.Exchange.Name(), symbol, interval, endTime, limit)
+	}
+
+	if limit <= maxKLinesPerQuery {
+		return session.Exchange.QueryKLines(ctx, symbol, interval, types.KLineQueryOptions{
+			EndTime: &endTime,
+			Limit:   limit,
+		})
+	}
+
+	startTime := endTime.Add(-time.Duration(limit) * interval.Duration())
+	q := &batch.KLineBatchQuery{Exchange: session.Exchange}
+	kLineC, errC := q.Query(ctx, symbol, interval, startTime, endTime)
+
+	var kLines []types.KLine
+	for k := range kLineC {
+		kLines = append(kLines, k)
+	}
+
+	if err := <-errC; err != nil {
+		return nil, err
+	}
+
+	if len(kLines) > limit {
+		kLines = kLines[len(kLines)-limit:]
+	}
+
+	return kLines, nil
+}
@@ -0,0 +1,174 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func TestExchangeSession_WarmUp(t *testing.T) {
+	session := &ExchangeSession{
+		usedSymbols:   make(map[string]struct{}),
+		warmUpWindows: make(map[string]map[types.Interval]int),
+	}
+
+	session.WarmUp("BTCUSDT",
+		types.IntervalWindow{Interval: types.Interval1m, Window: 3000},
+		types.IntervalWindow{Interval: types.Interval1h, Window: 100},
+	)
+	session.WarmUp("BTCUSDT", types.IntervalWindow{Interval: types.Interval1m, Window: 2000})
+
+	assert.Contains(t, session.usedSymbols, "BTCUSDT")
+	assert.Equal(t, 3000, session.kLineHistoryLimit("BTCUSDT", types.Interval1m))
+	assert.Equal(t, int(KLinePreloadLimit), session.kLineHistoryLimit("BTCUSDT", types.Interval1h))
+	assert.Equal(t, int(KLinePreloadLimit), session.kLineHistoryLimit("ETHUSDT", types.Interval1m))
+}
+
+func TestStandardIndicatorSet_WarmUpFromStore(t *testing.T) {
+	store := NewMarketDataStore("BTCUSDT")
+	set := NewStandardIndicatorSet("BTCUSDT", store)
+
+	// allocated before the history is loaded
+	early := set.SMA(types.IntervalWindow{Interval: types.Interval1h, Window: 3})
+
+	startTime := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		store.AddKLine(types.KLine{
+			Symbol:    "BTCUSDT",
+			Interval:  types.Interval1h,
+			StartTime: types.Time(startTime.Add(time.Duration(i) * time.Hour)),
+			Close:     fixedpoint.NewFromInt(int64(i + 1)),
+		})
+	}
+
+	// allocated after the history is loaded
+	late := set.EWMA(types.IntervalWindow{Interval: types.Interval1h, Window: 3})
+
+	assert.InDelta(t, 4.0, early.Last(), 1e-9)
+	assert.Equal(t, 5, late.Length())
+}
+
+// customIntervalExchange supports the 1m and 5m klines only
+type customIntervalExchange struct {
+	*mocks.MockExchange
+}
+
+func (e *customIntervalExchange) SupportedInterval() map[types.Interval]int {
+	return map[types.Interval]int{types.Interval1m: 1, types.Interval5m: 5}
+}
+
+func (e *customIntervalExchange) IsSupportedInterval(interval types.Interval) bool {
+	_, ok := e.SupportedInterval()[interval]
+	return ok
+}
+
+func TestExchangeSession_initSymbol_aggregatedWarmUp(t *testing.T) {
+	defer func(limit int64) { KLinePreloadLimit = limit }(KLinePreloadLimit)
+	KLinePreloadLimit = 4
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	environ := NewEnvironment()
+	session := environ.AddExchange("test", &customIntervalExchange{MockExchange: mockEx})
+	session.markets = types.MarketMap{"BTCUSDT": types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}}
+	session.WarmUp("BTCUSDT", types.IntervalWindow{Interval: types.Interval15m, Window: 3})
+
+	// 12 5m klines from 00:00 are aggregated into 4 15m klines
+	startTime := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var kLines []types.KLine
+	for i := 0; i < 12; i++ {
+		kLines = append(kLines, types.KLine{
+			Symbol:    "BTCUSDT",
+			Interval:  types.Interval5m,
+			StartTime: types.Time(startTime.Add(time.Duration(i) * 5 * time.Minute)),
+			Close:     fixedpoint.NewFromInt(int64(i + 1)),
+			Closed:    true,
+		})
+	}
+
+	mockEx.EXPECT().QueryKLines(gomock.Any(), "BTCUSDT", types.Interval5m, gomock.Any()).Return(kLines, nil)
+	mockEx.EXPECT().QueryKLines(gomock.Any(), "BTCUSDT", types.Interval1m, gomock.Any()).Return(nil, nil)
+
+	assert.NoError(t, session.initSymbol(context.Background(), environ, "BTCUSDT"))
+
+	store, ok := session.MarketDataStore("BTCUSDT")
+	if assert.True(t, ok) {
+		klines, ok := store.KLinesOfInterval(types.Interval15m)
+		if assert.True(t, ok) && assert.Len(t, *klines, 4) {
+			assert.Equal(t, fixedpoint.NewFromInt(12), (*klines)[3].Close)
+		}
+	}
+}
+
+// hourlyIntervalExchange supports the 1h klines only
+type hourlyIntervalExchange struct {
+	*mocks.MockExchange
+}
+
+func (e *hourlyIntervalExchange) SupportedInterval() map[types.Interval]int {
+	return map[types.Interval]int{types.Interval1h: 60}
+}
+
+func (e *hourlyIntervalExchange) IsSupportedInterval(interval types.Interval) bool {
+	_, ok := e.SupportedInterval()[interval]
+	return ok
+}
+
+func TestExchangeSession_Subscribe_aggregatedFromMarketTrades(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	session := NewExchangeSession("test", &hourlyIntervalExchange{MockExchange: mockEx})
+	session.Subscribe(types.KLineChannel, "BTCUSDT", types.SubscribeOptions{Interval: types.Interval15m})
+
+	// none of the supported intervals fits 15m, so the market trades are subscribed instead
+	sub := types.Subscription{Channel: types.MarketTradeChannel, Symbol: "BTCUSDT"}
+	assert.Contains(t, session.Subscriptions, sub)
+
+	aggregator, ok := session.kLineAggregators["BTCUSDT"][""]
+	if !assert.True(t, ok) {
+		return
+	}
+
+	var closed []types.KLine
+	session.MarketDataStream.OnKLineClosed(func(k types.KLine) {
+		closed = append(closed, k)
+	})
+
+	session.initializedSymbols["BTCUSDT"] = struct{}{}
+	session.bindKLineAggregator(aggregator, NewMarketDataStore("BTCUSDT"))
+
+	startTime := time.Date(2022, time.January, 1, 0, 10, 0, 0, time.UTC)
+	emitter := session.MarketDataStream.(types.StandardStreamEmitter)
+	for i, minutes := range []int{0, 5, 10, 20, 35} {
+		emitter.EmitMarketTrade(types.Trade{
+			ID:       uint64(i),
+			Symbol:   "BTCUSDT",
+			Price:    fixedpoint.NewFromInt(int64(100 + i)),
+			Quantity: fixedpoint.One,
+			Time:     types.Time(startTime.Add(time.Duration(minutes) * time.Minute)),
+		})
+	}
+
+	// 00:00 is partial, 00:15 is closed by the trade at 00:30, 00:30 is closed by the trade at 00:45
+	if assert.Len(t, closed, 2) {
+		assert.Equal(t, types.Interval15m, closed[0].Interval)
+		assert.Equal(t, time.Date(2022, time.January, 1, 0, 15, 0, 0, time.UTC), closed[0].StartTime.Time())
+		assert.Equal(t, fixedpoint.NewFromInt(102), closed[0].Close)
+		assert.Equal(t, fixedpoint.NewFromInt(103), closed[1].Close)
+	}
+}
@@ -0,0 +1,319 @@
+// Package charting renders the candlestick chart of a symbol with the strategy states,
+// e.g. the indicators, the trades, the open orders and the position.
+package charting
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/wcharczuk/go-chart/v2"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	DefaultWidth  = 1280
+	DefaultHeight = 720
+
+	// MaxWidth is the max width of the auto-sized chart
+	MaxWidth = 8192
+
+	// pixelsPerKLine is used for sizing the chart of the long kline history
+	pixelsPerKLine = 4
+)
+
+// Overlay is an indicator series drawn over the candlesticks.
+// The last value of the series is aligned to the last kline.
+type Overlay struct {
+	Name   string
+	Series types.Series
+
+	// Secondary plots the series on the secondary y axis, e.g. the oscillators like RSI
+	Secondary bool
+}
+
+// Chart builds the candlestick chart of a symbol
+type Chart struct {
+	Title    string
+	Symbol   string
+	Interval types.Interval
+
+	// Width and Height are the size of the rendered image, the width grows with the number of klines when it's zero
+	Width, Height int
+
+	klines   []types.KLine
+	overlays []Overlay
+	trades   []types.Trade
+	orders   []types.Order
+	lines    []*priceLineSeries
+}
+
+func New(symbol string, interval types.Interval, klines []types.KLine) *Chart {
+	return &Chart{
+		Title:    fmt.Sprintf("%s %s", symbol, interval),
+		Symbol:   symbol,
+		Interval: interval,
+		klines:   klines,
+	}
+}
+
+// AddOverlay adds an indicator series on the price axis, e.g. the moving averages
+func (c *Chart) AddOverlay(name string, series types.Series) *Chart {
+	c.overlays = append(c.overlays, Overlay{Name: name, Series: series})
+	return c
+}
+
+// AddSecondaryOverlay adds an indicator series on the secondary y axis
+func (c *Chart) AddSecondaryOverlay(name string, series types.Series) *Chart {
+	c.overlays = append(c.overlays, Overlay{Name: name, Series: series, Secondary: true})
+	return c
+}
+
+func (c *Chart) AddOverlays(overlays ...Overlay) *Chart {
+	c.overlays = append(c.overlays, overlays...)
+	return c
+}
+
+// AddTrades adds the trade markers, the trades of the other symbols and the trades outside the kline range are ignored
+func (c *Chart) AddTrades(trades ...types.Trade) *Chart {
+	from, to := c.timeRange()
+	for _, trade := range trades {
+		if trade.Symbol != "" && trade.Symbol != c.Symbol {
+			continue
+		}
+
+		if t := trade.Time.Time(); t.Before(from) || t.After(to) {
+			continue
+		}
+
+		c.trades = append(c.trades, trade)
+	}
+
+	return c
+}
+
+// AddOrders adds the open orders as the price lines, the orders of the other symbols are ignored
+func (c *Chart) AddOrders(orders ...types.Order) *Chart {
+	for _, order := range orders {
+		if order.Symbol != "" && order.Symbol != c.Symbol {
+			continue
+		}
+
+		if order.Price.IsZero() {
+			continue
+		}
+
+		c.orders = append(c.orders, order)
+	}
+
+	return c
+}
+
+// AddPosition adds the average cost line of the position, nothing is drawn if the position is closed
+func (c *Chart) AddPosition(position *types.Position) *Chart {
+	if position == nil || position.Base.IsZero() || position.AverageCost.IsZero() {
+		return c
+	}
+
+	name := fmt.Sprintf("position %s %s @ %s", position.Base.String(), position.BaseCurrency, position.AverageCost.String())
+	return c.addLine(name, position.AverageCost, chart.Style{
+		StrokeColor: chart.ColorBlue,
+		StrokeWidth: 2,
+	})
+}
+
+// AddPriceLine adds a dashed horizontal line, e.g. the stop loss price or the take profit price
+func (c *Chart) AddPriceLine(name string, price fixedpoint.Value) *Chart {
+	return c.addLine(name, price, chart.Style{
+		StrokeColor:     chart.ColorAlternateGray,
+		StrokeWidth:     1,
+		StrokeDashArray: dashArray,
+	})
+}
+
+func (c *Chart) addLine(name string, price fixedpoint.Value, style chart.Style) *Chart {
+	c.lines = append(c.lines, &priceLineSeries{name: name, price: price.Float64(), style: style})
+	return c
+}
+
+// timeRange returns the start time of the first kline and the end time of the last kline
+func (c *Chart) timeRange() (from, to time.Time) {
+	if len(c.klines) == 0 {
+		return from, to
+	}
+
+	first := c.klines[0]
+	last := c.klines[len(c.klines)-1]
+	return first.StartTime.Time(), last.StartTime.Time().Add(c.Interval.Duration())
+}
+
+// Canvas builds the go-chart canvas, it can be used for adding the custom series before rendering
+func (c *Chart) Canvas() (*types.Canvas, error) {
+	if len(c.klines) < 2 {
+		return nil, errors.New("at least 2 klines are required for rendering the chart")
+	}
+
+	from, to := c.timeRange()
+
+	canvas := types.NewCanvas(c.Title, c.Interval)
+	canvas.Width, canvas.Height = c.size()
+	canvas.XAxis.Range = &chart.ContinuousRange{Min: timeToX(from), Max: timeToX(to)}
+	canvas.YAxis.ValueFormatter = priceFormatter(c.klines)
+
+	canvas.Series = append(canvas.Series, &candlestickSeries{
+		name:     c.Symbol,
+		interval: c.Interval,
+		klines:   c.klines,
+	})
+
+	for _, overlay := range c.overlays {
+		if series, ok := c.overlaySeries(overlay); ok {
+			canvas.Series = append(canvas.Series, series)
+		}
+	}
+
+	if len(c.trades) > 0 {
+		canvas.Series = append(canvas.Series, &tradeMarkerSeries{name: "trades", trades: c.trades})
+	}
+
+	if len(c.orders) > 0 {
+		canvas.Series = append(canvas.Series, &orderLineSeries{name: "open orders", orders: c.orders, minX: timeToX(from)})
+	}
+
+	for _, line := range c.lines {
+		line.x = timeToX(from)
+		canvas.Series = append(canvas.Series, line)
+	}
+
+	return canvas, nil
+}
+
+func (c *Chart) overlaySeries(overlay Overlay) (chart.Series, bool) {
+	series := chart.TimeSeries{Name: overlay.Name}
+	if overlay.Secondary {
+		series.YAxis = chart.YAxisSecondary
+	}
+
+	// Index(0) is the last value of the series, it maps to the last kline
+	n := len(c.klines)
+	length := overlay.Series.Length()
+	if length > n {
+		length = n
+	}
+
+	for i := length - 1; i >= 0; i-- {
+		v := overlay.Series.Index(i)
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+
+		series.XValues = append(series.XValues, klineMiddleTime(c.klines[n-1-i], c.Interval))
+		series.YValues = append(series.YValues, v)
+	}
+
+	if len(series.XValues) == 0 {
+		return nil, false
+	}
+
+	return series, true
+}
+
+func (c *Chart) size() (width, height int) {
+	width, height = c.Width, c.Height
+	if height == 0 {
+		height = DefaultHeight
+	}
+
+	if width == 0 {
+		width = len(c.klines) * pixelsPerKLine
+		if width < DefaultWidth {
+			width = DefaultWidth
+		} else if width > MaxWidth {
+			width = MaxWidth
+		}
+	}
+
+	return width, height
+}
+
+// Render renders the chart as a PNG image
+func (c *Chart) Render(w io.Writer) error {
+	return render(c, w)
+}
+
+// RenderBuffer renders the chart into a buffer, the buffer can be sent by the notifiers directly
+func (c *Chart) RenderBuffer() (*bytes.Buffer, error) {
+	return renderBuffer(c)
+}
+
+// RenderFile renders the chart into a PNG file
+func (c *Chart) RenderFile(path string) error {
+	return renderFile(c, path)
+}
+
+// canvasBuilder is implemented by the charts of this package
+type canvasBuilder interface {
+	Canvas() (*types.Canvas, error)
+}
+
+func render(b canvasBuilder, w io.Writer) error {
+	canvas, err := b.Canvas()
+	if err != nil {
+		return err
+	}
+
+	return canvas.Render(chart.PNG, w)
+}
+
+func renderBuffer(b canvasBuilder) (*bytes.Buffer, error) {
+	var buffer bytes.Buffer
+	if err := render(b, &buffer); err != nil {
+		return nil, err
+	}
+
+	return &buffer, nil
+}
+
+func renderFile(b canvasBuilder, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := render(b, f); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// priceFormatter picks the precision of the price labels by the price level of the klines
+func priceFormatter(klines []types.KLine) chart.ValueFormatter {
+	price := klines[len(klines)-1].Close.Float64()
+
+	format := "%.2f"
+	switch {
+	case price <= 0:
+	case price < 0.01:
+		format = "%.8f"
+	case price < 1:
+		format = "%.6f"
+	case price < 100:
+		format = "%.4f"
+	}
+
+	return func(v interface{}) string {
+		if f, ok := v.(float64); ok {
+			return fmt.Sprintf(format, f)
+		}
+
+		return ""
+	}
+}
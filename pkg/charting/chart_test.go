@@ -0,0 +1,170 @@
+package charting
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wcharczuk/go-chart/v2"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var testStartTime = time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestKLines(n int) (klines []types.KLine) {
+	price := 20000.0
+	for i := 0; i < n; i++ {
+		open := price
+		if i%3 == 0 {
+			price -= 150
+		} else {
+			price += 100
+		}
+
+		startTime := testStartTime.Add(time.Duration(i) * time.Hour)
+		klines = append(klines, types.KLine{
+			Symbol:    "BTCUSDT",
+			Interval:  types.Interval1h,
+			StartTime: types.Time(startTime),
+			EndTime:   types.Time(startTime.Add(time.Hour - time.Millisecond)),
+			Open:      fixedpoint.NewFromFloat(open),
+			Close:     fixedpoint.NewFromFloat(price),
+			High:      fixedpoint.NewFromFloat(math.Max(open, price) + 50),
+			Low:       fixedpoint.NewFromFloat(math.Min(open, price) - 50),
+			Volume:    fixedpoint.NewFromInt(10),
+			Closed:    true,
+		})
+	}
+
+	return klines
+}
+
+func newTestSeries(values ...float64) types.Series {
+	series := types.NewQueue(len(values))
+	for _, v := range values {
+		series.Update(v)
+	}
+	return series
+}
+
+func TestChart_Render(t *testing.T) {
+	klines := newTestKLines(48)
+
+	c := New("BTCUSDT", types.Interval1h, klines)
+	c.AddOverlay("sma", newTestSeries(20000, 20050, 20100))
+	c.AddSecondaryOverlay("rsi", newTestSeries(30, 50, 70))
+	c.AddTrades(types.Trade{
+		Symbol: "BTCUSDT",
+		Side:   types.SideTypeBuy,
+		Price:  fixedpoint.NewFromInt(20100),
+		Time:   types.Time(testStartTime.Add(5 * time.Hour)),
+	})
+	c.AddOrders(types.Order{
+		SubmitOrder: types.SubmitOrder{
+			Symbol: "BTCUSDT",
+			Side:   types.SideTypeSell,
+			Price:  fixedpoint.NewFromInt(21000),
+		},
+		CreationTime: types.Time(testStartTime.Add(40 * time.Hour)),
+	})
+	c.AddPosition(&types.Position{
+		Symbol:       "BTCUSDT",
+		BaseCurrency: "BTC",
+		Base:         fixedpoint.MustNewFromString("0.1"),
+		AverageCost:  fixedpoint.NewFromInt(20100),
+	})
+	c.AddPriceLine("stop loss", fixedpoint.NewFromInt(19500))
+
+	canvas, err := c.Canvas()
+	require.NoError(t, err)
+	// candlesticks, 2 overlays, trades, orders, position and the price line
+	assert.Len(t, canvas.Series, 7)
+
+	buffer, err := c.RenderBuffer()
+	require.NoError(t, err)
+
+	img, err := png.Decode(buffer)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultWidth, img.Bounds().Dx())
+	assert.Equal(t, DefaultHeight, img.Bounds().Dy())
+}
+
+func TestChart_RenderFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chart.png")
+
+	c := New("BTCUSDT", types.Interval1h, newTestKLines(10))
+	c.Width, c.Height = 640, 480
+	require.NoError(t, c.RenderFile(path))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	require.NoError(t, err)
+	assert.Equal(t, 640, img.Bounds().Dx())
+	assert.Equal(t, 480, img.Bounds().Dy())
+}
+
+func TestChart_NotEnoughKLines(t *testing.T) {
+	var buffer bytes.Buffer
+	err := New("BTCUSDT", types.Interval1h, newTestKLines(1)).Render(&buffer)
+	assert.EqualError(t, err, "at least 2 klines are required for rendering the chart")
+}
+
+func TestChart_overlaySeries(t *testing.T) {
+	klines := newTestKLines(3)
+	c := New("BTCUSDT", types.Interval1h, klines)
+
+	// the series is longer than the klines, the oldest value is dropped
+	series, ok := c.overlaySeries(Overlay{Name: "sma", Series: newTestSeries(1, 2, math.NaN(), 4)})
+	require.True(t, ok)
+
+	ts := series.(chart.TimeSeries)
+	assert.Equal(t, []float64{2, 4}, ts.YValues)
+	assert.Equal(t, []time.Time{
+		testStartTime.Add(30 * time.Minute),
+		testStartTime.Add(2*time.Hour + 30*time.Minute),
+	}, ts.XValues)
+
+	// the constant series is drawn along all the klines
+	series, ok = c.overlaySeries(Overlay{Name: "zero", Series: types.NumberSeries(0)})
+	require.True(t, ok)
+	assert.Equal(t, []float64{0, 0, 0}, series.(chart.TimeSeries).YValues)
+
+	_, ok = c.overlaySeries(Overlay{Name: "empty", Series: newTestSeries()})
+	assert.False(t, ok)
+}
+
+func TestChart_AddTrades(t *testing.T) {
+	c := New("BTCUSDT", types.Interval1h, newTestKLines(3))
+	c.AddTrades(
+		types.Trade{ID: 1, Symbol: "BTCUSDT", Time: types.Time(testStartTime.Add(time.Hour))},
+		types.Trade{ID: 2, Symbol: "ETHUSDT", Time: types.Time(testStartTime.Add(time.Hour))},
+		types.Trade{ID: 3, Symbol: "BTCUSDT", Time: types.Time(testStartTime.Add(-time.Hour))},
+		types.Trade{ID: 4, Symbol: "BTCUSDT", Time: types.Time(testStartTime.Add(4 * time.Hour))},
+	)
+
+	if assert.Len(t, c.trades, 1) {
+		assert.Equal(t, uint64(1), c.trades[0].ID)
+	}
+}
+
+func TestChart_size(t *testing.T) {
+	c := New("BTCUSDT", types.Interval1m, newTestKLines(1000))
+	width, height := c.size()
+	assert.Equal(t, 4000, width)
+	assert.Equal(t, DefaultHeight, height)
+
+	c = New("BTCUSDT", types.Interval1m, newTestKLines(5000))
+	width, _ = c.size()
+	assert.Equal(t, MaxWidth, width)
+}
@@ -0,0 +1,101 @@
+package charting
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// LineChart plots the series against their indexes, e.g. the profit of each trade
+type LineChart struct {
+	Title string
+
+	// Width and Height are the size of the rendered image, the default size is used when they're zero
+	Width, Height int
+
+	series []Overlay
+}
+
+func NewLineChart(title string) *LineChart {
+	return &LineChart{Title: title}
+}
+
+// NewProfitChart plots the profit ratio of each trade with the break-even line at 1
+func NewProfitChart(title, name string, profit types.Series) *LineChart {
+	return NewLineChart(title).
+		AddSeries(name, profit).
+		AddSeries("1", types.NumberSeries(1))
+}
+
+// NewCumulativeProfitChart plots the asset value after each trade
+func NewCumulativeProfitChart(title string, cumProfit types.Series) *LineChart {
+	return NewLineChart(title).AddSeries("cumulative pnl", cumProfit)
+}
+
+// AddSeries adds a line, the constant series like types.NumberSeries are drawn as the horizontal lines
+func (c *LineChart) AddSeries(name string, series types.Series) *LineChart {
+	c.series = append(c.series, Overlay{Name: name, Series: series})
+	return c
+}
+
+// length returns the length of the longest series, the constant series are not counted
+func (c *LineChart) length() (length int) {
+	for _, s := range c.series {
+		if l := s.Series.Length(); l < math.MaxInt32 && l > length {
+			length = l
+		}
+	}
+
+	return length
+}
+
+// Canvas builds the go-chart canvas, it can be used for adding the custom series before rendering
+func (c *LineChart) Canvas() (*types.Canvas, error) {
+	length := c.length()
+	if length < 2 {
+		return nil, errors.New("at least 2 values are required for rendering the chart")
+	}
+
+	canvas := types.NewCanvas(c.Title)
+	canvas.Width, canvas.Height = c.Width, c.Height
+	if canvas.Width == 0 {
+		canvas.Width = DefaultWidth
+	}
+
+	if canvas.Height == 0 {
+		canvas.Height = DefaultHeight
+	}
+
+	canvas.YAxis.ValueFormatter = func(v interface{}) string {
+		if f, ok := v.(float64); ok {
+			return fmt.Sprintf("%.4f", f)
+		}
+
+		return ""
+	}
+
+	for _, s := range c.series {
+		canvas.PlotRaw(s.Name, s.Series, length)
+	}
+
+	return canvas, nil
+}
+
+// Render renders the chart as a PNG image
+func (c *LineChart) Render(w io.Writer) error {
+	return render(c, w)
+}
+
+// RenderBuffer renders the chart into a buffer, the buffer can be sent by the notifiers directly
+func (c *LineChart) RenderBuffer() (*bytes.Buffer, error) {
+	return renderBuffer(c)
+}
+
+// RenderFile renders the chart into a PNG file
+func (c *LineChart) RenderFile(path string) error {
+	return renderFile(c, path)
+}
@@ -0,0 +1,36 @@
+package charting
+
+import (
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wcharczuk/go-chart/v2"
+)
+
+func TestNewProfitChart(t *testing.T) {
+	c := NewProfitChart("drift", "pnl %", newTestSeries(1, 1.02, 0.99, 1.05))
+
+	canvas, err := c.Canvas()
+	require.NoError(t, err)
+	if assert.Len(t, canvas.Series, 2) {
+		assert.Equal(t, []float64{1, 1.02, 0.99, 1.05}, canvas.Series[0].(chart.ContinuousSeries).YValues)
+
+		// the break-even line has the same length as the profit series
+		assert.Equal(t, []float64{1, 1, 1, 1}, canvas.Series[1].(chart.ContinuousSeries).YValues)
+	}
+
+	buffer, err := c.RenderBuffer()
+	require.NoError(t, err)
+
+	img, err := png.Decode(buffer)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultWidth, img.Bounds().Dx())
+	assert.Equal(t, DefaultHeight, img.Bounds().Dy())
+}
+
+func TestLineChart_Canvas(t *testing.T) {
+	_, err := NewCumulativeProfitChart("drift", newTestSeries(1000)).Canvas()
+	assert.EqualError(t, err, "at least 2 values are required for rendering the chart")
+}
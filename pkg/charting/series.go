@@ -0,0 +1,304 @@
+package charting
+
+import (
+	"errors"
+	"time"
+
+	"github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var (
+	upColor   = chart.ColorGreen
+	downColor = chart.ColorRed
+
+	dashArray = []float64{5.0, 5.0}
+)
+
+// markerSize is the height of the trade marker triangle in pixels
+const markerSize = 8
+
+func sideColor(side types.SideType) drawing.Color {
+	if side == types.SideTypeSell {
+		return downColor
+	}
+
+	return upColor
+}
+
+func timeToX(t time.Time) float64 {
+	return chart.TimeToFloat64(t)
+}
+
+func translateX(canvasBox chart.Box, xrange chart.Range, x float64) int {
+	return canvasBox.Left + xrange.Translate(x)
+}
+
+func translateY(canvasBox chart.Box, yrange chart.Range, y float64) int {
+	return canvasBox.Bottom - yrange.Translate(y)
+}
+
+// candlestickSeries draws the klines as candlesticks, the x value of a candlestick is the middle of the kline.
+type candlestickSeries struct {
+	name     string
+	interval types.Interval
+	klines   []types.KLine
+}
+
+var _ chart.Series = &candlestickSeries{}
+var _ chart.BoundedValuesProvider = &candlestickSeries{}
+
+func (s *candlestickSeries) GetName() string {
+	return s.name
+}
+
+func (s *candlestickSeries) GetYAxis() chart.YAxisType {
+	return chart.YAxisPrimary
+}
+
+func (s *candlestickSeries) GetStyle() chart.Style {
+	return chart.Style{StrokeColor: chart.ColorBlack, StrokeWidth: 1}
+}
+
+func (s *candlestickSeries) Validate() error {
+	if len(s.klines) == 0 {
+		return errors.New("candlestick series must have at least one kline")
+	}
+
+	return nil
+}
+
+func (s *candlestickSeries) Len() int {
+	return len(s.klines)
+}
+
+func (s *candlestickSeries) GetBoundedValues(index int) (x, y1, y2 float64) {
+	k := s.klines[index]
+	return timeToX(klineMiddleTime(k, s.interval)), k.Low.Float64(), k.High.Float64()
+}
+
+func (s *candlestickSeries) Render(r chart.Renderer, canvasBox chart.Box, xrange chart.Range, yrange chart.Range, _ chart.Style) {
+	if len(s.klines) == 0 {
+		return
+	}
+
+	// the body takes 60% of the space of a kline
+	halfWidth := int(float64(canvasBox.Width()) / float64(len(s.klines)) * 0.3)
+
+	for _, k := range s.klines {
+		color := upColor
+		if k.Close.Compare(k.Open) < 0 {
+			color = downColor
+		}
+
+		x := translateX(canvasBox, xrange, timeToX(klineMiddleTime(k, s.interval)))
+
+		r.SetStrokeColor(color)
+		r.SetFillColor(color)
+		r.SetStrokeWidth(1)
+		r.SetStrokeDashArray(nil)
+
+		// the wick
+		r.MoveTo(x, translateY(canvasBox, yrange, k.High.Float64()))
+		r.LineTo(x, translateY(canvasBox, yrange, k.Low.Float64()))
+		r.Stroke()
+
+		// the body
+		top := translateY(canvasBox, yrange, k.Open.Float64())
+		bottom := translateY(canvasBox, yrange, k.Close.Float64())
+		if top > bottom {
+			top, bottom = bottom, top
+		}
+
+		if halfWidth == 0 || top == bottom {
+			r.MoveTo(x-halfWidth, top)
+			r.LineTo(x+halfWidth, top)
+			r.Stroke()
+			continue
+		}
+
+		r.MoveTo(x-halfWidth, top)
+		r.LineTo(x+halfWidth, top)
+		r.LineTo(x+halfWidth, bottom)
+		r.LineTo(x-halfWidth, bottom)
+		r.Close()
+		r.FillStroke()
+	}
+}
+
+// tradeMarkerSeries draws the trades as triangles, the buy trades point up and the sell trades point down.
+type tradeMarkerSeries struct {
+	name   string
+	trades []types.Trade
+}
+
+var _ chart.Series = &tradeMarkerSeries{}
+var _ chart.BoundedValuesProvider = &tradeMarkerSeries{}
+
+func (s *tradeMarkerSeries) GetName() string {
+	return s.name
+}
+
+func (s *tradeMarkerSeries) GetYAxis() chart.YAxisType {
+	return chart.YAxisPrimary
+}
+
+func (s *tradeMarkerSeries) GetStyle() chart.Style {
+	return chart.Style{StrokeColor: chart.ColorBlue, StrokeWidth: 1}
+}
+
+func (s *tradeMarkerSeries) Validate() error {
+	return nil
+}
+
+func (s *tradeMarkerSeries) Len() int {
+	return len(s.trades)
+}
+
+func (s *tradeMarkerSeries) GetBoundedValues(index int) (x, y1, y2 float64) {
+	t := s.trades[index]
+	price := t.Price.Float64()
+	return timeToX(t.Time.Time()), price, price
+}
+
+func (s *tradeMarkerSeries) Render(r chart.Renderer, canvasBox chart.Box, xrange chart.Range, yrange chart.Range, _ chart.Style) {
+	r.SetStrokeWidth(1)
+	r.SetStrokeDashArray(nil)
+
+	for _, t := range s.trades {
+		color := sideColor(t.Side)
+		r.SetStrokeColor(chart.ColorBlack)
+		r.SetFillColor(color)
+
+		x := translateX(canvasBox, xrange, timeToX(t.Time.Time()))
+		y := translateY(canvasBox, yrange, t.Price.Float64())
+
+		// the tip of the triangle points to the trade price
+		base := y + markerSize
+		if t.Side == types.SideTypeSell {
+			base = y - markerSize
+		}
+
+		r.MoveTo(x, y)
+		r.LineTo(x+markerSize/2, base)
+		r.LineTo(x-markerSize/2, base)
+		r.Close()
+		r.FillStroke()
+	}
+}
+
+// orderLineSeries draws the open orders as the dashed lines from the creation time of the order to the right edge.
+type orderLineSeries struct {
+	name   string
+	orders []types.Order
+
+	// minX is the left edge of the lines, the orders created before the first kline start from here
+	minX float64
+}
+
+var _ chart.Series = &orderLineSeries{}
+var _ chart.BoundedValuesProvider = &orderLineSeries{}
+
+func (s *orderLineSeries) GetName() string {
+	return s.name
+}
+
+func (s *orderLineSeries) GetYAxis() chart.YAxisType {
+	return chart.YAxisPrimary
+}
+
+func (s *orderLineSeries) GetStyle() chart.Style {
+	return chart.Style{StrokeColor: chart.ColorOrange, StrokeWidth: 1, StrokeDashArray: dashArray}
+}
+
+func (s *orderLineSeries) Validate() error {
+	return nil
+}
+
+func (s *orderLineSeries) Len() int {
+	return len(s.orders)
+}
+
+func (s *orderLineSeries) GetBoundedValues(index int) (x, y1, y2 float64) {
+	price := s.orders[index].Price.Float64()
+	return s.startX(s.orders[index]), price, price
+}
+
+func (s *orderLineSeries) startX(order types.Order) float64 {
+	x := timeToX(order.CreationTime.Time())
+	if x < s.minX {
+		return s.minX
+	}
+
+	return x
+}
+
+func (s *orderLineSeries) Render(r chart.Renderer, canvasBox chart.Box, xrange chart.Range, yrange chart.Range, _ chart.Style) {
+	r.SetStrokeWidth(1)
+	r.SetStrokeDashArray(dashArray)
+	defer r.SetStrokeDashArray(nil)
+
+	for _, order := range s.orders {
+		r.SetStrokeColor(sideColor(order.Side))
+
+		y := translateY(canvasBox, yrange, order.Price.Float64())
+		r.MoveTo(translateX(canvasBox, xrange, s.startX(order)), y)
+		r.LineTo(canvasBox.Right, y)
+		r.Stroke()
+	}
+}
+
+// priceLineSeries draws a horizontal line across the chart, e.g. the average cost of the position.
+type priceLineSeries struct {
+	name  string
+	price float64
+	style chart.Style
+
+	// x is any x value inside the chart, it's used for calculating the range
+	x float64
+}
+
+var _ chart.Series = &priceLineSeries{}
+var _ chart.BoundedValuesProvider = &priceLineSeries{}
+
+func (s *priceLineSeries) GetName() string {
+	return s.name
+}
+
+func (s *priceLineSeries) GetYAxis() chart.YAxisType {
+	return chart.YAxisPrimary
+}
+
+func (s *priceLineSeries) GetStyle() chart.Style {
+	return s.style
+}
+
+func (s *priceLineSeries) Validate() error {
+	return nil
+}
+
+func (s *priceLineSeries) Len() int {
+	return 1
+}
+
+func (s *priceLineSeries) GetBoundedValues(_ int) (x, y1, y2 float64) {
+	return s.x, s.price, s.price
+}
+
+func (s *priceLineSeries) Render(r chart.Renderer, canvasBox chart.Box, _ chart.Range, yrange chart.Range, _ chart.Style) {
+	r.SetStrokeColor(s.style.StrokeColor)
+	r.SetStrokeWidth(s.style.StrokeWidth)
+	r.SetStrokeDashArray(s.style.StrokeDashArray)
+	defer r.SetStrokeDashArray(nil)
+
+	y := translateY(canvasBox, yrange, s.price)
+	r.MoveTo(canvasBox.Left, y)
+	r.LineTo(canvasBox.Right, y)
+	r.Stroke()
+}
+
+func klineMiddleTime(k types.KLine, interval types.Interval) time.Time {
+	return k.StartTime.Time().Add(interval.Duration() / 2)
+}
@@ -45,6 +45,7 @@ func init() {
 	BacktestCmd.Flags().Bool("force", false, "force execution without confirm")
 	BacktestCmd.Flags().String("output", "", "the report output directory")
 	BacktestCmd.Flags().Bool("subdir", false, "generate report in the sub-directory of the output directory")
+	BacktestCmd.Flags().Bool("export-charts", false, "export the kline charts of the traded symbols into the report output directory")
 	RootCmd.AddCommand(BacktestCmd)
 }
 
@@ -108,6 +109,11 @@ var BacktestCmd = &cobra.Command{
 			return err
 		}
 
+		exportingCharts, err := cmd.Flags().GetBool("export-charts")
+		if err != nil {
+			return err
+		}
+
 		syncOnly, err := cmd.Flags().GetBool("sync-only")
 		if err != nil {
 			return err
@@ -263,10 +269,22 @@ var BacktestCmd = &cobra.Command{
 			if err != nil {
 				return errors.Wrap(err, "failed to create backtest exchange")
 			}
-			session := environ.AddExchange(name.String(), backtestExchange)
+			backtestExchange.MarketTradeService = environ.MarketTradeService
+
+			var exchange types.Exchange = backtestExchange
 			exchangeFromConfig := userConfig.Sessions[name.String()]
+
+			// the simulated margin account supports the cross margin only
+			useMargin := exchangeFromConfig != nil && exchangeFromConfig.Margin && !exchangeFromConfig.IsolatedMargin
+			if useMargin {
+				exchange = backtest.NewMarginExchange(backtestExchange)
+			}
+
+			session := environ.AddExchange(name.String(), exchange)
 			if exchangeFromConfig != nil {
 				session.UseHeikinAshi = exchangeFromConfig.UseHeikinAshi
+				session.Margin = useMargin
+				session.MarginHealth = exchangeFromConfig.MarginHealth
 			}
 		}
 
@@ -276,7 +294,7 @@ var BacktestCmd = &cobra.Command{
 
 		for _, session := range environ.Sessions() {
 			userDataStream := session.UserDataStream.(types.StandardStreamEmitter)
-			backtestEx := session.Exchange.(*backtest.Exchange)
+			backtestEx, _ := backtest.UnwrapExchange(session.Exchange)
 			backtestEx.MarketDataStream = session.MarketDataStream.(types.StandardStreamEmitter)
 			backtestEx.BindUserData(userDataStream)
 		}
@@ -552,6 +570,12 @@ var BacktestCmd = &cobra.Command{
 					if err := util.WriteJsonFile(filepath.Join(reportDir, reportFileName), &symbolReport); err != nil {
 						return err
 					}
+
+					if exportingCharts {
+						if err := exportSymbolCharts(trader, session, symbol, symbolReport.Intervals, reportDir); err != nil {
+							log.WithError(err).Warnf("unable to export the %s charts of session %s", symbol, session.Name)
+						}
+					}
 				}
 			}
 		}
@@ -601,7 +625,7 @@ func createSymbolReport(userConfig *bbgo.Config, session *bbgo.ExchangeSession,
 	*backtest.SessionSymbolReport,
 	error,
 ) {
-	backtestExchange, ok := session.Exchange.(*backtest.Exchange)
+	backtestExchange, ok := backtest.UnwrapExchange(session.Exchange)
 	if !ok {
 		return nil, fmt.Errorf("unexpected error, exchange instance is not a backtest exchange")
 	}
@@ -665,6 +689,24 @@ func createSymbolReport(userConfig *bbgo.Config, session *bbgo.ExchangeSession,
 	return &symbolReport, nil
 }
 
+// exportSymbolCharts renders the kline charts of the symbol with the trades and the strategy overlays,
+// one PNG file per subscribed interval.
+func exportSymbolCharts(trader *bbgo.Trader, session *bbgo.ExchangeSession, symbol string, intervals []types.Interval, reportDir string) error {
+	for _, interval := range intervals {
+		klines := bbgo.SessionKLines(session, symbol, interval, 0)
+		if len(klines) < 2 {
+			continue
+		}
+
+		chartFile := filepath.Join(reportDir, fmt.Sprintf("chart_%s_%s_%s.png", session.Name, symbol, interval))
+		if err := trader.SessionChart(session, symbol, interval, klines).RenderFile(chartFile); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func verify(userConfig *bbgo.Config, backtestService *service.BacktestService, sourceExchanges map[types.ExchangeName]types.Exchange, startTime, endTime time.Time) error {
 	for _, sourceExchange := range sourceExchanges {
 		err := backtestService.Verify(sourceExchange, userConfig.Backtest.Symbols, startTime, endTime)
@@ -699,7 +741,7 @@ func confirmation(s string) bool {
 
 func toExchangeSources(sessions map[string]*bbgo.ExchangeSession, startTime, endTime time.Time, extraIntervals ...types.Interval) (exchangeSources []*backtest.ExchangeDataSource, err error) {
 	for _, session := range sessions {
-		backtestEx := session.Exchange.(*backtest.Exchange)
+		backtestEx, _ := backtest.UnwrapExchange(session.Exchange)
 
 		c, err := backtestEx.SubscribeMarketData(startTime, endTime, extraIntervals...)
 		if err != nil {
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// go run ./cmd/bbgo execute --session=binance --symbol=BTCUSDT --side=buy --quantity=1 --algo=vwap --duration=4h
+var executeCmd = &cobra.Command{
+	Use:          "execute --session SESSION --symbol SYMBOL --side SIDE --quantity QUANTITY --algo vwap|pov|iceberg",
+	Short:        "execute a parent order with the execution algorithm and report the implementation shortfall",
+	SilenceUsage: true,
+	PreRunE: cobraInitRequired([]string{
+		"session",
+		"symbol",
+		"side",
+		"quantity",
+		"algo",
+	}),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
+			return err
+		}
+
+		symbol, err := cmd.Flags().GetString("symbol")
+		if err != nil {
+			return fmt.Errorf("can not get the symbol from flags: %w", err)
+		}
+
+		sideS, err := cmd.Flags().GetString("side")
+		if err != nil {
+			return fmt.Errorf("can't get side: %w", err)
+		}
+
+		side, err := types.StrToSideType(sideS)
+		if err != nil {
+			return err
+		}
+
+		quantity, err := getFixedPointFlag(cmd, "quantity")
+		if err != nil {
+			return err
+		}
+
+		limitPrice, err := getFixedPointFlag(cmd, "limit-price")
+		if err != nil {
+			return err
+		}
+
+		aggressive, err := cmd.Flags().GetBool("aggressive")
+		if err != nil {
+			return err
+		}
+
+		updateInterval, err := cmd.Flags().GetDuration("update-interval")
+		if err != nil {
+			return err
+		}
+
+		deadlineDuration, err := cmd.Flags().GetDuration("deadline")
+		if err != nil {
+			return err
+		}
+
+		var deadlineTime time.Time
+		if deadlineDuration > 0 {
+			deadlineTime = time.Now().Add(deadlineDuration)
+		}
+
+		algoName, err := cmd.Flags().GetString("algo")
+		if err != nil {
+			return err
+		}
+
+		environ := bbgo.NewEnvironment()
+		if algoName == "vwap" {
+			if err := environ.ConfigureDatabase(ctx); err != nil {
+				return err
+			}
+		}
+
+		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+			return err
+		}
+
+		if err := environ.Init(ctx); err != nil {
+			return err
+		}
+
+		session, ok := environ.Session(sessionName)
+		if !ok {
+			return fmt.Errorf("session %s not found", sessionName)
+		}
+
+		algo, err := newExecutionAlgo(cmd, algoName, environ, session, symbol)
+		if err != nil {
+			return err
+		}
+
+		executionCtx, cancelExecution := context.WithCancel(ctx)
+		defer cancelExecution()
+
+		execution := &bbgo.AlgoExecution{
+			Session:        session,
+			Symbol:         symbol,
+			Side:           side,
+			Quantity:       quantity,
+			Algo:           algo,
+			LimitPrice:     limitPrice,
+			Aggressive:     aggressive,
+			UpdateInterval: updateInterval,
+			DeadlineTime:   deadlineTime,
+		}
+
+		execution.OnChildOrder(func(order types.Order) {
+			log.Infof("child order submitted: %s", order.String())
+		})
+
+		if err := execution.Run(executionCtx); err != nil {
+			return err
+		}
+
+		var sigC = make(chan os.Signal, 1)
+		signal.Notify(sigC, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(sigC)
+
+		select {
+		case sig := <-sigC:
+			log.Warnf("signal %v", sig)
+			log.Infof("shutting down order executor...")
+			shutdownCtx, cancelShutdown := context.WithDeadline(ctx, time.Now().Add(10*time.Second))
+			execution.Shutdown(shutdownCtx)
+			cancelShutdown()
+
+		case <-execution.Done():
+			log.Infof("the order execution is completed")
+
+		case <-ctx.Done():
+
+		}
+
+		report := execution.Report()
+		fmt.Println(report.String())
+		return nil
+	},
+}
+
+func newExecutionAlgo(cmd *cobra.Command, algoName string, environ *bbgo.Environment, session *bbgo.ExchangeSession, symbol string) (bbgo.ExecutionAlgo, error) {
+	switch algoName {
+	case "vwap":
+		duration, err := cmd.Flags().GetDuration("duration")
+		if err != nil {
+			return nil, err
+		}
+
+		if duration <= 0 {
+			return nil, fmt.Errorf("--duration is required for the vwap algo")
+		}
+
+		profileDays, err := cmd.Flags().GetInt("profile-days")
+		if err != nil {
+			return nil, err
+		}
+
+		intervalS, err := cmd.Flags().GetString("profile-interval")
+		if err != nil {
+			return nil, err
+		}
+
+		interval := types.Interval(intervalS)
+		if _, ok := types.SupportedIntervals[interval]; !ok {
+			return nil, fmt.Errorf("invalid profile interval %s", intervalS)
+		}
+
+		if environ.DatabaseService == nil {
+			return nil, fmt.Errorf("database is not configured, the vwap algo requires the klines in the database")
+		}
+
+		backtestService := &service.BacktestService{DB: environ.DatabaseService.DB}
+		limit := profileDays * 24 * 60 / interval.Minutes()
+		klines, err := backtestService.QueryKLinesBackward(session.ExchangeName, symbol, interval, time.Now(), limit)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(klines) == 0 {
+			return nil, fmt.Errorf("%s %s klines not found, please sync the klines by the backtest command first", symbol, interval)
+		}
+
+		log.Infof("loaded %d %s %s klines for the volume profile", len(klines), symbol, interval)
+		return &bbgo.VWAPAlgo{
+			Profile:  bbgo.NewVolumeProfile(klines, interval),
+			Duration: duration,
+		}, nil
+
+	case "pov":
+		rate, err := getFixedPointFlag(cmd, "participation-rate")
+		if err != nil {
+			return nil, err
+		}
+
+		if rate.Sign() <= 0 || rate.Compare(fixedpoint.One) > 0 {
+			return nil, fmt.Errorf("--participation-rate should be in (0, 1]")
+		}
+
+		return &bbgo.POVAlgo{ParticipationRate: rate}, nil
+
+	case "iceberg":
+		clip, err := getFixedPointFlag(cmd, "clip")
+		if err != nil {
+			return nil, err
+		}
+
+		if clip.Sign() <= 0 {
+			return nil, fmt.Errorf("--clip is required for the iceberg algo")
+		}
+
+		variance, err := getFixedPointFlag(cmd, "clip-variance")
+		if err != nil {
+			return nil, err
+		}
+
+		return &bbgo.IcebergAlgo{
+			ClipQuantity: clip,
+			ClipVariance: variance,
+			Rand:         rand.New(rand.NewSource(time.Now().UnixNano())),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported execution algo: %s", algoName)
+}
+
+func getFixedPointFlag(cmd *cobra.Command, name string) (fixedpoint.Value, error) {
+	s, err := cmd.Flags().GetString(name)
+	if err != nil {
+		return fixedpoint.Zero, err
+	}
+
+	if s == "" {
+		return fixedpoint.Zero, nil
+	}
+
+	return fixedpoint.NewFromString(s)
+}
+
+func init() {
+	executeCmd.Flags().String("session", "", "the exchange session name for the execution")
+	executeCmd.Flags().String("symbol", "", "the trading pair, like btcusdt")
+	executeCmd.Flags().String("side", "", "the trading side: buy or sell")
+	executeCmd.Flags().String("quantity", "", "the quantity of the parent order")
+	executeCmd.Flags().String("algo", "", "the execution algorithm: vwap, pov or iceberg")
+	executeCmd.Flags().String("limit-price", "", "the worst price of the child orders")
+	executeCmd.Flags().Bool("aggressive", false, "take the liquidity by IOC orders instead of joining the best price")
+	executeCmd.Flags().Duration("update-interval", time.Second*10, "the interval of the schedule update")
+	executeCmd.Flags().Duration("deadline", 0, "execute the remaining quantity by a market order after the deadline")
+	executeCmd.Flags().Duration("duration", 0, "the duration of the vwap schedule")
+	executeCmd.Flags().Int("profile-days", 7, "the number of days of the stored klines for the vwap volume profile")
+	executeCmd.Flags().String("profile-interval", "1h", "the kline interval of the vwap volume profile")
+	executeCmd.Flags().String("participation-rate", "0.1", "the participation rate of the market volume for the pov algo")
+	executeCmd.Flags().String("clip", "", "the clip quantity for the iceberg algo")
+	executeCmd.Flags().String("clip-variance", "0.2", "the random variance of the iceberg clip quantity")
+
+	RootCmd.AddCommand(executeCmd)
+}
@@ -394,6 +394,151 @@ var submitOrderCmd = &cobra.Command{
 	},
 }
 
+// go run ./cmd/bbgo route-order --sessions binance,max --symbol BTCUSDT --side buy --quantity 1 --dry-run
+var routeOrderCmd = &cobra.Command{
+	Use:          "route-order --sessions SESSION,SESSION --symbol SYMBOL --side SIDE --quantity QUANTITY [--price PRICE]",
+	Short:        "route the order across the sessions by their order books, fee rates and balances",
+	SilenceUsage: true,
+	PreRunE: cobraInitRequired([]string{
+		"sessions",
+		"symbol",
+		"side",
+		"quantity",
+	}),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		sessionNames, err := cmd.Flags().GetStringSlice("sessions")
+		if err != nil {
+			return err
+		}
+
+		symbol, err := cmd.Flags().GetString("symbol")
+		if err != nil {
+			return fmt.Errorf("can't get the symbol from flags: %w", err)
+		}
+
+		side, err := cmd.Flags().GetString("side")
+		if err != nil {
+			return fmt.Errorf("can not get side: %w", err)
+		}
+
+		price, err := cmd.Flags().GetString("price")
+		if err != nil {
+			return fmt.Errorf("can not get price: %w", err)
+		}
+
+		quantity, err := cmd.Flags().GetString("quantity")
+		if err != nil {
+			return fmt.Errorf("can not get quantity: %w", err)
+		}
+
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return err
+		}
+
+		maxRounds, err := cmd.Flags().GetInt("max-rounds")
+		if err != nil {
+			return err
+		}
+
+		environ := bbgo.NewEnvironment()
+		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+			return err
+		}
+
+		var sessions []*bbgo.ExchangeSession
+		for _, name := range sessionNames {
+			session, ok := environ.Session(name)
+			if !ok {
+				return fmt.Errorf("session %s not found", name)
+			}
+
+			session.Subscribe(types.BookChannel, symbol, types.SubscribeOptions{})
+			sessions = append(sessions, session)
+		}
+
+		if err := environ.Init(ctx); err != nil {
+			return err
+		}
+
+		if err := environ.Connect(ctx); err != nil {
+			return err
+		}
+
+		if err := waitOrderBooks(ctx, sessions, symbol, 10*time.Second); err != nil {
+			return err
+		}
+
+		so := types.SubmitOrder{
+			Symbol:   symbol,
+			Side:     types.SideType(strings.ToUpper(side)),
+			Type:     types.OrderTypeMarket,
+			Quantity: fixedpoint.MustNewFromString(quantity),
+		}
+
+		if len(price) > 0 {
+			so.Type = types.OrderTypeLimit
+			so.Price = fixedpoint.MustNewFromString(price)
+		}
+
+		router := bbgo.NewSmartOrderRouter(sessions...)
+		router.MaxRounds = maxRounds
+
+		route, err := router.Route(so)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("route: %s", route)
+		log.Infof("estimated cost: %v", route.Cost())
+
+		if dryRun {
+			return nil
+		}
+
+		result, err := router.Execute(ctx, so)
+		for _, order := range result.Orders {
+			log.Infof("routed order: %s", order.String())
+		}
+
+		log.Infof("executed %v, remaining %v in %d rounds", result.Executed, result.Remaining, result.Rounds)
+		return err
+	},
+}
+
+// waitOrderBooks waits for the order books of the symbol to be loaded
+func waitOrderBooks(ctx context.Context, sessions []*bbgo.ExchangeSession, symbol string, timeout time.Duration) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		loaded := 0
+		for _, session := range sessions {
+			if book, ok := session.OrderBook(symbol); ok {
+				if _, _, ok := book.BestBidAndAsk(); ok {
+					loaded++
+				}
+			}
+		}
+
+		if loaded == len(sessions) {
+			return nil
+		}
+
+		select {
+		case <-timeoutCtx.Done():
+			return fmt.Errorf("%s order books are not loaded: %w", symbol, timeoutCtx.Err())
+
+		case <-ticker.C:
+		}
+	}
+}
+
 func init() {
 	listOrdersCmd.Flags().String("session", "", "the exchange session name for sync")
 	listOrdersCmd.Flags().String("symbol", "", "the trading pair, like btcusdt")
@@ -420,8 +565,17 @@ func init() {
 	executeOrderCmd.Flags().Duration("deadline", 0, "deadline of the order execution")
 	executeOrderCmd.Flags().Int("price-ticks", 0, "the number of price tick for the jump spread, default to 0")
 
+	routeOrderCmd.Flags().StringSlice("sessions", nil, "the exchange sessions to route the order to")
+	routeOrderCmd.Flags().String("symbol", "", "the trading pair, like btcusdt")
+	routeOrderCmd.Flags().String("side", "", "the trading side: buy or sell")
+	routeOrderCmd.Flags().String("price", "", "the limit price, the price levels beyond the limit price are not routed")
+	routeOrderCmd.Flags().String("quantity", "", "the trading quantity")
+	routeOrderCmd.Flags().Bool("dry-run", false, "show the route without submitting the orders")
+	routeOrderCmd.Flags().Int("max-rounds", 3, "the max number of the routing rounds for the unfilled quantity")
+
 	RootCmd.AddCommand(listOrdersCmd)
 	RootCmd.AddCommand(getOrderCmd)
 	RootCmd.AddCommand(submitOrderCmd)
 	RootCmd.AddCommand(executeOrderCmd)
+	RootCmd.AddCommand(routeOrderCmd)
 }
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 	"time"
@@ -24,13 +26,17 @@ func init() {
 	PnLCmd.Flags().Bool("sync", false, "sync before loading trades")
 	PnLCmd.Flags().String("since", "", "query trades from a time point")
 	PnLCmd.Flags().Uint64("limit", 0, "number of trades")
+	PnLCmd.Flags().String("method", string(pnl.CostBasisMethodAverageCost), "cost basis method: avg, fifo, lifo, hifo or specific")
+	PnLCmd.Flags().String("specific-lots", "", "a JSON file maps the closing trade ID to the opening trade IDs for the specific method, e.g. {\"123\": [100, 101]}")
+	PnLCmd.Flags().String("fiat", "", "convert the realized PnL into the fiat currency with the daily klines, e.g. USD or TWD")
+	PnLCmd.Flags().String("ledger", "", "export the lot ledger to the file (.csv, .tsv or .json)")
 	RootCmd.AddCommand(PnLCmd)
 }
 
 var PnLCmd = &cobra.Command{
 	Use:          "pnl",
-	Short:        "Average Cost Based PnL Calculator",
-	Long:         "This command calculates the average cost-based profit from your total trades",
+	Short:        "PnL Calculator",
+	Long:         "This command calculates the profit from your total trades with the average cost, FIFO, LIFO, HIFO or specific lot method",
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
@@ -86,6 +92,35 @@ var PnLCmd = &cobra.Command{
 			return err
 		}
 
+		methodOpt, err := cmd.Flags().GetString("method")
+		if err != nil {
+			return err
+		}
+
+		method := pnl.CostBasisMethod(methodOpt)
+		if !method.IsSupported() {
+			return fmt.Errorf("unsupported cost basis method %q", methodOpt)
+		}
+
+		fiatCurrency, err := cmd.Flags().GetString("fiat")
+		if err != nil {
+			return err
+		}
+
+		ledgerFile, err := cmd.Flags().GetString("ledger")
+		if err != nil {
+			return err
+		}
+
+		if method == pnl.CostBasisMethodAverageCost && (ledgerFile != "" || fiatCurrency != "") {
+			return errors.New("--ledger and --fiat require a lot-based --method, e.g. fifo")
+		}
+
+		specificLots, err := loadSpecificLots(cmd)
+		if err != nil {
+			return err
+		}
+
 		environ := bbgo.NewEnvironment()
 
 		if err := environ.ConfigureDatabase(ctx); err != nil {
@@ -190,16 +225,104 @@ var PnLCmd = &cobra.Command{
 		}
 
 		currentPrice := currentTick.Last
-		calculator := &pnl.AverageCostCalculator{
-			TradingFeeCurrency: tradingFeeCurrency,
-			Market:             market,
-		}
+		if method == pnl.CostBasisMethodAverageCost {
+			calculator := &pnl.AverageCostCalculator{
+				TradingFeeCurrency: tradingFeeCurrency,
+				Market:             market,
+			}
 
-		report := calculator.Calculate(symbol, trades, currentPrice)
-		report.Print()
+			report := calculator.Calculate(symbol, trades, currentPrice)
+			report.Print()
+		} else {
+			backtestService := &service.BacktestService{DB: environ.DatabaseService.DB}
+			if wantSync {
+				if err := syncPriceKLines(ctx, backtestService, session, market, fiatCurrency, trades, since, until); err != nil {
+					return err
+				}
+			}
+
+			calculator := &pnl.LotCalculator{
+				Method:       method,
+				Market:       market,
+				SpecificLots: specificLots,
+				FeePrice:     pnl.NewKLinePriceFunc(backtestService, exchange.Name(), market.QuoteCurrency),
+				FiatCurrency: fiatCurrency,
+				FiatPrice:    pnl.NewKLinePriceFunc(backtestService, exchange.Name(), fiatCurrency),
+			}
+
+			report, err := calculator.Calculate(symbol, trades, currentPrice)
+			if err != nil {
+				return err
+			}
+
+			report.Print()
+
+			if ledgerFile != "" {
+				if err := writeRecordsFile(ledgerFile, report.Ledger); err != nil {
+					return err
+				}
+
+				log.Infof("%d lot ledger entries are exported to %s", len(report.Ledger), ledgerFile)
+			}
+		}
 
 		log.Warnf("note that if you're using cross-exchange arbitrage, the PnL won't be accurate")
 		log.Warnf("withdrawal and deposits are not considered in the PnL")
 		return nil
 	},
 }
+
+func loadSpecificLots(cmd *cobra.Command) (map[uint64][]uint64, error) {
+	filename, err := cmd.Flags().GetString("specific-lots")
+	if err != nil || filename == "" {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var specificLots map[uint64][]uint64
+	if err := json.Unmarshal(content, &specificLots); err != nil {
+		return nil, fmt.Errorf("can not parse the specific lots file %s: %w", filename, err)
+	}
+
+	return specificLots, nil
+}
+
+// syncPriceKLines syncs the daily klines for converting the fees and the quote currency values
+func syncPriceKLines(ctx context.Context, backtestService *service.BacktestService, session *bbgo.ExchangeSession, market types.Market, fiatCurrency string, trades []types.Trade, since, until time.Time) error {
+	var pairs [][2]string
+	if fiatCurrency != "" && fiatCurrency != market.QuoteCurrency {
+		pairs = append(pairs, [2]string{market.QuoteCurrency, fiatCurrency})
+	}
+
+	feeCurrencies := map[string]struct{}{}
+	for _, trade := range trades {
+		if trade.FeeCurrency == "" || trade.FeeCurrency == market.BaseCurrency || trade.FeeCurrency == market.QuoteCurrency {
+			continue
+		}
+
+		if _, ok := feeCurrencies[trade.FeeCurrency]; !ok {
+			feeCurrencies[trade.FeeCurrency] = struct{}{}
+			pairs = append(pairs, [2]string{trade.FeeCurrency, market.QuoteCurrency})
+		}
+	}
+
+	for _, pair := range pairs {
+		for _, symbol := range []string{pair[0] + pair[1], pair[1] + pair[0]} {
+			if _, ok := session.Market(symbol); !ok {
+				continue
+			}
+
+			log.Infof("syncing %s daily klines for the price conversion", symbol)
+			if err := backtestService.Sync(ctx, session.Exchange, symbol, types.Interval1d, since.AddDate(0, 0, -1), until); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/cmd/cmdutil"
+	"github.com/c9s/bbgo/pkg/record"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultRecordDirectory = "data/record"
+
+func init() {
+	RecordCmd.Flags().String("session", "", "only record the given session")
+	RecordCmd.Flags().StringArray("symbol", []string{}, "the symbols to record, overrides the symbols of the record config")
+	RecordCmd.Flags().String("dir", "", "the directory of the record files, default: "+defaultRecordDirectory)
+	RecordCmd.Flags().String("rotation", "", "the time span of each record file, e.g., 1h, 1d")
+	RecordCmd.Flags().Bool("db", false, "insert the market trades into the database too")
+
+	recordImportCmd.Flags().String("session", "", "the session name of the records")
+	recordImportCmd.Flags().String("symbol", "", "the symbol of the records")
+	recordImportCmd.Flags().String("dir", defaultRecordDirectory, "the directory of the record files")
+	recordImportCmd.Flags().String("since", "", "import the records since the given time")
+	recordImportCmd.Flags().String("until", "", "import the records until the given time")
+
+	RecordCmd.AddCommand(recordImportCmd)
+	RootCmd.AddCommand(RecordCmd)
+}
+
+// go run ./cmd/bbgo record --config config/record.yaml
+// go run ./cmd/bbgo record --session binance --symbol BTCUSDT --rotation 1h
+var RecordCmd = &cobra.Command{
+	Use:          "record [--session=[exchange_name]] [--symbol=[pair_name]] [--dir=[directory]] [--rotation=[interval]] [--db]",
+	Short:        "record the market trades, book tickers and depth updates for replaying",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		config := &bbgo.RecordConfig{}
+		if userConfig.Record != nil {
+			*config = *userConfig.Record
+		}
+
+		if err := applyRecordFlags(cmd, config); err != nil {
+			return err
+		}
+
+		environ := bbgo.NewEnvironment()
+		if config.Database {
+			if err := environ.ConfigureDatabase(ctx); err != nil {
+				return err
+			}
+
+			if environ.MarketTradeService == nil {
+				return errors.New("database is not configured, the market trades can not be inserted")
+			}
+		}
+
+		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+			return err
+		}
+
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
+			return err
+		}
+
+		var sessionNames []string
+		for name := range environ.Sessions() {
+			if sessionName == "" || sessionName == name {
+				sessionNames = append(sessionNames, name)
+			}
+		}
+
+		writer := record.NewFileWriter(config.Directory, config.Rotation)
+
+		var recorders []*record.Recorder
+		var streams []types.Stream
+		for name, symbols := range config.SessionSymbols(sessionNames) {
+			if sessionName != "" && sessionName != name {
+				continue
+			}
+
+			session, ok := environ.Session(name)
+			if !ok {
+				return fmt.Errorf("session %s not found", name)
+			}
+
+			stream := session.Exchange.NewStream()
+			stream.SetPublicOnly()
+
+			recorder := record.NewRecorder(name, symbols, writer)
+			recorder.MarketTradeService = environ.MarketTradeService
+			recorder.Subscribe(stream, config.Channels, config.Depth)
+			recorder.BindStream(stream)
+
+			log.Infof("recording %s %v channels %v into %s", name, symbols, config.Channels, config.Directory)
+			recorders = append(recorders, recorder)
+			streams = append(streams, stream)
+		}
+
+		if len(streams) == 0 {
+			return errors.New("no symbol to record, please define the record symbols in the config or use the --symbol option")
+		}
+
+		for _, stream := range streams {
+			if err := stream.Connect(ctx); err != nil {
+				return err
+			}
+		}
+
+		// flush the gzip buffers periodically, so that the recorded data is not lost on crash
+		go func() {
+			ticker := time.NewTicker(10 * time.Second)
+			defer ticker.Stop()
+			for range ticker.C {
+				if err := writer.Flush(); err != nil {
+					log.WithError(err).Error("record flush error")
+				}
+			}
+		}()
+
+		cmdutil.WaitForSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+
+		for _, stream := range streams {
+			if err := stream.Close(); err != nil {
+				log.WithError(err).Errorf("stream close error")
+			}
+		}
+
+		for _, recorder := range recorders {
+			recorder.Stop()
+		}
+
+		return writer.Close()
+	},
+}
+
+func applyRecordFlags(cmd *cobra.Command, config *bbgo.RecordConfig) error {
+	symbols, err := cmd.Flags().GetStringArray("symbol")
+	if err != nil {
+		return err
+	}
+
+	if len(symbols) > 0 {
+		config.Symbols = nil
+		for _, symbol := range symbols {
+			config.Symbols = append(config.Symbols, bbgo.SyncSymbol{Symbol: symbol})
+		}
+	}
+
+	if dir, err := cmd.Flags().GetString("dir"); err != nil {
+		return err
+	} else if dir != "" {
+		config.Directory = dir
+	}
+
+	if rotation, err := cmd.Flags().GetString("rotation"); err != nil {
+		return err
+	} else if rotation != "" {
+		config.Rotation = types.Interval(rotation)
+	}
+
+	if db, err := cmd.Flags().GetBool("db"); err != nil {
+		return err
+	} else if db {
+		config.Database = true
+	}
+
+	if config.Directory == "" {
+		config.Directory = defaultRecordDirectory
+	}
+
+	if config.Rotation == "" {
+		config.Rotation = types.Interval1h
+	}
+
+	if _, ok := types.SupportedIntervals[config.Rotation]; !ok {
+		return fmt.Errorf("unsupported rotation interval %s", config.Rotation)
+	}
+
+	if len(config.Channels) == 0 {
+		config.Channels = []types.Channel{types.MarketTradeChannel, types.BookTickerChannel, types.BookChannel}
+	}
+
+	return nil
+}
+
+// go run ./cmd/bbgo record import --session binance --symbol BTCUSDT --since 2022-06-01
+var recordImportCmd = &cobra.Command{
+	Use:          "import --session=[exchange_name] --symbol=[pair_name] [--since=yyyy-mm-dd] [--until=yyyy-mm-dd]",
+	Short:        "import the recorded market trades into the database for backtest",
+	SilenceUsage: true,
+	PreRunE: cobraInitRequired([]string{
+		"session",
+		"symbol",
+	}),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
+			return err
+		}
+
+		symbol, err := cmd.Flags().GetString("symbol")
+		if err != nil {
+			return err
+		}
+
+		dir, err := cmd.Flags().GetString("dir")
+		if err != nil {
+			return err
+		}
+
+		var since, until time.Time
+		sinceOpt, err := cmd.Flags().GetString("since")
+		if err != nil {
+			return err
+		}
+
+		if sinceOpt != "" {
+			lt, err := types.ParseLooseFormatTime(sinceOpt)
+			if err != nil {
+				return err
+			}
+			since = lt.Time()
+		}
+
+		untilOpt, err := cmd.Flags().GetString("until")
+		if err != nil {
+			return err
+		}
+
+		if untilOpt != "" {
+			lt, err := types.ParseLooseFormatTime(untilOpt)
+			if err != nil {
+				return err
+			}
+			until = lt.Time()
+		}
+
+		environ := bbgo.NewEnvironment()
+		if err := environ.ConfigureDatabase(ctx); err != nil {
+			return err
+		}
+
+		if environ.MarketTradeService == nil {
+			return errors.New("database is not configured")
+		}
+
+		paths, err := record.Files(dir, sessionName, symbol, since, until)
+		if err != nil {
+			return err
+		}
+
+		var numOfTrades int
+		for _, path := range paths {
+			log.Infof("importing %s", path)
+			err := record.ReadFile(path, func(event record.Event) error {
+				if event.Type != record.EventTypeMarketTrade || event.Trade == nil {
+					return nil
+				}
+
+				tradeTime := event.Trade.Time.Time()
+				if (!since.IsZero() && tradeTime.Before(since)) || (!until.IsZero() && tradeTime.After(until)) {
+					return nil
+				}
+
+				// the trades might be inserted by the recorder already
+				if err := environ.MarketTradeService.Insert(*event.Trade); err != nil {
+					log.WithError(err).Debugf("market trade insert error: %+v", event.Trade)
+					return nil
+				}
+
+				numOfTrades++
+				return nil
+			})
+
+			if err != nil {
+				return fmt.Errorf("can not read the record file %s: %w", path, err)
+			}
+		}
+
+		log.Infof("%d market trades are imported", numOfTrades)
+		return nil
+	},
+}
@@ -0,0 +1,353 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/accounting/ledger"
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	ReportLedgerCmd.Flags().StringArray("session", []string{}, "target exchange sessions, all sessions are used if it's not given")
+	ReportLedgerCmd.Flags().Int("year", 0, "the tax year of the report, e.g. 2025")
+	ReportLedgerCmd.Flags().String("fiat", "USDT", "the fiat currency for the valuation")
+	ReportLedgerCmd.Flags().String("method", string(pnl.CostBasisMethodFIFO), "cost basis method of the realized gains: fifo, lifo or hifo")
+	ReportLedgerCmd.Flags().String("output", "", "the ledger file (.csv, .tsv or .json), default ledger-{year}.csv")
+	ReportLedgerCmd.Flags().String("summary", "", "the per-asset summary file (.csv, .tsv or .json), default ledger-{year}-summary.csv")
+	ReportLedgerCmd.Flags().Bool("sync", false, "sync the sessions and the daily klines for the valuation before generating the report")
+	ReportCmd.AddCommand(ReportLedgerCmd)
+	RootCmd.AddCommand(ReportCmd)
+}
+
+var ReportCmd = &cobra.Command{
+	Use:          "report",
+	Short:        "Generate reports from the synced history",
+	SilenceUsage: true,
+}
+
+// go run ./cmd/bbgo report ledger --year 2025 --fiat USD
+var ReportLedgerCmd = &cobra.Command{
+	Use:   "ledger",
+	Short: "Export a double-entry ledger of trades, fees, transfers, rewards and margin records for a tax year",
+	Long: "This command merges the synced trades, fees, deposits, withdrawals, rewards, margin loans, repays and interests " +
+		"into a double-entry ledger with the fiat values at the time of each entry, and the realized gains are calculated " +
+		"with the tax lots of all the synced trades",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		year, err := cmd.Flags().GetInt("year")
+		if err != nil {
+			return err
+		}
+
+		if year <= 0 {
+			return errors.New("--year [YEAR] is required")
+		}
+
+		sessionNames, err := cmd.Flags().GetStringArray("session")
+		if err != nil {
+			return err
+		}
+
+		fiatCurrency, err := cmd.Flags().GetString("fiat")
+		if err != nil {
+			return err
+		}
+
+		methodOpt, err := cmd.Flags().GetString("method")
+		if err != nil {
+			return err
+		}
+
+		method := pnl.CostBasisMethod(methodOpt)
+		if !method.IsSupported() || method == pnl.CostBasisMethodAverageCost || method == pnl.CostBasisMethodSpecific {
+			return fmt.Errorf("unsupported cost basis method %q, use fifo, lifo or hifo", methodOpt)
+		}
+
+		outputFile, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		if outputFile == "" {
+			outputFile = fmt.Sprintf("ledger-%d.csv", year)
+		}
+
+		summaryFile, err := cmd.Flags().GetString("summary")
+		if err != nil {
+			return err
+		}
+
+		if summaryFile == "" {
+			summaryFile = fmt.Sprintf("ledger-%d-summary.csv", year)
+		}
+
+		wantSync, err := cmd.Flags().GetBool("sync")
+		if err != nil {
+			return err
+		}
+
+		since := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+		until := since.AddDate(1, 0, 0)
+
+		environ := bbgo.NewEnvironment()
+
+		if err := environ.ConfigureDatabase(ctx); err != nil {
+			return err
+		}
+
+		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+			return err
+		}
+
+		sessions, err := selectReportSessions(environ, sessionNames)
+		if err != nil {
+			return err
+		}
+
+		if wantSync {
+			for _, session := range sessions {
+				if err := environ.SyncSession(ctx, session); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := environ.Init(ctx); err != nil {
+			return err
+		}
+
+		backtestService := &service.BacktestService{DB: environ.DatabaseService.DB}
+
+		var priceFuncs []pnl.PriceFunc
+		for _, session := range sessions {
+			priceFuncs = append(priceFuncs, pnl.NewKLinePriceFunc(backtestService, session.ExchangeName, fiatCurrency))
+		}
+
+		// the price is looked up from the sessions in order
+		fiatPrice := func(currency string, t time.Time) (fixedpoint.Value, bool) {
+			for _, priceFunc := range priceFuncs {
+				if price, ok := priceFunc(currency, t); ok {
+					return price, true
+				}
+			}
+			return fixedpoint.Zero, false
+		}
+
+		builder := ledger.NewBuilder(fiatCurrency, fiatPrice, method, since, until)
+
+		for _, session := range sessions {
+			exchangeName := session.ExchangeName
+
+			// all the trades before the year are loaded for the cost basis
+			trades, err := environ.TradeService.Query(service.QueryTradesOptions{
+				Exchange: exchangeName,
+				Until:    &until,
+			})
+			if err != nil {
+				return err
+			}
+
+			deposits, err := environ.DepositService.Query(exchangeName)
+			if err != nil {
+				return err
+			}
+
+			withdraws, err := environ.WithdrawService.Query(exchangeName)
+			if err != nil {
+				return err
+			}
+
+			rewards, err := environ.RewardService.QueryByTimeRange(ctx, exchangeName, since, until)
+			if err != nil {
+				return err
+			}
+
+			loans, err := environ.MarginService.QueryLoans(ctx, exchangeName, since, until)
+			if err != nil {
+				return err
+			}
+
+			repays, err := environ.MarginService.QueryRepays(ctx, exchangeName, since, until)
+			if err != nil {
+				return err
+			}
+
+			interests, err := environ.MarginService.QueryInterests(ctx, exchangeName, since, until)
+			if err != nil {
+				return err
+			}
+
+			log.Infof("%s: %d trades, %d deposits, %d withdrawals, %d rewards, %d loans, %d repays and %d interests loaded",
+				exchangeName, len(trades), len(deposits), len(withdraws), len(rewards), len(loans), len(repays), len(interests))
+
+			if wantSync {
+				assets := reportAssets(session.Markets(), trades, deposits, withdraws, rewards, loans, interests)
+				from := since
+				if len(trades) > 0 && trades[0].Time.Time().Before(from) {
+					from = trades[0].Time.Time()
+				}
+
+				if err := syncFiatKLines(ctx, backtestService, session, assets, fiatCurrency, from, until); err != nil {
+					return err
+				}
+			}
+
+			if err := builder.AddTrades(session.Markets(), trades); err != nil {
+				return err
+			}
+
+			builder.AddDeposits(deposits)
+			builder.AddWithdraws(withdraws)
+			builder.AddRewards(rewards)
+			builder.AddMarginLoans(loans)
+			builder.AddMarginRepays(repays)
+			builder.AddMarginInterests(interests)
+		}
+
+		report := builder.Build()
+
+		if err := writeRecordsFile(outputFile, report.Entries); err != nil {
+			return err
+		}
+
+		if err := writeRecordsFile(summaryFile, report.Summary); err != nil {
+			return err
+		}
+
+		total := report.Summary.Total()
+		log.Infof("%d ledger entries are exported to %s, the summary is exported to %s", len(report.Entries), outputFile, summaryFile)
+		log.Infof("realized gains: %s %s", total.RealizedGainFiat.String(), fiatCurrency)
+		log.Infof("reward income: %s %s", total.RewardIncomeFiat.String(), fiatCurrency)
+		log.Infof("fees: %s %s", total.FeesFiat.String(), fiatCurrency)
+		log.Infof("interest expense: %s %s", total.InterestExpenseFiat.String(), fiatCurrency)
+
+		if report.MissingPrices > 0 {
+			log.Warnf("%d entries have no %s price, run with --sync to sync the daily klines", report.MissingPrices, fiatCurrency)
+		}
+
+		return nil
+	},
+}
+
+// selectReportSessions returns the sessions of the given names or all sessions, one session per exchange
+// since the records in the database are stored by the exchange name.
+func selectReportSessions(environ *bbgo.Environment, sessionNames []string) ([]*bbgo.ExchangeSession, error) {
+	var sessions []*bbgo.ExchangeSession
+	if len(sessionNames) == 0 {
+		for _, session := range environ.Sessions() {
+			sessions = append(sessions, session)
+		}
+
+		sort.Slice(sessions, func(i, j int) bool {
+			return sessions[i].Name < sessions[j].Name
+		})
+	} else {
+		for _, sessionName := range sessionNames {
+			session, ok := environ.Session(sessionName)
+			if !ok {
+				return nil, fmt.Errorf("session %s not found", sessionName)
+			}
+			sessions = append(sessions, session)
+		}
+	}
+
+	var selected []*bbgo.ExchangeSession
+	exchanges := make(map[types.ExchangeName]struct{})
+	for _, session := range sessions {
+		if _, ok := exchanges[session.ExchangeName]; ok {
+			log.Warnf("session %s is skipped, the %s records are loaded by another session", session.Name, session.ExchangeName)
+			continue
+		}
+
+		exchanges[session.ExchangeName] = struct{}{}
+		selected = append(selected, session)
+	}
+
+	if len(selected) == 0 {
+		return nil, errors.New("no exchange session is configured")
+	}
+
+	return selected, nil
+}
+
+// reportAssets collects the assets that need to be valued in the fiat currency
+func reportAssets(markets types.MarketMap, trades []types.Trade, deposits []types.Deposit, withdraws []types.Withdraw, rewards []types.Reward, loans []types.MarginLoan, interests []types.MarginInterest) []string {
+	assets := make(map[string]struct{})
+	add := func(asset string) {
+		if asset != "" {
+			assets[asset] = struct{}{}
+		}
+	}
+
+	for _, trade := range trades {
+		if market, ok := markets[trade.Symbol]; ok {
+			add(market.QuoteCurrency)
+		}
+		add(trade.FeeCurrency)
+	}
+
+	for _, d := range deposits {
+		add(d.Asset)
+	}
+
+	for _, w := range withdraws {
+		add(w.Asset)
+		add(w.TransactionFeeCurrency)
+	}
+
+	for _, r := range rewards {
+		add(r.Currency)
+	}
+
+	for _, l := range loans {
+		add(l.Asset)
+	}
+
+	for _, i := range interests {
+		add(i.Asset)
+	}
+
+	var list []string
+	for asset := range assets {
+		list = append(list, asset)
+	}
+
+	sort.Strings(list)
+	return list
+}
+
+// syncFiatKLines syncs the daily klines of the assets against the fiat currency
+func syncFiatKLines(ctx context.Context, backtestService *service.BacktestService, session *bbgo.ExchangeSession, assets []string, fiatCurrency string, since, until time.Time) error {
+	for _, asset := range assets {
+		if asset == fiatCurrency {
+			continue
+		}
+
+		for _, symbol := range []string{asset + fiatCurrency, fiatCurrency + asset} {
+			if _, ok := session.Market(symbol); !ok {
+				continue
+			}
+
+			log.Infof("syncing %s daily klines for the fiat valuation", symbol)
+			if err := backtestService.Sync(ctx, session.Exchange, symbol, types.Interval1d, since.AddDate(0, 0, -1), until); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	return nil
+}
@@ -7,15 +7,19 @@ import (
 	"os/exec"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
 
+	"github.com/c9s/bbgo/pkg/alert"
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/cmd/cmdutil"
 	"github.com/c9s/bbgo/pkg/grpc"
+	"github.com/c9s/bbgo/pkg/interact"
 	"github.com/c9s/bbgo/pkg/server"
 )
 
@@ -162,10 +166,26 @@ func runConfig(basectx context.Context, cmd *cobra.Command, userConfig *bbgo.Con
 		return err
 	}
 
+	// the alert engine runs independently of the strategies,
+	// the interaction must be added before the trader connects the sessions and starts the interact
+	alertEngine, err := alert.NewEngine(userConfig.Alerts, environ.Sessions(), bbgo.Notification)
+	if err != nil {
+		return err
+	}
+
+	alertEngine.SetStore(bbgo.PersistenceServiceFacade.Get().NewStore("bbgo", "alerts"))
+	interact.AddCustomInteraction(alertEngine)
+
 	if err := trader.Run(ctx); err != nil {
 		return err
 	}
 
+	go alertEngine.Run(ctx)
+
+	if viper.GetBool("metrics") {
+		go environ.RunExposureMetrics(ctx, bbgo.DefaultExposureValuationCurrency, time.Minute)
+	}
+
 	if enableWebServer {
 		go func() {
 			s := &server.Server{
@@ -12,6 +12,8 @@ import (
 	_ "github.com/c9s/bbgo/pkg/strategy/emastop"
 	_ "github.com/c9s/bbgo/pkg/strategy/etf"
 	_ "github.com/c9s/bbgo/pkg/strategy/ewoDgtrd"
+	_ "github.com/c9s/bbgo/pkg/strategy/exprsignal"
+	_ "github.com/c9s/bbgo/pkg/strategy/externalsignal"
 	_ "github.com/c9s/bbgo/pkg/strategy/factorzoo"
 	_ "github.com/c9s/bbgo/pkg/strategy/flashcrash"
 	_ "github.com/c9s/bbgo/pkg/strategy/fmaker"
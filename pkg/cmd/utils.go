@@ -1,13 +1,18 @@
 package cmd
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/spf13/viper"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/c9s/bbgo/pkg/data/tsv"
 	"github.com/c9s/bbgo/pkg/exchange/ftx"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
@@ -49,3 +54,51 @@ func newExchange(session string) (types.Exchange, error) {
 	}
 	return nil, fmt.Errorf("unsupported session %s", session)
 }
+
+// writeRecordsFile writes the records into the file, the format is chosen by the file extension: .json, .tsv or .csv
+func writeRecordsFile(filename string, records types.CsvFormatter) error {
+	switch filepath.Ext(filename) {
+	case ".json":
+		out, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filename, out, 0644)
+
+	case ".tsv":
+		w, err := tsv.NewWriterFile(filename)
+		if err != nil {
+			return err
+		}
+
+		if err := w.Write(records.CsvHeader()); err != nil {
+			_ = w.Close()
+			return err
+		}
+
+		if err := w.WriteAll(records.CsvRecords()); err != nil {
+			_ = w.Close()
+			return err
+		}
+
+		return w.Close()
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(f)
+	if err := w.Write(records.CsvHeader()); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err := w.WriteAll(records.CsvRecords()); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,353 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Env is the compile environment of the expressions,
+// the price sources and the indicators are bound to the market data of the given interval.
+type Env struct {
+	Interval     types.Interval
+	IndicatorSet *bbgo.StandardIndicatorSet
+	Store        *bbgo.MarketDataStore
+}
+
+// Expr is a compiled expression, which is either a numeric series or a boolean series.
+type Expr struct {
+	Source string
+
+	series     types.SeriesExtend
+	boolSeries types.BoolSeries
+}
+
+// IsBool returns true if the expression is a condition
+func (e *Expr) IsBool() bool {
+	return e.boolSeries != nil
+}
+
+// Series returns the numeric series of the expression, nil if the expression is a condition
+func (e *Expr) Series() types.SeriesExtend {
+	return e.series
+}
+
+// BoolSeries returns the boolean series of the expression, nil if the expression is not a condition
+func (e *Expr) BoolSeries() types.BoolSeries {
+	return e.boolSeries
+}
+
+// Last returns the current value of the condition, non-zero numeric values are treated as true.
+func (e *Expr) Last() bool {
+	if e.boolSeries != nil {
+		return e.boolSeries.Last()
+	}
+
+	return e.series.Last() != 0
+}
+
+func (e *Expr) String() string {
+	return e.Source
+}
+
+// Compile parses the expression source and compiles it with the environment
+func Compile(source string, env Env) (*Expr, error) {
+	node, err := Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("expression %q parse error: %w", source, err)
+	}
+
+	c := &compiler{env: env}
+	v, err := c.compile(node)
+	if err != nil {
+		return nil, fmt.Errorf("expression %q compile error: %w", source, err)
+	}
+
+	e := &Expr{Source: source}
+	switch tv := v.(type) {
+	case types.Series:
+		e.series = types.NewSeries(tv)
+	case types.BoolSeries:
+		e.boolSeries = tv
+	}
+
+	return e, nil
+}
+
+type compiler struct {
+	env Env
+}
+
+// compile compiles the node into either types.Series or types.BoolSeries
+func (c *compiler) compile(node Node) (interface{}, error) {
+	switch n := node.(type) {
+	case *NumberNode:
+		return types.NumberSeries(n.Value), nil
+
+	case *IdentNode:
+		getter, ok := kLineSources[strings.ToLower(n.Name)]
+		if !ok {
+			return nil, fmt.Errorf("undefined identifier %s", n.Name)
+		}
+
+		return &sourceSeries{store: c.env.Store, interval: c.env.Interval, getter: getter}, nil
+
+	case *UnaryNode:
+		if n.Op == "!" {
+			x, err := c.compileBool(n.X)
+			if err != nil {
+				return nil, err
+			}
+			return &notSeries{a: x}, nil
+		}
+
+		x, err := c.compileSeries(n.X)
+		if err != nil {
+			return nil, err
+		}
+		return types.Mul(x, -1.0), nil
+
+	case *BinaryNode:
+		return c.compileBinary(n)
+
+	case *CallNode:
+		return c.compileCall(n)
+	}
+
+	return nil, fmt.Errorf("unsupported node %s", node)
+}
+
+func (c *compiler) compileSeries(node Node) (types.Series, error) {
+	v, err := c.compile(node)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := v.(types.Series)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a numeric series", node)
+	}
+
+	return s, nil
+}
+
+func (c *compiler) compileBool(node Node) (types.BoolSeries, error) {
+	v, err := c.compile(node)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := v.(types.BoolSeries)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a condition", node)
+	}
+
+	return s, nil
+}
+
+func (c *compiler) compileBinary(n *BinaryNode) (interface{}, error) {
+	switch n.Op {
+	case "&&", "||":
+		x, err := c.compileBool(n.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := c.compileBool(n.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &logicalSeries{a: x, b: y, op: n.Op}, nil
+	}
+
+	x, err := c.compileSeries(n.X)
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := c.compileSeries(n.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.Op {
+	case "+":
+		return types.Add(x, y), nil
+	case "-":
+		return types.Minus(x, y), nil
+	case "*":
+		return types.Mul(x, y), nil
+	case "/":
+		return types.Div(x, y), nil
+	case "<", "<=", ">", ">=", "==", "!=":
+		return &compareSeries{a: x, b: y, op: n.Op}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported operator %s", n.Op)
+}
+
+func (c *compiler) compileCall(n *CallNode) (interface{}, error) {
+	name := strings.ToLower(n.Func)
+	switch name {
+	case "sma", "ema", "ewma", "rsi", "stddev":
+		return c.compileIndicator(n, name)
+
+	case "atr":
+		window, err := c.windowArg(n, 0, 1)
+		if err != nil {
+			return nil, err
+		}
+		return c.env.IndicatorSet.ATR(types.IntervalWindow{Interval: c.env.Interval, Window: window}), nil
+
+	case "highest", "lowest":
+		if len(n.Args) != 2 {
+			return nil, fmt.Errorf("%s requires 2 arguments", n)
+		}
+
+		a, err := c.compileSeries(n.Args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		lookback, err := c.windowArg(n, 1, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		reduce := types.Highest
+		if name == "lowest" {
+			reduce = types.Lowest
+		}
+
+		return types.NewSeries(&rollingSeries{a: a, lookback: lookback, reduce: reduce}), nil
+
+	case "change", "shift":
+		if len(n.Args) < 1 || len(n.Args) > 2 {
+			return nil, fmt.Errorf("%s requires 1 or 2 arguments", n)
+		}
+
+		a, err := c.compileSeries(n.Args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		offset := 1
+		if len(n.Args) == 2 {
+			if offset, err = c.windowArg(n, 1, 2); err != nil {
+				return nil, err
+			}
+		}
+
+		if name == "shift" {
+			return types.Shift(a, offset), nil
+		}
+		return types.Change(a, offset), nil
+
+	case "abs":
+		if len(n.Args) != 1 {
+			return nil, fmt.Errorf("%s requires 1 argument", n)
+		}
+
+		a, err := c.compileSeries(n.Args[0])
+		if err != nil {
+			return nil, err
+		}
+		return types.Abs(a), nil
+
+	case "crossover", "crossunder":
+		if len(n.Args) != 2 {
+			return nil, fmt.Errorf("%s requires 2 arguments", n)
+		}
+
+		a, err := c.compileSeries(n.Args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := c.compileSeries(n.Args[1])
+		if err != nil {
+			return nil, err
+		}
+
+		if name == "crossover" {
+			return types.CrossOver(a, b), nil
+		}
+		return types.CrossUnder(a, b), nil
+	}
+
+	return nil, fmt.Errorf("undefined function %s", n.Func)
+}
+
+// compileIndicator compiles the indicator functions in the form of fn(source, window).
+// The indicators of the close price are allocated from the standard indicator set,
+// the indicators of the other price sources are bound to the market data store.
+func (c *compiler) compileIndicator(n *CallNode, name string) (interface{}, error) {
+	if len(n.Args) != 2 {
+		return nil, fmt.Errorf("%s requires 2 arguments", n)
+	}
+
+	ident, ok := n.Args[0].(*IdentNode)
+	if !ok {
+		return nil, fmt.Errorf("%s: the source of the indicator must be a price source", n)
+	}
+
+	sourceName := strings.ToLower(ident.Name)
+	getter, ok := kLineSources[sourceName]
+	if !ok {
+		return nil, fmt.Errorf("%s: undefined price source %s", n, ident.Name)
+	}
+
+	window, err := c.windowArg(n, 1, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	iw := types.IntervalWindow{Interval: c.env.Interval, Window: window}
+	if sourceName == "close" {
+		switch name {
+		case "sma":
+			return c.env.IndicatorSet.SMA(iw), nil
+		case "ema", "ewma":
+			return c.env.IndicatorSet.EWMA(iw), nil
+		case "rsi":
+			return c.env.IndicatorSet.RSI(iw), nil
+		case "stddev":
+			return c.env.IndicatorSet.StdDev(iw), nil
+		}
+	}
+
+	var inc types.UpdatableSeriesExtend
+	switch name {
+	case "sma":
+		inc = &indicator.SMA{IntervalWindow: iw}
+	case "ema", "ewma":
+		inc = &indicator.EWMA{IntervalWindow: iw}
+	case "rsi":
+		inc = &indicator.RSI{IntervalWindow: iw}
+	case "stddev":
+		inc = &indicator.StdDev{IntervalWindow: iw}
+	}
+
+	c.env.Store.BindKLinePusher(c.env.Interval, kLinePusherFunc(func(k types.KLine) {
+		inc.Update(getter(k))
+	}))
+	return inc, nil
+}
+
+// windowArg reads the positive integer argument at the given index
+func (c *compiler) windowArg(n *CallNode, index, numArgs int) (int, error) {
+	if len(n.Args) != numArgs {
+		return 0, fmt.Errorf("%s requires %d arguments", n, numArgs)
+	}
+
+	num, ok := n.Args[index].(*NumberNode)
+	if !ok || num.Value < 1 || num.Value != float64(int(num.Value)) {
+		return 0, fmt.Errorf("%s: argument %d must be a positive integer", n, index+1)
+	}
+
+	return int(num.Value), nil
+}
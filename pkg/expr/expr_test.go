@@ -0,0 +1,110 @@
+package expr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"1 + 2 * 3", "(1 + (2 * 3))"},
+		{"(1 + 2) * 3", "((1 + 2) * 3)"},
+		{"crossOver(ema(close,12), ema(close,26)) and rsi(close,14) < 30",
+			"(crossOver(ema(close, 12), ema(close, 26)) && (rsi(close, 14) < 30))"},
+		{"not close > open or volume >= 10", "(!(close > open) || (volume >= 10))"},
+		{"-close + 1", "(-close + 1)"},
+	}
+
+	for _, test := range tests {
+		node, err := Parse(test.source)
+		if assert.NoError(t, err, test.source) {
+			assert.Equal(t, test.want, node.String())
+		}
+	}
+}
+
+func TestParse_Error(t *testing.T) {
+	for _, source := range []string{"", "1 +", "ema(close, 12", "close $ 1", "(close"} {
+		_, err := Parse(source)
+		assert.Error(t, err, source)
+	}
+}
+
+func newTestEnv(closes ...float64) Env {
+	store := bbgo.NewMarketDataStore("BTCUSDT")
+	set := bbgo.NewStandardIndicatorSet("BTCUSDT", store)
+	env := Env{Interval: types.Interval1h, IndicatorSet: set, Store: store}
+
+	startTime := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i, c := range closes {
+		store.AddKLine(types.KLine{
+			Symbol:    "BTCUSDT",
+			Interval:  types.Interval1h,
+			StartTime: types.Time(startTime.Add(time.Duration(i) * time.Hour)),
+			Open:      fixedpoint.NewFromFloat(c - 1),
+			High:      fixedpoint.NewFromFloat(c + 2),
+			Low:       fixedpoint.NewFromFloat(c - 2),
+			Close:     fixedpoint.NewFromFloat(c),
+			Volume:    fixedpoint.One,
+		})
+	}
+
+	return env
+}
+
+func TestCompile(t *testing.T) {
+	env := newTestEnv(10, 11, 12, 13, 9, 8, 15)
+
+	e, err := Compile("close - open", env)
+	if assert.NoError(t, err) {
+		assert.False(t, e.IsBool())
+		assert.InDelta(t, 1.0, e.Series().Last(), 1e-9)
+	}
+
+	e, err = Compile("sma(close, 3) == (9 + 8 + 15) / 3", env)
+	if assert.NoError(t, err) {
+		assert.True(t, e.IsBool())
+		assert.True(t, e.Last())
+	}
+
+	e, err = Compile("highest(high, 3) > 16 and lowest(low, 3) == 6", env)
+	if assert.NoError(t, err) {
+		assert.True(t, e.Last())
+	}
+
+	e, err = Compile("crossOver(close, sma(close, 3))", env)
+	if assert.NoError(t, err) {
+		assert.True(t, e.Last())
+		assert.False(t, e.BoolSeries().Index(1))
+	}
+
+	e, err = Compile("not crossUnder(close, sma(high, 2))", env)
+	if assert.NoError(t, err) {
+		assert.True(t, e.Last())
+	}
+}
+
+func TestCompile_Error(t *testing.T) {
+	env := newTestEnv(10, 11, 12)
+	for _, source := range []string{
+		"foo",
+		"ema(close)",
+		"ema(close - open, 3)",
+		"ema(close, 0.5)",
+		"close and open",
+		"crossOver(close > open, close)",
+		"unknown(close, 3)",
+	} {
+		_, err := Compile(source, env)
+		assert.Error(t, err, source)
+	}
+}
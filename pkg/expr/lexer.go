@@ -0,0 +1,116 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenNumber
+	tokenIdent
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+)
+
+type token struct {
+	typ tokenType
+	val string
+	pos int
+}
+
+func (t token) String() string {
+	if t.typ == tokenEOF {
+		return "end of expression"
+	}
+
+	return fmt.Sprintf("%q at position %d", t.val, t.pos)
+}
+
+// keywordOperators maps the keyword operators to the symbol operators
+var keywordOperators = map[string]string{
+	"and": "&&",
+	"or":  "||",
+	"not": "!",
+}
+
+// twoCharOperators are the operators that consist of two characters
+var twoCharOperators = []string{"<=", ">=", "==", "!=", "&&", "||"}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	var runes = []rune(source)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{typ: tokenNumber, val: string(runes[start:i]), pos: start})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+
+			word := string(runes[start:i])
+			if op, ok := keywordOperators[strings.ToLower(word)]; ok {
+				tokens = append(tokens, token{typ: tokenOperator, val: op, pos: start})
+			} else {
+				tokens = append(tokens, token{typ: tokenIdent, val: word, pos: start})
+			}
+
+		case r == '(':
+			tokens = append(tokens, token{typ: tokenLeftParen, val: "(", pos: i})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, token{typ: tokenRightParen, val: ")", pos: i})
+			i++
+
+		case r == ',':
+			tokens = append(tokens, token{typ: tokenComma, val: ",", pos: i})
+			i++
+
+		default:
+			matched := false
+			if i+1 < len(runes) {
+				for _, op := range twoCharOperators {
+					if string(runes[i:i+2]) == op {
+						tokens = append(tokens, token{typ: tokenOperator, val: op, pos: i})
+						i += 2
+						matched = true
+						break
+					}
+				}
+			}
+
+			if matched {
+				continue
+			}
+
+			if strings.ContainsRune("+-*/<>!", r) {
+				tokens = append(tokens, token{typ: tokenOperator, val: string(r), pos: i})
+				i++
+				continue
+			}
+
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+		}
+	}
+
+	tokens = append(tokens, token{typ: tokenEOF, pos: len(runes)})
+	return tokens, nil
+}
@@ -0,0 +1,215 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Node is the node of the expression syntax tree
+type Node interface {
+	String() string
+}
+
+type NumberNode struct {
+	Value float64
+}
+
+func (n *NumberNode) String() string {
+	return strconv.FormatFloat(n.Value, 'f', -1, 64)
+}
+
+// IdentNode is the price source identifier, e.g., close, open, high, low, volume
+type IdentNode struct {
+	Name string
+}
+
+func (n *IdentNode) String() string {
+	return n.Name
+}
+
+// CallNode is the function call, e.g., ema(close, 12)
+type CallNode struct {
+	Func string
+	Args []Node
+}
+
+func (n *CallNode) String() string {
+	var args []string
+	for _, arg := range n.Args {
+		args = append(args, arg.String())
+	}
+
+	return n.Func + "(" + strings.Join(args, ", ") + ")"
+}
+
+type UnaryNode struct {
+	Op string
+	X  Node
+}
+
+func (n *UnaryNode) String() string {
+	return n.Op + n.X.String()
+}
+
+type BinaryNode struct {
+	Op   string
+	X, Y Node
+}
+
+func (n *BinaryNode) String() string {
+	return "(" + n.X.String() + " " + n.Op + " " + n.Y.String() + ")"
+}
+
+// binaryPrecedence defines the precedence of the binary operators, the higher binds tighter.
+var binaryPrecedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"<":  3, "<=": 3, ">": 3, ">=": 3, "==": 3, "!=": 3,
+	"+": 4, "-": 4,
+	"*": 5, "/": 5,
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse parses the expression source into the syntax tree
+func Parse(source string) (Node, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	node, err := p.parseBinary(1)
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.typ != tokenEOF {
+		return nil, fmt.Errorf("unexpected token %s", tok)
+	}
+
+	return node, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.typ != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseBinary parses the binary expressions with the precedence climbing method
+func (p *parser) parseBinary(minPrecedence int) (Node, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		if tok.typ != tokenOperator {
+			return x, nil
+		}
+
+		precedence, ok := binaryPrecedence[tok.val]
+		if !ok || precedence < minPrecedence {
+			return x, nil
+		}
+
+		p.next()
+		y, err := p.parseBinary(precedence + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		x = &BinaryNode{Op: tok.val, X: x, Y: y}
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	tok := p.peek()
+	if tok.typ == tokenOperator && (tok.val == "-" || tok.val == "!") {
+		p.next()
+
+		// "not" binds looser than the comparison, so that "not a > b" means "not (a > b)"
+		var x Node
+		var err error
+		if tok.val == "!" {
+			x, err = p.parseBinary(binaryPrecedence["<"])
+		} else {
+			x, err = p.parseUnary()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &UnaryNode{Op: tok.val, X: x}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.next()
+	switch tok.typ {
+	case tokenNumber:
+		v, err := strconv.ParseFloat(tok.val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", tok)
+		}
+		return &NumberNode{Value: v}, nil
+
+	case tokenIdent:
+		if p.peek().typ != tokenLeftParen {
+			return &IdentNode{Name: tok.val}, nil
+		}
+
+		p.next()
+		call := &CallNode{Func: tok.val}
+		if p.peek().typ == tokenRightParen {
+			p.next()
+			return call, nil
+		}
+
+		for {
+			arg, err := p.parseBinary(1)
+			if err != nil {
+				return nil, err
+			}
+			call.Args = append(call.Args, arg)
+
+			sep := p.next()
+			if sep.typ == tokenRightParen {
+				return call, nil
+			}
+
+			if sep.typ != tokenComma {
+				return nil, fmt.Errorf("expecting , or ) but got %s", sep)
+			}
+		}
+
+	case tokenLeftParen:
+		node, err := p.parseBinary(1)
+		if err != nil {
+			return nil, err
+		}
+
+		if closing := p.next(); closing.typ != tokenRightParen {
+			return nil, fmt.Errorf("expecting ) but got %s", closing)
+		}
+
+		return node, nil
+	}
+
+	return nil, fmt.Errorf("unexpected token %s", tok)
+}
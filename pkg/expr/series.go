@@ -0,0 +1,166 @@
+package expr
+
+import (
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// kLineValueGetter returns the value of a kline field
+type kLineValueGetter func(k types.KLine) float64
+
+var kLineSources = map[string]kLineValueGetter{
+	"open":   func(k types.KLine) float64 { return k.Open.Float64() },
+	"high":   func(k types.KLine) float64 { return k.High.Float64() },
+	"low":    func(k types.KLine) float64 { return k.Low.Float64() },
+	"close":  func(k types.KLine) float64 { return k.Close.Float64() },
+	"volume": func(k types.KLine) float64 { return k.Volume.Float64() },
+	"hl2":    func(k types.KLine) float64 { return k.High.Add(k.Low).Float64() / 2.0 },
+	"hlc3":   func(k types.KLine) float64 { return k.High.Add(k.Low).Add(k.Close).Float64() / 3.0 },
+}
+
+// sourceSeries reads the kline values from the market data store,
+// the kline window is looked up lazily since it's created when the first kline is added.
+type sourceSeries struct {
+	store    *bbgo.MarketDataStore
+	interval types.Interval
+	getter   kLineValueGetter
+}
+
+func (s *sourceSeries) window() types.KLineWindow {
+	if w, ok := s.store.KLinesOfInterval(s.interval); ok {
+		return *w
+	}
+	return nil
+}
+
+func (s *sourceSeries) Last() float64 {
+	return s.Index(0)
+}
+
+func (s *sourceSeries) Index(i int) float64 {
+	w := s.window()
+	if i >= len(w) {
+		return 0
+	}
+	return s.getter(w[len(w)-1-i])
+}
+
+func (s *sourceSeries) Length() int {
+	return len(s.window())
+}
+
+// kLinePusherFunc adapts a function to the indicator.KLinePusher interface
+type kLinePusherFunc func(k types.KLine)
+
+func (f kLinePusherFunc) PushK(k types.KLine) {
+	f(k)
+}
+
+// rollingSeries applies the reducer on the lookback window of each index, e.g., highest and lowest
+type rollingSeries struct {
+	a        types.Series
+	lookback int
+	reduce   func(a types.Series, lookback int) float64
+}
+
+func (s *rollingSeries) Last() float64 {
+	return s.Index(0)
+}
+
+func (s *rollingSeries) Index(i int) float64 {
+	if i >= s.a.Length() {
+		return 0
+	}
+	return s.reduce(types.Shift(s.a, i), s.lookback)
+}
+
+func (s *rollingSeries) Length() int {
+	return s.a.Length()
+}
+
+func minLength(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// compareSeries compares two numeric series
+type compareSeries struct {
+	a, b types.Series
+	op   string
+}
+
+func (s *compareSeries) Last() bool {
+	return s.Index(0)
+}
+
+func (s *compareSeries) Index(i int) bool {
+	if i >= s.Length() {
+		return false
+	}
+
+	x, y := s.a.Index(i), s.b.Index(i)
+	switch s.op {
+	case "<":
+		return x < y
+	case "<=":
+		return x <= y
+	case ">":
+		return x > y
+	case ">=":
+		return x >= y
+	case "==":
+		return x == y
+	case "!=":
+		return x != y
+	}
+	return false
+}
+
+func (s *compareSeries) Length() int {
+	return minLength(s.a.Length(), s.b.Length())
+}
+
+// logicalSeries combines two boolean series with "and" or "or"
+type logicalSeries struct {
+	a, b types.BoolSeries
+	op   string
+}
+
+func (s *logicalSeries) Last() bool {
+	return s.Index(0)
+}
+
+func (s *logicalSeries) Index(i int) bool {
+	switch s.op {
+	case "&&":
+		return s.a.Index(i) && s.b.Index(i)
+	case "||":
+		return s.a.Index(i) || s.b.Index(i)
+	}
+	return false
+}
+
+func (s *logicalSeries) Length() int {
+	return minLength(s.a.Length(), s.b.Length())
+}
+
+type notSeries struct {
+	a types.BoolSeries
+}
+
+func (s *notSeries) Last() bool {
+	return s.Index(0)
+}
+
+func (s *notSeries) Index(i int) bool {
+	if i >= s.a.Length() {
+		return false
+	}
+	return !s.a.Index(i)
+}
+
+func (s *notSeries) Length() int {
+	return s.a.Length()
+}
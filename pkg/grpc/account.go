@@ -0,0 +1,140 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/dynamic"
+	"github.com/c9s/bbgo/pkg/pb"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type AccountService struct {
+	Config  *bbgo.Config
+	Environ *bbgo.Environment
+	Trader  *bbgo.Trader
+
+	pb.UnimplementedAccountServiceServer
+}
+
+func (s *AccountService) QueryBalances(ctx context.Context, request *pb.QueryBalancesRequest) (*pb.QueryBalancesResponse, error) {
+	sessions, err := lookupSessions(s.Environ, request.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.QueryBalancesResponse{}
+	for _, session := range sessions {
+		resp.Balances = append(resp.Balances, transBalances(session, session.GetAccount().Balances())...)
+	}
+
+	return resp, nil
+}
+
+// QueryPositions returns the positions of the strategies that implement bbgo.PositionReader,
+// followed by the session positions that are not empty.
+func (s *AccountService) QueryPositions(ctx context.Context, request *pb.QueryPositionsRequest) (*pb.QueryPositionsResponse, error) {
+	sessions, err := lookupSessions(s.Environ, request.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	var strategies map[string]bbgo.SingleExchangeStrategy
+	if s.Trader != nil {
+		strategies, err = s.Trader.StrategiesBySignature()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &pb.QueryPositionsResponse{}
+	for _, session := range sessions {
+		var positions []*pb.Position
+		for id, strategy := range strategies {
+			if signatureSession(id) != session.Name {
+				continue
+			}
+
+			reader, ok := strategy.(bbgo.PositionReader)
+			if !ok {
+				continue
+			}
+
+			position := reader.CurrentPosition()
+			if position == nil || (len(request.Symbol) > 0 && position.Symbol != request.Symbol) {
+				continue
+			}
+
+			pbPosition := transPosition(session, position)
+			if len(pbPosition.Strategy) == 0 {
+				pbPosition.Strategy = strategy.ID()
+			}
+
+			if len(pbPosition.StrategyInstanceId) == 0 {
+				pbPosition.StrategyInstanceId = dynamic.CallID(strategy)
+			}
+
+			positions = append(positions, pbPosition)
+		}
+
+		for symbol, position := range session.Positions() {
+			if position.Base.IsZero() || (len(request.Symbol) > 0 && symbol != request.Symbol) {
+				continue
+			}
+
+			positions = append(positions, transPosition(session, position))
+		}
+
+		sort.Slice(positions, func(i, j int) bool {
+			if positions[i].Symbol != positions[j].Symbol {
+				return positions[i].Symbol < positions[j].Symbol
+			}
+			return positions[i].StrategyInstanceId < positions[j].StrategyInstanceId
+		})
+		resp.Positions = append(resp.Positions, positions...)
+	}
+
+	return resp, nil
+}
+
+func (s *AccountService) QueryMarginInfo(ctx context.Context, request *pb.QueryMarginInfoRequest) (*pb.QueryMarginInfoResponse, error) {
+	sessions, err := lookupSessions(s.Environ, request.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.QueryMarginInfoResponse{}
+	for _, session := range sessions {
+		if !session.Margin {
+			if len(request.Session) > 0 {
+				return nil, fmt.Errorf("session %s is not a margin session", session.Name)
+			}
+			continue
+		}
+
+		resp.MarginInfos = append(resp.MarginInfos, transMarginInfo(session, session.GetAccount()))
+	}
+
+	return resp, nil
+}
+
+// signatureSession returns the session name of the strategy signature key returned by bbgo.Trader.StrategiesBySignature
+func signatureSession(id string) string {
+	return strings.SplitN(id, ".", 2)[0]
+}
+
+func borrowedBalances(balances types.BalanceMap) types.BalanceMap {
+	borrowed := types.BalanceMap{}
+	for currency, balance := range balances {
+		if balance.Borrowed.IsZero() && balance.Interest.IsZero() {
+			continue
+		}
+
+		borrowed[currency] = balance
+	}
+
+	return borrowed
+}
@@ -2,11 +2,13 @@ package grpc
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/dynamic"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/pb"
 	"github.com/c9s/bbgo/pkg/types"
@@ -114,15 +116,70 @@ func toSubmitOrders(pbOrders []*pb.SubmitOrder) (submitOrders []types.SubmitOrde
 func transBalances(session *bbgo.ExchangeSession, balances types.BalanceMap) (pbBalances []*pb.Balance) {
 	for _, b := range balances {
 		pbBalances = append(pbBalances, &pb.Balance{
+			Session:   session.Name,
 			Exchange:  session.ExchangeName.String(),
 			Currency:  b.Currency,
 			Available: b.Available.String(),
 			Locked:    b.Locked.String(),
+			Borrowed:  b.Borrowed.String(),
 		})
 	}
+
+	sort.Slice(pbBalances, func(i, j int) bool {
+		return pbBalances[i].Currency < pbBalances[j].Currency
+	})
 	return pbBalances
 }
 
+func transPosition(session *bbgo.ExchangeSession, position *types.Position) *pb.Position {
+	return &pb.Position{
+		Session:            session.Name,
+		Exchange:           session.ExchangeName.String(),
+		Symbol:             position.Symbol,
+		BaseCurrency:       position.BaseCurrency,
+		QuoteCurrency:      position.QuoteCurrency,
+		Base:               position.Base.String(),
+		Quote:              position.Quote.String(),
+		AverageCost:        position.AverageCost.String(),
+		AccumulatedProfit:  position.AccumulatedProfit.String(),
+		Strategy:           position.Strategy,
+		StrategyInstanceId: position.StrategyInstanceID,
+		ChangedAt:          position.ChangedAt.UnixMilli(),
+	}
+}
+
+func transMarginInfo(session *bbgo.ExchangeSession, account *types.Account) *pb.MarginInfo {
+	return &pb.MarginInfo{
+		Session:           session.Name,
+		Exchange:          session.ExchangeName.String(),
+		Isolated:          session.IsolatedMargin,
+		IsolatedSymbol:    session.IsolatedMarginSymbol,
+		MarginLevel:       account.MarginLevel.String(),
+		MarginRatio:       account.MarginRatio.String(),
+		LiquidationPrice:  account.LiquidationPrice.String(),
+		TotalAccountValue: account.TotalAccountValue.String(),
+		Balances:          transBalances(session, borrowedBalances(account.Balances())),
+	}
+}
+
+func transStrategy(id string, strategy bbgo.SingleExchangeStrategy) *pb.Strategy {
+	pbStrategy := &pb.Strategy{
+		Id:         id,
+		Session:    signatureSession(id),
+		Strategy:   strategy.ID(),
+		InstanceId: dynamic.CallID(strategy),
+		Status:     string(types.StrategyStatusUnknown),
+	}
+
+	if reader, ok := strategy.(bbgo.StrategyStatusReader); ok {
+		pbStrategy.Status = string(reader.GetStatus())
+	}
+
+	_, pbStrategy.Toggleable = strategy.(bbgo.StrategyToggler)
+	_, pbStrategy.EmergencyStoppable = strategy.(bbgo.EmergencyStopper)
+	return pbStrategy
+}
+
 func transTrade(session *bbgo.ExchangeSession, trade types.Trade) *pb.Trade {
 	return &pb.Trade{
 		Session:     session.Name,
@@ -3,8 +3,11 @@ package grpc
 import (
 	"context"
 	"fmt"
+	"math"
 	"net"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -15,6 +18,7 @@ import (
 
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/pb"
+	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/types"
 )
 
@@ -102,15 +106,173 @@ func (s *TradingService) CancelOrder(ctx context.Context, request *pb.CancelOrde
 }
 
 func (s *TradingService) QueryOrder(ctx context.Context, request *pb.QueryOrderRequest) (*pb.QueryOrderResponse, error) {
-	panic("implement me")
+	session, err := lookupSession(s.Environ, request.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(request.Id) == 0 && len(request.ClientOrderId) == 0 {
+		return nil, fmt.Errorf("either order id or client order id is required")
+	}
+
+	queryService, ok := session.Exchange.(types.ExchangeOrderQueryService)
+	if !ok {
+		return nil, fmt.Errorf("exchange %s does not support order query", session.ExchangeName)
+	}
+
+	order, err := queryService.QueryOrder(ctx, types.OrderQuery{
+		Symbol:        request.Symbol,
+		OrderID:       request.Id,
+		ClientOrderID: request.ClientOrderId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.QueryOrderResponse{
+		Order: transOrder(session, *order),
+	}, nil
 }
 
+// QueryOrders queries the open orders from the exchange when only the open order states are requested
+// or when the database is not configured, otherwise the order history is loaded from the database.
 func (s *TradingService) QueryOrders(ctx context.Context, request *pb.QueryOrdersRequest) (*pb.QueryOrdersResponse, error) {
-	panic("implement me")
+	session, err := lookupSession(s.Environ, request.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	if isOpenOrderStates(request.State) || (len(request.State) == 0 && s.Environ.OrderService == nil) {
+		orders, err := session.Exchange.QueryOpenOrders(ctx, request.Symbol)
+		if err != nil {
+			return nil, err
+		}
+
+		resp := &pb.QueryOrdersResponse{}
+		for _, order := range filterOrders(orders, request.State, uint32(request.GroupId)) {
+			resp.Orders = append(resp.Orders, transOrder(session, order))
+		}
+
+		start, end := pageRange(len(resp.Orders), request.Pagination, request.Page, request.Limit, request.Offset)
+		resp.Orders = resp.Orders[start:end]
+		return resp, nil
+	}
+
+	if s.Environ.OrderService == nil {
+		return nil, fmt.Errorf("order history query requires the database to be configured")
+	}
+
+	// the group id is not stored in the database
+	if request.GroupId > 0 {
+		return nil, fmt.Errorf("group id filter is only supported by the open order query")
+	}
+
+	var statuses []types.OrderStatus
+	for _, state := range request.State {
+		statuses = append(statuses, types.OrderStatus(strings.ToUpper(state)))
+	}
+
+	// the page is selected by the database
+	since, until := timeRange(request.From, request.To)
+	start, end := pageRange(math.MaxInt32, request.Pagination, request.Page, request.Limit, request.Offset)
+	options := service.QueryOrdersOptions{
+		Exchange: session.ExchangeName,
+		Symbol:   request.Symbol,
+		Ordering: strings.ToUpper(request.OrderBy),
+		Since:    since,
+		Until:    until,
+		Statuses: statuses,
+		Offset:   uint64(start),
+	}
+
+	if request.Limit > 0 {
+		options.Limit = uint64(end - start)
+	}
+
+	aggOrders, err := s.Environ.OrderService.Query(options)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.QueryOrdersResponse{}
+	for _, aggOrder := range aggOrders {
+		resp.Orders = append(resp.Orders, transOrder(session, aggOrder.Order))
+	}
+
+	return resp, nil
 }
 
+// QueryTrades queries the synchronized trades from the database, it falls back to the exchange trade history api
+// when the database is not configured. The exchange field accepts either a session name or an exchange name.
 func (s *TradingService) QueryTrades(ctx context.Context, request *pb.QueryTradesRequest) (*pb.QueryTradesResponse, error) {
-	panic("implement me")
+	session, err := lookupExchangeSession(s.Environ, request.Exchange)
+	if err != nil {
+		return nil, err
+	}
+
+	since, until := timeRange(request.From, request.To)
+	start, end := pageRange(math.MaxInt32, request.Pagination, request.Page, request.Limit, request.Offset)
+
+	if s.Environ.TradeService != nil {
+		// the page is selected by the database
+		options := service.QueryTradesOptions{
+			Exchange: session.ExchangeName,
+			Symbol:   request.Symbol,
+			Since:    since,
+			Until:    until,
+			Ordering: strings.ToUpper(request.OrderBy),
+			Offset:   uint64(start),
+		}
+
+		if request.Limit > 0 {
+			options.Limit = uint64(end - start)
+		}
+
+		trades, err := s.Environ.TradeService.Query(options)
+		if err != nil {
+			return nil, err
+		}
+
+		resp := &pb.QueryTradesResponse{}
+		for _, trade := range trades {
+			resp.Trades = append(resp.Trades, transTrade(session, trade))
+		}
+
+		return resp, nil
+	}
+
+	historyService, ok := session.Exchange.(types.ExchangeTradeHistoryService)
+	if !ok {
+		return nil, fmt.Errorf("exchange %s does not support trade history query", session.ExchangeName)
+	}
+
+	if len(request.Symbol) == 0 {
+		return nil, fmt.Errorf("symbol is required for querying trades from the exchange")
+	}
+
+	// the trade history api has no offset, fetch enough trades to cover the requested page
+	limit := end
+	if request.Limit == 0 {
+		limit = 0
+	}
+
+	trades, err := historyService.QueryTrades(ctx, request.Symbol, &types.TradeQueryOptions{
+		StartTime: since,
+		EndTime:   until,
+		Limit:     int64(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.QueryTradesResponse{}
+	for _, trade := range trades {
+		resp.Trades = append(resp.Trades, transTrade(session, trade))
+	}
+
+	start, end = pageRange(len(resp.Trades), request.Pagination, request.Page, request.Limit, request.Offset)
+	resp.Trades = resp.Trades[start:end]
+	return resp, nil
 }
 
 type UserDataService struct {
@@ -328,12 +490,7 @@ type Server struct {
 	Trader  *bbgo.Trader
 }
 
-func (s *Server) ListenAndServe(bind string) error {
-	conn, err := net.Listen("tcp", bind)
-	if err != nil {
-		return errors.Wrapf(err, "failed to bind network at %s", bind)
-	}
-
+func (s *Server) newGrpcServer() *grpc.Server {
 	var grpcServer = grpc.NewServer()
 	pb.RegisterMarketDataServiceServer(grpcServer, &MarketDataService{
 		Config:  s.Config,
@@ -353,11 +510,179 @@ func (s *Server) ListenAndServe(bind string) error {
 		Trader:  s.Trader,
 	})
 
+	pb.RegisterAccountServiceServer(grpcServer, &AccountService{
+		Config:  s.Config,
+		Environ: s.Environ,
+		Trader:  s.Trader,
+	})
+
+	pb.RegisterStrategyServiceServer(grpcServer, &StrategyService{
+		Config:  s.Config,
+		Environ: s.Environ,
+		Trader:  s.Trader,
+	})
+
+	pb.RegisterSignalServiceServer(grpcServer, &SignalService{
+		Config:  s.Config,
+		Environ: s.Environ,
+		Trader:  s.Trader,
+	})
+
 	reflection.Register(grpcServer)
+	return grpcServer
+}
+
+func (s *Server) ListenAndServe(bind string) error {
+	conn, err := net.Listen("tcp", bind)
+	if err != nil {
+		return errors.Wrapf(err, "failed to bind network at %s", bind)
+	}
 
+	grpcServer := s.newGrpcServer()
 	if err := grpcServer.Serve(conn); err != nil {
 		return errors.Wrap(err, "failed to serve grpc connections")
 	}
 
 	return nil
 }
+
+func lookupSession(environ *bbgo.Environment, sessionName string) (*bbgo.ExchangeSession, error) {
+	if len(sessionName) == 0 {
+		return nil, fmt.Errorf("session name can not be empty")
+	}
+
+	session, ok := environ.Session(sessionName)
+	if !ok {
+		return nil, fmt.Errorf("session %s not found", sessionName)
+	}
+
+	return session, nil
+}
+
+// lookupSessions returns the session of the given name, or all the sessions sorted by name if the name is empty
+func lookupSessions(environ *bbgo.Environment, sessionName string) ([]*bbgo.ExchangeSession, error) {
+	if len(sessionName) > 0 {
+		session, err := lookupSession(environ, sessionName)
+		if err != nil {
+			return nil, err
+		}
+
+		return []*bbgo.ExchangeSession{session}, nil
+	}
+
+	var sessions []*bbgo.ExchangeSession
+	for _, session := range environ.Sessions() {
+		sessions = append(sessions, session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Name < sessions[j].Name
+	})
+	return sessions, nil
+}
+
+// lookupExchangeSession finds the session by the session name first,
+// then falls back to the first session (sorted by name) of the exchange name.
+func lookupExchangeSession(environ *bbgo.Environment, name string) (*bbgo.ExchangeSession, error) {
+	if session, ok := environ.Session(name); ok {
+		return session, nil
+	}
+
+	exchangeName, err := types.ValidExchangeName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := lookupSessions(environ, "")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		if session.ExchangeName == exchangeName {
+			return session, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no session of exchange %s found", exchangeName)
+}
+
+func isOpenOrderStates(states []string) bool {
+	if len(states) == 0 {
+		return false
+	}
+
+	for _, state := range states {
+		switch types.OrderStatus(strings.ToUpper(state)) {
+		case types.OrderStatusNew, types.OrderStatusPartiallyFilled:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func filterOrders(orders []types.Order, states []string, groupID uint32) (filtered []types.Order) {
+	for _, order := range orders {
+		if groupID > 0 && order.GroupID != groupID {
+			continue
+		}
+
+		if len(states) > 0 && !containsState(states, order.Status) {
+			continue
+		}
+
+		filtered = append(filtered, order)
+	}
+
+	return filtered
+}
+
+func containsState(states []string, status types.OrderStatus) bool {
+	for _, state := range states {
+		if strings.EqualFold(state, string(status)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// timeRange converts the millisecond timestamps of the request to the time range, zero means unbounded
+func timeRange(from, to int64) (since, until *time.Time) {
+	if from != 0 {
+		t := time.UnixMilli(from)
+		since = &t
+	}
+
+	if to != 0 {
+		t := time.UnixMilli(to)
+		until = &t
+	}
+
+	return since, until
+}
+
+// pageRange returns the slice range of the given page and limit when pagination is enabled,
+// otherwise the range of the offset and limit. The page number starts from 1.
+func pageRange(n int, pagination bool, page, limit, offset int64) (int, int) {
+	if pagination && page > 0 && limit > 0 {
+		offset = (page - 1) * limit
+	}
+
+	start, end := int64(0), int64(n)
+	if offset > 0 {
+		start = offset
+	}
+
+	if start > end {
+		start = end
+	}
+
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+
+	return int(start), int(end)
+}
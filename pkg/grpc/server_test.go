@@ -0,0 +1,454 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/pb"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+// orderQueryExchange adds the order query service to the mock exchange
+type orderQueryExchange struct {
+	*mocks.MockExchange
+
+	orders map[string]types.Order
+}
+
+func (e *orderQueryExchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	order, ok := e.orders[q.OrderID]
+	if !ok {
+		return nil, fmt.Errorf("order %s not found", q.OrderID)
+	}
+
+	return &order, nil
+}
+
+func (e *orderQueryExchange) QueryOrderTrades(ctx context.Context, q types.OrderQuery) ([]types.Trade, error) {
+	return nil, nil
+}
+
+type testStrategy struct {
+	*bbgo.StrategyController
+
+	Symbol   string
+	Position *types.Position
+
+	signals []bbgo.ExternalSignal
+}
+
+func (s *testStrategy) ID() string {
+	return "teststrategy"
+}
+
+func (s *testStrategy) InstanceID() string {
+	return s.ID() + ":" + s.Symbol
+}
+
+func (s *testStrategy) CurrentPosition() *types.Position {
+	return s.Position
+}
+
+func (s *testStrategy) ReceiveExternalSignal(ctx context.Context, signal bbgo.ExternalSignal, authToken string) (types.OrderSlice, error) {
+	if authToken != "secret" {
+		return nil, bbgo.ErrInvalidSignalAuthToken
+	}
+
+	s.signals = append(s.signals, signal)
+	return types.OrderSlice{
+		{SubmitOrder: types.SubmitOrder{Symbol: signal.Symbol, Side: types.SideTypeBuy, Type: types.OrderTypeMarket}, OrderID: 5},
+	}, nil
+}
+
+func (s *testStrategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	return nil
+}
+
+func newTestServer(t *testing.T, options ...func(environ *bbgo.Environment)) (*grpc.ClientConn, *orderQueryExchange, *testStrategy) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+	exchange := &orderQueryExchange{MockExchange: mockEx, orders: map[string]types.Order{}}
+
+	environ := bbgo.NewEnvironment()
+	session := environ.AddExchange("binance", exchange)
+	session.ExchangeName = types.ExchangeBinance
+	session.Margin = true
+	session.Account.MarginLevel = fixedpoint.MustNewFromString("2.5")
+	session.Account.UpdateBalances(types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.MustNewFromString("0.5"), Borrowed: fixedpoint.MustNewFromString("0.1")},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromInt(1000)},
+	})
+
+	strategy := &testStrategy{
+		StrategyController: &bbgo.StrategyController{Status: types.StrategyStatusRunning},
+		Symbol:             "BTCUSDT",
+		Position: &types.Position{
+			Symbol:        "BTCUSDT",
+			BaseCurrency:  "BTC",
+			QuoteCurrency: "USDT",
+			Base:          fixedpoint.MustNewFromString("0.2"),
+			AverageCost:   fixedpoint.NewFromInt(20000),
+		},
+	}
+
+	for _, option := range options {
+		option(environ)
+	}
+
+	trader := bbgo.NewTrader(environ)
+	require.NoError(t, trader.AttachStrategyOn("binance", strategy))
+
+	server := &Server{Environ: environ, Trader: trader}
+	grpcServer := server.newGrpcServer()
+
+	listener := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn, exchange, strategy
+}
+
+func TestTradingService_QueryOrders(t *testing.T) {
+	conn, exchange, _ := newTestServer(t)
+	client := pb.NewTradingServiceClient(conn)
+	ctx := context.Background()
+
+	exchange.orders["1"] = types.Order{
+		SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit},
+		Exchange:    types.ExchangeBinance,
+		OrderID:     1,
+		Status:      types.OrderStatusFilled,
+	}
+
+	resp, err := client.QueryOrder(ctx, &pb.QueryOrderRequest{Session: "binance", Symbol: "BTCUSDT", Id: "1"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1", resp.Order.Id)
+		assert.Equal(t, "FILLED", resp.Order.Status)
+	}
+
+	_, err = client.QueryOrder(ctx, &pb.QueryOrderRequest{Session: "binance"})
+	assert.Error(t, err)
+
+	_, err = client.QueryOrder(ctx, &pb.QueryOrderRequest{Session: "max", Id: "1"})
+	assert.Error(t, err)
+
+	exchange.EXPECT().QueryOpenOrders(gomock.Any(), "BTCUSDT").Return([]types.Order{
+		{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", GroupID: 1}, OrderID: 2, Status: types.OrderStatusNew},
+		{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", GroupID: 2}, OrderID: 3, Status: types.OrderStatusPartiallyFilled},
+		{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", GroupID: 2}, OrderID: 4, Status: types.OrderStatusNew},
+	}, nil).Times(2)
+
+	ordersResp, err := client.QueryOrders(ctx, &pb.QueryOrdersRequest{Session: "binance", Symbol: "BTCUSDT", GroupId: 2})
+	if assert.NoError(t, err) && assert.Len(t, ordersResp.Orders, 2) {
+		assert.Equal(t, "3", ordersResp.Orders[0].Id)
+		assert.Equal(t, "4", ordersResp.Orders[1].Id)
+	}
+
+	ordersResp, err = client.QueryOrders(ctx, &pb.QueryOrdersRequest{
+		Session:    "binance",
+		Symbol:     "BTCUSDT",
+		State:      []string{"new"},
+		Pagination: true,
+		Page:       2,
+		Limit:      1,
+	})
+	if assert.NoError(t, err) && assert.Len(t, ordersResp.Orders, 1) {
+		assert.Equal(t, "4", ordersResp.Orders[0].Id)
+	}
+
+	_, err = client.QueryOrders(ctx, &pb.QueryOrdersRequest{Session: "binance", State: []string{"FILLED"}})
+	assert.Error(t, err, "order history requires the database")
+}
+
+func TestTradingService_QueryOrderHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	conn, _, _ := newTestServer(t, func(environ *bbgo.Environment) {
+		environ.OrderService = &service.OrderService{DB: sqlx.NewDb(db, "mysql")}
+	})
+	client := pb.NewTradingServiceClient(conn)
+	ctx := context.Background()
+
+	// the filters and the page are selected by the database
+	from := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE orders.exchange = ? AND orders.symbol = ? AND orders.created_at >= ? AND orders.status IN (?) GROUP BY orders.gid  ORDER BY orders.gid DESC LIMIT 2 OFFSET 2")).
+		WithArgs(sqlmock.AnyArg(), "BTCUSDT", time.UnixMilli(from.UnixMilli()), "FILLED").
+		WillReturnRows(sqlmock.NewRows([]string{"gid", "order_id", "symbol", "status", "average_price"}).
+			AddRow(3, 3, "BTCUSDT", "FILLED", 20000.0).
+			AddRow(2, 2, "BTCUSDT", "FILLED", 20000.0))
+
+	resp, err := client.QueryOrders(ctx, &pb.QueryOrdersRequest{
+		Session:    "binance",
+		Symbol:     "BTCUSDT",
+		State:      []string{"filled"},
+		OrderBy:    "desc",
+		From:       from.UnixMilli(),
+		Pagination: true,
+		Page:       2,
+		Limit:      2,
+	})
+	if assert.NoError(t, err) && assert.Len(t, resp.Orders, 2) {
+		assert.Equal(t, "3", resp.Orders[0].Id)
+		assert.Equal(t, "2", resp.Orders[1].Id)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = client.QueryOrders(ctx, &pb.QueryOrdersRequest{Session: "binance", State: []string{"FILLED"}, GroupId: 1})
+	assert.Error(t, err, "the group id is not stored in the database")
+}
+
+func TestTradingService_QueryTradeHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	conn, _, _ := newTestServer(t, func(environ *bbgo.Environment) {
+		environ.TradeService = &service.TradeService{DB: sqlx.NewDb(db, "mysql")}
+	})
+	client := pb.NewTradingServiceClient(conn)
+
+	// the page is selected by the database
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM trades WHERE symbol = ? AND exchange = ? ORDER BY traded_at DESC, gid DESC LIMIT 2 OFFSET 4")).
+		WithArgs("BTCUSDT", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"gid", "id", "symbol", "side"}).
+			AddRow(5, 5, "BTCUSDT", "BUY").
+			AddRow(4, 4, "BTCUSDT", "SELL"))
+
+	resp, err := client.QueryTrades(context.Background(), &pb.QueryTradesRequest{
+		Exchange:   "binance",
+		Symbol:     "BTCUSDT",
+		OrderBy:    "desc",
+		Pagination: true,
+		Page:       3,
+		Limit:      2,
+	})
+	if assert.NoError(t, err) && assert.Len(t, resp.Trades, 2) {
+		assert.Equal(t, "5", resp.Trades[0].Id)
+		assert.Equal(t, "4", resp.Trades[1].Id)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountService(t *testing.T) {
+	conn, _, _ := newTestServer(t)
+	client := pb.NewAccountServiceClient(conn)
+	ctx := context.Background()
+
+	balancesResp, err := client.QueryBalances(ctx, &pb.QueryBalancesRequest{})
+	if assert.NoError(t, err) && assert.Len(t, balancesResp.Balances, 2) {
+		assert.Equal(t, "BTC", balancesResp.Balances[0].Currency)
+		assert.Equal(t, "binance", balancesResp.Balances[0].Session)
+		assert.Equal(t, "0.1", balancesResp.Balances[0].Borrowed)
+		assert.Equal(t, "USDT", balancesResp.Balances[1].Currency)
+	}
+
+	positionsResp, err := client.QueryPositions(ctx, &pb.QueryPositionsRequest{Session: "binance"})
+	if assert.NoError(t, err) && assert.Len(t, positionsResp.Positions, 1) {
+		position := positionsResp.Positions[0]
+		assert.Equal(t, "BTCUSDT", position.Symbol)
+		assert.Equal(t, "0.2", position.Base)
+		assert.Equal(t, "20000", position.AverageCost)
+		assert.Equal(t, "teststrategy", position.Strategy)
+		assert.Equal(t, "teststrategy:BTCUSDT", position.StrategyInstanceId)
+	}
+
+	positionsResp, err = client.QueryPositions(ctx, &pb.QueryPositionsRequest{Symbol: "ETHUSDT"})
+	if assert.NoError(t, err) {
+		assert.Empty(t, positionsResp.Positions)
+	}
+
+	marginResp, err := client.QueryMarginInfo(ctx, &pb.QueryMarginInfoRequest{Session: "binance"})
+	if assert.NoError(t, err) && assert.Len(t, marginResp.MarginInfos, 1) {
+		info := marginResp.MarginInfos[0]
+		assert.Equal(t, "2.5", info.MarginLevel)
+		if assert.Len(t, info.Balances, 1) {
+			assert.Equal(t, "BTC", info.Balances[0].Currency)
+		}
+	}
+}
+
+func TestStrategyService(t *testing.T) {
+	conn, _, strategy := newTestServer(t)
+	client := pb.NewStrategyServiceClient(conn)
+	ctx := context.Background()
+
+	listResp, err := client.ListStrategies(ctx, &pb.ListStrategiesRequest{})
+	require.NoError(t, err)
+	require.Len(t, listResp.Strategies, 1)
+
+	id := listResp.Strategies[0].Id
+	assert.Equal(t, "binance.teststrategy:BTCUSDT", id)
+	assert.Equal(t, "binance", listResp.Strategies[0].Session)
+	assert.Equal(t, "RUNNING", listResp.Strategies[0].Status)
+	assert.True(t, listResp.Strategies[0].Toggleable)
+	assert.True(t, listResp.Strategies[0].EmergencyStoppable)
+
+	resp, err := client.SuspendStrategy(ctx, &pb.StrategyRequest{Id: id})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "STOPPED", resp.Strategy.Status)
+		assert.Equal(t, types.StrategyStatusStopped, strategy.GetStatus())
+	}
+
+	_, err = client.SuspendStrategy(ctx, &pb.StrategyRequest{Id: id})
+	assert.Error(t, err, "suspending a stopped strategy")
+
+	resp, err = client.ResumeStrategy(ctx, &pb.StrategyRequest{Id: id})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "RUNNING", resp.Strategy.Status)
+	}
+
+	emergencyStopped := false
+	strategy.OnEmergencyStop(func() {
+		emergencyStopped = true
+	})
+
+	resp, err = client.EmergencyStopStrategy(ctx, &pb.StrategyRequest{Id: id})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "STOPPED", resp.Strategy.Status)
+		assert.True(t, emergencyStopped)
+	}
+
+	_, err = client.QueryStrategyStatus(ctx, &pb.StrategyRequest{Id: "binance.unknown"})
+	assert.Error(t, err)
+}
+
+func TestSignalService(t *testing.T) {
+	conn, _, strategy := newTestServer(t)
+	client := pb.NewSignalServiceClient(conn)
+
+	request := &pb.SubmitSignalRequest{
+		Signal: &pb.Signal{
+			Id:             "signal-1",
+			Strategy:       "teststrategy:BTCUSDT",
+			Symbol:         "BTCUSDT",
+			Type:           pb.SignalType_TARGET_POSITION,
+			TargetPosition: "0.5",
+			CreatedAt:      1660000000000,
+		},
+	}
+
+	_, err := client.SubmitSignal(context.Background(), request)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	resp, err := client.SubmitSignal(ctx, request)
+	if assert.NoError(t, err) && assert.Len(t, resp.Orders, 1) {
+		assert.Equal(t, "5", resp.Orders[0].Id)
+	}
+
+	if assert.Len(t, strategy.signals, 1) {
+		signal := strategy.signals[0]
+		assert.Equal(t, "signal-1", signal.ID)
+		assert.Equal(t, bbgo.ExternalSignalTypeTargetPosition, signal.Type)
+		assert.Equal(t, "0.5", signal.TargetPosition.String())
+		assert.Equal(t, int64(1660000000000), signal.Time.Time().UnixMilli())
+	}
+
+	request.Signal.Strategy = "binance.teststrategy:BTCUSDT"
+	_, err = client.SubmitSignal(ctx, request)
+	assert.NoError(t, err, "the strategy signature is accepted")
+
+	request.Signal.Strategy = "teststrategy:ETHUSDT"
+	_, err = client.SubmitSignal(ctx, request)
+	assert.Error(t, err, "receiver not found")
+
+	request.Signal.Strategy = ""
+	request.Signal.Quantity = "abc"
+	_, err = client.SubmitSignal(ctx, request)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "invalid quantity")
+
+	_, err = (&SignalService{}).SubmitSignal(ctx, request)
+	assert.Error(t, err, "trader is not initialized")
+}
+
+func Test_toExternalSignal(t *testing.T) {
+	tests := []struct {
+		name   string
+		signal *pb.Signal
+		expErr string
+	}{
+		{"order intent", &pb.Signal{Type: pb.SignalType_ORDER_INTENT, Side: pb.SignalSide_SIGNAL_SIDE_SELL, Quantity: "1", OrderType: pb.OrderType_LIMIT, Price: "100"}, ""},
+		{"target position without side", &pb.Signal{Type: pb.SignalType_TARGET_POSITION, TargetPosition: "1"}, ""},
+		{"order intent without side", &pb.Signal{Type: pb.SignalType_ORDER_INTENT, Quantity: "1"}, "side is required"},
+		{"unknown side", &pb.Signal{Type: pb.SignalType_ORDER_INTENT, Side: pb.SignalSide(9), Quantity: "1"}, "unexpected signal side"},
+		{"stop limit", &pb.Signal{Type: pb.SignalType_ORDER_INTENT, Side: pb.SignalSide_SIGNAL_SIDE_BUY, Quantity: "1", OrderType: pb.OrderType_STOP_LIMIT, Price: "100"}, "unsupported order type"},
+		{"post only", &pb.Signal{Type: pb.SignalType_TARGET_POSITION, TargetPosition: "1", OrderType: pb.OrderType_POST_ONLY, Price: "100"}, "unsupported order type"},
+		{"unknown order type", &pb.Signal{Type: pb.SignalType_TARGET_POSITION, TargetPosition: "1", OrderType: pb.OrderType(9)}, "unsupported order type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := toExternalSignal(tt.signal)
+			if tt.expErr == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.expErr)
+			}
+		})
+	}
+
+	signal, err := toExternalSignal(&pb.Signal{Type: pb.SignalType_ORDER_INTENT, Side: pb.SignalSide_SIGNAL_SIDE_SELL, Quantity: "1", OrderType: pb.OrderType_LIMIT, Price: "100"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.SideTypeSell, signal.Side)
+		assert.Equal(t, types.OrderTypeLimit, signal.OrderType)
+	}
+}
+
+func Test_pageRange(t *testing.T) {
+	tests := []struct {
+		name                string
+		n                   int
+		pagination          bool
+		page, limit, offset int64
+		expStart, expEnd    int
+	}{
+		{"all", 10, false, 0, 0, 0, 0, 10},
+		{"offset and limit", 10, false, 0, 3, 2, 2, 5},
+		{"offset out of range", 10, false, 0, 3, 20, 10, 10},
+		{"page", 10, true, 2, 4, 0, 4, 8},
+		{"last page", 10, true, 3, 4, 0, 8, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := pageRange(tt.n, tt.pagination, tt.page, tt.limit, tt.offset)
+			assert.Equal(t, tt.expStart, start)
+			assert.Equal(t, tt.expEnd, end)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/pb"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type SignalService struct {
+	Config  *bbgo.Config
+	Environ *bbgo.Environment
+	Trader  *bbgo.Trader
+
+	pb.UnimplementedSignalServiceServer
+}
+
+// SubmitSignal dispatches the signal to the external signal receiver strategy.
+// The auth token is read from the "authorization" metadata, e.g. "Bearer <token>".
+func (s *SignalService) SubmitSignal(ctx context.Context, request *pb.SubmitSignalRequest) (*pb.SubmitSignalResponse, error) {
+	if s.Trader == nil {
+		return nil, fmt.Errorf("trader is not initialized")
+	}
+
+	if request.Signal == nil {
+		return nil, fmt.Errorf("signal can not be empty")
+	}
+
+	signal, err := toExternalSignal(request.Signal)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	createdOrders, err := s.Trader.DispatchExternalSignal(ctx, signal, authTokenFromContext(ctx))
+	if err != nil {
+		if errors.Is(err, bbgo.ErrInvalidSignalAuthToken) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		return nil, err
+	}
+
+	resp := &pb.SubmitSignalResponse{}
+	for _, createdOrder := range createdOrders {
+		resp.Orders = append(resp.Orders, transOrder(nil, createdOrder))
+	}
+
+	return resp, nil
+}
+
+func authTokenFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return ""
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(values[0], "Bearer "))
+}
+
+func toSignalType(signalType pb.SignalType) (bbgo.ExternalSignalType, error) {
+	switch signalType {
+	case pb.SignalType_TARGET_POSITION:
+		return bbgo.ExternalSignalTypeTargetPosition, nil
+	case pb.SignalType_ORDER_INTENT:
+		return bbgo.ExternalSignalTypeOrderIntent, nil
+
+	}
+
+	return "", fmt.Errorf("unexpected signal type: %v", signalType)
+}
+
+// toSignalSide maps the signal side strictly, the unspecified side is converted to the empty side
+func toSignalSide(side pb.SignalSide) (types.SideType, error) {
+	switch side {
+	case pb.SignalSide_SIGNAL_SIDE_UNSPECIFIED:
+		return "", nil
+	case pb.SignalSide_SIGNAL_SIDE_BUY:
+		return types.SideTypeBuy, nil
+	case pb.SignalSide_SIGNAL_SIDE_SELL:
+		return types.SideTypeSell, nil
+
+	}
+
+	return "", fmt.Errorf("unexpected signal side: %v", side)
+}
+
+// toSignalOrderType maps the order type strictly, unlike toOrderType the unsupported order types are rejected
+func toSignalOrderType(orderType pb.OrderType) (types.OrderType, error) {
+	switch orderType {
+	case pb.OrderType_MARKET:
+		return types.OrderTypeMarket, nil
+	case pb.OrderType_LIMIT:
+		return types.OrderTypeLimit, nil
+
+	}
+
+	return "", fmt.Errorf("unsupported order type %v, order type should be either MARKET or LIMIT", orderType)
+}
+
+func toExternalSignal(pbSignal *pb.Signal) (signal bbgo.ExternalSignal, err error) {
+	signal = bbgo.ExternalSignal{
+		ID:       pbSignal.Id,
+		Strategy: pbSignal.Strategy,
+		Symbol:   pbSignal.Symbol,
+	}
+
+	if pbSignal.CreatedAt > 0 {
+		signal.Time = types.NewMillisecondTimestampFromInt(pbSignal.CreatedAt)
+	}
+
+	if signal.Type, err = toSignalType(pbSignal.Type); err != nil {
+		return signal, err
+	}
+
+	if signal.Side, err = toSignalSide(pbSignal.Side); err != nil {
+		return signal, err
+	}
+
+	if signal.Type == bbgo.ExternalSignalTypeOrderIntent && signal.Side == "" {
+		return signal, fmt.Errorf("side is required for the order intent signal")
+	}
+
+	if signal.OrderType, err = toSignalOrderType(pbSignal.OrderType); err != nil {
+		return signal, err
+	}
+
+	if signal.TargetPosition, err = fixedpoint.NewFromString(pbSignal.TargetPosition); err != nil {
+		return signal, fmt.Errorf("invalid target position %q: %w", pbSignal.TargetPosition, err)
+	}
+
+	if signal.Quantity, err = fixedpoint.NewFromString(pbSignal.Quantity); err != nil {
+		return signal, fmt.Errorf("invalid quantity %q: %w", pbSignal.Quantity, err)
+	}
+
+	if signal.Price, err = fixedpoint.NewFromString(pbSignal.Price); err != nil {
+		return signal, fmt.Errorf("invalid price %q: %w", pbSignal.Price, err)
+	}
+
+	return signal, nil
+}
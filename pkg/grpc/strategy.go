@@ -0,0 +1,142 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/pb"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type StrategyService struct {
+	Config  *bbgo.Config
+	Environ *bbgo.Environment
+	Trader  *bbgo.Trader
+
+	pb.UnimplementedStrategyServiceServer
+}
+
+func (s *StrategyService) ListStrategies(ctx context.Context, request *pb.ListStrategiesRequest) (*pb.ListStrategiesResponse, error) {
+	strategies, err := s.strategies()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListStrategiesResponse{}
+	for id, strategy := range strategies {
+		if len(request.Session) > 0 && signatureSession(id) != request.Session {
+			continue
+		}
+
+		resp.Strategies = append(resp.Strategies, transStrategy(id, strategy))
+	}
+
+	sort.Slice(resp.Strategies, func(i, j int) bool {
+		return resp.Strategies[i].Id < resp.Strategies[j].Id
+	})
+	return resp, nil
+}
+
+func (s *StrategyService) QueryStrategyStatus(ctx context.Context, request *pb.StrategyRequest) (*pb.StrategyResponse, error) {
+	strategy, err := s.strategy(request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.StrategyResponse{Strategy: transStrategy(request.Id, strategy)}, nil
+}
+
+func (s *StrategyService) SuspendStrategy(ctx context.Context, request *pb.StrategyRequest) (*pb.StrategyResponse, error) {
+	strategy, err := s.strategy(request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	controller, ok := strategy.(bbgo.StrategyToggler)
+	if !ok {
+		return nil, fmt.Errorf("strategy %s does not implement StrategyToggler", request.Id)
+	}
+
+	if controller.GetStatus() != types.StrategyStatusRunning {
+		return nil, fmt.Errorf("strategy %s is not running", request.Id)
+	}
+
+	if err := controller.Suspend(); err != nil {
+		return nil, err
+	}
+
+	log.Infof("grpc: strategy %s is suspended", request.Id)
+	return &pb.StrategyResponse{Strategy: transStrategy(request.Id, strategy)}, nil
+}
+
+func (s *StrategyService) ResumeStrategy(ctx context.Context, request *pb.StrategyRequest) (*pb.StrategyResponse, error) {
+	strategy, err := s.strategy(request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	controller, ok := strategy.(bbgo.StrategyToggler)
+	if !ok {
+		return nil, fmt.Errorf("strategy %s does not implement StrategyToggler", request.Id)
+	}
+
+	if controller.GetStatus() != types.StrategyStatusStopped {
+		return nil, fmt.Errorf("strategy %s is running", request.Id)
+	}
+
+	if err := controller.Resume(); err != nil {
+		return nil, err
+	}
+
+	log.Infof("grpc: strategy %s is resumed", request.Id)
+	return &pb.StrategyResponse{Strategy: transStrategy(request.Id, strategy)}, nil
+}
+
+func (s *StrategyService) EmergencyStopStrategy(ctx context.Context, request *pb.StrategyRequest) (*pb.StrategyResponse, error) {
+	strategy, err := s.strategy(request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	stopper, ok := strategy.(bbgo.EmergencyStopper)
+	if !ok {
+		return nil, fmt.Errorf("strategy %s does not implement EmergencyStopper", request.Id)
+	}
+
+	if err := stopper.EmergencyStop(); err != nil {
+		return nil, err
+	}
+
+	log.Warnf("grpc: strategy %s is emergency stopped", request.Id)
+	return &pb.StrategyResponse{Strategy: transStrategy(request.Id, strategy)}, nil
+}
+
+func (s *StrategyService) strategies() (map[string]bbgo.SingleExchangeStrategy, error) {
+	if s.Trader == nil {
+		return nil, fmt.Errorf("trader is not initialized")
+	}
+
+	return s.Trader.StrategiesBySignature()
+}
+
+func (s *StrategyService) strategy(id string) (bbgo.SingleExchangeStrategy, error) {
+	if len(id) == 0 {
+		return nil, fmt.Errorf("strategy id can not be empty")
+	}
+
+	strategies, err := s.strategies()
+	if err != nil {
+		return nil, err
+	}
+
+	strategy, ok := strategies[id]
+	if !ok {
+		return nil, fmt.Errorf("strategy %s not found", id)
+	}
+
+	return strategy, nil
+}
@@ -28,7 +28,12 @@ func (l *Line) handleKLineWindowUpdate(interval types.Interval, allKLines types.
 	}
 
 	newTime := allKLines.Last().EndTime.Time()
-	delta := int(newTime.Sub(l.currentTime).Minutes()) / l.Interval.Minutes()
+
+	// the bars are not time-based, every bar moves the line by one step
+	delta := 1
+	if minutes := l.Interval.Minutes(); minutes > 0 {
+		delta = int(newTime.Sub(l.currentTime).Minutes()) / minutes
+	}
 	l.startIndex += delta
 	l.endIndex += delta
 	l.currentTime = newTime
@@ -0,0 +1,49 @@
+package interact
+
+import (
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// AuditLogger records the executions of the private commands
+type AuditLogger interface {
+	Insert(log types.AuditLog) error
+}
+
+func (it *Interact) audit(session Session, command string, args []string, err error) {
+	outcome := types.AuditOutcomeSuccess
+	if err != nil {
+		outcome = types.AuditOutcomeError
+	}
+
+	it.auditOutcome(session, command, args, outcome, err)
+}
+
+func (it *Interact) auditOutcome(session Session, command string, args []string, outcome types.AuditOutcome, err error) {
+	record := types.AuditLog{
+		Actor:     session.ID(),
+		Role:      string(session.GetRole()),
+		Command:   command,
+		Arguments: strings.Join(args, " "),
+		Outcome:   outcome,
+		Time:      types.Time(time.Now()),
+	}
+
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	log.Infof("[interact] audit: %s", record.String())
+
+	if it.auditLogger == nil {
+		return
+	}
+
+	if err := it.auditLogger.Insert(record); err != nil {
+		log.WithError(err).Errorf("[interact] can not insert the audit log")
+	}
+}
@@ -0,0 +1,272 @@
+package interact
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type memoryAuditLogger struct {
+	logs []types.AuditLog
+}
+
+func (l *memoryAuditLogger) Insert(log types.AuditLog) error {
+	l.logs = append(l.logs, log)
+	return nil
+}
+
+type roleTestInteraction struct {
+	stopped bool
+	closed  string
+	reset   string
+}
+
+func (m *roleTestInteraction) Commands(interact *Interact) {
+	interact.PrivateCommand("/position", "", func(reply Reply) error {
+		reply.Message("position")
+		return nil
+	}).RequireRole(RoleViewer)
+
+	interact.PrivateCommand("/closeposition", "", func(reply Reply) error {
+		reply.Message("choose one strategy")
+		return nil
+	}).Next(func(signature string) error {
+		m.closed = signature
+		return nil
+	})
+
+	interact.PrivateCommand("/emergencystop", "", func(reply Reply) error {
+		m.stopped = true
+		reply.Message("stopped")
+		return nil
+	}).RequireRole(RoleAdmin).Confirm()
+
+	interact.PrivateCommand("/resetposition", "", func(reply Reply) error {
+		reply.Message("choose one strategy")
+		return nil
+	}).Next(func(signature string) error {
+		m.reset = signature
+		return nil
+	}).Confirm()
+}
+
+func newRoleTestInteraction(t *testing.T) (*Interact, *Telegram, *roleTestInteraction, *memoryAuditLogger) {
+	b, err := tb.NewBot(tb.Settings{
+		Offline: true,
+	})
+	require.NoError(t, err)
+
+	it := New()
+	telegram := &Telegram{
+		Bot: b,
+	}
+	it.AddMessenger(telegram)
+
+	logger := &memoryAuditLogger{}
+	it.SetAuditLogger(logger)
+
+	custom := &roleTestInteraction{}
+	it.AddCustomInteraction(custom)
+	it.AddCustomInteraction(&AuthInteract{
+		Mode:  AuthModeToken,
+		Token: "admin-token",
+		TokenRoles: map[string]Role{
+			"viewer-token": RoleViewer,
+		},
+	})
+
+	require.NoError(t, it.init())
+	return it, telegram, custom, logger
+}
+
+func TestAuthInteract_TokenRoles(t *testing.T) {
+	it, telegram, _, _ := newRoleTestInteraction(t)
+
+	session := telegram.loadSession(&tb.Message{Chat: &tb.Chat{ID: 22}, Sender: &tb.User{ID: 999}})
+	assert.NoError(t, it.runCommand(session, "/auth", nil, telegram.newReply(session)))
+	assert.NoError(t, it.handleResponse(session, "viewer-token", telegram.newReply(session)))
+	assert.True(t, session.IsAuthorized())
+	assert.Equal(t, RoleViewer, session.GetRole())
+
+	session = telegram.loadSession(&tb.Message{Chat: &tb.Chat{ID: 23}, Sender: &tb.User{ID: 1000}})
+	assert.NoError(t, it.runCommand(session, "/auth", nil, telegram.newReply(session)))
+	assert.NoError(t, it.handleResponse(session, "admin-token", telegram.newReply(session)))
+	assert.Equal(t, RoleAdmin, session.GetRole())
+
+	// the sessions authorized before the roles were introduced
+	session = telegram.loadSession(&tb.Message{Chat: &tb.Chat{ID: 24}, Sender: &tb.User{ID: 1001}})
+	assert.Equal(t, RoleAdmin, session.GetRole())
+
+	role, err := ParseRole("Operator")
+	assert.NoError(t, err)
+	assert.Equal(t, RoleOperator, role)
+
+	_, err = ParseRole("root")
+	assert.Error(t, err)
+}
+
+func TestAuthInteract_OTPTokenRoles(t *testing.T) {
+	b, err := tb.NewBot(tb.Settings{
+		Offline: true,
+	})
+	require.NoError(t, err)
+
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: "interact", AccountName: "test"})
+	require.NoError(t, err)
+
+	it := New()
+	telegram := &Telegram{Bot: b}
+	it.AddMessenger(telegram)
+	it.AddCustomInteraction(&AuthInteract{
+		Mode:               AuthModeOTP,
+		OneTimePasswordKey: key,
+		TokenRoles: map[string]Role{
+			"operator-token": RoleOperator,
+		},
+	})
+	require.NoError(t, it.init())
+
+	code, err := totp.GenerateCode(key.Secret(), time.Now())
+	require.NoError(t, err)
+
+	// the role token alone doesn't authorize the session
+	session := telegram.loadSession(&tb.Message{Chat: &tb.Chat{ID: 22}, Sender: &tb.User{ID: 999}})
+	assert.NoError(t, it.runCommand(session, "/auth", nil, telegram.newReply(session)))
+	assert.NoError(t, it.handleResponse(session, "operator-token", telegram.newReply(session)))
+	assert.False(t, session.IsAuthorized())
+
+	assert.Error(t, it.handleResponse(session, "000000x", telegram.newReply(session)))
+	assert.False(t, session.IsAuthorized())
+
+	assert.NoError(t, it.handleResponse(session, code, telegram.newReply(session)))
+	assert.True(t, session.IsAuthorized())
+	assert.Equal(t, RoleOperator, session.GetRole())
+	assert.Equal(t, StateAuthenticated, session.GetState())
+
+	// the one-time password alone authorizes the default role
+	session = telegram.loadSession(&tb.Message{Chat: &tb.Chat{ID: 23}, Sender: &tb.User{ID: 1000}})
+	assert.NoError(t, it.runCommand(session, "/auth", nil, telegram.newReply(session)))
+	assert.NoError(t, it.handleResponse(session, code, telegram.newReply(session)))
+	assert.True(t, session.IsAuthorized())
+	assert.Equal(t, RoleAdmin, session.GetRole())
+	assert.Equal(t, StateAuthenticated, session.GetState())
+}
+
+func TestInteract_RequireRole(t *testing.T) {
+	it, telegram, custom, logger := newRoleTestInteraction(t)
+
+	session := telegram.loadSession(&tb.Message{Chat: &tb.Chat{ID: 22}, Sender: &tb.User{ID: 999}})
+	session.SetRole(RoleViewer)
+	session.SetAuthorized()
+
+	reply := telegram.newReply(session)
+	assert.NoError(t, it.runCommand(session, "/position", nil, reply))
+	assert.Equal(t, "position", reply.message)
+
+	// private commands require the operator role by default
+	err := it.runCommand(session, "/closeposition", nil, telegram.newReply(session))
+	assert.EqualError(t, err, "permission denied, /closeposition requires the operator role, your role is viewer")
+
+	// the role can be overridden
+	it.SetCommandRole("/closeposition", RoleViewer)
+	assert.NoError(t, it.runCommand(session, "/closeposition", nil, telegram.newReply(session)))
+	assert.NoError(t, it.handleResponse(session, "grid:BTCUSDT", telegram.newReply(session)))
+	assert.Equal(t, "grid:BTCUSDT", custom.closed)
+
+	if assert.Len(t, logger.logs, 4) {
+		assert.Equal(t, "/position", logger.logs[0].Command)
+		assert.Equal(t, types.AuditOutcomeSuccess, logger.logs[0].Outcome)
+		assert.Equal(t, "viewer", logger.logs[0].Role)
+		assert.Equal(t, "telegram-999-22", logger.logs[0].Actor)
+
+		assert.Equal(t, types.AuditOutcomeDenied, logger.logs[1].Outcome)
+		assert.Contains(t, logger.logs[1].Error, "permission denied")
+
+		assert.Equal(t, "/closeposition", logger.logs[3].Command)
+		assert.Equal(t, "grid:BTCUSDT", logger.logs[3].Arguments)
+	}
+}
+
+func TestInteract_Confirm(t *testing.T) {
+	it, telegram, custom, logger := newRoleTestInteraction(t)
+
+	session := telegram.loadSession(&tb.Message{Chat: &tb.Chat{ID: 22}, Sender: &tb.User{ID: 999}})
+	session.SetAuthorized()
+
+	reply := telegram.newReply(session)
+	assert.NoError(t, it.runCommand(session, "/emergencystop", nil, reply))
+	assert.Equal(t, "Are you sure to execute /emergencystop? Reply yes to confirm.", reply.message)
+	assert.Len(t, reply.buttons, 2)
+	assert.Equal(t, StateConfirming, session.GetState())
+	assert.False(t, custom.stopped)
+
+	assert.NoError(t, it.handleResponse(session, "no", telegram.newReply(session)))
+	assert.False(t, custom.stopped)
+	assert.Equal(t, StatePublic, session.GetState())
+
+	assert.NoError(t, it.runCommand(session, "/emergencystop", nil, telegram.newReply(session)))
+	assert.NoError(t, it.handleResponse(session, "yes", telegram.newReply(session)))
+	assert.True(t, custom.stopped)
+
+	// the confirmation can be disabled
+	custom.stopped = false
+	it.SetCommandConfirmation("/emergencystop", false)
+	assert.NoError(t, it.runCommand(session, "/emergencystop", nil, telegram.newReply(session)))
+	assert.True(t, custom.stopped)
+
+	if assert.Len(t, logger.logs, 3) {
+		assert.Equal(t, types.AuditOutcomeCancelled, logger.logs[0].Outcome)
+		assert.Equal(t, types.AuditOutcomeSuccess, logger.logs[1].Outcome)
+		assert.Equal(t, "admin", logger.logs[1].Role)
+	}
+}
+
+func TestInteract_ConfirmStep(t *testing.T) {
+	it, telegram, custom, logger := newRoleTestInteraction(t)
+
+	session := telegram.loadSession(&tb.Message{Chat: &tb.Chat{ID: 22}, Sender: &tb.User{ID: 999}})
+	session.SetAuthorized()
+
+	// the confirmation is asked before the step that resets the position
+	reply := telegram.newReply(session)
+	assert.NoError(t, it.runCommand(session, "/resetposition", nil, reply))
+	assert.Equal(t, "choose one strategy", reply.message)
+
+	reply = telegram.newReply(session)
+	assert.NoError(t, it.handleResponse(session, "grid:BTCUSDT", reply))
+	assert.Equal(t, "Are you sure to execute /resetposition grid:BTCUSDT? Reply yes to confirm.", reply.message)
+	assert.Equal(t, StateConfirming, session.GetState())
+	assert.Empty(t, custom.reset)
+
+	assert.NoError(t, it.handleResponse(session, "yes", telegram.newReply(session)))
+	assert.Equal(t, "grid:BTCUSDT", custom.reset)
+	assert.Equal(t, StatePublic, session.GetState())
+
+	// the overridden confirmation is asked before the last step as well
+	it.SetCommandConfirmation("/closeposition", true)
+	assert.NoError(t, it.runCommand(session, "/closeposition", nil, telegram.newReply(session)))
+	assert.NotEqual(t, StateConfirming, session.GetState())
+
+	assert.NoError(t, it.handleResponse(session, "grid:ETHUSDT", telegram.newReply(session)))
+	assert.Equal(t, StateConfirming, session.GetState())
+
+	assert.NoError(t, it.handleResponse(session, "no", telegram.newReply(session)))
+	assert.Empty(t, custom.closed)
+	assert.Equal(t, StatePublic, session.GetState())
+
+	if assert.Len(t, logger.logs, 4) {
+		assert.Equal(t, "/resetposition", logger.logs[1].Command)
+		assert.Equal(t, "grid:BTCUSDT", logger.logs[1].Arguments)
+		assert.Equal(t, types.AuditOutcomeSuccess, logger.logs[1].Outcome)
+
+		assert.Equal(t, "/closeposition", logger.logs[3].Command)
+		assert.Equal(t, "grid:ETHUSDT", logger.logs[3].Arguments)
+		assert.Equal(t, types.AuditOutcomeCancelled, logger.logs[3].Outcome)
+	}
+}
@@ -32,6 +32,41 @@ type AuthInteract struct {
 	Token string `json:"authToken,omitempty"`
 
 	OneTimePasswordKey *otp.Key `json:"otpKey,omitempty"`
+
+	// Role is the role of the users authorized by the token or the one-time password, defaults to admin
+	Role Role `json:"role,omitempty"`
+
+	// TokenRoles maps the additional authentication tokens to the roles,
+	// so that you can give the viewer or the operator access to other users.
+	// In the OTP mode, the one-time password is still required after the token.
+	TokenRoles map[string]Role `json:"tokenRoles,omitempty"`
+}
+
+func (it *AuthInteract) defaultRole() Role {
+	if it.Role == "" {
+		return RoleAdmin
+	}
+	return it.Role
+}
+
+// tokenRole returns the role of the given token
+func (it *AuthInteract) tokenRole(token string) (Role, bool) {
+	if len(it.Token) > 0 && token == it.Token {
+		return it.defaultRole(), true
+	}
+
+	if role, ok := it.TokenRoles[token]; ok && len(token) > 0 {
+		return role, true
+	}
+
+	return "", false
+}
+
+func (it *AuthInteract) authorize(session Session, role Role, reply Reply) {
+	reply.Message("Great! You're authenticated!")
+	session.SetOriginState(StateAuthenticated)
+	session.SetRole(role)
+	session.SetAuthorized()
 }
 
 func (it *AuthInteract) Commands(interact *Interact) {
@@ -56,8 +91,10 @@ func (it *AuthInteract) Commands(interact *Interact) {
 			reply.Message("Please enter your authentication token")
 			session.SetAuthorizing(true)
 			return nil
-		}).Next(func(token string, reply Reply) error {
-			if token == it.Token {
+		}).Next(func(token string, reply Reply, session Session) error {
+			if role, ok := it.tokenRole(token); ok {
+				// the session is not authorized until the one-time password is validated
+				session.SetRole(role)
 				reply.Message("Token passed, please enter your one-time password")
 
 				code, err := totp.GenerateCode(it.OneTimePasswordKey.Secret(), time.Now())
@@ -74,15 +111,42 @@ func (it *AuthInteract) Commands(interact *Interact) {
 			return ErrAuthenticationFailed
 		}).NamedNext(StateAuthenticated, func(code string, reply Reply, session Session) error {
 			if totp.Validate(code, it.OneTimePasswordKey.Secret()) {
-				reply.Message("Great! You're authenticated!")
-				session.SetOriginState(StateAuthenticated)
-				session.SetAuthorized()
+				it.authorize(session, session.GetRole(), reply)
 				return nil
 			}
 
 			reply.Message("Incorrect authentication code")
 			return ErrAuthenticationFailed
 		})
+	} else if it.Mode == AuthModeOTP {
+		interact.Command("/auth", "authorize", func(reply Reply, session Session) error {
+			session.SetAuthorizing(true)
+			reply.Message("Enter your one-time password")
+			return nil
+		}).Next(func(code string, reply Reply, session Session) (State, error) {
+			if role, ok := it.TokenRoles[code]; ok && len(code) > 0 {
+				// the role tokens still require the one-time password
+				session.SetRole(role)
+				reply.Message("Token passed, please enter your one-time password")
+				return "", nil
+			}
+
+			if totp.Validate(code, it.OneTimePasswordKey.Secret()) {
+				it.authorize(session, it.defaultRole(), reply)
+				return StateAuthenticated, nil
+			}
+
+			reply.Message("Incorrect one-time pass code")
+			return "", ErrAuthenticationFailed
+		}).NamedNext(StateAuthenticated, func(code string, reply Reply, session Session) error {
+			if totp.Validate(code, it.OneTimePasswordKey.Secret()) {
+				it.authorize(session, session.GetRole(), reply)
+				return nil
+			}
+
+			reply.Message("Incorrect one-time pass code")
+			return ErrAuthenticationFailed
+		})
 	} else {
 		interact.Command("/auth", "authorize", func(reply Reply, session Session) error {
 			switch it.Mode {
@@ -90,10 +154,6 @@ func (it *AuthInteract) Commands(interact *Interact) {
 				session.SetAuthorizing(true)
 				reply.Message("Enter your authentication token")
 
-			case AuthModeOTP:
-				session.SetAuthorizing(true)
-				reply.Message("Enter your one-time password")
-
 			default:
 				log.Warnf("unexpected auth mode: %s", it.Mode)
 			}
@@ -101,23 +161,12 @@ func (it *AuthInteract) Commands(interact *Interact) {
 		}).NamedNext(StateAuthenticated, func(code string, reply Reply, session Session) error {
 			switch it.Mode {
 			case AuthModeToken:
-				if code == it.Token {
-					reply.Message("Great! You're authenticated!")
-					session.SetOriginState(StateAuthenticated)
-					session.SetAuthorized()
+				if role, ok := it.tokenRole(code); ok {
+					it.authorize(session, role, reply)
 					return nil
 				}
 				reply.Message("Incorrect authentication token")
 
-			case AuthModeOTP:
-				if totp.Validate(code, it.OneTimePasswordKey.Secret()) {
-					reply.Message("Great! You're authenticated!")
-					session.SetOriginState(StateAuthenticated)
-					session.SetAuthorized()
-					return nil
-				}
-				reply.Message("Incorrect one-time pass code")
-
 			default:
 				log.Warnf("unexpected auth mode: %s", it.Mode)
 			}
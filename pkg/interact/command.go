@@ -14,10 +14,19 @@ type Command struct {
 	// StateF is the command handler function
 	F interface{}
 
+	// role is the minimal role required to execute the private command
+	role Role
+
+	// confirmState is the state of the step that needs the user to confirm before executing it
+	confirmState State
+
 	stateID              int
 	states               map[State]State
 	statesFunc           map[State]interface{}
 	initState, lastState State
+
+	// lastFuncState is the state of the last defined step
+	lastFuncState State
 }
 
 func NewCommand(name, desc string, f interface{}) *Command {
@@ -32,10 +41,34 @@ func NewCommand(name, desc string, f interface{}) *Command {
 	return c.Next(f)
 }
 
+// RequireRole sets the minimal role required to execute the private command
+func (c *Command) RequireRole(role Role) *Command {
+	c.role = role
+	return c
+}
+
+// Confirm asks the user to confirm before executing the last defined step, it's used for the destructive actions.
+// Call it after the step that executes the action, e.g. the step that closes the position.
+func (c *Command) Confirm() *Command {
+	c.confirmState = c.lastFuncState
+	return c
+}
+
+// executingState returns the state of the step that needs the confirmation,
+// the last defined step is used if the step is not specified by Confirm.
+func (c *Command) executingState() State {
+	if c.confirmState != "" {
+		return c.confirmState
+	}
+
+	return c.lastFuncState
+}
+
 // Transit defines the state transition that is not related to the last defined state.
 func (c *Command) Transit(state1, state2 State, f interface{}) *Command {
 	c.states[state1] = state2
 	c.statesFunc[state1] = f
+	c.lastFuncState = state1
 	return c
 }
 
@@ -50,6 +83,7 @@ func (c *Command) NamedNext(n State, f interface{}) *Command {
 	nextState := n
 	c.states[curState] = nextState
 	c.statesFunc[curState] = f
+	c.lastFuncState = curState
 	c.lastState = nextState
 	return c
 }
@@ -65,6 +99,7 @@ func (c *Command) Cycle(f interface{}) *Command {
 	nextState := curState
 	c.states[curState] = nextState
 	c.statesFunc[curState] = f
+	c.lastFuncState = curState
 	c.lastState = nextState
 	return c
 }
@@ -84,6 +119,7 @@ func (c *Command) Next(f interface{}) *Command {
 
 	c.states[curState] = nextState
 	c.statesFunc[curState] = f
+	c.lastFuncState = curState
 	c.lastState = nextState
 	return c
 }
@@ -3,9 +3,13 @@ package interact
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 type CustomInteraction interface {
@@ -31,6 +35,8 @@ type Session interface {
 	IsAuthorized() bool
 	SetAuthorized()
 	SetAuthorizing(b bool)
+	GetRole() Role
+	SetRole(role Role)
 }
 
 // Interact implements the interaction between bot and message software.
@@ -46,11 +52,34 @@ type Interact struct {
 	states     map[State]State
 	statesFunc map[State]interface{}
 
+	// stateCommands maps the states to the commands, it's used for auditing the command steps
+	stateCommands map[State]*Command
+
+	// commandRoles overrides the required roles of the private commands
+	commandRoles map[string]Role
+
+	// confirmations overrides the confirmation settings of the commands
+	confirmations map[string]bool
+
+	// pendingCommands are the commands waiting for the confirmation, indexed by the session id
+	pendingCommands map[string]pendingCommand
+	pendingMutex    sync.Mutex
+
+	auditLogger AuditLogger
+
 	customInteractions []CustomInteraction
 
 	messengers []Messenger
 }
 
+type pendingCommand struct {
+	name string
+	args []string
+
+	// state is the state of the step that is executed after the confirmation
+	state State
+}
+
 func New() *Interact {
 	return &Interact{
 		startTime:       time.Now(),
@@ -58,9 +87,28 @@ func New() *Interact {
 		privateCommands: make(map[string]*Command),
 		states:          make(map[State]State),
 		statesFunc:      make(map[State]interface{}),
+		stateCommands:   make(map[State]*Command),
+		commandRoles:    make(map[string]Role),
+		confirmations:   make(map[string]bool),
+		pendingCommands: make(map[string]pendingCommand),
 	}
 }
 
+// SetAuditLogger sets the logger that records the executions of the private commands
+func (it *Interact) SetAuditLogger(logger AuditLogger) {
+	it.auditLogger = logger
+}
+
+// SetCommandRole overrides the minimal role required to execute the private command
+func (it *Interact) SetCommandRole(command string, role Role) {
+	it.commandRoles[command] = role
+}
+
+// SetCommandConfirmation overrides whether the command needs the confirmation before executing it
+func (it *Interact) SetCommandConfirmation(command string, confirm bool) {
+	it.confirmations[command] = confirm
+}
+
 func (it *Interact) AddCustomInteraction(custom CustomInteraction) {
 	custom.Commands(it)
 	it.customInteractions = append(it.customInteractions, custom)
@@ -101,35 +149,35 @@ func (it *Interact) handleResponse(session Session, text string, ctxObjects ...i
 	case StatePublic, StateAuthenticated:
 		return nil
 
+	case StateConfirming:
+		return it.handleConfirmation(session, text, ctxObjects...)
+
 	}
 
 	args := parseCommand(text)
 
 	state := session.GetState()
-	f, ok := it.statesFunc[state]
-	if !ok {
+	if _, ok := it.statesFunc[state]; !ok {
 		return fmt.Errorf("state function of %s is not defined", state)
 	}
 
-	ctxObjects = append(ctxObjects, session)
-	_, err := ParseFuncArgsAndCall(f, args, ctxObjects...)
-	if err != nil {
-		return err
-	}
-
-	nextState, end := it.getNextState(session, state)
-	if end {
-		session.SetState(session.GetOriginState())
+	cmd, ok := it.stateCommands[state]
+	if ok && it.needsConfirmation(cmd, state) {
+		it.askConfirmation(session, cmd, state, args, ctxObjects...)
 		return nil
 	}
 
-	session.SetState(nextState)
-	return nil
+	return it.executeStep(session, cmd, state, args, ctxObjects...)
 }
 
 func (it *Interact) getCommand(session Session, command string) (*Command, error) {
 	if session.IsAuthorized() {
 		if cmd, ok := it.privateCommands[command]; ok {
+			required := it.requiredRole(cmd)
+			if role := session.GetRole(); !role.Allows(required) {
+				return nil, fmt.Errorf("permission denied, %s requires the %s role, your role is %s", command, required, role)
+			}
+
 			return cmd, nil
 		}
 	} else {
@@ -147,20 +195,61 @@ func (it *Interact) getCommand(session Session, command string) (*Command, error
 }
 
 func (it *Interact) runCommand(session Session, command string, args []string, ctxObjects ...interface{}) error {
+	// any new command cancels the command that is waiting for the confirmation
+	it.popPendingCommand(session)
+
 	cmd, err := it.getCommand(session, command)
 	if err != nil {
+		if c, ok := it.privateCommands[command]; ok && session.IsAuthorized() {
+			it.auditOutcome(session, c.Name, args, types.AuditOutcomeDenied, err)
+		}
 		return err
 	}
 
-	ctxObjects = append(ctxObjects, session)
+	if it.needsConfirmation(cmd, cmd.initState) {
+		it.askConfirmation(session, cmd, cmd.initState, args, ctxObjects...)
+		return nil
+	}
+
 	session.SetState(cmd.initState)
-	if _, err := ParseFuncArgsAndCall(cmd.F, args, ctxObjects...); err != nil {
+	return it.executeStep(session, cmd, cmd.initState, args, ctxObjects...)
+}
+
+// askConfirmation keeps the step as the pending command and asks the user to confirm it
+func (it *Interact) askConfirmation(session Session, cmd *Command, state State, args []string, ctxObjects ...interface{}) {
+	it.pendingMutex.Lock()
+	it.pendingCommands[session.ID()] = pendingCommand{name: cmd.Name, args: args, state: state}
+	it.pendingMutex.Unlock()
+
+	session.SetState(StateConfirming)
+	if reply := findReply(ctxObjects); reply != nil {
+		reply.Message(fmt.Sprintf("Are you sure to execute %s? Reply yes to confirm.", strings.TrimSpace(cmd.Name+" "+strings.Join(args, " "))))
+		reply.AddButton("yes", "confirm", "yes")
+		reply.AddButton("no", "confirm", "no")
+	}
+}
+
+// executeStep calls the function of the state and moves the session to the next state,
+// cmd is nil if the state doesn't belong to any command.
+func (it *Interact) executeStep(session Session, cmd *Command, state State, args []string, ctxObjects ...interface{}) error {
+	ctxObjects = append(ctxObjects, session)
+	returnedState, err := ParseFuncArgsAndCall(it.statesFunc[state], args, ctxObjects...)
+	if cmd != nil && it.isPrivate(cmd) {
+		it.audit(session, cmd.Name, args, err)
+	}
+
+	if err != nil {
 		return err
 	}
 
-	// if we can successfully execute the command, then we can go to the next state.
-	state := session.GetState()
-	nextState, end := it.getNextState(session, state)
+	// if we can successfully execute the step, then we can go to the next state.
+	nextState, end := it.getNextState(session, session.GetState())
+
+	// the step can jump to another state by returning it, e.g., to skip the rest of the steps
+	if returnedState != "" {
+		_, hasTransition := it.statesFunc[returnedState]
+		nextState, end = returnedState, !hasTransition
+	}
 	if end {
 		session.SetState(session.GetOriginState())
 		return nil
@@ -170,6 +259,82 @@ func (it *Interact) runCommand(session Session, command string, args []string, c
 	return nil
 }
 
+func (it *Interact) handleConfirmation(session Session, text string, ctxObjects ...interface{}) error {
+	pending, ok := it.popPendingCommand(session)
+	session.SetState(session.GetOriginState())
+	if !ok {
+		return nil
+	}
+
+	reply := findReply(ctxObjects)
+	if kc, ok := reply.(KeyboardController); ok {
+		kc.RemoveKeyboard()
+	}
+
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "yes", "y":
+		cmd, err := it.getCommand(session, pending.name)
+		if err != nil {
+			return err
+		}
+
+		session.SetState(pending.state)
+		return it.executeStep(session, cmd, pending.state, pending.args, ctxObjects...)
+
+	default:
+		it.auditOutcome(session, pending.name, pending.args, types.AuditOutcomeCancelled, nil)
+		if reply != nil {
+			reply.Message(fmt.Sprintf("%s is cancelled", pending.name))
+		}
+	}
+
+	return nil
+}
+
+func (it *Interact) popPendingCommand(session Session) (pendingCommand, bool) {
+	it.pendingMutex.Lock()
+	defer it.pendingMutex.Unlock()
+
+	pending, ok := it.pendingCommands[session.ID()]
+	delete(it.pendingCommands, session.ID())
+	return pending, ok
+}
+
+func (it *Interact) isPrivate(cmd *Command) bool {
+	return it.privateCommands[cmd.Name] == cmd
+}
+
+func (it *Interact) requiredRole(cmd *Command) Role {
+	if role, ok := it.commandRoles[cmd.Name]; ok {
+		return role
+	}
+
+	if cmd.role != "" {
+		return cmd.role
+	}
+
+	// private commands are for operators by default
+	return RoleOperator
+}
+
+// needsConfirmation returns true if the step of the state needs the user to confirm before executing it
+func (it *Interact) needsConfirmation(cmd *Command, state State) bool {
+	if confirm, ok := it.confirmations[cmd.Name]; ok {
+		return confirm && state == cmd.executingState()
+	}
+
+	return cmd.confirmState != "" && state == cmd.confirmState
+}
+
+func findReply(ctxObjects []interface{}) Reply {
+	for _, obj := range ctxObjects {
+		if reply, ok := obj.(Reply); ok {
+			return reply
+		}
+	}
+	return nil
+}
+
 func (it *Interact) AddMessenger(messenger Messenger) {
 	// pass Responder function
 	messenger.SetTextMessageResponder(func(session Session, message string, reply Reply, ctxObjects ...interface{}) error {
@@ -217,6 +382,7 @@ func (it *Interact) registerCommands(commands map[string]*Command) error {
 		}
 		for s, f := range cmd.statesFunc {
 			it.statesFunc[s] = f
+			it.stateCommands[s] = cmd
 		}
 
 		// register commands to the service
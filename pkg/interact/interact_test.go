@@ -43,6 +43,26 @@ func Test_parseFuncArgsAndCall_InterfaceInjection(t *testing.T) {
 	assert.Equal(t, "123", buf.String())
 }
 
+func Test_parseFuncArgsAndCall_Variadic(t *testing.T) {
+	var got []string
+	f := func(w io.Writer, action string, args ...string) error {
+		got = append([]string{action}, args...)
+		return nil
+	}
+
+	buf := bytes.NewBuffer(nil)
+	_, err := ParseFuncArgsAndCall(f, []string{"add", "BTCUSDT", "cross", "30000"}, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"add", "BTCUSDT", "cross", "30000"}, got)
+
+	_, err = ParseFuncArgsAndCall(f, []string{"list"}, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"list"}, got)
+
+	_, err = ParseFuncArgsAndCall(f, nil, buf)
+	assert.EqualError(t, err, "missing argument #1, 0 arguments are given")
+}
+
 func Test_parseCommand(t *testing.T) {
 	args := parseCommand(`closePosition "BTC USDT" 3.1415926 market`)
 	t.Logf("args: %+v", args)
@@ -0,0 +1,476 @@
+package interact
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	// force interface type check
+	_ = Reply(&MatrixReply{})
+	_ = KeyboardController(&MatrixReply{})
+	_ = Messenger(&Matrix{})
+}
+
+const matrixSyncTimeout = 30 * time.Second
+
+type MatrixSessionMap map[string]*MatrixSession
+
+type MatrixSession struct {
+	BaseSession
+
+	matrix *Matrix
+
+	RoomID string `json:"roomID"`
+	UserID string `json:"userID"`
+
+	// buttons are the options of the last reply, the user can reply the option number to choose the option
+	buttons []Button
+}
+
+func NewMatrixSession(matrix *Matrix, roomID, userID string) *MatrixSession {
+	return &MatrixSession{
+		BaseSession: BaseSession{
+			OriginState:  StatePublic,
+			CurrentState: StatePublic,
+			Authorized:   false,
+			authorizing:  false,
+
+			StartedTime: time.Now(),
+		},
+		matrix: matrix,
+		RoomID: roomID,
+		UserID: userID,
+	}
+}
+
+func (s *MatrixSession) ID() string {
+	return fmt.Sprintf("matrix-%s-%s", s.RoomID, s.UserID)
+}
+
+func (s *MatrixSession) SetAuthorized() {
+	s.BaseSession.SetAuthorized()
+	s.matrix.EmitAuthorized(s)
+}
+
+func matrixSessionKey(roomID, userID string) string {
+	return roomID + "|" + userID
+}
+
+// MatrixReply renders the buttons as the numbered options since the matrix clients don't have the reply keyboard
+type MatrixReply struct {
+	matrix  *Matrix
+	session *MatrixSession
+
+	message        string
+	buttons        []Button
+	removeKeyboard bool
+	set            bool
+}
+
+func (r *MatrixReply) Send(message string) {
+	if err := r.matrix.SendText(context.Background(), r.session.RoomID, message); err != nil {
+		log.WithError(err).Errorf("[matrix] message send error")
+	}
+}
+
+func (r *MatrixReply) Message(message string) {
+	r.message = message
+	r.set = true
+}
+
+func (r *MatrixReply) RemoveKeyboard() {
+	r.removeKeyboard = true
+	r.set = true
+}
+
+func (r *MatrixReply) AddButton(text string, name string, value string) {
+	r.buttons = append(r.buttons, Button{Text: text, Name: name, Value: value})
+	r.set = true
+}
+
+func (r *MatrixReply) AddMultipleButtons(buttonsForm [][3]string) {
+	for _, buttonForm := range buttonsForm {
+		r.AddButton(buttonForm[0], buttonForm[1], buttonForm[2])
+	}
+}
+
+func (r *MatrixReply) build() string {
+	if r.removeKeyboard || len(r.buttons) > 0 {
+		r.session.buttons = r.buttons
+	}
+
+	if len(r.buttons) == 0 {
+		return r.message
+	}
+
+	var sb strings.Builder
+	sb.WriteString(r.message)
+	if r.message != "" {
+		sb.WriteString("\n")
+	}
+
+	for i, button := range r.buttons {
+		sb.WriteString(fmt.Sprintf("\n[%d] %s", i+1, button.Text))
+	}
+
+	sb.WriteString("\n\nreply the option number or the option text")
+	return sb.String()
+}
+
+type matrixEvent struct {
+	Type    string `json:"type"`
+	EventID string `json:"event_id"`
+	Sender  string `json:"sender"`
+	Content struct {
+		MsgType string `json:"msgtype"`
+		Body    string `json:"body"`
+	} `json:"content"`
+}
+
+type matrixSyncResponse struct {
+	NextBatch string `json:"next_batch"`
+	Rooms     struct {
+		Join map[string]struct {
+			Timeline struct {
+				Events []matrixEvent `json:"events"`
+			} `json:"timeline"`
+		} `json:"join"`
+		Invite map[string]json.RawMessage `json:"invite"`
+	} `json:"rooms"`
+}
+
+// Matrix is the messenger of the matrix protocol, it polls the messages through the client-server sync API.
+// The bot joins the rooms it's invited to, and the commands are sent as the text messages started with "/".
+//go:generate callbackgen -type Matrix
+type Matrix struct {
+	HomeserverURL string `json:"homeserverURL"`
+	AccessToken   string `json:"-"`
+
+	// UserID is the user ID of the bot, it's queried from the homeserver if it's empty
+	UserID string `json:"userID,omitempty"`
+
+	// Private is used to protect the bot, users not authenticated can not send the messages except the commands
+	Private bool `json:"private,omitempty"`
+
+	client *http.Client
+
+	sessions MatrixSessionMap
+
+	// textMessageResponder is used for interact to register its message handler
+	textMessageResponder Responder
+
+	commands   []*Command
+	responders map[string]Responder
+
+	since string
+	txnID int64
+
+	mu sync.Mutex
+
+	authorizedCallbacks []func(s *MatrixSession)
+}
+
+func NewMatrix(homeserverURL, accessToken string) *Matrix {
+	return &Matrix{
+		HomeserverURL: strings.TrimRight(homeserverURL, "/"),
+		AccessToken:   accessToken,
+		Private:       true,
+		client:        &http.Client{Timeout: matrixSyncTimeout + 10*time.Second},
+		sessions:      make(MatrixSessionMap),
+		responders:    make(map[string]Responder),
+	}
+}
+
+func (m *Matrix) SetTextMessageResponder(responder Responder) {
+	m.textMessageResponder = responder
+}
+
+func (m *Matrix) AddCommand(cmd *Command, responder Responder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.commands = append(m.commands, cmd)
+	m.responders[strings.ToLower(cmd.Name)] = responder
+}
+
+func (m *Matrix) Start(ctx context.Context) {
+	if m.UserID == "" {
+		userID, err := m.whoami(ctx)
+		if err != nil {
+			log.WithError(err).Errorf("[matrix] can not query the bot user id")
+			return
+		}
+
+		m.UserID = userID
+	}
+
+	// skip the message history before the bot starts
+	if err := m.sync(ctx, 0, false); err != nil {
+		log.WithError(err).Errorf("[matrix] initial sync error")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		if err := m.sync(ctx, matrixSyncTimeout, true); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			log.WithError(err).Errorf("[matrix] sync error, retrying...")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+		}
+	}
+}
+
+func (m *Matrix) sync(ctx context.Context, timeout time.Duration, handle bool) error {
+	query := url.Values{}
+	query.Set("timeout", strconv.FormatInt(timeout.Milliseconds(), 10))
+	if m.since != "" {
+		query.Set("since", m.since)
+	}
+
+	var resp matrixSyncResponse
+	if err := m.do(ctx, http.MethodGet, "/_matrix/client/v3/sync?"+query.Encode(), nil, &resp); err != nil {
+		return err
+	}
+
+	m.since = resp.NextBatch
+
+	for roomID := range resp.Rooms.Invite {
+		if err := m.do(ctx, http.MethodPost, "/_matrix/client/v3/join/"+url.PathEscape(roomID), struct{}{}, nil); err != nil {
+			log.WithError(err).Errorf("[matrix] can not join room %s", roomID)
+		}
+	}
+
+	if !handle {
+		return nil
+	}
+
+	for roomID, room := range resp.Rooms.Join {
+		for _, evt := range room.Timeline.Events {
+			if evt.Type != "m.room.message" || evt.Content.MsgType != "m.text" || evt.Sender == m.UserID {
+				continue
+			}
+
+			m.handleMessage(ctx, roomID, evt.Sender, evt.Content.Body)
+		}
+	}
+
+	return nil
+}
+
+func (m *Matrix) handleMessage(ctx context.Context, roomID, sender, text string) {
+	text = strings.TrimSpace(text)
+	log.Infof("[matrix] message from %s in %s: %s", sender, roomID, text)
+
+	session := m.loadSession(roomID, sender)
+	reply := m.newReply(session)
+
+	if strings.HasPrefix(text, "/") {
+		name, payload := text, ""
+		if i := strings.IndexAny(text, " \t\n"); i > 0 {
+			name, payload = text[:i], strings.TrimSpace(text[i+1:])
+		}
+
+		m.mu.Lock()
+		responder, ok := m.responders[strings.ToLower(name)]
+		m.mu.Unlock()
+
+		if ok {
+			if err := responder(session, payload, reply); err != nil {
+				log.WithError(err).Errorf("[matrix] responder error")
+				m.sendText(ctx, roomID, fmt.Sprintf("error: %v", err))
+				return
+			}
+
+			m.sendReply(ctx, reply)
+			return
+		}
+	}
+
+	if m.Private && !session.authorizing && !session.Authorized {
+		log.Warn("[matrix] matrix is set to private mode, skipping message")
+		return
+	}
+
+	// map the option number to the button value
+	if n, err := strconv.Atoi(text); err == nil && n >= 1 && n <= len(session.buttons) {
+		text = session.buttons[n-1].Value
+	} else {
+		for _, button := range session.buttons {
+			if button.Text == text {
+				text = button.Value
+				break
+			}
+		}
+	}
+
+	if m.textMessageResponder != nil {
+		if err := m.textMessageResponder(session, text, reply); err != nil {
+			log.WithError(err).Errorf("[matrix] response handling error")
+			m.sendText(ctx, roomID, fmt.Sprintf("error: %v", err))
+			return
+		}
+	}
+
+	m.sendReply(ctx, reply)
+}
+
+func (m *Matrix) sendReply(ctx context.Context, reply *MatrixReply) {
+	if !reply.set {
+		return
+	}
+
+	if text := reply.build(); len(text) > 0 {
+		m.sendText(ctx, reply.session.RoomID, text)
+	}
+}
+
+func (m *Matrix) sendText(ctx context.Context, roomID, text string) {
+	if err := m.SendText(ctx, roomID, text); err != nil {
+		log.WithError(err).Errorf("[matrix] message send error")
+	}
+}
+
+// SendText sends the text message to the room
+func (m *Matrix) SendText(ctx context.Context, roomID, text string) error {
+	m.mu.Lock()
+	m.txnID++
+	txnID := fmt.Sprintf("bbgo-%d-%d", time.Now().UnixNano(), m.txnID)
+	m.mu.Unlock()
+
+	content := map[string]string{
+		"msgtype": "m.text",
+		"body":    text,
+	}
+
+	path := fmt.Sprintf("/_matrix/client/v3/rooms/%s/send/m.room.message/%s", url.PathEscape(roomID), url.PathEscape(txnID))
+	return m.do(ctx, http.MethodPut, path, content, nil)
+}
+
+func (m *Matrix) whoami(ctx context.Context) (string, error) {
+	var resp struct {
+		UserID string `json:"user_id"`
+	}
+
+	if err := m.do(ctx, http.MethodGet, "/_matrix/client/v3/account/whoami", nil, &resp); err != nil {
+		return "", err
+	}
+
+	return resp.UserID, nil
+}
+
+func (m *Matrix) do(ctx context.Context, method, path string, payload, result interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, m.HomeserverURL+path, body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+m.AccessToken)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("matrix api %s %s error: status %d: %s", method, path, resp.StatusCode, data)
+	}
+
+	if result != nil {
+		return json.Unmarshal(data, result)
+	}
+
+	return nil
+}
+
+func (m *Matrix) loadSession(roomID, userID string) *MatrixSession {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.sessions == nil {
+		m.sessions = make(MatrixSessionMap)
+	}
+
+	key := matrixSessionKey(roomID, userID)
+	session, ok := m.sessions[key]
+	if ok {
+		return session
+	}
+
+	session = NewMatrixSession(m, roomID, userID)
+	m.sessions[key] = session
+
+	log.Infof("[matrix] allocated a new session: %+v", session)
+	return session
+}
+
+func (m *Matrix) newReply(session *MatrixSession) *MatrixReply {
+	return &MatrixReply{
+		matrix:  m,
+		session: session,
+	}
+}
+
+func (m *Matrix) Sessions() MatrixSessionMap {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.sessions
+}
+
+func (m *Matrix) RestoreSessions(sessions MatrixSessionMap) {
+	if len(sessions) == 0 {
+		return
+	}
+
+	log.Infof("[matrix] restoring matrix %d sessions", len(sessions))
+
+	m.mu.Lock()
+	m.sessions = sessions
+	m.mu.Unlock()
+
+	for _, session := range sessions {
+		// update matrix context reference
+		session.matrix = m
+	}
+}
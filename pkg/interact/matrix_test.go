@@ -0,0 +1,216 @@
+package interact
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHomeserver implements the matrix client-server API used by the messenger
+type fakeHomeserver struct {
+	t *testing.T
+
+	mu      sync.Mutex
+	batch   int
+	events  []matrixEvent
+	invites []string
+	joined  []string
+
+	sentC chan string
+}
+
+func newFakeHomeserver(t *testing.T) (*fakeHomeserver, *httptest.Server) {
+	hs := &fakeHomeserver{t: t, sentC: make(chan string, 10)}
+
+	// the history message before the bot starts
+	hs.push("@alice:example.org", "/position")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/_matrix/client/v3/account/whoami", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"user_id": "@bbgo:example.org"}`))
+	})
+	mux.HandleFunc("/_matrix/client/v3/sync", hs.sync)
+	mux.HandleFunc("/_matrix/client/v3/join/", func(w http.ResponseWriter, r *http.Request) {
+		hs.mu.Lock()
+		hs.joined = append(hs.joined, strings.TrimPrefix(r.URL.Path, "/_matrix/client/v3/join/"))
+		hs.mu.Unlock()
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/_matrix/client/v3/rooms/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.True(t, strings.HasPrefix(r.URL.Path, "/_matrix/client/v3/rooms/!room:example.org/send/m.room.message/"), r.URL.Path)
+
+		var content map[string]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&content))
+		hs.sentC <- content["body"]
+		_, _ = w.Write([]byte(`{"event_id": "$sent"}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return hs, server
+}
+
+func (hs *fakeHomeserver) push(sender, body string) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	evt := matrixEvent{Type: "m.room.message", Sender: sender, EventID: fmt.Sprintf("$%d", len(hs.events))}
+	evt.Content.MsgType = "m.text"
+	evt.Content.Body = body
+	hs.events = append(hs.events, evt)
+}
+
+func (hs *fakeHomeserver) sync(w http.ResponseWriter, r *http.Request) {
+	deadline := time.Now().Add(100 * time.Millisecond)
+	for {
+		hs.mu.Lock()
+		if len(hs.events) > 0 || len(hs.invites) > 0 || time.Now().After(deadline) || r.URL.Query().Get("timeout") == "0" {
+			break
+		}
+		hs.mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+	}
+	defer hs.mu.Unlock()
+
+	hs.batch++
+	resp := map[string]interface{}{"next_batch": fmt.Sprintf("s%d", hs.batch)}
+	rooms := map[string]interface{}{}
+	if len(hs.events) > 0 {
+		// deliver one event per sync to keep the order
+		rooms["join"] = map[string]interface{}{
+			"!room:example.org": map[string]interface{}{
+				"timeline": map[string]interface{}{"events": hs.events[:1]},
+			},
+		}
+		hs.events = hs.events[1:]
+	}
+
+	if len(hs.invites) > 0 {
+		invites := map[string]interface{}{}
+		for _, roomID := range hs.invites {
+			invites[roomID] = map[string]interface{}{}
+		}
+		rooms["invite"] = invites
+		hs.invites = nil
+	}
+
+	resp["rooms"] = rooms
+	assert.NoError(hs.t, json.NewEncoder(w).Encode(resp))
+}
+
+func (hs *fakeHomeserver) receive() string {
+	select {
+	case body := <-hs.sentC:
+		return body
+	case <-time.After(3 * time.Second):
+		hs.t.Fatal("matrix message timeout")
+	}
+	return ""
+}
+
+type testPositionInteraction struct {
+	closed string
+}
+
+func (it *testPositionInteraction) Commands(interact *Interact) {
+	interact.PrivateCommand("/closeposition", "close position", func(reply Reply) error {
+		reply.Message("Please choose one strategy")
+		reply.AddMultipleButtons([][3]string{
+			{"grid:BTCUSDT", "strategy", "grid:btcusdt"},
+			{"bollmaker:ETHUSDT", "strategy", "bollmaker:ethusdt"},
+		})
+		return nil
+	}).Next(func(signature string, reply Reply) error {
+		it.closed = signature
+		reply.Message("closed " + signature)
+		if kc, ok := reply.(KeyboardController); ok {
+			kc.RemoveKeyboard()
+		}
+		return nil
+	})
+}
+
+func TestMatrix(t *testing.T) {
+	hs, server := newFakeHomeserver(t)
+
+	it := New()
+	matrix := NewMatrix(server.URL, "token")
+	it.AddMessenger(matrix)
+	it.AddCustomInteraction(&AuthInteract{Mode: AuthModeToken, Token: "s3cret"})
+
+	positionInteraction := &testPositionInteraction{}
+	it.AddCustomInteraction(positionInteraction)
+
+	var authorized *MatrixSession
+	matrix.OnAuthorized(func(s *MatrixSession) {
+		authorized = s
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, it.Start(ctx))
+
+	// wait for the initial sync that skips the message history
+	assert.Eventually(t, func() bool {
+		hs.mu.Lock()
+		defer hs.mu.Unlock()
+		return hs.batch > 0
+	}, 3*time.Second, 5*time.Millisecond)
+
+	hs.mu.Lock()
+	hs.invites = append(hs.invites, "!room:example.org")
+	hs.mu.Unlock()
+
+	// the private command requires the authorization
+	hs.push("@alice:example.org", "/closeposition")
+	assert.Contains(t, hs.receive(), "private command can not be executed in the public mode")
+
+	// the text messages are ignored in the private mode
+	hs.push("@alice:example.org", "hello")
+
+	hs.push("@alice:example.org", "/auth")
+	assert.Equal(t, "Enter your authentication token", hs.receive())
+
+	hs.push("@alice:example.org", "s3cret")
+	assert.Equal(t, "Great! You're authenticated!", hs.receive())
+	if assert.NotNil(t, authorized) {
+		assert.Equal(t, "matrix-!room:example.org-@alice:example.org", authorized.ID())
+	}
+
+	// the messages of the bot itself are ignored
+	hs.push("@bbgo:example.org", "/auth")
+
+	hs.push("@alice:example.org", "/closeposition")
+	assert.Equal(t, "Please choose one strategy\n\n[1] grid:BTCUSDT\n[2] bollmaker:ETHUSDT\n\nreply the option number or the option text", hs.receive())
+
+	hs.push("@alice:example.org", "2")
+	assert.Equal(t, "closed bollmaker:ethusdt", hs.receive())
+	assert.Equal(t, "bollmaker:ethusdt", positionInteraction.closed)
+
+	session := matrix.Sessions()[matrixSessionKey("!room:example.org", "@alice:example.org")]
+	if assert.NotNil(t, session) {
+		assert.Empty(t, session.buttons)
+		assert.True(t, session.IsAuthorized())
+	}
+
+	hs.mu.Lock()
+	assert.Equal(t, []string{"!room:example.org"}, hs.joined)
+	hs.mu.Unlock()
+
+	select {
+	case body := <-hs.sentC:
+		t.Errorf("unexpected message: %s", body)
+	default:
+	}
+}
@@ -1,6 +1,7 @@
 package interact
 
 import (
+	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
@@ -20,7 +21,15 @@ func ParseFuncArgsAndCall(f interface{}, args []string, objects ...interface{})
 		at := ft.In(i)
 
 		// get the kind of argument
-		switch k := at.Kind(); k {
+		k := at.Kind()
+		switch k {
+		case reflect.String, reflect.Bool, reflect.Int64, reflect.Float64:
+			if argIndex >= len(args) {
+				return "", fmt.Errorf("missing argument #%d, %d arguments are given", argIndex+1, len(args))
+			}
+		}
+
+		switch k {
 
 		case reflect.Interface:
 			found := false
@@ -80,10 +89,24 @@ func ParseFuncArgsAndCall(f interface{}, args []string, objects ...interface{})
 			av := reflect.ValueOf(nf)
 			rArgs = append(rArgs, av)
 			argIndex++
+
+		case reflect.Slice:
+			// the variadic string arguments take the rest of the arguments
+			if at.Elem().Kind() == reflect.String && argIndex < len(args) {
+				rArgs = append(rArgs, reflect.ValueOf(args[argIndex:]))
+				argIndex = len(args)
+			} else {
+				rArgs = append(rArgs, reflect.MakeSlice(at, 0, 0))
+			}
 		}
 	}
 
-	out := fv.Call(rArgs)
+	var out []reflect.Value
+	if ft.IsVariadic() {
+		out = fv.CallSlice(rArgs)
+	} else {
+		out = fv.Call(rArgs)
+	}
 	if ft.NumOut() == 0 {
 		return "", nil
 	}
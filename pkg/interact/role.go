@@ -0,0 +1,41 @@
+package interact
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role is the role of the authorized user, the private commands are mapped to the minimal roles
+// that are allowed to execute them.
+type Role string
+
+const (
+	// RoleViewer can only execute the read-only commands, e.g., /position, /balances
+	RoleViewer Role = "viewer"
+
+	// RoleOperator can execute the trading commands, e.g., /closeposition, /suspend
+	RoleOperator Role = "operator"
+
+	// RoleAdmin can execute all the commands, e.g., /emergencystop, /audit
+	RoleAdmin Role = "admin"
+)
+
+var roleLevels = map[Role]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+func ParseRole(s string) (Role, error) {
+	role := Role(strings.ToLower(s))
+	if _, ok := roleLevels[role]; !ok {
+		return "", fmt.Errorf("invalid role %q, valid roles: viewer, operator, admin", s)
+	}
+
+	return role, nil
+}
+
+// Allows checks if the role has the permission of the required role
+func (r Role) Allows(required Role) bool {
+	return roleLevels[r] >= roleLevels[required]
+}
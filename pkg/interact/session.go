@@ -10,6 +10,7 @@ type BaseSession struct {
 	OriginState  State     `json:"originState,omitempty"`
 	CurrentState State     `json:"currentState,omitempty"`
 	Authorized   bool      `json:"authorized,omitempty"`
+	Role         Role      `json:"role,omitempty"`
 	StartedTime  time.Time `json:"startedTime,omitempty"`
 
 	// authorizing -- the user started authorizing himself/herself, do not ignore the message
@@ -42,6 +43,19 @@ func (s *BaseSession) IsAuthorized() bool {
 	return s.Authorized
 }
 
+// GetRole returns the role of the authorized user,
+// the sessions authorized before the roles were introduced are treated as admin.
+func (s *BaseSession) GetRole() Role {
+	if s.Role == "" {
+		return RoleAdmin
+	}
+	return s.Role
+}
+
+func (s *BaseSession) SetRole(role Role) {
+	s.Role = role
+}
+
 func (s *BaseSession) SetAuthorizing(b bool) {
 	s.authorizing = b
 }
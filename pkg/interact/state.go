@@ -5,4 +5,7 @@ type State string
 const (
 	StatePublic        State = "public"
 	StateAuthenticated State = "authenticated"
+
+	// StateConfirming is the state of waiting for the confirmation of a command
+	StateConfirming State = "confirming"
 )
@@ -0,0 +1,34 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddOrdersStrategy, downAddOrdersStrategy)
+
+}
+
+func upAddOrdersStrategy(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE `orders` ADD COLUMN `strategy` VARCHAR(32) NULL;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddOrdersStrategy(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE `orders` DROP COLUMN `strategy`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,34 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAuditLogs, downAuditLogs)
+
+}
+
+func upAuditLogs(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `audit_logs`\n(\n    `gid`       BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n    `actor`     VARCHAR(128)    NOT NULL,\n    `role`      VARCHAR(16)     NOT NULL DEFAULT '',\n    `command`   VARCHAR(64)     NOT NULL,\n    `arguments` TEXT            NOT NULL,\n    `outcome`   VARCHAR(16)     NOT NULL,\n    `error`     TEXT            NOT NULL,\n    `time`      DATETIME(3)     NOT NULL,\n    PRIMARY KEY (`gid`),\n    INDEX `idx_audit_logs_actor` (`actor`, `time`),\n    INDEX `idx_audit_logs_command` (`command`, `time`)\n);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAuditLogs(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `audit_logs`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
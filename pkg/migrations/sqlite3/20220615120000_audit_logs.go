@@ -0,0 +1,44 @@
+package sqlite3
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAuditLogs, downAuditLogs)
+
+}
+
+func upAuditLogs(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `audit_logs`\n(\n    `gid`       INTEGER PRIMARY KEY AUTOINCREMENT,\n    `actor`     VARCHAR(128) NOT NULL,\n    `role`      VARCHAR(16)  NOT NULL DEFAULT '',\n    `command`   VARCHAR(64)  NOT NULL,\n    `arguments` TEXT         NOT NULL DEFAULT '',\n    `outcome`   VARCHAR(16)  NOT NULL,\n    `error`     TEXT         NOT NULL DEFAULT '',\n    `time`      DATETIME(3)  NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX `idx_audit_logs_actor` ON `audit_logs` (`actor`, `time`);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX `idx_audit_logs_command` ON `audit_logs` (`command`, `time`);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAuditLogs(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `audit_logs`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
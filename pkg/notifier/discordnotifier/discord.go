@@ -0,0 +1,309 @@
+package discordnotifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"golang.org/x/time/rate"
+
+	"github.com/c9s/bbgo/pkg/notifier/httpqueue"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var log = logrus.WithField("service", "discord")
+
+// maxContentLength is the max length of the message content of discord
+const maxContentLength = 2000
+
+const (
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = 30 * time.Second
+)
+
+type slackAttachmentCreator interface {
+	SlackAttachment() slack.Attachment
+}
+
+type EmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type EmbedFooter struct {
+	Text string `json:"text"`
+}
+
+type Embed struct {
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Color       int          `json:"color,omitempty"`
+	Fields      []EmbedField `json:"fields,omitempty"`
+	Footer      *EmbedFooter `json:"footer,omitempty"`
+}
+
+// Message is the discord webhook message, see https://discord.com/developers/docs/resources/webhook#execute-webhook
+type Message struct {
+	Username string  `json:"username,omitempty"`
+	Content  string  `json:"content,omitempty"`
+	Embeds   []Embed `json:"embeds,omitempty"`
+}
+
+// Notifier sends the notifications to the discord channels through the channel webhooks
+type Notifier struct {
+	webhookURL string
+	username   string
+	channels   map[string]string
+
+	queue *httpqueue.Queue
+}
+
+type Option func(notifier *Notifier)
+
+// WithUsername overrides the username of the webhook
+func WithUsername(username string) Option {
+	return func(notifier *Notifier) {
+		notifier.username = username
+	}
+}
+
+// WithChannels routes the notification channels to the channel webhook URLs,
+// the unrouted channels are sent to the default webhook
+func WithChannels(channels map[string]string) Option {
+	return func(notifier *Notifier) {
+		notifier.channels = channels
+	}
+}
+
+// WithBackoff sets the initial and the max backoff duration of the retries
+func WithBackoff(initial, max time.Duration) Option {
+	return func(notifier *Notifier) {
+		notifier.queue.InitialBackoff = initial
+		notifier.queue.MaxBackoff = max
+	}
+}
+
+func New(webhookURL string, options ...Option) *Notifier {
+	queue := httpqueue.New("discord", defaultInitialBackoff, defaultMaxBackoff)
+
+	// follow the discord limit of 30 requests per minute for a webhook
+	queue.Limiter = rate.NewLimiter(rate.Every(2*time.Second), 5)
+	queue.RetryAfter = parseRetryAfter
+
+	notifier := &Notifier{
+		webhookURL: webhookURL,
+		queue:      queue,
+	}
+
+	for _, o := range options {
+		o(notifier)
+	}
+
+	queue.Start()
+
+	return notifier
+}
+
+func (n *Notifier) route(channel string) string {
+	if url, ok := n.channels[channel]; ok && url != "" {
+		return url
+	}
+
+	return n.webhookURL
+}
+
+func (n *Notifier) Notify(obj interface{}, args ...interface{}) {
+	n.NotifyTo("", obj, args...)
+}
+
+func filterEmbeds(args []interface{}) (embeds []Embed, pureArgs []interface{}) {
+	var firstObjectOffset = -1
+	for idx, arg := range args {
+		switch a := arg.(type) {
+
+		case slack.Attachment:
+			embeds = append(embeds, embedFromAttachment(a))
+
+		case slackAttachmentCreator:
+			embeds = append(embeds, embedFromAttachment(a.SlackAttachment()))
+
+		case types.PlainText:
+			// the value types like fixedpoint.Value are the format arguments
+			if reflect.TypeOf(arg).Kind() != reflect.Ptr {
+				continue
+			}
+
+			embeds = append(embeds, Embed{Description: a.PlainText()})
+
+		default:
+			continue
+		}
+
+		if firstObjectOffset == -1 {
+			firstObjectOffset = idx
+		}
+	}
+
+	pureArgs = args
+	if firstObjectOffset > -1 {
+		pureArgs = args[:firstObjectOffset]
+	}
+
+	return embeds, pureArgs
+}
+
+func (n *Notifier) NotifyTo(channel string, obj interface{}, args ...interface{}) {
+	embeds, pureArgs := filterEmbeds(args)
+
+	message := Message{Username: n.username}
+
+	switch a := obj.(type) {
+	case string:
+		message.Content = fmt.Sprintf(a, pureArgs...)
+		message.Embeds = embeds
+
+	case slack.Attachment:
+		message.Embeds = append([]Embed{embedFromAttachment(a)}, embeds...)
+
+	case slackAttachmentCreator:
+		message.Embeds = append([]Embed{embedFromAttachment(a.SlackAttachment())}, embeds...)
+
+	case types.PlainText:
+		message.Content = a.PlainText()
+		message.Embeds = embeds
+
+	case types.Stringer:
+		message.Content = a.String()
+		message.Embeds = embeds
+
+	default:
+		log.Errorf("discord message conversion error, unsupported object: %T %+v", a, a)
+		return
+	}
+
+	message.Content = truncateContent(message.Content)
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		log.WithError(err).Error("discord message encode error")
+		return
+	}
+
+	n.queue.Enqueue(httpqueue.Request{URL: n.route(channel), ContentType: "application/json", Body: body})
+}
+
+func (n *Notifier) SendPhoto(buffer *bytes.Buffer) {
+	n.SendPhotoTo("", buffer)
+}
+
+// SendPhotoTo uploads the photo as the file attachment of the webhook message
+func (n *Notifier) SendPhotoTo(channel string, buffer *bytes.Buffer) {
+	payload, err := json.Marshal(Message{Username: n.username})
+	if err != nil {
+		log.WithError(err).Error("discord message encode error")
+		return
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("payload_json", string(payload)); err != nil {
+		log.WithError(err).Error("discord multipart error")
+		return
+	}
+
+	part, err := writer.CreateFormFile("files[0]", "photo.png")
+	if err != nil {
+		log.WithError(err).Error("discord multipart error")
+		return
+	}
+
+	if _, err := part.Write(buffer.Bytes()); err != nil {
+		log.WithError(err).Error("discord multipart error")
+		return
+	}
+
+	if err := writer.Close(); err != nil {
+		log.WithError(err).Error("discord multipart error")
+		return
+	}
+
+	n.queue.Enqueue(httpqueue.Request{URL: n.route(channel), ContentType: writer.FormDataContentType(), Body: body.Bytes()})
+}
+
+// truncateContent truncates the content to the max length of discord, which counts the characters instead of the bytes
+func truncateContent(content string) string {
+	if utf8.RuneCountInString(content) <= maxContentLength {
+		return content
+	}
+
+	runes := []rune(content)
+	return string(runes[:maxContentLength-3]) + "..."
+}
+
+// parseRetryAfter parses the retry_after of the discord rate limit response
+func parseRetryAfter(resp *http.Response, body []byte) time.Duration {
+	var rateLimit struct {
+		RetryAfter float64 `json:"retry_after"`
+	}
+
+	if json.Unmarshal(body, &rateLimit) == nil && rateLimit.RetryAfter > 0 {
+		return time.Duration(rateLimit.RetryAfter * float64(time.Second))
+	}
+
+	return 0
+}
+
+var slackColors = map[string]int{
+	"good":    0x2eb886,
+	"warning": 0xdaa038,
+	"danger":  0xa30200,
+}
+
+func embedFromAttachment(a slack.Attachment) Embed {
+	embed := Embed{
+		Title:       a.Title,
+		Description: a.Text,
+		Color:       parseColor(a.Color),
+	}
+
+	if embed.Description == "" {
+		embed.Description = a.Pretext
+	}
+
+	for _, field := range a.Fields {
+		embed.Fields = append(embed.Fields, EmbedField{
+			Name:   field.Title,
+			Value:  field.Value,
+			Inline: field.Short,
+		})
+	}
+
+	if a.Footer != "" {
+		embed.Footer = &EmbedFooter{Text: a.Footer}
+	}
+
+	return embed
+}
+
+func parseColor(color string) int {
+	if c, ok := slackColors[color]; ok {
+		return c
+	}
+
+	c, err := strconv.ParseInt(strings.TrimPrefix(color, "#"), 16, 32)
+	if err != nil {
+		return 0
+	}
+
+	return int(c)
+}
@@ -0,0 +1,155 @@
+package discordnotifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type capturedRequest struct {
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+func newStubServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request) bool) (*httptest.Server, chan capturedRequest) {
+	requestC := make(chan capturedRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		if handler != nil && !handler(w, r) {
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		requestC <- capturedRequest{Path: r.URL.Path, Header: r.Header, Body: body}
+	}))
+	t.Cleanup(server.Close)
+	return server, requestC
+}
+
+func receive(t *testing.T, requestC chan capturedRequest) capturedRequest {
+	select {
+	case req := <-requestC:
+		return req
+	case <-time.After(3 * time.Second):
+		t.Fatal("discord webhook request timeout")
+	}
+	return capturedRequest{}
+}
+
+func TestNotifier_NotifyTo(t *testing.T) {
+	server, requestC := newStubServer(t, nil)
+
+	notifier := New(server.URL+"/default",
+		WithUsername("bbgo"),
+		WithChannels(map[string]string{"#trades": server.URL + "/trades"}))
+
+	notifier.Notify("%s price %s", "BTCUSDT", fixedpoint.NewFromInt(20000))
+
+	req := receive(t, requestC)
+	assert.Equal(t, "/default", req.Path)
+
+	var message Message
+	require.NoError(t, json.Unmarshal(req.Body, &message))
+	assert.Equal(t, "bbgo", message.Username)
+	assert.Equal(t, "BTCUSDT price 20000", message.Content)
+	assert.Empty(t, message.Embeds)
+
+	trade := types.Trade{
+		Exchange: types.ExchangeBinance,
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Price:    fixedpoint.NewFromInt(20000),
+		Quantity: fixedpoint.One,
+	}
+	notifier.NotifyTo("#trades", trade)
+
+	req = receive(t, requestC)
+	assert.Equal(t, "/trades", req.Path)
+
+	message = Message{}
+	require.NoError(t, json.Unmarshal(req.Body, &message))
+	if assert.Len(t, message.Embeds, 1) {
+		attachment := trade.SlackAttachment()
+		assert.Equal(t, attachment.Title, message.Embeds[0].Title)
+		assert.Len(t, message.Embeds[0].Fields, len(attachment.Fields))
+	}
+}
+
+func TestNotifier_Retry(t *testing.T) {
+	var attempts int32
+	server, requestC := newStubServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": 0.01, "global": false}`))
+			return false
+		}
+		return true
+	})
+
+	notifier := New(server.URL, WithBackoff(time.Millisecond, 5*time.Millisecond))
+	notifier.Notify("hello")
+
+	receive(t, requestC)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
+
+func TestNotifier_SendPhoto(t *testing.T) {
+	server, requestC := newStubServer(t, nil)
+
+	notifier := New(server.URL+"/default", WithChannels(map[string]string{"charts": server.URL + "/charts"}))
+	notifier.SendPhotoTo("charts", bytes.NewBufferString("png"))
+
+	req := receive(t, requestC)
+	assert.Equal(t, "/charts", req.Path)
+	assert.Contains(t, req.Header.Get("Content-Type"), "multipart/form-data")
+	assert.Contains(t, string(req.Body), `name="payload_json"`)
+	assert.Contains(t, string(req.Body), `name="files[0]"; filename="photo.png"`)
+}
+
+func Test_embedFromAttachment(t *testing.T) {
+	embed := embedFromAttachment(slack.Attachment{
+		Color:  "#ff0000",
+		Title:  "title",
+		Text:   "text",
+		Footer: "footer",
+		Fields: []slack.AttachmentField{{Title: "Price", Value: "100", Short: true}},
+	})
+	assert.Equal(t, 0xff0000, embed.Color)
+	assert.Equal(t, "title", embed.Title)
+	assert.Equal(t, "text", embed.Description)
+	assert.Equal(t, "footer", embed.Footer.Text)
+	assert.Equal(t, []EmbedField{{Name: "Price", Value: "100", Inline: true}}, embed.Fields)
+
+	assert.Equal(t, 0x2eb886, parseColor("good"))
+	assert.Equal(t, 0, parseColor("unknown"))
+}
+
+func Test_truncateContent(t *testing.T) {
+	assert.Equal(t, "short", truncateContent("short"))
+
+	// the multi-byte characters are counted as one character and not split
+	content := strings.Repeat("價", maxContentLength)
+	assert.Equal(t, content, truncateContent(content))
+
+	truncated := truncateContent(content + "格")
+	assert.True(t, utf8.ValidString(truncated))
+	assert.Equal(t, maxContentLength, utf8.RuneCountInString(truncated))
+	assert.True(t, strings.HasSuffix(truncated, "..."))
+}
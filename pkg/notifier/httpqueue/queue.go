@@ -0,0 +1,163 @@
+package httpqueue
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	DefaultMaxRetries = 3
+	DefaultQueueSize  = 100
+)
+
+// Request is the queued POST request of the notifiers
+type Request struct {
+	URL         string
+	ContentType string
+	Body        []byte
+}
+
+// Queue posts the queued requests in a background worker, the requests are sent one by one,
+// and the failed requests are retried with the exponential backoff.
+// The fields should be set before Start is called.
+type Queue struct {
+	Client *http.Client
+
+	// Limiter throttles the requests if it's set
+	Limiter *rate.Limiter
+
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// Prepare is called before the request is sent, e.g., to add the custom or the signature headers
+	Prepare func(httpReq *http.Request, req Request)
+
+	// RetryAfter returns the wait duration of the rate limited response, the backoff is used if it returns zero
+	RetryAfter func(resp *http.Response, body []byte) time.Duration
+
+	name  string
+	log   logrus.FieldLogger
+	taskC chan Request
+}
+
+// New creates the queue, name is used in the error messages and the logs
+func New(name string, initialBackoff, maxBackoff time.Duration) *Queue {
+	return &Queue{
+		Client:         &http.Client{Timeout: 15 * time.Second},
+		MaxRetries:     DefaultMaxRetries,
+		InitialBackoff: initialBackoff,
+		MaxBackoff:     maxBackoff,
+		name:           name,
+		log:            logrus.WithField("service", name),
+		taskC:          make(chan Request, DefaultQueueSize),
+	}
+}
+
+// Start starts the worker of the queue
+func (q *Queue) Start() {
+	go q.worker()
+}
+
+func (q *Queue) worker() {
+	ctx := context.Background()
+	for req := range q.taskC {
+		if q.Limiter != nil {
+			if err := q.Limiter.Wait(ctx); err != nil {
+				q.log.WithError(err).Errorf("%s rate limiter error", q.name)
+			}
+		}
+
+		if err := q.Send(ctx, req); err != nil {
+			q.log.WithError(err).Errorf("%s request error", q.name)
+		}
+	}
+}
+
+// Enqueue queues the request, the request is dropped if the queue is full
+func (q *Queue) Enqueue(req Request) {
+	select {
+	case q.taskC <- req:
+	case <-time.After(50 * time.Millisecond):
+		q.log.Warnf("%s queue is full, dropping the notification", q.name)
+	}
+}
+
+// Send sends the request synchronously with the retries
+func (q *Queue) Send(ctx context.Context, req Request) error {
+	backoff := q.InitialBackoff
+
+	var err error
+	for attempt := 0; attempt <= q.MaxRetries; attempt++ {
+		var retryAfter time.Duration
+		retryAfter, err = q.post(ctx, req)
+		if err == nil || retryAfter < 0 {
+			return err
+		}
+
+		wait := backoff
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+
+		if attempt < q.MaxRetries {
+			q.log.WithError(err).Warnf("retrying %s request in %s (%d/%d)", q.name, wait, attempt+1, q.MaxRetries)
+			time.Sleep(wait)
+		}
+
+		backoff *= 2
+		if backoff > q.MaxBackoff {
+			backoff = q.MaxBackoff
+		}
+	}
+
+	return err
+}
+
+// post sends the request once, it returns a negative retryAfter if the request should not be retried
+func (q *Queue) post(ctx context.Context, req Request) (time.Duration, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return -1, err
+	}
+
+	httpReq.Header.Set("Content-Type", req.ContentType)
+	if q.Prepare != nil {
+		q.Prepare(httpReq, req)
+	}
+
+	resp, err := q.Client.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return 0, nil
+	}
+
+	err = fmt.Errorf("%s response status %d: %s", q.name, resp.StatusCode, data)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if q.RetryAfter != nil {
+			return q.RetryAfter(resp, data), err
+		}
+
+		return 0, err
+	}
+
+	if resp.StatusCode >= 500 {
+		return 0, err
+	}
+
+	return -1, err
+}
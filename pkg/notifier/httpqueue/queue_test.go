@@ -0,0 +1,73 @@
+package httpqueue
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueue_Send(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "value", r.Header.Get("X-Test"))
+
+		switch atomic.AddInt32(&attempts, 1) {
+		case 1:
+			w.WriteHeader(http.StatusInternalServerError)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	var retryAfterCalls int32
+	queue := New("test", time.Millisecond, 5*time.Millisecond)
+	queue.Prepare = func(httpReq *http.Request, req Request) {
+		httpReq.Header.Set("X-Test", "value")
+	}
+	queue.RetryAfter = func(resp *http.Response, body []byte) time.Duration {
+		atomic.AddInt32(&retryAfterCalls, 1)
+		return time.Millisecond
+	}
+
+	err := queue.Send(context.Background(), Request{URL: server.URL, ContentType: "application/json", Body: []byte("{}")})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&retryAfterCalls))
+}
+
+func TestQueue_SendGiveUp(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		if r.URL.Path == "/bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	queue := New("test", time.Millisecond, 2*time.Millisecond)
+	queue.MaxRetries = 2
+
+	// the server errors are retried until the max retries
+	err := queue.Send(context.Background(), Request{URL: server.URL, ContentType: "application/json"})
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+
+	// the client errors are not retried
+	atomic.StoreInt32(&attempts, 0)
+	err = queue.Send(context.Background(), Request{URL: server.URL + "/bad", ContentType: "application/json"})
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
@@ -0,0 +1,292 @@
+package webhooknotifier
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/notifier/httpqueue"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var log = logrus.WithField("service", "webhook")
+
+const (
+	SignatureHeader = "X-BBGO-Signature"
+	TimestampHeader = "X-BBGO-Timestamp"
+)
+
+const (
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+)
+
+// Payload is the default JSON payload of the webhook request,
+// it's also the data of the field templates.
+type Payload struct {
+	Channel     string    `json:"channel,omitempty"`
+	Type        string    `json:"type,omitempty"`
+	Text        string    `json:"text"`
+	Attachments []string  `json:"attachments,omitempty"`
+	Time        time.Time `json:"time"`
+
+	// Object is the notified object, the templates can access the fields of the object, e.g., {{ .Object.Symbol }}
+	Object interface{} `json:"-"`
+}
+
+// Notifier posts the notifications to the HTTP endpoint as JSON payloads.
+// The requests are signed with HMAC-SHA256 if the secret is set, and the failed requests are retried with the exponential backoff.
+type Notifier struct {
+	url      string
+	secret   string
+	headers  map[string]string
+	fields   map[string]*template.Template
+	channels map[string]string
+
+	queue *httpqueue.Queue
+}
+
+type Option func(notifier *Notifier)
+
+// WithSecret signs the requests with the secret
+func WithSecret(secret string) Option {
+	return func(notifier *Notifier) {
+		notifier.secret = secret
+	}
+}
+
+// WithHeaders adds the custom headers to the requests
+func WithHeaders(headers map[string]string) Option {
+	return func(notifier *Notifier) {
+		notifier.headers = headers
+	}
+}
+
+// WithChannels routes the notification channels to the URLs, the unrouted channels are sent to the default URL
+func WithChannels(channels map[string]string) Option {
+	return func(notifier *Notifier) {
+		notifier.channels = channels
+	}
+}
+
+// WithMaxRetries sets the max number of the retries of a failed request
+func WithMaxRetries(maxRetries int) Option {
+	return func(notifier *Notifier) {
+		notifier.queue.MaxRetries = maxRetries
+	}
+}
+
+// WithBackoff sets the initial and the max backoff duration of the retries
+func WithBackoff(initial, max time.Duration) Option {
+	return func(notifier *Notifier) {
+		notifier.queue.InitialBackoff = initial
+		notifier.queue.MaxBackoff = max
+	}
+}
+
+// WithHTTPClient replaces the default http client
+func WithHTTPClient(client *http.Client) Option {
+	return func(notifier *Notifier) {
+		notifier.queue.Client = client
+	}
+}
+
+// New creates the webhook notifier of the url.
+// fields are the text/template strings of the JSON payload fields rendered with Payload,
+// the default payload is sent if fields are not given.
+func New(url string, fields map[string]string, options ...Option) (*Notifier, error) {
+	notifier := &Notifier{
+		url:    url,
+		fields: make(map[string]*template.Template),
+		queue:  httpqueue.New("webhook", defaultInitialBackoff, defaultMaxBackoff),
+	}
+
+	for name, text := range fields {
+		tmpl, err := template.New(name).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("webhook field %s template parse error: %w", name, err)
+		}
+
+		notifier.fields[name] = tmpl
+	}
+
+	for _, o := range options {
+		o(notifier)
+	}
+
+	notifier.queue.Prepare = notifier.sign
+	notifier.queue.Start()
+
+	return notifier, nil
+}
+
+func (n *Notifier) route(channel string) string {
+	if url, ok := n.channels[channel]; ok && url != "" {
+		return url
+	}
+
+	return n.url
+}
+
+func (n *Notifier) Notify(obj interface{}, args ...interface{}) {
+	n.NotifyTo("", obj, args...)
+}
+
+func (n *Notifier) NotifyTo(channel string, obj interface{}, args ...interface{}) {
+	var texts, pureArgs = filterPlaintextMessages(args)
+
+	payload := Payload{
+		Channel:     channel,
+		Type:        fmt.Sprintf("%T", obj),
+		Attachments: texts,
+		Time:        time.Now(),
+		Object:      obj,
+	}
+
+	switch a := obj.(type) {
+	case string:
+		payload.Type = "text"
+		payload.Text = fmt.Sprintf(a, pureArgs...)
+
+	case types.PlainText:
+		payload.Text = a.PlainText()
+
+	case types.Stringer:
+		payload.Text = a.String()
+
+	default:
+		log.Errorf("unsupported notification format: %T %+v", a, a)
+		return
+	}
+
+	body, err := n.encode(payload)
+	if err != nil {
+		log.WithError(err).Error("webhook payload encode error")
+		return
+	}
+
+	n.queue.Enqueue(httpqueue.Request{URL: n.route(channel), ContentType: "application/json", Body: body})
+}
+
+func (n *Notifier) SendPhoto(buffer *bytes.Buffer) {
+	n.SendPhotoTo("", buffer)
+}
+
+// SendPhotoTo posts the photo as a multipart form with the "payload" field and the "photo" file
+func (n *Notifier) SendPhotoTo(channel string, buffer *bytes.Buffer) {
+	payload, err := n.encode(Payload{Channel: channel, Type: "photo", Time: time.Now()})
+	if err != nil {
+		log.WithError(err).Error("webhook payload encode error")
+		return
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("payload", string(payload)); err != nil {
+		log.WithError(err).Error("webhook multipart error")
+		return
+	}
+
+	part, err := writer.CreateFormFile("photo", "photo.png")
+	if err != nil {
+		log.WithError(err).Error("webhook multipart error")
+		return
+	}
+
+	if _, err := part.Write(buffer.Bytes()); err != nil {
+		log.WithError(err).Error("webhook multipart error")
+		return
+	}
+
+	if err := writer.Close(); err != nil {
+		log.WithError(err).Error("webhook multipart error")
+		return
+	}
+
+	n.queue.Enqueue(httpqueue.Request{URL: n.route(channel), ContentType: writer.FormDataContentType(), Body: body.Bytes()})
+}
+
+func (n *Notifier) encode(payload Payload) ([]byte, error) {
+	if len(n.fields) == 0 {
+		return json.Marshal(payload)
+	}
+
+	fields := make(map[string]string, len(n.fields))
+	for name, tmpl := range n.fields {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, payload); err != nil {
+			return nil, fmt.Errorf("webhook field %s template execute error: %w", name, err)
+		}
+
+		fields[name] = buf.String()
+	}
+
+	return json.Marshal(fields)
+}
+
+// sign adds the custom headers and the signature headers to the request
+func (n *Notifier) sign(httpReq *http.Request, req httpqueue.Request) {
+	for key, value := range n.headers {
+		httpReq.Header.Set(key, value)
+	}
+
+	if n.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		httpReq.Header.Set(TimestampHeader, timestamp)
+		httpReq.Header.Set(SignatureHeader, Sign(n.secret, timestamp, req.Body))
+	}
+}
+
+// Sign returns the signature header value of the request body, the receiver should verify the signature by
+// computing HMAC-SHA256 of "{timestamp}.{body}" with the shared secret.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func filterPlaintextMessages(args []interface{}) (texts []string, pureArgs []interface{}) {
+	var firstObjectOffset = -1
+	for idx, arg := range args {
+		// the value types like fixedpoint.Value are the format arguments
+		if rt := reflect.TypeOf(arg); rt == nil || rt.Kind() != reflect.Ptr {
+			continue
+		}
+
+		switch a := arg.(type) {
+
+		case types.PlainText:
+			texts = append(texts, a.PlainText())
+
+		case types.Stringer:
+			texts = append(texts, a.String())
+
+		default:
+			continue
+		}
+
+		if firstObjectOffset == -1 {
+			firstObjectOffset = idx
+		}
+	}
+
+	pureArgs = args
+	if firstObjectOffset > -1 {
+		pureArgs = args[:firstObjectOffset]
+	}
+
+	return texts, pureArgs
+}
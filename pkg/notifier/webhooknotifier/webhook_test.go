@@ -0,0 +1,166 @@
+package webhooknotifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/notifier/httpqueue"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type capturedRequest struct {
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+func newStubServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request) bool) (*httptest.Server, chan capturedRequest) {
+	requestC := make(chan capturedRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		if handler != nil && !handler(w, r) {
+			return
+		}
+
+		requestC <- capturedRequest{Path: r.URL.Path, Header: r.Header, Body: body}
+	}))
+	t.Cleanup(server.Close)
+	return server, requestC
+}
+
+func receive(t *testing.T, requestC chan capturedRequest) capturedRequest {
+	select {
+	case req := <-requestC:
+		return req
+	case <-time.After(3 * time.Second):
+		t.Fatal("webhook request timeout")
+	}
+	return capturedRequest{}
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	server, requestC := newStubServer(t, nil)
+
+	notifier, err := New(server.URL+"/default", nil,
+		WithSecret("s3cret"),
+		WithHeaders(map[string]string{"X-Api-Key": "key"}),
+		WithChannels(map[string]string{"#trades": server.URL + "/trades"}))
+	require.NoError(t, err)
+
+	trade := &types.Trade{
+		Exchange: types.ExchangeBinance,
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Price:    fixedpoint.NewFromInt(20000),
+		Quantity: fixedpoint.One,
+	}
+	notifier.Notify("%s price %s", "BTCUSDT", fixedpoint.NewFromInt(20000), trade)
+
+	req := receive(t, requestC)
+	assert.Equal(t, "/default", req.Path)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, "key", req.Header.Get("X-Api-Key"))
+	assert.Equal(t, Sign("s3cret", req.Header.Get(TimestampHeader), req.Body), req.Header.Get(SignatureHeader))
+
+	var payload Payload
+	require.NoError(t, json.Unmarshal(req.Body, &payload))
+	assert.Equal(t, "text", payload.Type)
+	assert.Equal(t, "BTCUSDT price 20000", payload.Text)
+	assert.Equal(t, []string{trade.PlainText()}, payload.Attachments)
+
+	notifier.NotifyTo("#trades", trade)
+	req = receive(t, requestC)
+	assert.Equal(t, "/trades", req.Path)
+	require.NoError(t, json.Unmarshal(req.Body, &payload))
+	assert.Equal(t, "#trades", payload.Channel)
+	assert.Equal(t, "*types.Trade", payload.Type)
+	assert.Equal(t, trade.PlainText(), payload.Text)
+}
+
+func TestNotifier_Fields(t *testing.T) {
+	server, requestC := newStubServer(t, nil)
+
+	notifier, err := New(server.URL, map[string]string{
+		"summary": "[{{ .Channel }}] {{ .Object.Symbol }} {{ .Object.Side }}",
+		"message": "{{ .Text }}",
+	})
+	require.NoError(t, err)
+
+	trade := &types.Trade{Symbol: "ETHUSDT", Side: types.SideTypeSell}
+	notifier.NotifyTo("alerts", trade)
+
+	req := receive(t, requestC)
+
+	var fields map[string]string
+	require.NoError(t, json.Unmarshal(req.Body, &fields))
+	assert.Equal(t, "[alerts] ETHUSDT SELL", fields["summary"])
+	assert.Equal(t, trade.PlainText(), fields["message"])
+
+	_, err = New(server.URL, map[string]string{"bad": "{{ .Text"})
+	assert.Error(t, err)
+}
+
+func TestNotifier_Retry(t *testing.T) {
+	var attempts int32
+	server, requestC := newStubServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+		switch atomic.AddInt32(&attempts, 1) {
+		case 1:
+			w.WriteHeader(http.StatusInternalServerError)
+			return false
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+			return false
+		}
+		return true
+	})
+
+	notifier, err := New(server.URL, nil, WithBackoff(time.Millisecond, 5*time.Millisecond))
+	require.NoError(t, err)
+
+	notifier.Notify("hello")
+	receive(t, requestC)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+
+	// the client errors are not retried
+	var badAttempts int32
+	badServer, _ := newStubServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+		atomic.AddInt32(&badAttempts, 1)
+		w.WriteHeader(http.StatusBadRequest)
+		return false
+	})
+
+	notifier, err = New(badServer.URL, nil, WithBackoff(time.Millisecond, 5*time.Millisecond))
+	require.NoError(t, err)
+
+	err = notifier.queue.Send(context.Background(), httpqueue.Request{URL: badServer.URL, ContentType: "application/json", Body: []byte("{}")})
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&badAttempts))
+}
+
+func TestNotifier_SendPhoto(t *testing.T) {
+	server, requestC := newStubServer(t, nil)
+
+	notifier, err := New(server.URL+"/default", nil, WithChannels(map[string]string{"charts": server.URL + "/charts"}))
+	require.NoError(t, err)
+
+	notifier.SendPhotoTo("charts", bytes.NewBufferString("png"))
+
+	req := receive(t, requestC)
+	assert.Equal(t, "/charts", req.Path)
+	assert.Contains(t, req.Header.Get("Content-Type"), "multipart/form-data")
+	assert.Contains(t, string(req.Body), `name="photo"; filename="photo.png"`)
+	assert.Contains(t, string(req.Body), `"type":"photo"`)
+}
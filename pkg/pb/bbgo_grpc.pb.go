@@ -508,3 +508,477 @@ var TradingService_ServiceDesc = grpc.ServiceDesc{
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/pb/bbgo.proto",
 }
+
+// AccountServiceClient is the client API for AccountService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AccountServiceClient interface {
+	QueryBalances(ctx context.Context, in *QueryBalancesRequest, opts ...grpc.CallOption) (*QueryBalancesResponse, error)
+	QueryPositions(ctx context.Context, in *QueryPositionsRequest, opts ...grpc.CallOption) (*QueryPositionsResponse, error)
+	QueryMarginInfo(ctx context.Context, in *QueryMarginInfoRequest, opts ...grpc.CallOption) (*QueryMarginInfoResponse, error)
+}
+
+type accountServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAccountServiceClient(cc grpc.ClientConnInterface) AccountServiceClient {
+	return &accountServiceClient{cc}
+}
+
+func (c *accountServiceClient) QueryBalances(ctx context.Context, in *QueryBalancesRequest, opts ...grpc.CallOption) (*QueryBalancesResponse, error) {
+	out := new(QueryBalancesResponse)
+	err := c.cc.Invoke(ctx, "/bbgo.AccountService/QueryBalances", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) QueryPositions(ctx context.Context, in *QueryPositionsRequest, opts ...grpc.CallOption) (*QueryPositionsResponse, error) {
+	out := new(QueryPositionsResponse)
+	err := c.cc.Invoke(ctx, "/bbgo.AccountService/QueryPositions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) QueryMarginInfo(ctx context.Context, in *QueryMarginInfoRequest, opts ...grpc.CallOption) (*QueryMarginInfoResponse, error) {
+	out := new(QueryMarginInfoResponse)
+	err := c.cc.Invoke(ctx, "/bbgo.AccountService/QueryMarginInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AccountServiceServer is the server API for AccountService service.
+// All implementations must embed UnimplementedAccountServiceServer
+// for forward compatibility
+type AccountServiceServer interface {
+	QueryBalances(context.Context, *QueryBalancesRequest) (*QueryBalancesResponse, error)
+	QueryPositions(context.Context, *QueryPositionsRequest) (*QueryPositionsResponse, error)
+	QueryMarginInfo(context.Context, *QueryMarginInfoRequest) (*QueryMarginInfoResponse, error)
+	mustEmbedUnimplementedAccountServiceServer()
+}
+
+// UnimplementedAccountServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedAccountServiceServer struct {
+}
+
+func (UnimplementedAccountServiceServer) QueryBalances(context.Context, *QueryBalancesRequest) (*QueryBalancesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryBalances not implemented")
+}
+func (UnimplementedAccountServiceServer) QueryPositions(context.Context, *QueryPositionsRequest) (*QueryPositionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryPositions not implemented")
+}
+func (UnimplementedAccountServiceServer) QueryMarginInfo(context.Context, *QueryMarginInfoRequest) (*QueryMarginInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryMarginInfo not implemented")
+}
+func (UnimplementedAccountServiceServer) mustEmbedUnimplementedAccountServiceServer() {}
+
+// UnsafeAccountServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AccountServiceServer will
+// result in compilation errors.
+type UnsafeAccountServiceServer interface {
+	mustEmbedUnimplementedAccountServiceServer()
+}
+
+func RegisterAccountServiceServer(s grpc.ServiceRegistrar, srv AccountServiceServer) {
+	s.RegisterService(&AccountService_ServiceDesc, srv)
+}
+
+func _AccountService_QueryBalances_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryBalancesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).QueryBalances(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bbgo.AccountService/QueryBalances",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).QueryBalances(ctx, req.(*QueryBalancesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_QueryPositions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryPositionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).QueryPositions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bbgo.AccountService/QueryPositions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).QueryPositions(ctx, req.(*QueryPositionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_QueryMarginInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryMarginInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).QueryMarginInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bbgo.AccountService/QueryMarginInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).QueryMarginInfo(ctx, req.(*QueryMarginInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AccountService_ServiceDesc is the grpc.ServiceDesc for AccountService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AccountService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bbgo.AccountService",
+	HandlerType: (*AccountServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "QueryBalances",
+			Handler:    _AccountService_QueryBalances_Handler,
+		},
+		{
+			MethodName: "QueryPositions",
+			Handler:    _AccountService_QueryPositions_Handler,
+		},
+		{
+			MethodName: "QueryMarginInfo",
+			Handler:    _AccountService_QueryMarginInfo_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/pb/bbgo.proto",
+}
+
+// StrategyServiceClient is the client API for StrategyService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type StrategyServiceClient interface {
+	ListStrategies(ctx context.Context, in *ListStrategiesRequest, opts ...grpc.CallOption) (*ListStrategiesResponse, error)
+	QueryStrategyStatus(ctx context.Context, in *StrategyRequest, opts ...grpc.CallOption) (*StrategyResponse, error)
+	SuspendStrategy(ctx context.Context, in *StrategyRequest, opts ...grpc.CallOption) (*StrategyResponse, error)
+	ResumeStrategy(ctx context.Context, in *StrategyRequest, opts ...grpc.CallOption) (*StrategyResponse, error)
+	EmergencyStopStrategy(ctx context.Context, in *StrategyRequest, opts ...grpc.CallOption) (*StrategyResponse, error)
+}
+
+type strategyServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStrategyServiceClient(cc grpc.ClientConnInterface) StrategyServiceClient {
+	return &strategyServiceClient{cc}
+}
+
+func (c *strategyServiceClient) ListStrategies(ctx context.Context, in *ListStrategiesRequest, opts ...grpc.CallOption) (*ListStrategiesResponse, error) {
+	out := new(ListStrategiesResponse)
+	err := c.cc.Invoke(ctx, "/bbgo.StrategyService/ListStrategies", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *strategyServiceClient) QueryStrategyStatus(ctx context.Context, in *StrategyRequest, opts ...grpc.CallOption) (*StrategyResponse, error) {
+	out := new(StrategyResponse)
+	err := c.cc.Invoke(ctx, "/bbgo.StrategyService/QueryStrategyStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *strategyServiceClient) SuspendStrategy(ctx context.Context, in *StrategyRequest, opts ...grpc.CallOption) (*StrategyResponse, error) {
+	out := new(StrategyResponse)
+	err := c.cc.Invoke(ctx, "/bbgo.StrategyService/SuspendStrategy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *strategyServiceClient) ResumeStrategy(ctx context.Context, in *StrategyRequest, opts ...grpc.CallOption) (*StrategyResponse, error) {
+	out := new(StrategyResponse)
+	err := c.cc.Invoke(ctx, "/bbgo.StrategyService/ResumeStrategy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *strategyServiceClient) EmergencyStopStrategy(ctx context.Context, in *StrategyRequest, opts ...grpc.CallOption) (*StrategyResponse, error) {
+	out := new(StrategyResponse)
+	err := c.cc.Invoke(ctx, "/bbgo.StrategyService/EmergencyStopStrategy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StrategyServiceServer is the server API for StrategyService service.
+// All implementations must embed UnimplementedStrategyServiceServer
+// for forward compatibility
+type StrategyServiceServer interface {
+	ListStrategies(context.Context, *ListStrategiesRequest) (*ListStrategiesResponse, error)
+	QueryStrategyStatus(context.Context, *StrategyRequest) (*StrategyResponse, error)
+	SuspendStrategy(context.Context, *StrategyRequest) (*StrategyResponse, error)
+	ResumeStrategy(context.Context, *StrategyRequest) (*StrategyResponse, error)
+	EmergencyStopStrategy(context.Context, *StrategyRequest) (*StrategyResponse, error)
+	mustEmbedUnimplementedStrategyServiceServer()
+}
+
+// UnimplementedStrategyServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedStrategyServiceServer struct {
+}
+
+func (UnimplementedStrategyServiceServer) ListStrategies(context.Context, *ListStrategiesRequest) (*ListStrategiesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStrategies not implemented")
+}
+func (UnimplementedStrategyServiceServer) QueryStrategyStatus(context.Context, *StrategyRequest) (*StrategyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryStrategyStatus not implemented")
+}
+func (UnimplementedStrategyServiceServer) SuspendStrategy(context.Context, *StrategyRequest) (*StrategyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SuspendStrategy not implemented")
+}
+func (UnimplementedStrategyServiceServer) ResumeStrategy(context.Context, *StrategyRequest) (*StrategyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeStrategy not implemented")
+}
+func (UnimplementedStrategyServiceServer) EmergencyStopStrategy(context.Context, *StrategyRequest) (*StrategyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EmergencyStopStrategy not implemented")
+}
+func (UnimplementedStrategyServiceServer) mustEmbedUnimplementedStrategyServiceServer() {}
+
+// UnsafeStrategyServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StrategyServiceServer will
+// result in compilation errors.
+type UnsafeStrategyServiceServer interface {
+	mustEmbedUnimplementedStrategyServiceServer()
+}
+
+func RegisterStrategyServiceServer(s grpc.ServiceRegistrar, srv StrategyServiceServer) {
+	s.RegisterService(&StrategyService_ServiceDesc, srv)
+}
+
+func _StrategyService_ListStrategies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStrategiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StrategyServiceServer).ListStrategies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bbgo.StrategyService/ListStrategies",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StrategyServiceServer).ListStrategies(ctx, req.(*ListStrategiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StrategyService_QueryStrategyStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StrategyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StrategyServiceServer).QueryStrategyStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bbgo.StrategyService/QueryStrategyStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StrategyServiceServer).QueryStrategyStatus(ctx, req.(*StrategyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StrategyService_SuspendStrategy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StrategyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StrategyServiceServer).SuspendStrategy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bbgo.StrategyService/SuspendStrategy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StrategyServiceServer).SuspendStrategy(ctx, req.(*StrategyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StrategyService_ResumeStrategy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StrategyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StrategyServiceServer).ResumeStrategy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bbgo.StrategyService/ResumeStrategy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StrategyServiceServer).ResumeStrategy(ctx, req.(*StrategyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StrategyService_EmergencyStopStrategy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StrategyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StrategyServiceServer).EmergencyStopStrategy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bbgo.StrategyService/EmergencyStopStrategy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StrategyServiceServer).EmergencyStopStrategy(ctx, req.(*StrategyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// StrategyService_ServiceDesc is the grpc.ServiceDesc for StrategyService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var StrategyService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bbgo.StrategyService",
+	HandlerType: (*StrategyServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListStrategies",
+			Handler:    _StrategyService_ListStrategies_Handler,
+		},
+		{
+			MethodName: "QueryStrategyStatus",
+			Handler:    _StrategyService_QueryStrategyStatus_Handler,
+		},
+		{
+			MethodName: "SuspendStrategy",
+			Handler:    _StrategyService_SuspendStrategy_Handler,
+		},
+		{
+			MethodName: "ResumeStrategy",
+			Handler:    _StrategyService_ResumeStrategy_Handler,
+		},
+		{
+			MethodName: "EmergencyStopStrategy",
+			Handler:    _StrategyService_EmergencyStopStrategy_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/pb/bbgo.proto",
+}
+
+// SignalServiceClient is the client API for SignalService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SignalServiceClient interface {
+	SubmitSignal(ctx context.Context, in *SubmitSignalRequest, opts ...grpc.CallOption) (*SubmitSignalResponse, error)
+}
+
+type signalServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSignalServiceClient(cc grpc.ClientConnInterface) SignalServiceClient {
+	return &signalServiceClient{cc}
+}
+
+func (c *signalServiceClient) SubmitSignal(ctx context.Context, in *SubmitSignalRequest, opts ...grpc.CallOption) (*SubmitSignalResponse, error) {
+	out := new(SubmitSignalResponse)
+	err := c.cc.Invoke(ctx, "/bbgo.SignalService/SubmitSignal", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SignalServiceServer is the server API for SignalService service.
+// All implementations must embed UnimplementedSignalServiceServer
+// for forward compatibility
+type SignalServiceServer interface {
+	SubmitSignal(context.Context, *SubmitSignalRequest) (*SubmitSignalResponse, error)
+	mustEmbedUnimplementedSignalServiceServer()
+}
+
+// UnimplementedSignalServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedSignalServiceServer struct {
+}
+
+func (UnimplementedSignalServiceServer) SubmitSignal(context.Context, *SubmitSignalRequest) (*SubmitSignalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitSignal not implemented")
+}
+func (UnimplementedSignalServiceServer) mustEmbedUnimplementedSignalServiceServer() {}
+
+// UnsafeSignalServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SignalServiceServer will
+// result in compilation errors.
+type UnsafeSignalServiceServer interface {
+	mustEmbedUnimplementedSignalServiceServer()
+}
+
+func RegisterSignalServiceServer(s grpc.ServiceRegistrar, srv SignalServiceServer) {
+	s.RegisterService(&SignalService_ServiceDesc, srv)
+}
+
+func _SignalService_SubmitSignal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitSignalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignalServiceServer).SubmitSignal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bbgo.SignalService/SubmitSignal",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignalServiceServer).SubmitSignal(ctx, req.(*SubmitSignalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SignalService_ServiceDesc is the grpc.ServiceDesc for SignalService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SignalService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bbgo.SignalService",
+	HandlerType: (*SignalServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitSignal",
+			Handler:    _SignalService_SubmitSignal_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/pb/bbgo.proto",
+}
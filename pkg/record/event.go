@@ -0,0 +1,44 @@
+package record
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type EventType string
+
+const (
+	EventTypeMarketTrade  EventType = "trade"
+	EventTypeBookTicker   EventType = "bookTicker"
+	EventTypeBookSnapshot EventType = "bookSnapshot"
+	EventTypeBookUpdate   EventType = "bookUpdate"
+
+	// EventTypeGap marks the time range that the market data is missing,
+	// e.g., the stream is disconnected or the recorder is stopped.
+	// The order book should be reset when a gap is found, until the next book snapshot.
+	EventTypeGap EventType = "gap"
+)
+
+// Event is a recorded market data event, Time is the local time that the event is received.
+type Event struct {
+	Type    EventType `json:"type"`
+	Session string    `json:"session"`
+	Symbol  string    `json:"symbol"`
+	Time    time.Time `json:"time"`
+
+	Trade      *types.Trade          `json:"trade,omitempty"`
+	BookTicker *types.BookTicker     `json:"bookTicker,omitempty"`
+	Book       *types.SliceOrderBook `json:"book,omitempty"`
+	Gap        *Gap                  `json:"gap,omitempty"`
+}
+
+type Gap struct {
+	// From is the time that the data starts missing, zero if it's unknown (e.g., the recorder is started)
+	From time.Time `json:"from,omitempty"`
+
+	// To is the time that the data is available again, zero if it's unknown (e.g., the recorder is stopped)
+	To time.Time `json:"to,omitempty"`
+
+	Reason string `json:"reason"`
+}
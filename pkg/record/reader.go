@@ -0,0 +1,87 @@
+package record
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Files returns the record files of the session and the symbol that may contain the events in [since, until],
+// sorted by the rotation start time.
+func Files(directory, session, symbol string, since, until time.Time) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(directory, session, symbol, "*"+fileExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	type recordFile struct {
+		path      string
+		startTime time.Time
+	}
+
+	var files []recordFile
+	for _, path := range matches {
+		startTime, err := time.Parse(fileTimeLayout, strings.TrimSuffix(filepath.Base(path), fileExtension))
+		if err != nil {
+			continue
+		}
+
+		files = append(files, recordFile{path: path, startTime: startTime})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].startTime.Before(files[j].startTime)
+	})
+
+	var paths []string
+	for i, f := range files {
+		// the file ends when the next file starts
+		if i+1 < len(files) && !since.IsZero() && files[i+1].startTime.Before(since) {
+			continue
+		}
+
+		if !until.IsZero() && f.startTime.After(until) {
+			break
+		}
+
+		paths = append(paths, f.path)
+	}
+
+	return paths, nil
+}
+
+// ReadFile reads the events of the record file in the recorded order
+func ReadFile(path string, fn func(event Event) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+
+	defer gz.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(gz))
+	for decoder.More() {
+		var event Event
+		if err := decoder.Decode(&event); err != nil {
+			return err
+		}
+
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
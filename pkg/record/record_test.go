@@ -0,0 +1,122 @@
+package record
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func readEvents(t *testing.T, paths []string) (events []Event) {
+	for _, path := range paths {
+		err := ReadFile(path, func(event Event) error {
+			events = append(events, event)
+			return nil
+		})
+		assert.NoError(t, err)
+	}
+	return events
+}
+
+func TestFileWriter_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	startTime := time.Date(2022, time.June, 1, 0, 30, 0, 0, time.UTC)
+
+	writer := NewFileWriter(dir, types.Interval1h)
+	for i := 0; i < 4; i++ {
+		err := writer.Write(Event{
+			Type:    EventTypeMarketTrade,
+			Session: "binance",
+			Symbol:  "BTCUSDT",
+			Time:    startTime.Add(time.Duration(i) * 20 * time.Minute),
+			Trade:   &types.Trade{ID: uint64(i), Exchange: types.ExchangeBinance, Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: fixedpoint.NewFromInt(30000)},
+		})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+
+	// re-open the writer, the events of the same rotation are appended
+	writer = NewFileWriter(dir, types.Interval1h)
+	assert.NoError(t, writer.Write(Event{Type: EventTypeGap, Session: "binance", Symbol: "BTCUSDT", Time: startTime.Add(80 * time.Minute), Gap: &Gap{Reason: "started"}}))
+	assert.NoError(t, writer.Close())
+
+	paths, err := Files(dir, "binance", "BTCUSDT", time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		writer.FilePath("binance", "BTCUSDT", startTime),
+		writer.FilePath("binance", "BTCUSDT", startTime.Add(time.Hour)),
+	}, paths)
+
+	events := readEvents(t, paths)
+	if assert.Len(t, events, 5) {
+		assert.Equal(t, uint64(0), events[0].Trade.ID)
+		assert.Equal(t, fixedpoint.NewFromInt(30000), events[0].Trade.Price)
+		assert.Equal(t, EventTypeGap, events[4].Type)
+	}
+
+	paths, err = Files(dir, "binance", "BTCUSDT", startTime.Add(time.Hour), time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, paths, 1)
+}
+
+type memoryWriter struct {
+	events []Event
+}
+
+func (w *memoryWriter) Write(event Event) error {
+	w.events = append(w.events, event)
+	return nil
+}
+
+func (w *memoryWriter) Close() error {
+	return nil
+}
+
+func TestRecorder_Gaps(t *testing.T) {
+	writer := &memoryWriter{}
+	recorder := NewRecorder("binance", []string{"BTCUSDT"}, writer)
+
+	now := time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)
+	recorder.now = func() time.Time { return now }
+
+	stream := &types.StandardStream{}
+	recorder.BindStream(stream)
+
+	stream.EmitConnect()
+	stream.EmitMarketTrade(types.Trade{ID: 1, Symbol: "BTCUSDT"})
+	stream.EmitMarketTrade(types.Trade{ID: 2, Symbol: "ETHUSDT"})
+	stream.EmitBookTickerUpdate(types.BookTicker{Symbol: "BTCUSDT", Buy: fixedpoint.One})
+
+	now = now.Add(time.Minute)
+	stream.EmitDisconnect()
+
+	now = now.Add(time.Minute)
+	stream.EmitConnect()
+	stream.EmitBookSnapshot(types.SliceOrderBook{Symbol: "BTCUSDT"})
+	recorder.Stop()
+
+	var eventTypes []EventType
+	for _, event := range writer.events {
+		eventTypes = append(eventTypes, event.Type)
+		assert.Equal(t, "binance", event.Session)
+	}
+
+	assert.Equal(t, []EventType{
+		EventTypeGap, EventTypeMarketTrade, EventTypeBookTicker, EventTypeGap, EventTypeBookSnapshot, EventTypeGap,
+	}, eventTypes)
+
+	if assert.Len(t, writer.events, 6) {
+		assert.Equal(t, "started", writer.events[0].Gap.Reason)
+
+		gap := writer.events[3].Gap
+		assert.Equal(t, "reconnected", gap.Reason)
+		assert.Equal(t, time.Date(2022, time.June, 1, 0, 1, 0, 0, time.UTC), gap.From)
+		assert.Equal(t, time.Date(2022, time.June, 1, 0, 2, 0, 0, time.UTC), gap.To)
+
+		assert.Equal(t, "stopped", writer.events[5].Gap.Reason)
+		assert.True(t, writer.events[5].Gap.To.IsZero())
+	}
+}
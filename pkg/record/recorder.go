@@ -0,0 +1,164 @@
+package record
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var log = logrus.WithField("component", "record")
+
+// Recorder records the market trades, the book tickers and the order book updates of the market data stream.
+// Gap events are written when the stream is disconnected and reconnected, and when the recorder is started and stopped,
+// so that the replay side knows where the data is not continuous.
+type Recorder struct {
+	Session string
+	Symbols []string
+	Writer  Writer
+
+	// MarketTradeService is optional, the market trades are inserted into the database if it's set
+	MarketTradeService *service.MarketTradeService
+
+	mu             sync.Mutex
+	symbols        map[string]struct{}
+	connected      bool
+	disconnectedAt time.Time
+
+	// now is used for testing
+	now func() time.Time
+}
+
+func NewRecorder(session string, symbols []string, writer Writer) *Recorder {
+	r := &Recorder{
+		Session: session,
+		Symbols: symbols,
+		Writer:  writer,
+		symbols: make(map[string]struct{}),
+		now:     time.Now,
+	}
+
+	for _, symbol := range symbols {
+		r.symbols[symbol] = struct{}{}
+	}
+
+	return r
+}
+
+// Subscribe subscribes the channels of the symbols on the stream.
+// The book ticker channel and the book channel are subscribed with the given depth.
+func (r *Recorder) Subscribe(stream types.Stream, channels []types.Channel, depth types.Depth) {
+	for _, symbol := range r.Symbols {
+		for _, channel := range channels {
+			switch channel {
+			case types.BookChannel:
+				stream.Subscribe(channel, symbol, types.SubscribeOptions{Depth: depth})
+			default:
+				stream.Subscribe(channel, symbol, types.SubscribeOptions{})
+			}
+		}
+	}
+}
+
+func (r *Recorder) BindStream(stream types.Stream) {
+	stream.OnConnect(r.handleConnect)
+	stream.OnDisconnect(r.handleDisconnect)
+
+	stream.OnMarketTrade(func(trade types.Trade) {
+		if !r.accept(trade.Symbol) {
+			return
+		}
+
+		r.write(Event{Type: EventTypeMarketTrade, Symbol: trade.Symbol, Trade: &trade})
+
+		if r.MarketTradeService != nil {
+			if err := r.MarketTradeService.Insert(trade); err != nil {
+				log.WithError(err).Errorf("market trade insert error: %+v", trade)
+			}
+		}
+	})
+
+	stream.OnBookTickerUpdate(func(bookTicker types.BookTicker) {
+		if !r.accept(bookTicker.Symbol) {
+			return
+		}
+
+		r.write(Event{Type: EventTypeBookTicker, Symbol: bookTicker.Symbol, BookTicker: &bookTicker})
+	})
+
+	stream.OnBookSnapshot(func(book types.SliceOrderBook) {
+		if !r.accept(book.Symbol) {
+			return
+		}
+
+		r.write(Event{Type: EventTypeBookSnapshot, Symbol: book.Symbol, Book: &book})
+	})
+
+	stream.OnBookUpdate(func(book types.SliceOrderBook) {
+		if !r.accept(book.Symbol) {
+			return
+		}
+
+		r.write(Event{Type: EventTypeBookUpdate, Symbol: book.Symbol, Book: &book})
+	})
+}
+
+func (r *Recorder) accept(symbol string) bool {
+	_, ok := r.symbols[symbol]
+	return ok
+}
+
+func (r *Recorder) handleConnect() {
+	r.mu.Lock()
+	gap := &Gap{From: r.disconnectedAt, To: r.now(), Reason: "reconnected"}
+	if !r.connected && r.disconnectedAt.IsZero() {
+		gap.Reason = "started"
+	}
+	r.connected = true
+	r.disconnectedAt = time.Time{}
+	r.mu.Unlock()
+
+	r.writeGap(gap)
+}
+
+func (r *Recorder) handleDisconnect() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.disconnectedAt.IsZero() {
+		r.disconnectedAt = r.now()
+	}
+}
+
+func (r *Recorder) writeGap(gap *Gap) {
+	for _, symbol := range r.Symbols {
+		r.write(Event{Type: EventTypeGap, Symbol: symbol, Gap: gap})
+	}
+}
+
+func (r *Recorder) write(event Event) {
+	event.Session = r.Session
+	if event.Time.IsZero() {
+		event.Time = r.now()
+	}
+
+	if err := r.Writer.Write(event); err != nil {
+		log.WithError(err).Errorf("can not write the %s event of %s", event.Type, event.Symbol)
+	}
+}
+
+// Stop writes the gap events that mark the end of the recording.
+// The writer is not closed since it could be shared by the recorders.
+func (r *Recorder) Stop() {
+	r.mu.Lock()
+	from := r.now()
+	if !r.disconnectedAt.IsZero() {
+		from = r.disconnectedAt
+	}
+	r.mu.Unlock()
+
+	r.writeGap(&Gap{From: from, Reason: "stopped"})
+}
@@ -0,0 +1,143 @@
+package record
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const fileTimeLayout = "20060102T150405"
+
+const fileExtension = ".jsonl.gz"
+
+// Writer writes the recorded events
+type Writer interface {
+	Write(event Event) error
+	Close() error
+}
+
+// FileWriter writes the events into the gzip compressed json lines files.
+// The files are separated by session and symbol, and rotated by the given interval:
+//
+//    {directory}/{session}/{symbol}/{rotation start time}.jsonl.gz
+//
+// When the file of the current rotation exists, the events are appended as a new gzip member,
+// so that the file can still be read as a single gzip stream.
+type FileWriter struct {
+	Directory string
+	Rotation  types.Interval
+
+	mu    sync.Mutex
+	files map[string]*rotatingFile
+}
+
+type rotatingFile struct {
+	startTime time.Time
+	file      *os.File
+	gzip      *gzip.Writer
+	encoder   *json.Encoder
+}
+
+func (f *rotatingFile) Close() error {
+	if err := f.gzip.Close(); err != nil {
+		_ = f.file.Close()
+		return err
+	}
+
+	return f.file.Close()
+}
+
+func NewFileWriter(directory string, rotation types.Interval) *FileWriter {
+	return &FileWriter{
+		Directory: directory,
+		Rotation:  rotation,
+		files:     make(map[string]*rotatingFile),
+	}
+}
+
+// FilePath returns the file path of the rotation that contains the given time
+func (w *FileWriter) FilePath(session, symbol string, t time.Time) string {
+	return filepath.Join(w.Directory, session, symbol, w.Rotation.Truncate(t).Format(fileTimeLayout)+fileExtension)
+}
+
+func (w *FileWriter) Write(event Event) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	key := event.Session + "/" + event.Symbol
+	startTime := w.Rotation.Truncate(event.Time)
+
+	f, ok := w.files[key]
+	if ok && !f.startTime.Equal(startTime) {
+		delete(w.files, key)
+		if err := f.Close(); err != nil {
+			return err
+		}
+		ok = false
+	}
+
+	if !ok {
+		var err error
+		f, err = w.open(w.FilePath(event.Session, event.Symbol, event.Time), startTime)
+		if err != nil {
+			return err
+		}
+
+		w.files[key] = f
+	}
+
+	return f.encoder.Encode(event)
+}
+
+func (w *FileWriter) open(path string, startTime time.Time) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("can not open the record file %s: %w", path, err)
+	}
+
+	gz := gzip.NewWriter(file)
+	return &rotatingFile{
+		startTime: startTime,
+		file:      file,
+		gzip:      gz,
+		encoder:   json.NewEncoder(gz),
+	}, nil
+}
+
+// Flush flushes the buffered events to the files
+func (w *FileWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, f := range w.files {
+		if err := f.gzip.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *FileWriter) Close() (err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for key, f := range w.files {
+		if closeErr := f.Close(); closeErr != nil {
+			err = closeErr
+		}
+		delete(w.files, key)
+	}
+
+	return err
+}
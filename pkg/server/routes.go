@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
@@ -10,6 +11,7 @@ import (
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -25,6 +27,9 @@ import (
 
 const DefaultBindAddress = "localhost:8080"
 
+// maxSignalRequestSize is the max body size of the signal request
+const maxSignalRequestSize = 64 * 1024
+
 type Setup struct {
 	// Context is the trader context
 	Context context.Context
@@ -52,7 +57,7 @@ func (s *Server) newEngine(ctx context.Context) *gin.Engine {
 	r := gin.Default()
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowHeaders:     []string{"Origin", "Content-Type"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
 		AllowWebSockets:  true,
@@ -139,6 +144,7 @@ func (s *Server) newEngine(ctx context.Context) *gin.Engine {
 	})
 
 	r.GET("/api/orders/closed", s.listClosedOrders)
+	r.GET("/api/audit", s.listAuditLogs)
 	r.GET("/api/trading-volume", s.tradingVolume)
 
 	r.POST("/api/sessions/test", func(c *gin.Context) {
@@ -224,6 +230,7 @@ func (s *Server) newEngine(ctx context.Context) *gin.Engine {
 	})
 
 	r.GET("/api/assets", s.listAssets)
+	r.GET("/api/exposure", s.getExposure)
 	r.GET("/api/sessions/:session", s.listSessions)
 	r.GET("/api/sessions/:session/trades", s.listSessionTrades)
 	r.GET("/api/sessions/:session/open-orders", s.listSessionOpenOrders)
@@ -248,6 +255,7 @@ func (s *Server) newEngine(ctx context.Context) *gin.Engine {
 	})
 
 	r.GET("/api/strategies/single", s.listStrategies)
+	r.POST("/api/signals", s.submitSignal)
 	r.NoRoute(s.assetsHandler)
 	return r
 }
@@ -313,6 +321,46 @@ func (s *Server) listClosedOrders(c *gin.Context) {
 	})
 }
 
+func (s *Server) listAuditLogs(c *gin.Context) {
+	if s.Environ.AuditService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database is not configured"})
+		return
+	}
+
+	gidStr := c.DefaultQuery("gid", "0")
+	lastGID, err := strconv.ParseInt(gidStr, 10, 64)
+	if err != nil {
+		logrus.WithError(err).Error("last gid parse error")
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	limitStr := c.DefaultQuery("limit", "100")
+	limit, err := strconv.ParseUint(limitStr, 10, 64)
+	if err != nil {
+		logrus.WithError(err).Error("limit parse error")
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	logs, err := s.Environ.AuditService.Query(c, service.QueryAuditLogsOptions{
+		Actor:   c.Query("actor"),
+		Command: c.Query("command"),
+		Outcome: types.AuditOutcome(c.Query("outcome")),
+		LastGID: lastGID,
+		Limit:   limit,
+	})
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		logrus.WithError(err).Error("audit log query error")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"auditLogs": logs,
+	})
+}
+
 func (s *Server) listStrategies(c *gin.Context) {
 	var stashes []map[string]interface{}
 
@@ -334,6 +382,41 @@ func (s *Server) listStrategies(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"strategies": stashes})
 }
 
+// submitSignal dispatches the external signal to the receiver strategy,
+// the auth token is read from the Authorization header, e.g. "Bearer <token>".
+func (s *Server) submitSignal(c *gin.Context) {
+	if s.Trader == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "trader is not running"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSignalRequestSize)
+
+	var signal bbgo.ExternalSignal
+	if err := c.ShouldBindJSON(&signal); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authToken := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+	orders, err := s.Trader.DispatchExternalSignal(c, signal, authToken)
+	if err != nil {
+		if errors.Is(err, bbgo.ErrInvalidSignalAuthToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if orders == nil {
+		orders = types.OrderSlice{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"orders": orders})
+}
+
 func (s *Server) listSessions(c *gin.Context) {
 	sessionName := c.Param("session")
 	session, ok := s.Environ.Session(sessionName)
@@ -480,6 +563,19 @@ func (s *Server) listAssets(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"assets": totalAssets})
 }
 
+func (s *Server) getExposure(c *gin.Context) {
+	currency := c.DefaultQuery("currency", bbgo.DefaultExposureValuationCurrency)
+
+	exposure, err := s.Environ.Exposure(c, currency)
+	if err != nil {
+		logrus.WithError(err).Error("exposure calculation failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"exposure": exposure})
+}
+
 func (s *Server) setupSaveConfig(c *gin.Context) {
 	if len(s.Config.Sessions) == 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "session is not configured"})
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// AuditService stores the audit logs of the interactive commands,
+// the audit log is append-only, so there is no update or delete method.
+type AuditService struct {
+	DB *sqlx.DB
+}
+
+type QueryAuditLogsOptions struct {
+	Actor   string
+	Command string
+	Outcome types.AuditOutcome
+
+	// LastGID is used for pagination, only the records before the gid are returned
+	LastGID int64
+	Limit   uint64
+}
+
+func (s *AuditService) Insert(log types.AuditLog) error {
+	_, err := s.DB.NamedExec(`
+			INSERT INTO audit_logs (actor, role, command, arguments, outcome, error, time)
+			VALUES (:actor, :role, :command, :arguments, :outcome, :error, :time)`,
+		log)
+	return err
+}
+
+// Query returns the audit logs sorted by the gid in descending order
+func (s *AuditService) Query(ctx context.Context, options QueryAuditLogsOptions) ([]types.AuditLog, error) {
+	conditions := sq.And{}
+	if len(options.Actor) > 0 {
+		conditions = append(conditions, sq.Eq{"actor": options.Actor})
+	}
+
+	if len(options.Command) > 0 {
+		conditions = append(conditions, sq.Eq{"command": options.Command})
+	}
+
+	if len(options.Outcome) > 0 {
+		conditions = append(conditions, sq.Eq{"outcome": options.Outcome})
+	}
+
+	if options.LastGID > 0 {
+		conditions = append(conditions, sq.Lt{"gid": options.LastGID})
+	}
+
+	limit := options.Limit
+	if limit == 0 {
+		limit = 100
+	}
+
+	sel := sq.Select("gid", "actor", "role", "command", "arguments", "outcome", "error", "time").
+		From("audit_logs").
+		OrderBy("gid DESC").
+		Limit(limit)
+
+	if len(conditions) > 0 {
+		sel = sel.Where(conditions)
+	}
+
+	sql, args, err := sel.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.QueryxContext(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var logs []types.AuditLog
+	for rows.Next() {
+		var log types.AuditLog
+		if err := rows.StructScan(&log); err != nil {
+			return logs, err
+		}
+
+		logs = append(logs, log)
+	}
+
+	return logs, rows.Err()
+}
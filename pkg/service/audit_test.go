@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestAuditService(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &AuditService{DB: xdb}
+
+	startTime := time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)
+	for i, cmd := range []string{"/position", "/closeposition", "/emergencystop"} {
+		err = service.Insert(types.AuditLog{
+			Actor:   "telegram-999-22",
+			Role:    "admin",
+			Command: cmd,
+			Outcome: types.AuditOutcomeSuccess,
+			Time:    types.Time(startTime.Add(time.Duration(i) * time.Minute)),
+		})
+		assert.NoError(t, err)
+	}
+
+	err = service.Insert(types.AuditLog{
+		Actor:     "telegram-1000-22",
+		Role:      "viewer",
+		Command:   "/closeposition",
+		Arguments: "grid:BTCUSDT",
+		Outcome:   types.AuditOutcomeDenied,
+		Error:     errors.New("permission denied").Error(),
+		Time:      types.Time(startTime.Add(5 * time.Minute)),
+	})
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	logs, err := service.Query(ctx, QueryAuditLogsOptions{})
+	if assert.NoError(t, err) && assert.Len(t, logs, 4) {
+		assert.Equal(t, "telegram-1000-22", logs[0].Actor)
+		assert.Equal(t, "grid:BTCUSDT", logs[0].Arguments)
+		assert.Equal(t, types.AuditOutcomeDenied, logs[0].Outcome)
+		assert.Equal(t, startTime.Add(5*time.Minute), logs[0].Time.Time().UTC())
+	}
+
+	logs, err = service.Query(ctx, QueryAuditLogsOptions{Command: "/closeposition"})
+	if assert.NoError(t, err) {
+		assert.Len(t, logs, 2)
+	}
+
+	logs, err = service.Query(ctx, QueryAuditLogsOptions{Actor: "telegram-999-22", Limit: 2})
+	if assert.NoError(t, err) && assert.Len(t, logs, 2) {
+		assert.Equal(t, "/emergencystop", logs[0].Command)
+
+		logs, err = service.Query(ctx, QueryAuditLogsOptions{Actor: "telegram-999-22", LastGID: logs[1].GID})
+		if assert.NoError(t, err) && assert.Len(t, logs, 1) {
+			assert.Equal(t, "/position", logs[0].Command)
+		}
+	}
+}
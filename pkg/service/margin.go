@@ -145,3 +145,70 @@ func SelectLastMarginLiquidations(ex types.ExchangeName, limit uint64) sq.Select
 		OrderBy("time DESC").
 		Limit(limit)
 }
+
+// QueryLoans queries the margin loans of the exchange in the time range [since, until)
+func (s *MarginService) QueryLoans(ctx context.Context, ex types.ExchangeName, since, until time.Time) (loans []types.MarginLoan, err error) {
+	err = s.queryByTimeRange(ctx, "margin_loans", ex, since, until, func(rows *sqlx.Rows) error {
+		var loan types.MarginLoan
+		if err := rows.StructScan(&loan); err != nil {
+			return err
+		}
+		loans = append(loans, loan)
+		return nil
+	})
+	return loans, err
+}
+
+// QueryRepays queries the margin repays of the exchange in the time range [since, until)
+func (s *MarginService) QueryRepays(ctx context.Context, ex types.ExchangeName, since, until time.Time) (repays []types.MarginRepay, err error) {
+	err = s.queryByTimeRange(ctx, "margin_repays", ex, since, until, func(rows *sqlx.Rows) error {
+		var repay types.MarginRepay
+		if err := rows.StructScan(&repay); err != nil {
+			return err
+		}
+		repays = append(repays, repay)
+		return nil
+	})
+	return repays, err
+}
+
+// QueryInterests queries the margin interests of the exchange in the time range [since, until)
+func (s *MarginService) QueryInterests(ctx context.Context, ex types.ExchangeName, since, until time.Time) (interests []types.MarginInterest, err error) {
+	err = s.queryByTimeRange(ctx, "margin_interests", ex, since, until, func(rows *sqlx.Rows) error {
+		var interest types.MarginInterest
+		if err := rows.StructScan(&interest); err != nil {
+			return err
+		}
+		interests = append(interests, interest)
+		return nil
+	})
+	return interests, err
+}
+
+func (s *MarginService) queryByTimeRange(ctx context.Context, table string, ex types.ExchangeName, since, until time.Time, scan func(rows *sqlx.Rows) error) error {
+	sql, args, err := sq.Select("*").
+		From(table).
+		Where(sq.Eq{"exchange": ex}).
+		Where(sq.GtOrEq{"time": since}).
+		Where(sq.Lt{"time": until}).
+		OrderBy("time ASC").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.DB.QueryxContext(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
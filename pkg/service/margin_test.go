@@ -10,7 +10,9 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/c9s/bbgo/pkg/exchange/binance"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/testutil"
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 func TestMarginService(t *testing.T) {
@@ -50,3 +52,47 @@ func TestMarginService(t *testing.T) {
 	err = service.Sync(ctx, ex, "USDT", time.Date(2022, time.February, 1, 0, 0, 0, 0, time.UTC))
 	assert.NoError(t, err)
 }
+
+func TestMarginService_Query(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+
+	dbx := sqlx.NewDb(db.DB, "sqlite3")
+	service := &MarginService{DB: dbx}
+
+	t0 := time.Date(2022, time.February, 1, 0, 0, 0, 0, time.UTC)
+	_, err = dbx.NamedExec("INSERT INTO margin_loans (transaction_id, exchange, asset, principle, time) VALUES (:transaction_id, :exchange, :asset, :principle, :time)",
+		types.MarginLoan{TransactionID: 1, Exchange: types.ExchangeBinance, Asset: "USDT", Principle: fixedpoint.NewFromInt(100), Time: types.Time(t0)})
+	assert.NoError(t, err)
+
+	_, err = dbx.NamedExec("INSERT INTO margin_repays (transaction_id, exchange, asset, principle, time) VALUES (:transaction_id, :exchange, :asset, :principle, :time)",
+		types.MarginRepay{TransactionID: 2, Exchange: types.ExchangeBinance, Asset: "USDT", Principle: fixedpoint.NewFromInt(100), Time: types.Time(t0.AddDate(1, 0, 0))})
+	assert.NoError(t, err)
+
+	_, err = dbx.NamedExec("INSERT INTO margin_interests (exchange, asset, principle, interest, interest_rate, time) VALUES (:exchange, :asset, :principle, :interest, :interest_rate, :time)",
+		types.MarginInterest{Exchange: types.ExchangeBinance, Asset: "USDT", Principle: fixedpoint.NewFromInt(100), Interest: fixedpoint.MustNewFromString("0.01"), InterestRate: fixedpoint.MustNewFromString("0.0001"), Time: types.Time(t0.Add(time.Hour))})
+	assert.NoError(t, err)
+
+	until := t0.AddDate(0, 6, 0)
+	loans, err := service.QueryLoans(ctx, types.ExchangeBinance, t0, until)
+	assert.NoError(t, err)
+	if assert.Len(t, loans, 1) {
+		assert.Equal(t, "100", loans[0].Principle.String())
+	}
+
+	repays, err := service.QueryRepays(ctx, types.ExchangeBinance, t0, until)
+	assert.NoError(t, err)
+	assert.Len(t, repays, 0)
+
+	interests, err := service.QueryInterests(ctx, types.ExchangeBinance, t0, until)
+	assert.NoError(t, err)
+	if assert.Len(t, interests, 1) {
+		assert.Equal(t, "0.01", interests[0].Interest.String())
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// MarketTradeService stores the public market trades, the stored trades are used to build
+// the trade-based bars (tick, volume, dollar and renko bars) in backtest.
+type MarketTradeService struct {
+	DB *sqlx.DB
+}
+
+func (s *MarketTradeService) Insert(trade types.Trade) error {
+	_, err := s.DB.NamedExec(`
+			INSERT INTO market_trades (id, exchange, symbol, price, quantity, quote_quantity, side, is_buyer, traded_at)
+			VALUES (:id, :exchange, :symbol, :price, :quantity, :quote_quantity, :side, :is_buyer, :traded_at)`,
+		trade)
+	return err
+}
+
+// Query returns the market trades in the time range [since, until], sorted by the trade time
+func (s *MarketTradeService) Query(ctx context.Context, ex types.ExchangeName, symbol string, since, until time.Time) ([]types.Trade, error) {
+	sql, args, err := sq.Select("id", "exchange", "symbol", "price", "quantity", "quote_quantity", "side", "is_buyer", "traded_at").
+		From("market_trades").
+		Where(sq.And{
+			sq.Eq{"exchange": ex},
+			sq.Eq{"symbol": symbol},
+			sq.GtOrEq{"traded_at": since},
+			sq.LtOrEq{"traded_at": until},
+		}).
+		OrderBy("traded_at ASC", "id ASC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.QueryxContext(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var trades []types.Trade
+	for rows.Next() {
+		var trade types.Trade
+		if err := rows.StructScan(&trade); err != nil {
+			return trades, err
+		}
+
+		trades = append(trades, trade)
+	}
+
+	return trades, rows.Err()
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestMarketTradeService(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &MarketTradeService{DB: xdb}
+
+	startTime := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		err = service.Insert(types.Trade{
+			ID:            uint64(i + 1),
+			Exchange:      types.ExchangeBinance,
+			Symbol:        "BTCUSDT",
+			Price:         fixedpoint.NewFromInt(int64(40000 + i)),
+			Quantity:      fixedpoint.One,
+			QuoteQuantity: fixedpoint.NewFromInt(int64(40000 + i)),
+			Side:          types.SideTypeBuy,
+			IsBuyer:       true,
+			Time:          types.Time(startTime.Add(time.Duration(i) * time.Minute)),
+		})
+		assert.NoError(t, err)
+	}
+
+	// duplicated trade
+	err = service.Insert(types.Trade{
+		ID:       1,
+		Exchange: types.ExchangeBinance,
+		Symbol:   "BTCUSDT",
+		Time:     types.Time(startTime),
+	})
+	assert.Error(t, err)
+
+	trades, err := service.Query(context.Background(), types.ExchangeBinance, "BTCUSDT", startTime.Add(time.Minute), startTime.Add(3*time.Minute))
+	if assert.NoError(t, err) && assert.Len(t, trades, 3) {
+		assert.Equal(t, uint64(2), trades[0].ID)
+		assert.Equal(t, fixedpoint.NewFromInt(40001), trades[0].Price)
+		assert.Equal(t, types.SideTypeBuy, trades[0].Side)
+		assert.Equal(t, startTime.Add(3*time.Minute), trades[2].Time.Time().UTC())
+	}
+}
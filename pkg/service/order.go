@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"strconv"
 	"strings"
 	"time"
@@ -109,16 +110,24 @@ type QueryOrdersOptions struct {
 	Symbol   string
 	LastGID  int64
 	Ordering string
+
+	// Since and Until filter the orders by the creation time
+	Since, Until *time.Time
+
+	// Statuses filters the orders by the order status
+	Statuses []types.OrderStatus
+
+	// Limit is the max number of the returned orders, defaultOrderQueryLimit is used when it's zero
+	Limit  uint64
+	Offset uint64
 }
 
+const defaultOrderQueryLimit = 500
+
 func (s *OrderService) Query(options QueryOrdersOptions) ([]AggOrder, error) {
 	sql := genOrderSQL(options)
 
-	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
-		"exchange": options.Exchange,
-		"symbol":   options.Symbol,
-		"gid":      options.LastGID,
-	})
+	rows, err := s.DB.NamedQuery(sql, genOrderSQLArgs(options))
 	if err != nil {
 		return nil, err
 	}
@@ -136,22 +145,41 @@ func genOrderSQL(options QueryOrdersOptions) string {
 		ordering = options.Ordering
 	}
 
+	// the columns are qualified since the trades table has the same columns
 	var where []string
 	if options.LastGID > 0 {
 		switch ordering {
 		case "ASC":
-			where = append(where, "gid > :gid")
+			where = append(where, "orders.gid > :gid")
 		case "DESC":
-			where = append(where, "gid < :gid")
+			where = append(where, "orders.gid < :gid")
 
 		}
 	}
 
 	if len(options.Exchange) > 0 {
-		where = append(where, "exchange = :exchange")
+		where = append(where, "orders.exchange = :exchange")
 	}
 	if len(options.Symbol) > 0 {
-		where = append(where, "symbol = :symbol")
+		where = append(where, "orders.symbol = :symbol")
+	}
+	if options.Since != nil {
+		where = append(where, "orders.created_at >= :since")
+	}
+	if options.Until != nil {
+		where = append(where, "orders.created_at < :until")
+	}
+	if len(options.Statuses) > 0 {
+		var names []string
+		for i := range options.Statuses {
+			names = append(names, ":status"+strconv.Itoa(i))
+		}
+		where = append(where, "orders.status IN ("+strings.Join(names, ", ")+")")
+	}
+
+	limit := options.Limit
+	if limit == 0 {
+		limit = defaultOrderQueryLimit
 	}
 
 	sql := `SELECT orders.*, IFNULL(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders` +
@@ -161,12 +189,38 @@ func genOrderSQL(options QueryOrdersOptions) string {
 	}
 	sql += ` GROUP BY orders.gid `
 	sql += ` ORDER BY orders.gid ` + ordering
-	sql += ` LIMIT ` + strconv.Itoa(500)
+	sql += ` LIMIT ` + strconv.FormatUint(limit, 10)
+	if options.Offset > 0 {
+		sql += ` OFFSET ` + strconv.FormatUint(options.Offset, 10)
+	}
 
 	log.Info(sql)
 	return sql
 }
 
+// genOrderSQLArgs returns the named arguments of the sql generated by genOrderSQL
+func genOrderSQLArgs(options QueryOrdersOptions) map[string]interface{} {
+	args := map[string]interface{}{
+		"exchange": options.Exchange,
+		"symbol":   options.Symbol,
+		"gid":      options.LastGID,
+	}
+
+	if options.Since != nil {
+		args["since"] = *options.Since
+	}
+
+	if options.Until != nil {
+		args["until"] = *options.Until
+	}
+
+	for i, status := range options.Statuses {
+		args["status"+strconv.Itoa(i)] = status
+	}
+
+	return args
+}
+
 func (s *OrderService) scanAggRows(rows *sqlx.Rows) (orders []AggOrder, err error) {
 	for rows.Next() {
 		var order AggOrder
@@ -194,17 +248,24 @@ func (s *OrderService) scanRows(rows *sqlx.Rows) (orders []types.Order, err erro
 }
 
 func (s *OrderService) Insert(order types.Order) (err error) {
+	// attribute the order to the strategy by the client order ID
+	if !order.StrategyID.Valid {
+		if info, ok := types.ParseClientOrderID(order.ClientOrderID); ok && info.StrategyID != "" {
+			order.StrategyID = sql.NullString{String: info.StrategyID, Valid: true}
+		}
+	}
+
 	if s.DB.DriverName() == "mysql" {
 		_, err = s.DB.NamedExec(`
-			INSERT INTO orders (exchange, order_id, client_order_id, order_type, status, symbol, price, stop_price, quantity, executed_quantity, side, is_working, time_in_force, created_at, updated_at, is_margin, is_futures, is_isolated)
-			VALUES (:exchange, :order_id, :client_order_id, :order_type, :status, :symbol, :price, :stop_price, :quantity, :executed_quantity, :side, :is_working, :time_in_force, :created_at, :updated_at, :is_margin, :is_futures, :is_isolated)
-			ON DUPLICATE KEY UPDATE status=:status, executed_quantity=:executed_quantity, is_working=:is_working, updated_at=:updated_at`, order)
+			INSERT INTO orders (exchange, order_id, client_order_id, order_type, status, symbol, price, stop_price, quantity, executed_quantity, side, is_working, time_in_force, created_at, updated_at, is_margin, is_futures, is_isolated, strategy)
+			VALUES (:exchange, :order_id, :client_order_id, :order_type, :status, :symbol, :price, :stop_price, :quantity, :executed_quantity, :side, :is_working, :time_in_force, :created_at, :updated_at, :is_margin, :is_futures, :is_isolated, :strategy)
+			ON DUPLICATE KEY UPDATE status=:status, executed_quantity=:executed_quantity, is_working=:is_working, updated_at=:updated_at, strategy=:strategy`, order)
 		return err
 	}
 
 	_, err = s.DB.NamedExec(`
-			INSERT INTO orders (exchange, order_id, client_order_id, order_type, status, symbol, price, stop_price, quantity, executed_quantity, side, is_working, time_in_force, created_at, updated_at, is_margin, is_futures, is_isolated)
-			VALUES (:exchange, :order_id, :client_order_id, :order_type, :status, :symbol, :price, :stop_price, :quantity, :executed_quantity, :side, :is_working, :time_in_force, :created_at, :updated_at, :is_margin, :is_futures, :is_isolated)
+			INSERT INTO orders (exchange, order_id, client_order_id, order_type, status, symbol, price, stop_price, quantity, executed_quantity, side, is_working, time_in_force, created_at, updated_at, is_margin, is_futures, is_isolated, strategy)
+			VALUES (:exchange, :order_id, :client_order_id, :order_type, :status, :symbol, :price, :stop_price, :quantity, :executed_quantity, :side, :is_working, :time_in_force, :created_at, :updated_at, :is_margin, :is_futures, :is_isolated, :strategy)
 	`, order)
 
 	return err
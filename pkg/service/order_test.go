@@ -2,8 +2,13 @@ package service
 
 import (
 	"testing"
+	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 func Test_genOrderSQL(t *testing.T) {
@@ -21,4 +26,74 @@ func Test_genOrderSQL(t *testing.T) {
 		assert.Equal(t, "SELECT orders.*, IFNULL(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) GROUP BY orders.gid  ORDER BY orders.gid DESC LIMIT 500", genOrderSQL(o))
 	})
 
+	t.Run("filters", func(t *testing.T) {
+		since := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+		o := QueryOrdersOptions{
+			Exchange: types.ExchangeBinance,
+			Since:    &since,
+			Statuses: []types.OrderStatus{types.OrderStatusFilled, types.OrderStatusCanceled},
+			Limit:    10,
+			Offset:   20,
+		}
+		assert.Equal(t, "SELECT orders.*, IFNULL(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) WHERE orders.exchange = :exchange AND orders.created_at >= :since AND orders.status IN (:status0, :status1) GROUP BY orders.gid  ORDER BY orders.gid ASC LIMIT 10 OFFSET 20", genOrderSQL(o))
+
+		args := genOrderSQLArgs(o)
+		assert.Equal(t, since, args["since"])
+		assert.Equal(t, types.OrderStatusCanceled, args["status1"])
+		assert.NotContains(t, args, "until")
+	})
+}
+
+func Test_orderService_Query(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &OrderService{DB: xdb}
+
+	startTime := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	statuses := []types.OrderStatus{types.OrderStatusFilled, types.OrderStatusCanceled, types.OrderStatusFilled, types.OrderStatusFilled}
+	for i, status := range statuses {
+		err = service.Insert(types.Order{
+			SubmitOrder: types.SubmitOrder{
+				Symbol:   "BTCUSDT",
+				Side:     types.SideTypeBuy,
+				Type:     types.OrderTypeLimit,
+				Price:    fixedpoint.NewFromInt(20000),
+				Quantity: fixedpoint.One,
+			},
+			Exchange:     types.ExchangeBinance,
+			OrderID:      uint64(i + 1),
+			Status:       status,
+			CreationTime: types.Time(startTime.Add(time.Duration(i) * time.Hour)),
+			UpdateTime:   types.Time(startTime.Add(time.Duration(i) * time.Hour)),
+		})
+		assert.NoError(t, err)
+	}
+
+	since := startTime.Add(time.Hour)
+	orders, err := service.Query(QueryOrdersOptions{
+		Exchange: types.ExchangeBinance,
+		Symbol:   "BTCUSDT",
+		Since:    &since,
+		Statuses: []types.OrderStatus{types.OrderStatusFilled},
+		Ordering: "DESC",
+		Limit:    1,
+		Offset:   1,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, orders, 1) {
+		assert.Equal(t, uint64(3), orders[0].OrderID)
+	}
+
+	until := startTime.Add(time.Hour)
+	orders, err = service.Query(QueryOrdersOptions{Exchange: types.ExchangeBinance, Until: &until})
+	assert.NoError(t, err)
+	if assert.Len(t, orders, 1) {
+		assert.Equal(t, uint64(1), orders[0].OrderID)
+	}
 }
@@ -134,6 +134,28 @@ func (s *RewardService) QueryUnspent(ctx context.Context, ex types.ExchangeName,
 	return s.scanRows(rows)
 }
 
+// QueryByTimeRange queries all the rewards of the exchange created in the time range [since, until)
+func (s *RewardService) QueryByTimeRange(ctx context.Context, ex types.ExchangeName, since, until time.Time) ([]types.Reward, error) {
+	sql, args, err := sq.Select("*").
+		From("rewards").
+		Where(sq.Eq{"exchange": ex}).
+		Where(sq.GtOrEq{"created_at": since}).
+		Where(sq.Lt{"created_at": until}).
+		OrderBy("created_at ASC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.QueryxContext(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	return s.scanRows(rows)
+}
+
 func (s *RewardService) MarkCurrencyAsSpent(ctx context.Context, currency string) error {
 	result, err := s.DB.NamedExecContext(ctx, "UPDATE `rewards` SET `spent` = TRUE WHERE `currency` = :currency AND `spent` IS FALSE", map[string]interface{}{
 		"currency": currency,
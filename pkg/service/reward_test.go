@@ -138,3 +138,39 @@ func TestRewardService_AggregateUnspentCurrencyPosition(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, fixedpoint.One, v)
 }
+
+func TestRewardService_QueryByTimeRange(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &RewardService{DB: xdb}
+
+	t0 := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, uuid := range []string{"test01", "test02", "test03"} {
+		err = service.Insert(types.Reward{
+			UUID:      uuid,
+			Exchange:  "max",
+			Type:      "commission",
+			Currency:  "BTC",
+			Quantity:  fixedpoint.One,
+			State:     "done",
+			Spent:     i == 0,
+			CreatedAt: types.Time(t0.AddDate(0, i*6, 0)),
+		})
+		assert.NoError(t, err)
+	}
+
+	rewards, err := service.QueryByTimeRange(ctx, types.ExchangeMax, t0, t0.AddDate(1, 0, 0))
+	assert.NoError(t, err)
+	if assert.Len(t, rewards, 2) {
+		assert.Equal(t, "test01", rewards[0].UUID)
+		assert.Equal(t, "test02", rewards[1].UUID)
+	}
+}
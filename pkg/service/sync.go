@@ -42,6 +42,11 @@ func (s *SyncService) SyncSessionSymbols(ctx context.Context, exchange types.Exc
 			if err := s.OrderService.Sync(ctx, exchange, symbol, startTime); err != nil {
 				return err
 			}
+
+			// the orders are attributed to the strategies by the client order IDs
+			if err := s.TradeService.UpdateStrategies(ctx, exchange.Name(), symbol); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -24,10 +25,14 @@ type QueryTradesOptions struct {
 	Symbol   string
 	LastGID  int64
 	Since    *time.Time
+	Until    *time.Time
 
 	// ASC or DESC
 	Ordering string
 	Limit    uint64
+
+	// Offset skips the first trades of the result, it's used for pagination
+	Offset uint64
 }
 
 type TradingVolume struct {
@@ -276,7 +281,14 @@ func (s *TradeService) Query(options QueryTradesOptions) ([]types.Trade, error)
 		sel = sel.Where(sq.GtOrEq{"traded_at": options.Since})
 	}
 
-	sel = sel.Where(sq.Eq{"symbol": options.Symbol})
+	if options.Until != nil {
+		sel = sel.Where(sq.Lt{"traded_at": options.Until})
+	}
+
+	// query the trades of all symbols if the symbol is not given
+	if options.Symbol != "" {
+		sel = sel.Where(sq.Eq{"symbol": options.Symbol})
+	}
 
 	if options.Exchange != "" {
 		sel = sel.Where(sq.Eq{"exchange": options.Exchange})
@@ -287,14 +299,24 @@ func (s *TradeService) Query(options QueryTradesOptions) ([]types.Trade, error)
 		sel = sel.Where(sq.Eq{"exchange": options.Sessions})
 	}
 
-	if options.Ordering != "" {
-		sel = sel.OrderBy("traded_at " + options.Ordering)
-	} else {
-		sel = sel.OrderBy("traded_at ASC")
+	ordering := "ASC"
+	switch v := strings.ToUpper(options.Ordering); v {
+	case "DESC", "ASC":
+		ordering = v
 	}
 
+	// the gid breaks the ties of the trade time so that the pages don't overlap
+	sel = sel.OrderBy("traded_at "+ordering, "gid "+ordering)
+
 	if options.Limit > 0 {
 		sel = sel.Limit(options.Limit)
+	} else if options.Offset > 0 {
+		// OFFSET requires LIMIT in both mysql and sqlite
+		sel = sel.Limit(math.MaxInt64)
+	}
+
+	if options.Offset > 0 {
+		sel = sel.Offset(options.Offset)
 	}
 
 	sql, args, err := sel.ToSql()
@@ -394,6 +416,20 @@ func (s *TradeService) Insert(trade types.Trade) error {
 	return err
 }
 
+// UpdateStrategies fills the strategy column of the trades from the strategy of their orders
+func (s *TradeService) UpdateStrategies(ctx context.Context, ex types.ExchangeName, symbol string) error {
+	_, err := s.DB.ExecContext(ctx, `
+		UPDATE trades SET strategy = (
+			SELECT orders.strategy FROM orders
+			WHERE orders.exchange = trades.exchange AND orders.order_id = trades.order_id
+		)
+		WHERE trades.exchange = ? AND trades.symbol = ? AND trades.strategy IS NULL AND EXISTS (
+			SELECT 1 FROM orders
+			WHERE orders.exchange = trades.exchange AND orders.order_id = trades.order_id AND orders.strategy IS NOT NULL
+		)`, ex, symbol)
+	return err
+}
+
 func (s *TradeService) DeleteAll() error {
 	_, err := s.DB.Exec(`DELETE FROM trades`)
 	return err
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -35,6 +36,44 @@ func Test_tradeService(t *testing.T) {
 		Time:          types.Time(time.Now()),
 	})
 	assert.NoError(t, err)
+
+	err = service.Insert(types.Trade{
+		ID:            2,
+		OrderID:       2,
+		Exchange:      "binance",
+		Price:         fixedpoint.NewFromInt(100),
+		Quantity:      fixedpoint.One,
+		QuoteQuantity: fixedpoint.NewFromInt(100),
+		Symbol:        "ETHUSDT",
+		Side:          "SELL",
+		Time:          types.Time(time.Now().Add(-time.Hour)),
+	})
+	assert.NoError(t, err)
+
+	// query the trades of all symbols
+	trades, err := service.Query(QueryTradesOptions{Exchange: "binance"})
+	assert.NoError(t, err)
+	assert.Len(t, trades, 2)
+
+	until := time.Now().Add(-time.Minute)
+	trades, err = service.Query(QueryTradesOptions{Exchange: "binance", Until: &until})
+	assert.NoError(t, err)
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, "ETHUSDT", trades[0].Symbol)
+	}
+
+	// the page is selected by the offset and the limit
+	trades, err = service.Query(QueryTradesOptions{Exchange: "binance", Ordering: "DESC", Limit: 1, Offset: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, "ETHUSDT", trades[0].Symbol)
+	}
+
+	trades, err = service.Query(QueryTradesOptions{Exchange: "binance", Ordering: "ASC", Offset: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, "BTCUSDT", trades[0].Symbol)
+	}
 }
 
 func Test_queryTradingVolumeSQL(t *testing.T) {
@@ -87,3 +126,63 @@ func Test_queryTradesSQL(t *testing.T) {
 		}))
 	})
 }
+
+func Test_tradeService_UpdateStrategies(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	tradeService := &TradeService{DB: xdb}
+	orderService := &OrderService{DB: xdb}
+
+	types.RegisterClientOrderIDStrategy("bollmaker")
+	clientOrderIDs := types.NewClientOrderIDGenerator("bollmaker", "bollmaker:BTCUSDT", 0)
+
+	now := time.Now()
+	for i, clientOrderID := range []string{"x-NSUYEBKM" + clientOrderIDs.Next(), "manual"} {
+		orderID := uint64(i + 1)
+		err = orderService.Insert(types.Order{
+			SubmitOrder: types.SubmitOrder{
+				ClientOrderID: clientOrderID,
+				Symbol:        "BTCUSDT",
+				Side:          types.SideTypeBuy,
+				Type:          types.OrderTypeLimit,
+				Quantity:      fixedpoint.One,
+				Price:         fixedpoint.NewFromInt(1000),
+			},
+			Exchange:     "binance",
+			OrderID:      orderID,
+			Status:       types.OrderStatusFilled,
+			CreationTime: types.Time(now),
+			UpdateTime:   types.Time(now),
+		})
+		assert.NoError(t, err)
+
+		err = tradeService.Insert(types.Trade{
+			ID:            orderID,
+			OrderID:       orderID,
+			Exchange:      "binance",
+			Price:         fixedpoint.NewFromInt(1000),
+			Quantity:      fixedpoint.One,
+			QuoteQuantity: fixedpoint.NewFromInt(1000),
+			Symbol:        "BTCUSDT",
+			Side:          types.SideTypeBuy,
+			IsBuyer:       true,
+			Time:          types.Time(now.Add(time.Duration(i) * time.Minute)),
+		})
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, tradeService.UpdateStrategies(context.Background(), "binance", "BTCUSDT"))
+
+	trades, err := tradeService.Query(QueryTradesOptions{Exchange: "binance", Ordering: "ASC"})
+	assert.NoError(t, err)
+	if assert.Len(t, trades, 2) {
+		assert.Equal(t, "bollmaker", trades[0].StrategyID.String)
+		assert.False(t, trades[1].StrategyID.Valid)
+	}
+}
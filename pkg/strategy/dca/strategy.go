@@ -114,6 +114,10 @@ func (s *Strategy) Run(ctx context.Context, _ bbgo.OrderExecutor, session *bbgo.
 	})
 	s.orderExecutor.Bind()
 
+	if _, err := s.orderExecutor.BindReconciler(ctx); err != nil {
+		log.WithError(err).Errorf("%s position reconciliation error", s.Symbol)
+	}
+
 	numOfInvestmentPerPeriod := fixedpoint.NewFromFloat(float64(s.BudgetPeriod.Duration()) / float64(s.InvestmentInterval.Duration()))
 	s.budgetPerInvestment = s.Budget.Div(numOfInvestmentPerPeriod)
 
@@ -0,0 +1,90 @@
+package drift
+
+import (
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/charting"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// numOfChartKLines is the number of the klines drawn on the indicator chart
+const numOfChartKLines = 300
+
+var _ bbgo.ChartOverlayProvider = &Strategy{}
+
+// ChartOverlays draws the moving average and the drift filters on the /chart command and the backtest charts,
+// the 1m drift is drawn on the 1m chart.
+func (s *Strategy) ChartOverlays(symbol string, interval types.Interval) []charting.Overlay {
+	if symbol != s.Symbol {
+		return nil
+	}
+
+	switch {
+	case interval == s.Interval && s.drift != nil:
+		return []charting.Overlay{
+			{Name: "ma", Series: s.ma},
+			{Name: "price", Series: s.priceLines},
+			{Name: "drift", Series: s.drift, Secondary: true},
+			{Name: "driftOrig", Series: s.drift.drift, Secondary: true},
+			{Name: "pos", Series: types.NumberSeries(s.DriftFilterPos), Secondary: true},
+			{Name: "neg", Series: types.NumberSeries(s.DriftFilterNeg), Secondary: true},
+			{Name: "ppos", Series: types.NumberSeries(s.DDriftFilterPos), Secondary: true},
+			{Name: "nneg", Series: types.NumberSeries(s.DDriftFilterNeg), Secondary: true},
+		}
+
+	case interval == types.Interval1m && s.drift1m != nil:
+		return []charting.Overlay{
+			{Name: "drift1m", Series: s.drift1m, Secondary: true},
+		}
+	}
+
+	return nil
+}
+
+// indicatorChart builds the chart of the strategy interval with the klines aggregated by the serial market data store
+func (s *Strategy) indicatorChart(store *bbgo.SerialMarketDataStore) *charting.Chart {
+	var klines []types.KLine
+	if window, ok := store.KLinesOfInterval(s.Interval); ok {
+		klines = window.Tail(numOfChartKLines)
+	}
+
+	c := bbgo.NewSessionChart(s.Session, s.Symbol, s.Interval, klines, s)
+	c.Title = s.InstanceID()
+	return c
+}
+
+func (s *Strategy) profitChart(profit types.Series) *charting.LineChart {
+	if s.GraphPNLDeductFee {
+		return charting.NewProfitChart(s.InstanceID(), "pnl % (with Fee Deducted)", profit)
+	}
+
+	return charting.NewProfitChart(s.InstanceID(), "pnl %", profit)
+}
+
+func (s *Strategy) InitDrawCommands(store *bbgo.SerialMarketDataStore, profit, cumProfit types.Series) {
+	bbgo.RegisterChartCommand("/draw", "Draw Indicators", func() bbgo.ChartRenderer {
+		return s.indicatorChart(store)
+	})
+
+	bbgo.RegisterChartCommand("/pnl", "Draw PNL(%) per trade", func() bbgo.ChartRenderer {
+		return s.profitChart(profit)
+	})
+
+	bbgo.RegisterChartCommand("/cumpnl", "Draw Cummulative PNL(Quote)", func() bbgo.ChartRenderer {
+		return charting.NewCumulativeProfitChart(s.InstanceID(), cumProfit)
+	})
+}
+
+// Draw renders the indicator chart and the pnl charts into the graph paths
+func (s *Strategy) Draw(store *bbgo.SerialMarketDataStore, profit, cumProfit types.Series) {
+	if err := s.indicatorChart(store).RenderFile(s.CanvasPath); err != nil {
+		log.WithError(err).Errorf("cannot render in drift on path %s", s.CanvasPath)
+	}
+
+	if err := s.profitChart(profit).RenderFile(s.GraphPNLPath); err != nil {
+		log.WithError(err).Errorf("render pnl on path %s", s.GraphPNLPath)
+	}
+
+	if err := charting.NewCumulativeProfitChart(s.InstanceID(), cumProfit).RenderFile(s.GraphCumPNLPath); err != nil {
+		log.WithError(err).Errorf("render cumpnl on path %s", s.GraphCumPNLPath)
+	}
+}
@@ -12,7 +12,6 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"github.com/wcharczuk/go-chart/v2"
 
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/datatype/floats"
@@ -131,8 +130,7 @@ func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
 	if maxWindow < maxWindow1m {
 		maxWindow = maxWindow1m
 	}
-	bbgo.KLinePreloadLimit = int64((maxWindow/1000 + 1) * 1000)
-	log.Errorf("set kLinePreloadLimit to %d, %d %d", bbgo.KLinePreloadLimit, s.Interval.Minutes(), maxWindow)
+	session.WarmUp(s.Symbol, types.IntervalWindow{Interval: types.Interval1m, Window: maxWindow})
 	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{
 		Interval: types.Interval1m,
 	})
@@ -403,107 +401,6 @@ func (s *Strategy) initTickerFunctions(ctx context.Context) {
 
 }
 
-func (s *Strategy) DrawIndicators(time types.Time) *types.Canvas {
-	canvas := types.NewCanvas(s.InstanceID(), s.Interval)
-	Length := s.priceLines.Length()
-	if Length > 300 {
-		Length = 300
-	}
-	log.Infof("draw indicators with %d data", Length)
-	mean := s.priceLines.Mean(Length)
-	highestPrice := s.priceLines.Minus(mean).Abs().Highest(Length)
-	highestDrift := s.drift.Abs().Highest(Length)
-	hi := s.drift.drift.Abs().Highest(Length)
-	h1m := s.drift1m.Abs().Highest(Length * s.Interval.Minutes())
-	ratio := highestPrice / highestDrift
-
-	//canvas.Plot("upband", s.ma.Add(s.stdevHigh), time, Length)
-	canvas.Plot("ma", s.ma, time, Length)
-	//canvas.Plot("downband", s.ma.Minus(s.stdevLow), time, Length)
-	canvas.Plot("pos", types.NumberSeries(s.DriftFilterPos*ratio+mean), time, Length)
-	canvas.Plot("neg", types.NumberSeries(s.DriftFilterNeg*ratio+mean), time, Length)
-	fmt.Printf("%f %f\n", highestPrice, hi)
-	canvas.Plot("ppos", types.NumberSeries(s.DDriftFilterPos*(highestPrice/hi)+mean), time, Length)
-	canvas.Plot("nneg", types.NumberSeries(s.DDriftFilterNeg*(highestPrice/hi)+mean), time, Length)
-
-	canvas.Plot("drift", s.drift.Mul(ratio).Add(mean), time, Length)
-	canvas.Plot("driftOrig", s.drift.drift.Mul(highestPrice/hi).Add(mean), time, Length)
-	canvas.Plot("drift1m", s.drift1m.Mul(highestPrice/h1m).Add(mean), time, Length*s.Interval.Minutes(), types.Interval1m)
-	canvas.Plot("zero", types.NumberSeries(mean), time, Length)
-	canvas.Plot("price", s.priceLines, time, Length)
-	return canvas
-}
-
-func (s *Strategy) DrawPNL(profit types.Series) *types.Canvas {
-	canvas := types.NewCanvas(s.InstanceID())
-	log.Errorf("pnl Highest: %f, Lowest: %f", types.Highest(profit, profit.Length()), types.Lowest(profit, profit.Length()))
-	length := profit.Length()
-	if s.GraphPNLDeductFee {
-		canvas.PlotRaw("pnl % (with Fee Deducted)", profit, length)
-	} else {
-		canvas.PlotRaw("pnl %", profit, length)
-	}
-	canvas.YAxis = chart.YAxis{
-		ValueFormatter: func(v interface{}) string {
-			if vf, isFloat := v.(float64); isFloat {
-				return fmt.Sprintf("%.4f", vf)
-			}
-			return ""
-		},
-	}
-	canvas.PlotRaw("1", types.NumberSeries(1), length)
-	return canvas
-}
-
-func (s *Strategy) DrawCumPNL(cumProfit types.Series) *types.Canvas {
-	canvas := types.NewCanvas(s.InstanceID())
-	canvas.PlotRaw("cummulative pnl", cumProfit, cumProfit.Length())
-	canvas.YAxis = chart.YAxis{
-		ValueFormatter: func(v interface{}) string {
-			if vf, isFloat := v.(float64); isFloat {
-				return fmt.Sprintf("%.4f", vf)
-			}
-			return ""
-		},
-	}
-	return canvas
-}
-
-func (s *Strategy) Draw(time types.Time, profit types.Series, cumProfit types.Series) {
-	canvas := s.DrawIndicators(time)
-	f, err := os.Create(s.CanvasPath)
-	if err != nil {
-		log.WithError(err).Errorf("cannot create on %s", s.CanvasPath)
-		return
-	}
-	defer f.Close()
-	if err := canvas.Render(chart.PNG, f); err != nil {
-		log.WithError(err).Errorf("cannot render in drift")
-	}
-
-	canvas = s.DrawPNL(profit)
-	f, err = os.Create(s.GraphPNLPath)
-	if err != nil {
-		log.WithError(err).Errorf("open pnl")
-		return
-	}
-	defer f.Close()
-	if err := canvas.Render(chart.PNG, f); err != nil {
-		log.WithError(err).Errorf("render pnl")
-	}
-
-	canvas = s.DrawCumPNL(cumProfit)
-	f, err = os.Create(s.GraphCumPNLPath)
-	if err != nil {
-		log.WithError(err).Errorf("open cumpnl")
-		return
-	}
-	defer f.Close()
-	if err := canvas.Render(chart.PNG, f); err != nil {
-		log.WithError(err).Errorf("render cumpnl")
-	}
-}
-
 // Sending new rebalance orders cost too much.
 // Modify the position instead to expect the strategy itself rebalance on Close
 func (s *Strategy) Rebalance(ctx context.Context) {
@@ -939,39 +836,6 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		s.TrailingStopLossType = "kline"
 	}
 
-	bbgo.RegisterCommand("/draw", "Draw Indicators", func(reply interact.Reply) {
-		canvas := s.DrawIndicators(s.frameKLine.StartTime)
-		var buffer bytes.Buffer
-		if err := canvas.Render(chart.PNG, &buffer); err != nil {
-			log.WithError(err).Errorf("cannot render indicators in drift")
-			reply.Message(fmt.Sprintf("[error] cannot render indicators in drift: %v", err))
-			return
-		}
-		bbgo.SendPhoto(&buffer)
-	})
-
-	bbgo.RegisterCommand("/pnl", "Draw PNL(%) per trade", func(reply interact.Reply) {
-		canvas := s.DrawPNL(&profit)
-		var buffer bytes.Buffer
-		if err := canvas.Render(chart.PNG, &buffer); err != nil {
-			log.WithError(err).Errorf("cannot render pnl in drift")
-			reply.Message(fmt.Sprintf("[error] cannot render pnl in drift: %v", err))
-			return
-		}
-		bbgo.SendPhoto(&buffer)
-	})
-
-	bbgo.RegisterCommand("/cumpnl", "Draw Cummulative PNL(Quote)", func(reply interact.Reply) {
-		canvas := s.DrawCumPNL(&cumProfit)
-		var buffer bytes.Buffer
-		if err := canvas.Render(chart.PNG, &buffer); err != nil {
-			log.WithError(err).Errorf("cannot render cumpnl in drift")
-			reply.Message(fmt.Sprintf("[error] canot render cumpnl in drift: %v", err))
-			return
-		}
-		bbgo.SendPhoto(&buffer)
-	})
-
 	bbgo.RegisterCommand("/config", "Show latest config", func(reply interact.Reply) {
 		var buffer bytes.Buffer
 		s.Print(&buffer, false)
@@ -1006,6 +870,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		log.WithError(err).Errorf("initIndicator failed")
 		return nil
 	}
+	s.InitDrawCommands(store, &profit, &cumProfit)
 	store.OnKLineClosed(func(kline types.KLine) {
 		s.minutesCounter = int(kline.StartTime.Time().Add(kline.Interval.Duration()).Sub(s.startTime).Minutes())
 		if kline.Interval == types.Interval1m {
@@ -1030,7 +895,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		os.Stdout.Write(buffer.Bytes())
 
 		if s.GenerateGraph {
-			s.Draw(s.frameKLine.StartTime, &profit, &cumProfit)
+			s.Draw(store, &profit, &cumProfit)
 		}
 		wg.Done()
 	})
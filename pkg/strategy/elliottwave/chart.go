@@ -0,0 +1,66 @@
+package elliottwave
+
+import (
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/charting"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// numOfChartKLines is the number of the klines drawn on the indicator chart
+const numOfChartKLines = 300
+
+var _ bbgo.ChartOverlayProvider = &Strategy{}
+
+// ChartOverlays draws the price source and the elliott wave oscillator on the /chart command and the backtest charts
+func (s *Strategy) ChartOverlays(symbol string, interval types.Interval) []charting.Overlay {
+	if symbol != s.Symbol || interval != s.Interval || s.ewo == nil {
+		return nil
+	}
+
+	return []charting.Overlay{
+		{Name: "price", Series: s.priceLines},
+		{Name: "ewo", Series: s.ewo, Secondary: true},
+		{Name: "zero", Series: types.NumberSeries(0), Secondary: true},
+	}
+}
+
+// indicatorChart builds the chart of the strategy interval with the klines aggregated by the serial market data store
+func (s *Strategy) indicatorChart(store *bbgo.SerialMarketDataStore) *charting.Chart {
+	var klines []types.KLine
+	if window, ok := store.KLinesOfInterval(s.Interval); ok {
+		klines = window.Tail(numOfChartKLines)
+	}
+
+	c := bbgo.NewSessionChart(s.Session, s.Symbol, s.Interval, klines, s)
+	c.Title = s.InstanceID()
+	return c
+}
+
+func (s *Strategy) InitDrawCommands(store *bbgo.SerialMarketDataStore, profit, cumProfit types.Series) {
+	bbgo.RegisterChartCommand("/draw", "Draw Indicators", func() bbgo.ChartRenderer {
+		return s.indicatorChart(store)
+	})
+
+	bbgo.RegisterChartCommand("/pnl", "Draw PNL(%) per trade", func() bbgo.ChartRenderer {
+		return charting.NewProfitChart(s.InstanceID(), "pnl %", profit)
+	})
+
+	bbgo.RegisterChartCommand("/cumpnl", "Draw Cummulative PNL(Quote)", func() bbgo.ChartRenderer {
+		return charting.NewCumulativeProfitChart(s.InstanceID(), cumProfit)
+	})
+}
+
+// Draw renders the indicator chart and the pnl charts into the graph paths
+func (s *Strategy) Draw(store *bbgo.SerialMarketDataStore, profit, cumProfit types.Series) {
+	if err := s.indicatorChart(store).RenderFile(s.GraphIndicatorPath); err != nil {
+		log.WithError(err).Errorf("cannot render elliottwave on path %s", s.GraphIndicatorPath)
+	}
+
+	if err := charting.NewProfitChart(s.InstanceID(), "pnl %", profit).RenderFile(s.GraphPNLPath); err != nil {
+		log.WithError(err).Errorf("cannot render pnl on path %s", s.GraphPNLPath)
+	}
+
+	if err := charting.NewCumulativeProfitChart(s.InstanceID(), cumProfit).RenderFile(s.GraphCumPNLPath); err != nil {
+		log.WithError(err).Errorf("cannot render cumpnl on path %s", s.GraphCumPNLPath)
+	}
+}
@@ -0,0 +1,240 @@
+package exprsignal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/expr"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "exprsignal"
+
+var log = logrus.WithField("strategy", ID)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// Strategy opens the position when the entry expression is true,
+// and closes the position when the exit expression is true.
+// The expressions are evaluated when the kline of the interval is closed, for example:
+//
+//   entry: "crossOver(ema(close, 12), ema(close, 26)) and rsi(close, 14) < 30"
+//   exit: "crossUnder(ema(close, 12), ema(close, 26))"
+type Strategy struct {
+	Environment *bbgo.Environment
+	Symbol      string `json:"symbol"`
+	Market      types.Market
+
+	// Interval is the kline interval that the expressions are evaluated on
+	Interval types.Interval `json:"interval"`
+
+	// Side is the side of the entry order, buy for opening long position, sell for opening short position
+	Side types.SideType `json:"side"`
+
+	// Entry is the condition expression for opening the position
+	Entry string `json:"entry"`
+
+	// Exit is the condition expression for closing the position, optional
+	Exit string `json:"exit"`
+
+	bbgo.OpenPositionOptions
+
+	// persistence fields
+	Position    *types.Position    `persistence:"position"`
+	ProfitStats *types.ProfitStats `persistence:"profit_stats"`
+	TradeStats  *types.TradeStats  `persistence:"trade_stats"`
+
+	ExitMethods bbgo.ExitMethodSet `json:"exits"`
+
+	StandardIndicatorSet *bbgo.StandardIndicatorSet
+	MarketDataStore      *bbgo.MarketDataStore
+
+	entry, exit *expr.Expr
+
+	session       *bbgo.ExchangeSession
+	orderExecutor *bbgo.GeneralOrderExecutor
+
+	// StrategyController
+	bbgo.StrategyController
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) InstanceID() string {
+	return fmt.Sprintf("%s:%s:%s", ID, s.Symbol, s.Interval)
+}
+
+func (s *Strategy) Defaults() error {
+	if s.Side == "" {
+		s.Side = types.SideTypeBuy
+	}
+	return nil
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return fmt.Errorf("symbol is required")
+	}
+
+	if len(s.Interval) == 0 {
+		return fmt.Errorf("interval is required")
+	}
+
+	if s.Side != types.SideTypeBuy && s.Side != types.SideTypeSell {
+		return fmt.Errorf("invalid side %s, side should be either buy or sell", s.Side)
+	}
+
+	if len(s.Entry) == 0 {
+		return fmt.Errorf("entry expression is required")
+	}
+
+	for _, source := range []string{s.Entry, s.Exit} {
+		if len(source) == 0 {
+			continue
+		}
+
+		if _, err := expr.Parse(source); err != nil {
+			return fmt.Errorf("invalid expression %q: %w", source, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.Interval})
+	s.ExitMethods.SetAndSubscribe(session, s)
+}
+
+func (s *Strategy) CurrentPosition() *types.Position {
+	return s.Position
+}
+
+func (s *Strategy) ClosePosition(ctx context.Context, percentage fixedpoint.Value) error {
+	return s.orderExecutor.ClosePosition(ctx, percentage)
+}
+
+func (s *Strategy) compileExpressions() (err error) {
+	env := expr.Env{
+		Interval:     s.Interval,
+		IndicatorSet: s.StandardIndicatorSet,
+		Store:        s.MarketDataStore,
+	}
+
+	if s.entry, err = expr.Compile(s.Entry, env); err != nil {
+		return err
+	}
+
+	if len(s.Exit) > 0 {
+		if s.exit, err = expr.Compile(s.Exit, env); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	var instanceID = s.InstanceID()
+
+	if s.Position == nil {
+		s.Position = types.NewPositionFromMarket(s.Market)
+	}
+
+	if s.ProfitStats == nil {
+		s.ProfitStats = types.NewProfitStats(s.Market)
+	}
+
+	if s.TradeStats == nil {
+		s.TradeStats = types.NewTradeStats(s.Symbol)
+	}
+
+	if err := s.compileExpressions(); err != nil {
+		return err
+	}
+
+	// StrategyController
+	s.Status = types.StrategyStatusRunning
+
+	s.OnSuspend(func() {
+		// Cancel active orders
+		_ = s.orderExecutor.GracefulCancel(ctx)
+	})
+
+	s.OnEmergencyStop(func() {
+		// Cancel active orders
+		_ = s.orderExecutor.GracefulCancel(ctx)
+		// Close 100% position
+		_ = s.ClosePosition(ctx, fixedpoint.One)
+	})
+
+	s.session = session
+	s.orderExecutor = bbgo.NewGeneralOrderExecutor(session, s.Symbol, ID, instanceID, s.Position)
+	s.orderExecutor.BindEnvironment(s.Environment)
+	s.orderExecutor.BindProfitStats(s.ProfitStats)
+	s.orderExecutor.BindTradeStats(s.TradeStats)
+	s.orderExecutor.TradeCollector().OnPositionUpdate(func(position *types.Position) {
+		bbgo.Sync(s)
+	})
+	s.orderExecutor.Bind()
+
+	s.ExitMethods.Bind(session, s.orderExecutor)
+
+	session.MarketDataStream.OnKLineClosed(types.KLineWith(s.Symbol, s.Interval, func(kline types.KLine) {
+		if s.Status != types.StrategyStatusRunning {
+			return
+		}
+
+		s.handleKLineClosed(ctx, kline)
+	}))
+
+	bbgo.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		_, _ = fmt.Fprintln(os.Stderr, s.TradeStats.String())
+		_ = s.orderExecutor.GracefulCancel(ctx)
+	})
+
+	return nil
+}
+
+func (s *Strategy) handleKLineClosed(ctx context.Context, kline types.KLine) {
+	closePrice := kline.Close
+	if s.Position.IsOpened(closePrice) {
+		if s.exit != nil && s.exit.Last() {
+			bbgo.Notify("%s exit condition %s is satisfied at price %f, closing position", s.Symbol, s.exit, closePrice.Float64())
+			if err := s.orderExecutor.ClosePosition(ctx, fixedpoint.One, "exprExit"); err != nil {
+				log.WithError(err).Errorf("failed to close position")
+			}
+		}
+		return
+	}
+
+	if !s.entry.Last() {
+		return
+	}
+
+	bbgo.Notify("%s entry condition %s is satisfied at price %f, opening %s position", s.Symbol, s.entry, closePrice.Float64(), s.Side)
+
+	// graceful cancel all active orders
+	_ = s.orderExecutor.GracefulCancel(ctx)
+
+	opts := s.OpenPositionOptions
+	opts.Long = s.Side == types.SideTypeBuy
+	opts.Short = s.Side == types.SideTypeSell
+	opts.Price = closePrice
+	opts.Tags = []string{"exprEntry"}
+	if err := s.orderExecutor.OpenPosition(ctx, opts); err != nil {
+		log.WithError(err).Errorf("failed to open position")
+	}
+}
@@ -0,0 +1,404 @@
+package externalsignal
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "externalsignal"
+
+const defaultMaxSignalAge = 30 * time.Second
+
+var log = logrus.WithField("strategy", ID)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// Strategy executes the signals produced outside of bbgo, for example by a python research process.
+// The signals are submitted through the gRPC SignalService or the HTTP API (POST /api/signals),
+// and there are two types of signals:
+//
+//	targetPosition: move the strategy position to the target base quantity
+//	orderIntent: submit an order of the given side and quantity
+//
+// Every signal is verified with the auth token, the signal age and the size limits before
+// it's executed through the general order executor, so the circuit breaker and the risk engine still apply.
+type Strategy struct {
+	Environment *bbgo.Environment
+	Symbol      string `json:"symbol"`
+	Market      types.Market
+
+	// AuthToken is the token that the signal producer has to present,
+	// it's read from the environment variable EXTERNAL_SIGNAL_AUTH_TOKEN if it's not set.
+	AuthToken string `json:"authToken"`
+
+	// MaxSignalAge is the max age of the accepted signal, signals older than this are rejected as stale.
+	MaxSignalAge types.Duration `json:"maxSignalAge"`
+
+	// MaxQuantity is the max base quantity of the order created by a single signal
+	MaxQuantity fixedpoint.Value `json:"maxQuantity"`
+
+	// MaxPosition is the max absolute base position that the signals can open, optional
+	MaxPosition fixedpoint.Value `json:"maxPosition"`
+
+	// persistence fields
+	Position    *types.Position    `persistence:"position"`
+	ProfitStats *types.ProfitStats `persistence:"profit_stats"`
+	TradeStats  *types.TradeStats  `persistence:"trade_stats"`
+
+	session       *bbgo.ExchangeSession
+	orderExecutor *bbgo.GeneralOrderExecutor
+
+	// mu serializes the signal executions
+	mu sync.Mutex
+
+	// receivedSignals stores the signal ids and the signal times for rejecting the duplicated signals
+	receivedSignals map[string]time.Time
+
+	// StrategyController
+	bbgo.StrategyController
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) InstanceID() string {
+	return fmt.Sprintf("%s:%s", ID, s.Symbol)
+}
+
+func (s *Strategy) Defaults() error {
+	if len(s.AuthToken) == 0 {
+		s.AuthToken = os.Getenv("EXTERNAL_SIGNAL_AUTH_TOKEN")
+	}
+
+	if s.MaxSignalAge == 0 {
+		s.MaxSignalAge = types.Duration(defaultMaxSignalAge)
+	}
+	return nil
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return fmt.Errorf("symbol is required")
+	}
+
+	if len(s.AuthToken) == 0 {
+		return fmt.Errorf("authToken is required, or set the environment variable EXTERNAL_SIGNAL_AUTH_TOKEN")
+	}
+
+	if s.MaxQuantity.Sign() <= 0 {
+		return fmt.Errorf("maxQuantity should be greater than 0")
+	}
+
+	if s.MaxPosition.Sign() < 0 {
+		return fmt.Errorf("maxPosition can not be negative")
+	}
+
+	if s.MaxSignalAge < 0 {
+		return fmt.Errorf("maxSignalAge can not be negative")
+	}
+
+	return nil
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	// subscribe the kline for updating the last price, which is used for the min notional check
+	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: types.Interval1m})
+}
+
+func (s *Strategy) CurrentPosition() *types.Position {
+	return s.Position
+}
+
+func (s *Strategy) ClosePosition(ctx context.Context, percentage fixedpoint.Value) error {
+	return s.orderExecutor.ClosePosition(ctx, percentage)
+}
+
+// ReceiveExternalSignal implements bbgo.ExternalSignalReceiver
+func (s *Strategy) ReceiveExternalSignal(ctx context.Context, signal bbgo.ExternalSignal, authToken string) (types.OrderSlice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.orderExecutor == nil {
+		return nil, fmt.Errorf("strategy %s is not started", s.InstanceID())
+	}
+
+	if s.Status != types.StrategyStatusRunning {
+		return nil, fmt.Errorf("strategy %s is not running", s.InstanceID())
+	}
+
+	// accept the lower case order type, e.g. "limit"
+	signal.OrderType = types.OrderType(strings.ToUpper(string(signal.OrderType)))
+
+	now := time.Now()
+	if err := s.validateSignal(signal, authToken, now); err != nil {
+		log.WithError(err).Warnf("rejected %s", signal)
+		return nil, err
+	}
+
+	if err := s.checkDuplicated(signal, now); err != nil {
+		log.WithError(err).Warnf("rejected %s", signal)
+		return nil, err
+	}
+
+	if signal.Type == bbgo.ExternalSignalTypeTargetPosition {
+		// the remaining orders of the previous target are replaced by the new target,
+		// the trades of the canceled orders are processed so that the position is up-to-date before reconciling
+		if err := s.orderExecutor.GracefulCancel(ctx); err != nil {
+			return nil, err
+		}
+
+		s.orderExecutor.TradeCollector().Process()
+	}
+
+	price := signal.Price
+	if price.IsZero() {
+		price, _ = s.session.LastPrice(s.Symbol)
+	}
+
+	submitOrder, err := s.reconcile(signal, price)
+	if err != nil {
+		log.WithError(err).Warnf("rejected %s", signal)
+		return nil, err
+	}
+
+	if submitOrder == nil {
+		log.Infof("%s: position %s is already reconciled", signal, s.Position.GetBase().String())
+		s.markReceived(signal)
+		return nil, nil
+	}
+
+	bbgo.Notify("%s: submitting %s %s %s order, quantity %s", signal, s.Symbol, submitOrder.Type, submitOrder.Side, submitOrder.Quantity.String())
+
+	createdOrders, err := s.orderExecutor.SubmitOrders(ctx, *submitOrder)
+	if err != nil {
+		// the signal is not marked so that the producer can retry it
+		return nil, err
+	}
+
+	s.markReceived(signal)
+	return createdOrders, nil
+}
+
+// validateSignal verifies the auth token, the staleness and the content of the signal
+func (s *Strategy) validateSignal(signal bbgo.ExternalSignal, authToken string, now time.Time) error {
+	if subtle.ConstantTimeCompare([]byte(authToken), []byte(s.AuthToken)) != 1 {
+		return bbgo.ErrInvalidSignalAuthToken
+	}
+
+	if len(signal.ID) == 0 {
+		return fmt.Errorf("signal id is required")
+	}
+
+	if signal.Symbol != s.Symbol {
+		return fmt.Errorf("signal symbol %s does not match the strategy symbol %s", signal.Symbol, s.Symbol)
+	}
+
+	signalTime := signal.Time.Time()
+	if signalTime.IsZero() {
+		return fmt.Errorf("signal time is required")
+	}
+
+	maxAge := s.MaxSignalAge.Duration()
+	if maxAge > 0 {
+		if age := now.Sub(signalTime); age > maxAge {
+			return fmt.Errorf("signal is stale, created %s ago, max signal age is %s", age, maxAge)
+		} else if age < -maxAge {
+			return fmt.Errorf("signal time %s is in the future", signalTime)
+		}
+	}
+
+	switch signal.Type {
+	case bbgo.ExternalSignalTypeTargetPosition:
+		if s.MaxPosition.Sign() > 0 && signal.TargetPosition.Abs().Compare(s.MaxPosition) > 0 {
+			return fmt.Errorf("target position %s exceeds the max position %s", signal.TargetPosition.String(), s.MaxPosition.String())
+		}
+
+	case bbgo.ExternalSignalTypeOrderIntent:
+		if signal.Side != types.SideTypeBuy && signal.Side != types.SideTypeSell {
+			return fmt.Errorf("invalid side %q, side should be either buy or sell", signal.Side)
+		}
+
+		if signal.Quantity.Sign() <= 0 {
+			return fmt.Errorf("quantity should be greater than 0")
+		}
+
+	default:
+		return fmt.Errorf("unsupported signal type %q", signal.Type)
+	}
+
+	switch signal.OrderType {
+	case "", types.OrderTypeMarket:
+	case types.OrderTypeLimit:
+		if signal.Price.Sign() <= 0 {
+			return fmt.Errorf("price is required for the limit order")
+		}
+
+	default:
+		return fmt.Errorf("unsupported order type %q, order type should be either MARKET or LIMIT", signal.OrderType)
+	}
+
+	return nil
+}
+
+// checkDuplicated rejects the signal that is already executed.
+// Ids of the signals older than the max signal age are dropped since those signals are rejected as stale anyway.
+func (s *Strategy) checkDuplicated(signal bbgo.ExternalSignal, now time.Time) error {
+	if maxAge := s.MaxSignalAge.Duration(); maxAge > 0 {
+		for id, signalTime := range s.receivedSignals {
+			if now.Sub(signalTime) > maxAge {
+				delete(s.receivedSignals, id)
+			}
+		}
+	}
+
+	if _, ok := s.receivedSignals[signal.ID]; ok {
+		return fmt.Errorf("duplicated signal %s", signal.ID)
+	}
+
+	return nil
+}
+
+// markReceived records the signal id once the signal is executed, the same signal can only be executed once.
+func (s *Strategy) markReceived(signal bbgo.ExternalSignal) {
+	if s.receivedSignals == nil {
+		s.receivedSignals = make(map[string]time.Time)
+	}
+
+	s.receivedSignals[signal.ID] = signal.Time.Time()
+}
+
+// reconcile converts the signal to the order against the current position,
+// it returns nil if the position is already at the target.
+func (s *Strategy) reconcile(signal bbgo.ExternalSignal, price fixedpoint.Value) (*types.SubmitOrder, error) {
+	base := s.Position.GetBase()
+
+	var side types.SideType
+	var quantity fixedpoint.Value
+	switch signal.Type {
+	case bbgo.ExternalSignalTypeTargetPosition:
+		delta := signal.TargetPosition.Sub(base)
+		if delta.Sign() > 0 {
+			side = types.SideTypeBuy
+		} else {
+			side = types.SideTypeSell
+		}
+		quantity = delta.Abs()
+
+	case bbgo.ExternalSignalTypeOrderIntent:
+		side = signal.Side
+		quantity = signal.Quantity
+
+		newBase := base.Add(quantity)
+		if side == types.SideTypeSell {
+			newBase = base.Sub(quantity)
+		}
+
+		if s.MaxPosition.Sign() > 0 && newBase.Abs().Compare(s.MaxPosition) > 0 {
+			return nil, fmt.Errorf("resulting position %s exceeds the max position %s", newBase.String(), s.MaxPosition.String())
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported signal type %q", signal.Type)
+	}
+
+	quantity = s.Market.TruncateQuantity(quantity)
+	if quantity.Compare(s.MaxQuantity) > 0 {
+		return nil, fmt.Errorf("order quantity %s exceeds the max quantity %s", quantity.String(), s.MaxQuantity.String())
+	}
+
+	if quantity.IsZero() || quantity.Compare(s.Market.MinQuantity) < 0 ||
+		(price.Sign() > 0 && quantity.Mul(price).Compare(s.Market.MinNotional) < 0) {
+		if signal.Type == bbgo.ExternalSignalTypeTargetPosition {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("order quantity %s is less than the market min quantity or min notional", quantity.String())
+	}
+
+	orderType := signal.OrderType
+	if len(orderType) == 0 {
+		orderType = types.OrderTypeMarket
+	}
+
+	submitOrder := &types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Market:   s.Market,
+		Side:     side,
+		Type:     orderType,
+		Quantity: quantity,
+		Tag:      "externalSignal",
+	}
+
+	if orderType == types.OrderTypeLimit {
+		submitOrder.Price = s.Market.TruncatePrice(signal.Price)
+		submitOrder.TimeInForce = types.TimeInForceGTC
+	}
+
+	return submitOrder, nil
+}
+
+func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	var instanceID = s.InstanceID()
+
+	if s.Position == nil {
+		s.Position = types.NewPositionFromMarket(s.Market)
+	}
+
+	if s.ProfitStats == nil {
+		s.ProfitStats = types.NewProfitStats(s.Market)
+	}
+
+	if s.TradeStats == nil {
+		s.TradeStats = types.NewTradeStats(s.Symbol)
+	}
+
+	// StrategyController
+	s.Status = types.StrategyStatusRunning
+
+	s.OnSuspend(func() {
+		// Cancel active orders
+		_ = s.orderExecutor.GracefulCancel(ctx)
+	})
+
+	s.OnEmergencyStop(func() {
+		// Cancel active orders
+		_ = s.orderExecutor.GracefulCancel(ctx)
+		// Close 100% position
+		_ = s.ClosePosition(ctx, fixedpoint.One)
+	})
+
+	s.mu.Lock()
+	s.session = session
+	s.orderExecutor = bbgo.NewGeneralOrderExecutor(session, s.Symbol, ID, instanceID, s.Position)
+	s.orderExecutor.BindEnvironment(s.Environment)
+	s.orderExecutor.BindProfitStats(s.ProfitStats)
+	s.orderExecutor.BindTradeStats(s.TradeStats)
+	s.orderExecutor.TradeCollector().OnPositionUpdate(func(position *types.Position) {
+		bbgo.Sync(s)
+	})
+	s.orderExecutor.Bind()
+	s.mu.Unlock()
+
+	bbgo.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		_, _ = fmt.Fprintln(os.Stderr, s.TradeStats.String())
+		_ = s.orderExecutor.GracefulCancel(ctx)
+	})
+
+	return nil
+}
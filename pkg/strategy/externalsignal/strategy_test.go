@@ -0,0 +1,209 @@
+package externalsignal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newTestStrategy(base string) *Strategy {
+	market := types.Market{
+		Symbol:          "BTCUSDT",
+		BaseCurrency:    "BTC",
+		QuoteCurrency:   "USDT",
+		PricePrecision:  2,
+		VolumePrecision: 4,
+		StepSize:        fixedpoint.MustNewFromString("0.0001"),
+		TickSize:        fixedpoint.MustNewFromString("0.01"),
+		MinQuantity:     fixedpoint.MustNewFromString("0.0001"),
+		MinNotional:     fixedpoint.NewFromInt(10),
+	}
+
+	position := types.NewPositionFromMarket(market)
+	position.Base = fixedpoint.MustNewFromString(base)
+
+	s := &Strategy{
+		Symbol:      "BTCUSDT",
+		Market:      market,
+		AuthToken:   "secret",
+		MaxQuantity: fixedpoint.NewFromInt(1),
+		MaxPosition: fixedpoint.NewFromInt(2),
+		Position:    position,
+	}
+	_ = s.Defaults()
+	return s
+}
+
+func TestStrategy_validateSignal(t *testing.T) {
+	now := time.Now()
+	s := newTestStrategy("0")
+
+	validSignal := bbgo.ExternalSignal{
+		ID:             "1",
+		Symbol:         "BTCUSDT",
+		Type:           bbgo.ExternalSignalTypeTargetPosition,
+		TargetPosition: fixedpoint.MustNewFromString("0.5"),
+		Time:           types.MillisecondTimestamp(now.Add(-time.Second)),
+	}
+
+	tests := []struct {
+		name      string
+		authToken string
+		modify    func(signal *bbgo.ExternalSignal)
+		expErr    string
+	}{
+		{"valid", "secret", func(signal *bbgo.ExternalSignal) {}, ""},
+		{"invalid token", "guess", func(signal *bbgo.ExternalSignal) {}, "invalid signal auth token"},
+		{"empty token", "", func(signal *bbgo.ExternalSignal) {}, "invalid signal auth token"},
+		{"missing id", "secret", func(signal *bbgo.ExternalSignal) { signal.ID = "" }, "signal id is required"},
+		{"symbol mismatch", "secret", func(signal *bbgo.ExternalSignal) { signal.Symbol = "ETHUSDT" }, "does not match"},
+		{"missing time", "secret", func(signal *bbgo.ExternalSignal) { signal.Time = types.MillisecondTimestamp{} }, "signal time is required"},
+		{"stale", "secret", func(signal *bbgo.ExternalSignal) {
+			signal.Time = types.MillisecondTimestamp(now.Add(-time.Minute))
+		}, "signal is stale"},
+		{"future", "secret", func(signal *bbgo.ExternalSignal) {
+			signal.Time = types.MillisecondTimestamp(now.Add(time.Minute))
+		}, "in the future"},
+		{"target exceeds max position", "secret", func(signal *bbgo.ExternalSignal) {
+			signal.TargetPosition = fixedpoint.NewFromInt(-3)
+		}, "exceeds the max position"},
+		{"invalid side", "secret", func(signal *bbgo.ExternalSignal) {
+			signal.Type = bbgo.ExternalSignalTypeOrderIntent
+			signal.Quantity = fixedpoint.One
+		}, "invalid side"},
+		{"zero quantity", "secret", func(signal *bbgo.ExternalSignal) {
+			signal.Type = bbgo.ExternalSignalTypeOrderIntent
+			signal.Side = types.SideTypeBuy
+		}, "quantity should be greater than 0"},
+		{"limit order without price", "secret", func(signal *bbgo.ExternalSignal) {
+			signal.OrderType = types.OrderTypeLimit
+		}, "price is required"},
+		{"unsupported order type", "secret", func(signal *bbgo.ExternalSignal) {
+			signal.OrderType = types.OrderTypeStopLimit
+		}, "unsupported order type"},
+		{"unsupported signal type", "secret", func(signal *bbgo.ExternalSignal) {
+			signal.Type = "close"
+		}, "unsupported signal type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signal := validSignal
+			tt.modify(&signal)
+
+			err := s.validateSignal(signal, tt.authToken, now)
+			if tt.expErr == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.expErr)
+			}
+		})
+	}
+}
+
+func TestStrategy_checkDuplicated(t *testing.T) {
+	now := time.Now()
+	s := newTestStrategy("0")
+
+	signal := bbgo.ExternalSignal{ID: "1", Time: types.MillisecondTimestamp(now)}
+	assert.NoError(t, s.checkDuplicated(signal, now), "the signal is not executed yet")
+	assert.NoError(t, s.checkDuplicated(signal, now), "the rejected signal can be retried")
+
+	s.markReceived(signal)
+	assert.Error(t, s.checkDuplicated(signal, now), "duplicated signal")
+
+	// the expired ids are dropped
+	later := now.Add(time.Minute)
+	assert.NoError(t, s.checkDuplicated(bbgo.ExternalSignal{ID: "2", Time: types.MillisecondTimestamp(later)}, later))
+	assert.Empty(t, s.receivedSignals)
+}
+
+func TestStrategy_reconcile(t *testing.T) {
+	price := fixedpoint.NewFromInt(20000)
+
+	t.Run("target position increases the long position", func(t *testing.T) {
+		s := newTestStrategy("0.2")
+		submitOrder, err := s.reconcile(bbgo.ExternalSignal{
+			Type:           bbgo.ExternalSignalTypeTargetPosition,
+			TargetPosition: fixedpoint.MustNewFromString("0.5"),
+		}, price)
+		if assert.NoError(t, err) && assert.NotNil(t, submitOrder) {
+			assert.Equal(t, types.SideTypeBuy, submitOrder.Side)
+			assert.Equal(t, types.OrderTypeMarket, submitOrder.Type)
+			assert.Equal(t, "0.3", submitOrder.Quantity.String())
+		}
+	})
+
+	t.Run("target position flips to short", func(t *testing.T) {
+		s := newTestStrategy("0.2")
+		submitOrder, err := s.reconcile(bbgo.ExternalSignal{
+			Type:           bbgo.ExternalSignalTypeTargetPosition,
+			TargetPosition: fixedpoint.MustNewFromString("-0.3"),
+			OrderType:      types.OrderTypeLimit,
+			Price:          fixedpoint.MustNewFromString("20000.123"),
+		}, price)
+		if assert.NoError(t, err) && assert.NotNil(t, submitOrder) {
+			assert.Equal(t, types.SideTypeSell, submitOrder.Side)
+			assert.Equal(t, types.OrderTypeLimit, submitOrder.Type)
+			assert.Equal(t, "0.5", submitOrder.Quantity.String())
+			assert.Equal(t, "20000.12", submitOrder.Price.String())
+		}
+	})
+
+	t.Run("target position is already reconciled", func(t *testing.T) {
+		s := newTestStrategy("0.2")
+		submitOrder, err := s.reconcile(bbgo.ExternalSignal{
+			Type:           bbgo.ExternalSignalTypeTargetPosition,
+			TargetPosition: fixedpoint.MustNewFromString("0.20001"),
+		}, price)
+		assert.NoError(t, err)
+		assert.Nil(t, submitOrder)
+	})
+
+	t.Run("target position exceeds max quantity", func(t *testing.T) {
+		s := newTestStrategy("-0.5")
+		_, err := s.reconcile(bbgo.ExternalSignal{
+			Type:           bbgo.ExternalSignalTypeTargetPosition,
+			TargetPosition: fixedpoint.One,
+		}, price)
+		assert.Error(t, err)
+	})
+
+	t.Run("order intent exceeds max position", func(t *testing.T) {
+		s := newTestStrategy("1.5")
+		_, err := s.reconcile(bbgo.ExternalSignal{
+			Type:     bbgo.ExternalSignalTypeOrderIntent,
+			Side:     types.SideTypeBuy,
+			Quantity: fixedpoint.One,
+		}, price)
+		assert.Error(t, err)
+	})
+
+	t.Run("order intent reduces the position", func(t *testing.T) {
+		s := newTestStrategy("1.5")
+		submitOrder, err := s.reconcile(bbgo.ExternalSignal{
+			Type:     bbgo.ExternalSignalTypeOrderIntent,
+			Side:     types.SideTypeSell,
+			Quantity: fixedpoint.One,
+		}, price)
+		if assert.NoError(t, err) && assert.NotNil(t, submitOrder) {
+			assert.Equal(t, types.SideTypeSell, submitOrder.Side)
+			assert.Equal(t, "1", submitOrder.Quantity.String())
+		}
+	})
+
+	t.Run("order intent below min notional", func(t *testing.T) {
+		s := newTestStrategy("0")
+		_, err := s.reconcile(bbgo.ExternalSignal{
+			Type:     bbgo.ExternalSignalTypeOrderIntent,
+			Side:     types.SideTypeBuy,
+			Quantity: fixedpoint.MustNewFromString("0.0001"),
+		}, price)
+		assert.Error(t, err)
+	})
+}
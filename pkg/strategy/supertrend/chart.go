@@ -0,0 +1,53 @@
+package supertrend
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/charting"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var _ bbgo.ChartOverlayProvider = &Strategy{}
+
+// ChartOverlays draws the supertrend line and the double DEMA on the /chart command and the backtest charts
+func (s *Strategy) ChartOverlays(symbol string, interval types.Interval) (overlays []charting.Overlay) {
+	if symbol != s.Symbol || interval != s.Interval {
+		return nil
+	}
+
+	if s.Supertrend != nil {
+		overlays = append(overlays, charting.Overlay{Name: "supertrend", Series: s.Supertrend})
+	}
+
+	if s.doubleDema != nil {
+		overlays = append(overlays,
+			charting.Overlay{Name: fmt.Sprintf("dema %d", s.doubleDema.FastDEMAWindow), Series: s.doubleDema.fastDEMA},
+			charting.Overlay{Name: fmt.Sprintf("dema %d", s.doubleDema.SlowDEMAWindow), Series: s.doubleDema.slowDEMA})
+	}
+
+	return overlays
+}
+
+func (s *Strategy) InitDrawCommands(profit, cumProfit types.Series) {
+	bbgo.RegisterChartCommand("/pnl", "Draw PNL(%) per trade", func() bbgo.ChartRenderer {
+		return charting.NewProfitChart(s.InstanceID(), "pnl %", profit)
+	})
+
+	bbgo.RegisterChartCommand("/cumpnl", "Draw Cummulative PNL(Quote)", func() bbgo.ChartRenderer {
+		return charting.NewCumulativeProfitChart(s.InstanceID(), cumProfit)
+	})
+}
+
+// Draw renders the pnl charts into the graph paths
+func (s *Strategy) Draw(profit, cumProfit types.Series) error {
+	if err := charting.NewProfitChart(s.InstanceID(), "pnl %", profit).RenderFile(s.GraphPNLPath); err != nil {
+		return fmt.Errorf("cannot render pnl on path %s: %w", s.GraphPNLPath, err)
+	}
+
+	if err := charting.NewCumulativeProfitChart(s.InstanceID(), cumProfit).RenderFile(s.GraphCumPNLPath); err != nil {
+		return fmt.Errorf("cannot render cumpnl on path %s: %w", s.GraphCumPNLPath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess = AuditOutcome("success")
+
+	AuditOutcomeError = AuditOutcome("error")
+
+	// the user's role is not allowed to execute the command
+	AuditOutcomeDenied = AuditOutcome("denied")
+
+	// the user declined the confirmation of the command
+	AuditOutcomeCancelled = AuditOutcome("cancelled")
+)
+
+// AuditLog is the record of an interactive command execution
+type AuditLog struct {
+	GID       int64        `json:"gid" db:"gid"`
+	Actor     string       `json:"actor" db:"actor"`
+	Role      string       `json:"role" db:"role"`
+	Command   string       `json:"command" db:"command"`
+	Arguments string       `json:"arguments" db:"arguments"`
+	Outcome   AuditOutcome `json:"outcome" db:"outcome"`
+	Error     string       `json:"error" db:"error"`
+	Time      Time         `json:"time" db:"time"`
+}
+
+func (l AuditLog) EffectiveTime() time.Time {
+	return l.Time.Time()
+}
+
+func (l AuditLog) String() (o string) {
+	o = fmt.Sprintf("%s %s (%s) %s", l.Time.Time().Format(time.RFC3339), l.Actor, l.Role, l.Command)
+	if len(l.Arguments) > 0 {
+		o += " " + l.Arguments
+	}
+
+	o += ": " + string(l.Outcome)
+	if len(l.Error) > 0 {
+		o += " (" + l.Error + ")"
+	}
+
+	return o
+}
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// BarChannel subscribes the bars built from the market trades, the bar type and the bar size are
+// given by SubscribeOptions.BarType and SubscribeOptions.BarSize.
+var BarChannel = Channel("bar")
+
+// BarType is the type of the bars that are not sampled by time
+type BarType string
+
+const (
+	// BarTypeTick closes a bar every N trades
+	BarTypeTick BarType = "tick"
+
+	// BarTypeVolume closes a bar when the traded base volume reaches N
+	BarTypeVolume BarType = "volume"
+
+	// BarTypeDollar closes a bar when the traded quote volume reaches N
+	BarTypeDollar BarType = "dollar"
+
+	// BarTypeRenko closes a brick when the price moves N from the close price of the last brick
+	BarTypeRenko BarType = "renko"
+)
+
+var SupportedBarTypes = []BarType{BarTypeTick, BarTypeVolume, BarTypeDollar, BarTypeRenko}
+
+func (t BarType) IsSupported() bool {
+	for _, s := range SupportedBarTypes {
+		if s == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Interval returns the pseudo interval of the bars, e.g., "volume:100".
+// The bars are emitted as klines with this interval, so that the kline filters and the indicators
+// could be bound to the bars like the time-based klines.
+// Note that the pseudo interval is not a time duration, Interval.Minutes() and Interval.Duration() return 0 for it.
+func (t BarType) Interval(size fixedpoint.Value) Interval {
+	return Interval(string(t) + ":" + size.String())
+}
+
+// IsBarInterval returns true if the interval is a pseudo interval of the bars
+func IsBarInterval(interval Interval) bool {
+	return strings.ContainsRune(string(interval), ':')
+}
+
+// ParseBarInterval parses the pseudo interval of the bars, e.g., "volume:100"
+func ParseBarInterval(interval Interval) (barType BarType, size fixedpoint.Value, ok bool) {
+	parts := strings.SplitN(string(interval), ":", 2)
+	if len(parts) != 2 {
+		return "", fixedpoint.Zero, false
+	}
+
+	barType = BarType(parts[0])
+	if !barType.IsSupported() {
+		return "", fixedpoint.Zero, false
+	}
+
+	size, err := fixedpoint.NewFromString(parts[1])
+	if err != nil || size.Sign() <= 0 {
+		return "", fixedpoint.Zero, false
+	}
+
+	return barType, size, true
+}
@@ -0,0 +1,140 @@
+package types
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// The strategy client order ID is encoded as
+//
+//   B{strategy code:4}{instance code:5}{sequence:12}
+//
+// all the codes are lowercase base36, the strategy code and the instance code are the hashes of the strategy ID and
+// the strategy instance ID. The 22 characters fit in the client order ID length limits of the supported exchanges
+// after the broker prefix is prepended (32 characters on Binance, MAX and OKEx), and the ID is alphanumeric for OKEx.
+const (
+	clientOrderIDMarker = "B"
+
+	strategyCodeLength = 4
+	instanceCodeLength = 5
+	sequenceLength     = 12
+
+	ClientOrderIDLength = len(clientOrderIDMarker) + strategyCodeLength + instanceCodeLength + sequenceLength
+)
+
+var clientOrderIDStrategies = struct {
+	sync.Mutex
+	m map[string]string
+}{m: make(map[string]string)}
+
+// RegisterClientOrderIDStrategy registers the strategy ID for decoding the strategy code of the client order IDs
+func RegisterClientOrderIDStrategy(strategyID string) {
+	clientOrderIDStrategies.Lock()
+	clientOrderIDStrategies.m[StrategyCode(strategyID)] = strategyID
+	clientOrderIDStrategies.Unlock()
+}
+
+// StrategyCode returns the client order ID code of the strategy ID
+func StrategyCode(strategyID string) string {
+	return hashCode(strategyID, strategyCodeLength)
+}
+
+// InstanceCode returns the client order ID code of the strategy instance ID
+func InstanceCode(instanceID string) string {
+	return hashCode(instanceID, instanceCodeLength)
+}
+
+// ClientOrderIDInfo is the decoded strategy client order ID
+type ClientOrderIDInfo struct {
+	StrategyCode string
+	InstanceCode string
+	Sequence     uint64
+
+	// StrategyID is empty if the strategy is not registered
+	StrategyID string
+}
+
+// ParseClientOrderID decodes the strategy client order ID, the broker prefix of the exchange is ignored.
+func ParseClientOrderID(clientOrderID string) (info ClientOrderIDInfo, ok bool) {
+	if len(clientOrderID) < ClientOrderIDLength {
+		return info, false
+	}
+
+	code := clientOrderID[len(clientOrderID)-ClientOrderIDLength:]
+	if !strings.HasPrefix(code, clientOrderIDMarker) {
+		return info, false
+	}
+
+	code = code[len(clientOrderIDMarker):]
+	for _, c := range code {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z') {
+			return info, false
+		}
+	}
+
+	seq, err := strconv.ParseUint(code[strategyCodeLength+instanceCodeLength:], 36, 64)
+	if err != nil {
+		return info, false
+	}
+
+	info.StrategyCode = code[:strategyCodeLength]
+	info.InstanceCode = code[strategyCodeLength : strategyCodeLength+instanceCodeLength]
+	info.Sequence = seq
+
+	clientOrderIDStrategies.Lock()
+	info.StrategyID = clientOrderIDStrategies.m[info.StrategyCode]
+	clientOrderIDStrategies.Unlock()
+	return info, true
+}
+
+// ClientOrderIDGenerator generates the client order IDs of a strategy instance
+type ClientOrderIDGenerator struct {
+	strategyCode string
+	instanceCode string
+	sequence     uint64
+}
+
+// NewClientOrderIDGenerator creates the generator, the sequence starts from the seed,
+// use the current time in milliseconds to keep the IDs unique after restart.
+func NewClientOrderIDGenerator(strategyID, instanceID string, seed uint64) *ClientOrderIDGenerator {
+	return &ClientOrderIDGenerator{
+		strategyCode: StrategyCode(strategyID),
+		instanceCode: InstanceCode(instanceID),
+		sequence:     seed,
+	}
+}
+
+func (g *ClientOrderIDGenerator) Next() string {
+	seq := atomic.AddUint64(&g.sequence, 1)
+	return clientOrderIDMarker + g.strategyCode + g.instanceCode + padCode(strconv.FormatUint(seq, 36), sequenceLength)
+}
+
+// Owns returns true if the client order ID is generated by the same strategy instance
+func (g *ClientOrderIDGenerator) Owns(clientOrderID string) bool {
+	info, ok := ParseClientOrderID(clientOrderID)
+	return ok && info.StrategyCode == g.strategyCode && info.InstanceCode == g.instanceCode
+}
+
+func hashCode(s string, length int) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+
+	// 36^length fits in uint64 for the code lengths
+	mod := uint64(1)
+	for i := 0; i < length; i++ {
+		mod *= 36
+	}
+
+	return padCode(strconv.FormatUint(h.Sum64()%mod, 36), length)
+}
+
+func padCode(code string, length int) string {
+	if len(code) >= length {
+		return code[len(code)-length:]
+	}
+
+	return strings.Repeat("0", length-len(code)) + code
+}
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientOrderIDGenerator(t *testing.T) {
+	RegisterClientOrderIDStrategy("pivotshort")
+
+	g := NewClientOrderIDGenerator("pivotshort", "pivotshort:BTCUSDT:1h", 1654041600000)
+
+	id := g.Next()
+	assert.Len(t, id, ClientOrderIDLength)
+	assert.Regexp(t, "^[0-9a-zA-Z]+$", id)
+	assert.NotEqual(t, id, g.Next())
+
+	// with the broker prefix
+	info, ok := ParseClientOrderID("x-NSUYEBKM" + id)
+	assert.True(t, ok)
+	assert.Equal(t, "pivotshort", info.StrategyID)
+	assert.Equal(t, StrategyCode("pivotshort"), info.StrategyCode)
+	assert.Equal(t, InstanceCode("pivotshort:BTCUSDT:1h"), info.InstanceCode)
+	assert.Equal(t, uint64(1654041600001), info.Sequence)
+
+	assert.True(t, g.Owns("x-bbgo-"+id))
+	assert.False(t, NewClientOrderIDGenerator("pivotshort", "pivotshort:ETHUSDT:1h", 0).Owns(id))
+
+	// the unregistered strategy
+	info, ok = ParseClientOrderID(NewClientOrderIDGenerator("unknown", "unknown", 0).Next())
+	assert.True(t, ok)
+	assert.Empty(t, info.StrategyID)
+
+	// the truncated uuid client order ID
+	_, ok = ParseClientOrderID("x-NSUYEBKM8a4c1f2e-3b5d-4e6f-a7")
+	assert.False(t, ok)
+
+	_, ok = ParseClientOrderID("short")
+	assert.False(t, ok)
+}
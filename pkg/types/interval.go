@@ -9,9 +9,15 @@ import (
 
 type Interval string
 
+// Minutes returns the minutes of the interval.
+// The pseudo intervals of the bars, e.g., "volume:100", are not time-based, 0 is returned for them.
 func (i Interval) Minutes() int {
 	m, ok := SupportedIntervals[i]
 	if !ok {
+		if IsBarInterval(i) {
+			return 0
+		}
+
 		return ParseInterval(i)
 	}
 	return m
@@ -21,6 +27,30 @@ func (i Interval) Duration() time.Duration {
 	return time.Duration(i.Minutes()) * time.Minute
 }
 
+// Truncate returns the start time of the interval that contains the given time.
+// Weekly intervals are aligned to Monday 00:00 UTC, monthly intervals are aligned to the first day of the month,
+// and the other intervals are aligned to the unix epoch.
+func (i Interval) Truncate(t time.Time) time.Time {
+	t = t.UTC()
+	if i == Interval1mo {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	d := i.Duration()
+	if d == 0 {
+		// the bars are not aligned to the time
+		return t
+	}
+
+	if d%(7*24*time.Hour) == 0 {
+		// the zero time (0001-01-01) is on Monday
+		return t.Truncate(d)
+	}
+
+	seconds := int64(d / time.Second)
+	return time.Unix(t.Unix()-t.Unix()%seconds, 0).UTC()
+}
+
 func (i *Interval) UnmarshalJSON(b []byte) (err error) {
 	var a string
 	err = json.Unmarshal(b, &a)
@@ -2,8 +2,11 @@ package types
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
 )
 
 func TestParseInterval(t *testing.T) {
@@ -12,3 +15,34 @@ func TestParseInterval(t *testing.T) {
 	assert.Equal(t, ParseInterval("72d"), 72*24*60)
 	assert.Equal(t, ParseInterval("3Mo"), 3*30*24*60)
 }
+
+func TestInterval_Truncate(t *testing.T) {
+	tt := time.Date(2022, time.September, 14, 7, 31, 20, 0, time.UTC) // Wednesday
+	assert.Equal(t, time.Date(2022, time.September, 14, 7, 30, 0, 0, time.UTC), Interval15m.Truncate(tt))
+	assert.Equal(t, time.Date(2022, time.September, 14, 6, 0, 0, 0, time.UTC), Interval2h.Truncate(tt))
+	assert.Equal(t, time.Date(2022, time.September, 14, 0, 0, 0, 0, time.UTC), Interval1d.Truncate(tt))
+	assert.Equal(t, time.Date(2022, time.September, 13, 0, 0, 0, 0, time.UTC), Interval3d.Truncate(tt))
+	assert.Equal(t, time.Date(2022, time.September, 12, 0, 0, 0, 0, time.UTC), Interval1w.Truncate(tt))
+	assert.Equal(t, time.Date(2022, time.September, 1, 0, 0, 0, 0, time.UTC), Interval1mo.Truncate(tt))
+}
+
+func TestInterval_BarInterval(t *testing.T) {
+	interval := BarTypeVolume.Interval(fixedpoint.NewFromInt(100))
+	assert.True(t, IsBarInterval(interval))
+	assert.Equal(t, 0, interval.Minutes())
+	assert.Equal(t, time.Duration(0), interval.Duration())
+
+	tt := time.Date(2022, time.September, 14, 7, 31, 20, 0, time.UTC)
+	assert.Equal(t, tt, interval.Truncate(tt))
+
+	barType, size, ok := ParseBarInterval(interval)
+	if assert.True(t, ok) {
+		assert.Equal(t, BarTypeVolume, barType)
+		assert.Equal(t, "100", size.String())
+	}
+
+	_, _, ok = ParseBarInterval("volume:abc")
+	assert.False(t, ok)
+	_, _, ok = ParseBarInterval("foo:100")
+	assert.False(t, ok)
+}
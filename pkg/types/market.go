@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"strconv"
 	"time"
 
 	"github.com/leekchan/accounting"
@@ -49,6 +50,28 @@ func (d *Duration) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// UnmarshalYAML parses the duration string, e.g., "30s", or the number of seconds
+func (d *Duration) UnmarshalYAML(unmarshal func(a interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+
+	if dd, err := time.ParseDuration(s); err == nil {
+		*d = Duration(dd)
+		return nil
+	}
+
+	// the number without the unit is in seconds
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(int64(seconds * float64(time.Second)))
+	return nil
+}
+
 type Market struct {
 	Symbol string `json:"symbol"`
 
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 )
@@ -78,6 +79,39 @@ func TestDurationParse(t *testing.T) {
 	}
 }
 
+func TestDuration_UnmarshalYAML(t *testing.T) {
+	type A struct {
+		Duration Duration `yaml:"duration"`
+	}
+
+	tests := []struct {
+		input    string
+		expected Duration
+		err      bool
+	}{
+		{input: "duration: 30s", expected: Duration(30 * time.Second)},
+		{input: "duration: 1m30s", expected: Duration(90 * time.Second)},
+		{input: `duration: "2h"`, expected: Duration(2 * time.Hour)},
+		{input: "duration: 10", expected: Duration(10 * time.Second)},
+		{input: "duration: 1.5", expected: Duration(1500 * time.Millisecond)},
+		{input: "duration: abc", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			var a A
+			err := yaml.Unmarshal([]byte(test.input), &a)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, a.Duration)
+		})
+	}
+}
+
 func Test_formatPrice(t *testing.T) {
 	type args struct {
 		price    fixedpoint.Value
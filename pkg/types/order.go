@@ -1,6 +1,7 @@
 package types
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -254,6 +255,9 @@ type Order struct {
 	IsFutures  bool `json:"isFutures" db:"is_futures"`
 	IsMargin   bool `json:"isMargin" db:"is_margin"`
 	IsIsolated bool `json:"isIsolated" db:"is_isolated"`
+
+	// StrategyID is the strategy decoded from the client order ID
+	StrategyID sql.NullString `json:"strategyID" db:"strategy"`
 }
 
 func (o Order) CsvHeader() []string {
@@ -11,6 +11,8 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
 )
 
 const pingInterval = 30 * time.Second
@@ -440,9 +442,17 @@ type SubscribeOptions struct {
 	Interval Interval `json:"interval,omitempty"`
 	Depth    Depth    `json:"depth,omitempty"`
 	Speed    Speed    `json:"speed,omitempty"`
+
+	// BarType and BarSize are used by the bar channel
+	BarType BarType          `json:"barType,omitempty"`
+	BarSize fixedpoint.Value `json:"barSize,omitempty"`
 }
 
 func (o SubscribeOptions) String() string {
+	if len(o.BarType) > 0 {
+		return string(o.BarType.Interval(o.BarSize))
+	}
+
 	if len(o.Interval) > 0 {
 		return string(o.Interval)
 	}
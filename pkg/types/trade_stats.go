@@ -32,7 +32,8 @@ func (s *IntervalProfitCollector) Update(profit *Profit) {
 		panic("No valid start time. Did you create IntervalProfitCollector instance using NewIntervalProfitCollector?")
 	} else {
 		duration := s.Interval.Duration()
-		if profit.TradedAt.Before(s.tmpTime.Add(duration)) {
+		// the bar intervals have no duration, all the profits are collected in the same interval
+		if duration <= 0 || profit.TradedAt.Before(s.tmpTime.Add(duration)) {
 			(*s.Profits)[len(*s.Profits)-1] *= 1. + profit.NetProfitMargin.Float64()
 		} else {
 			for {
@@ -2,6 +2,7 @@ from . import enums
 from . import handlers
 from . import utils
 from .services import MarketService
+from .services import SignalService
 from .services import TradingService
 from .services import UserDataService
 from .stream import Stream
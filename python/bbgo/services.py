@@ -1,5 +1,8 @@
 from __future__ import annotations
 
+import time
+import uuid
+from decimal import Decimal
 from typing import Iterator
 from typing import List
 
@@ -177,3 +180,57 @@ class TradingService(object):
                                               offset=offset)
         response = self.stub.QueryTrades(request)
         return response
+
+
+class SignalService(object):
+    stub: bbgo_pb2_grpc.SignalServiceStub
+
+    def __init__(self, host: str, port: int, auth_token: str) -> None:
+        self.stub = bbgo_pb2_grpc.SignalServiceStub(get_insecure_channel(host, port))
+        self.metadata = [('authorization', f'Bearer {auth_token}')]
+
+    def submit_target_position(self,
+                               symbol: str,
+                               target_position: Decimal,
+                               strategy: str = None,
+                               order_type: str = 'market',
+                               price: Decimal = None,
+                               signal_id: str = None) -> List[Order]:
+        signal = bbgo_pb2.Signal(id=signal_id or str(uuid.uuid4()),
+                                 strategy=strategy or "",
+                                 symbol=symbol,
+                                 type=bbgo_pb2.TARGET_POSITION,
+                                 target_position=str(target_position),
+                                 order_type=OrderType.from_str(order_type).value,
+                                 price=str(price or ""),
+                                 created_at=int(time.time() * 1000))
+        return self.submit_signal(signal)
+
+    def submit_order_intent(self,
+                            symbol: str,
+                            side: str,
+                            quantity: Decimal,
+                            strategy: str = None,
+                            order_type: str = 'market',
+                            price: Decimal = None,
+                            signal_id: str = None) -> List[Order]:
+        signal = bbgo_pb2.Signal(id=signal_id or str(uuid.uuid4()),
+                                 strategy=strategy or "",
+                                 symbol=symbol,
+                                 type=bbgo_pb2.ORDER_INTENT,
+                                 side=bbgo_pb2.SignalSide.Value('SIGNAL_SIDE_' + SideType.from_str(side).name),
+                                 quantity=str(quantity),
+                                 order_type=OrderType.from_str(order_type).value,
+                                 price=str(price or ""),
+                                 created_at=int(time.time() * 1000))
+        return self.submit_signal(signal)
+
+    def submit_signal(self, signal: bbgo_pb2.Signal) -> List[Order]:
+        request = bbgo_pb2.SubmitSignalRequest(signal=signal)
+        response = self.stub.SubmitSignal(request, metadata=self.metadata)
+
+        error = ErrorMessage.from_pb(response.error)
+        if error.code != 0:
+            logger.error(error.message)
+
+        return [Order.from_pb(order) for order in response.orders]
@@ -15,7 +15,7 @@ _sym_db = _symbol_database.Default()
 
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\nbbgo.proto\x12\x04\x62\x62go\"\x07\n\x05\x45mpty\"2\n\x05\x45rror\x12\x12\n\nerror_code\x18\x01 \x01(\x03\x12\x15\n\rerror_message\x18\x02 \x01(\t\"\"\n\x0fUserDataRequest\x12\x0f\n\x07session\x18\x01 \x01(\t\"\xc4\x01\n\x08UserData\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x10\n\x08\x65xchange\x18\x02 \x01(\t\x12\x1e\n\x07\x63hannel\x18\x03 \x01(\x0e\x32\r.bbgo.Channel\x12\x1a\n\x05\x65vent\x18\x04 \x01(\x0e\x32\x0b.bbgo.Event\x12\x1f\n\x08\x62\x61lances\x18\x05 \x03(\x0b\x32\r.bbgo.Balance\x12\x1b\n\x06trades\x18\x06 \x03(\x0b\x32\x0b.bbgo.Trade\x12\x1b\n\x06orders\x18\x07 \x03(\x0b\x32\x0b.bbgo.Order\"=\n\x10SubscribeRequest\x12)\n\rsubscriptions\x18\x01 \x03(\x0b\x32\x12.bbgo.Subscription\"q\n\x0cSubscription\x12\x10\n\x08\x65xchange\x18\x01 \x01(\t\x12\x1e\n\x07\x63hannel\x18\x02 \x01(\x0e\x32\r.bbgo.Channel\x12\x0e\n\x06symbol\x18\x03 \x01(\t\x12\r\n\x05\x64\x65pth\x18\x04 \x01(\t\x12\x10\n\x08interval\x18\x05 \x01(\t\"\xa1\x02\n\nMarketData\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x10\n\x08\x65xchange\x18\x02 \x01(\t\x12\x0e\n\x06symbol\x18\x03 \x01(\t\x12\x1e\n\x07\x63hannel\x18\x04 \x01(\x0e\x32\r.bbgo.Channel\x12\x1a\n\x05\x65vent\x18\x05 \x01(\x0e\x32\x0b.bbgo.Event\x12\x1a\n\x05\x64\x65pth\x18\x06 \x01(\x0b\x32\x0b.bbgo.Depth\x12\x1a\n\x05kline\x18\x07 \x01(\x0b\x32\x0b.bbgo.KLine\x12\x1c\n\x06ticker\x18\t \x01(\x0b\x32\x0c.bbgo.Ticker\x12\x1b\n\x06trades\x18\x08 \x03(\x0b\x32\x0b.bbgo.Trade\x12\x15\n\rsubscribed_at\x18\x0c \x01(\x03\x12\x1a\n\x05\x65rror\x18\r \x01(\x0b\x32\x0b.bbgo.Error\"k\n\x05\x44\x65pth\x12\x10\n\x08\x65xchange\x18\x01 \x01(\t\x12\x0e\n\x06symbol\x18\x02 \x01(\t\x12\x1f\n\x04\x61sks\x18\x03 \x03(\x0b\x32\x11.bbgo.PriceVolume\x12\x1f\n\x04\x62ids\x18\x04 \x03(\x0b\x32\x11.bbgo.PriceVolume\",\n\x0bPriceVolume\x12\r\n\x05price\x18\x01 \x01(\t\x12\x0e\n\x06volume\x18\x02 \x01(\t\"\xc7\x01\n\x05Trade\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x10\n\x08\x65xchange\x18\x02 \x01(\t\x12\x0e\n\x06symbol\x18\x03 \x01(\t\x12\n\n\x02id\x18\x04 \x01(\t\x12\r\n\x05price\x18\x05 \x01(\t\x12\x10\n\x08quantity\x18\x06 \x01(\t\x12\x12\n\ncreated_at\x18\x07 \x01(\x03\x12\x18\n\x04side\x18\x08 \x01(\x0e\x32\n.bbgo.Side\x12\x14\n\x0c\x66\x65\x65_currency\x18\t \x01(\t\x12\x0b\n\x03\x66\x65\x65\x18\n \x01(\t\x12\r\n\x05maker\x18\x0b \x01(\x08\"r\n\x06Ticker\x12\x10\n\x08\x65xchange\x18\x01 \x01(\t\x12\x0e\n\x06symbol\x18\x02 \x01(\t\x12\x0c\n\x04open\x18\x03 \x01(\x01\x12\x0c\n\x04high\x18\x04 \x01(\x01\x12\x0b\n\x03low\x18\x05 \x01(\x01\x12\r\n\x05\x63lose\x18\x06 \x01(\x01\x12\x0e\n\x06volume\x18\x07 \x01(\x01\"\x93\x02\n\x05Order\x12\x10\n\x08\x65xchange\x18\x01 \x01(\t\x12\x0e\n\x06symbol\x18\x02 \x01(\t\x12\n\n\x02id\x18\x03 \x01(\t\x12\x18\n\x04side\x18\x04 \x01(\x0e\x32\n.bbgo.Side\x12#\n\norder_type\x18\x05 \x01(\x0e\x32\x0f.bbgo.OrderType\x12\r\n\x05price\x18\x06 \x01(\t\x12\x12\n\nstop_price\x18\x07 \x01(\t\x12\x0e\n\x06status\x18\t \x01(\t\x12\x10\n\x08quantity\x18\x0b \x01(\t\x12\x19\n\x11\x65xecuted_quantity\x18\x0c \x01(\t\x12\x17\n\x0f\x63lient_order_id\x18\x0e \x01(\t\x12\x10\n\x08group_id\x18\x0f \x01(\x03\x12\x12\n\ncreated_at\x18\n \x01(\x03\"\xdf\x01\n\x0bSubmitOrder\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x10\n\x08\x65xchange\x18\x02 \x01(\t\x12\x0e\n\x06symbol\x18\x03 \x01(\t\x12\x18\n\x04side\x18\x04 \x01(\x0e\x32\n.bbgo.Side\x12\r\n\x05price\x18\x06 \x01(\t\x12\x10\n\x08quantity\x18\x05 \x01(\t\x12\x12\n\nstop_price\x18\x07 \x01(\t\x12#\n\norder_type\x18\x08 \x01(\x0e\x32\x0f.bbgo.OrderType\x12\x17\n\x0f\x63lient_order_id\x18\t \x01(\t\x12\x10\n\x08group_id\x18\n \x01(\x03\"s\n\x07\x42\x61lance\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x10\n\x08\x65xchange\x18\x02 \x01(\t\x12\x10\n\x08\x63urrency\x18\x03 \x01(\t\x12\x11\n\tavailable\x18\x04 \x01(\t\x12\x0e\n\x06locked\x18\x05 \x01(\t\x12\x10\n\x08\x62orrowed\x18\x06 \x01(\t\"O\n\x12SubmitOrderRequest\x12\x0f\n\x07session\x18\x01 \x01(\t\x12(\n\rsubmit_orders\x18\x02 \x03(\x0b\x32\x11.bbgo.SubmitOrder\"_\n\x13SubmitOrderResponse\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x1b\n\x06orders\x18\x02 \x03(\x0b\x32\x0b.bbgo.Order\x12\x1a\n\x05\x65rror\x18\x03 \x01(\x0b\x32\x0b.bbgo.Error\"P\n\x12\x43\x61ncelOrderRequest\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x10\n\x08order_id\x18\x02 \x01(\t\x12\x17\n\x0f\x63lient_order_id\x18\x03 \x01(\t\"M\n\x13\x43\x61ncelOrderResponse\x12\x1a\n\x05order\x18\x01 \x01(\x0b\x32\x0b.bbgo.Order\x12\x1a\n\x05\x65rror\x18\x02 \x01(\x0b\x32\x0b.bbgo.Error\"I\n\x11QueryOrderRequest\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\n\n\x02id\x18\x02 \x01(\t\x12\x17\n\x0f\x63lient_order_id\x18\x03 \x01(\t\"L\n\x12QueryOrderResponse\x12\x1a\n\x05order\x18\x01 \x01(\x0b\x32\x0b.bbgo.Order\x12\x1a\n\x05\x65rror\x18\x02 \x01(\x0b\x32\x0b.bbgo.Error\"\xa9\x01\n\x12QueryOrdersRequest\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x0e\n\x06symbol\x18\x02 \x01(\t\x12\r\n\x05state\x18\x03 \x03(\t\x12\x10\n\x08order_by\x18\x04 \x01(\t\x12\x10\n\x08group_id\x18\x05 \x01(\x03\x12\x12\n\npagination\x18\x06 \x01(\x08\x12\x0c\n\x04page\x18\x07 \x01(\x03\x12\r\n\x05limit\x18\x08 \x01(\x03\x12\x0e\n\x06offset\x18\t \x01(\x03\"N\n\x13QueryOrdersResponse\x12\x1b\n\x06orders\x18\x01 \x03(\x0b\x32\x0b.bbgo.Order\x12\x1a\n\x05\x65rror\x18\x02 \x01(\x0b\x32\x0b.bbgo.Error\"\xb6\x01\n\x12QueryTradesRequest\x12\x10\n\x08\x65xchange\x18\x01 \x01(\t\x12\x0e\n\x06symbol\x18\x02 \x01(\t\x12\x11\n\ttimestamp\x18\x03 \x01(\x03\x12\x0c\n\x04\x66rom\x18\x04 \x01(\x03\x12\n\n\x02to\x18\x05 \x01(\x03\x12\x10\n\x08order_by\x18\x06 \x01(\t\x12\x12\n\npagination\x18\x07 \x01(\x08\x12\x0c\n\x04page\x18\x08 \x01(\x03\x12\r\n\x05limit\x18\t \x01(\x03\x12\x0e\n\x06offset\x18\n \x01(\x03\"N\n\x13QueryTradesResponse\x12\x1b\n\x06trades\x18\x01 \x03(\x0b\x32\x0b.bbgo.Trade\x12\x1a\n\x05\x65rror\x18\x02 \x01(\x0b\x32\x0b.bbgo.Error\"}\n\x12QueryKLinesRequest\x12\x10\n\x08\x65xchange\x18\x01 \x01(\t\x12\x0e\n\x06symbol\x18\x02 \x01(\t\x12\x10\n\x08interval\x18\x03 \x01(\t\x12\x12\n\nstart_time\x18\x04 \x01(\x03\x12\x10\n\x08\x65nd_time\x18\x05 \x01(\x03\x12\r\n\x05limit\x18\x06 \x01(\x03\"N\n\x13QueryKLinesResponse\x12\x1b\n\x06klines\x18\x01 \x03(\x0b\x32\x0b.bbgo.KLine\x12\x1a\n\x05\x65rror\x18\x02 \x01(\x0b\x32\x0b.bbgo.Error\"\xce\x01\n\x05KLine\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x10\n\x08\x65xchange\x18\x02 \x01(\t\x12\x0e\n\x06symbol\x18\x03 \x01(\t\x12\x0c\n\x04open\x18\x04 \x01(\t\x12\x0c\n\x04high\x18\x05 \x01(\t\x12\x0b\n\x03low\x18\x06 \x01(\t\x12\r\n\x05\x63lose\x18\x07 \x01(\t\x12\x0e\n\x06volume\x18\x08 \x01(\t\x12\x14\n\x0cquote_volume\x18\t \x01(\t\x12\x12\n\nstart_time\x18\n \x01(\x03\x12\x10\n\x08\x65nd_time\x18\x0b \x01(\x03\x12\x0e\n\x06\x63losed\x18\x0c \x01(\x08*n\n\x05\x45vent\x12\x0b\n\x07UNKNOWN\x10\x00\x12\x0e\n\nSUBSCRIBED\x10\x01\x12\x10\n\x0cUNSUBSCRIBED\x10\x02\x12\x0c\n\x08SNAPSHOT\x10\x03\x12\n\n\x06UPDATE\x10\x04\x12\x11\n\rAUTHENTICATED\x10\x05\x12\t\n\x05\x45RROR\x10\x63*M\n\x07\x43hannel\x12\x08\n\x04\x42OOK\x10\x00\x12\t\n\x05TRADE\x10\x01\x12\n\n\x06TICKER\x10\x02\x12\t\n\x05KLINE\x10\x03\x12\x0b\n\x07\x42\x41LANCE\x10\x04\x12\t\n\x05ORDER\x10\x05*\x19\n\x04Side\x12\x07\n\x03\x42UY\x10\x00\x12\x08\n\x04SELL\x10\x01*a\n\tOrderType\x12\n\n\x06MARKET\x10\x00\x12\t\n\x05LIMIT\x10\x01\x12\x0f\n\x0bSTOP_MARKET\x10\x02\x12\x0e\n\nSTOP_LIMIT\x10\x03\x12\r\n\tPOST_ONLY\x10\x04\x12\r\n\tIOC_LIMIT\x10\x05\x32\x94\x01\n\x11MarketDataService\x12\x39\n\tSubscribe\x12\x16.bbgo.SubscribeRequest\x1a\x10.bbgo.MarketData\"\x00\x30\x01\x12\x44\n\x0bQueryKLines\x12\x18.bbgo.QueryKLinesRequest\x1a\x19.bbgo.QueryKLinesResponse\"\x00\x32I\n\x0fUserDataService\x12\x36\n\tSubscribe\x12\x15.bbgo.UserDataRequest\x1a\x0e.bbgo.UserData\"\x00\x30\x01\x32\xeb\x02\n\x0eTradingService\x12\x44\n\x0bSubmitOrder\x12\x18.bbgo.SubmitOrderRequest\x1a\x19.bbgo.SubmitOrderResponse\"\x00\x12\x44\n\x0b\x43\x61ncelOrder\x12\x18.bbgo.CancelOrderRequest\x1a\x19.bbgo.CancelOrderResponse\"\x00\x12\x41\n\nQueryOrder\x12\x17.bbgo.QueryOrderRequest\x1a\x18.bbgo.QueryOrderResponse\"\x00\x12\x44\n\x0bQueryOrders\x12\x18.bbgo.QueryOrdersRequest\x1a\x19.bbgo.QueryOrdersResponse\"\x00\x12\x44\n\x0bQueryTrades\x12\x18.bbgo.QueryTradesRequest\x1a\x19.bbgo.QueryTradesResponse\"\x00\x42\x07Z\x05../pbb\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\nbbgo.proto\x12\x04\x62\x62go\"\x07\n\x05\x45mpty\"2\n\x05\x45rror\x12\x12\n\nerror_code\x18\x01 \x01(\x03\x12\x15\n\rerror_message\x18\x02 \x01(\t\"\"\n\x0fUserDataRequest\x12\x0f\n\x07session\x18\x01 \x01(\t\"\xc4\x01\n\x08UserData\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x10\n\x08\x65xchange\x18\x02 \x01(\t\x12\x1e\n\x07\x63hannel\x18\x03 \x01(\x0e\x32\r.bbgo.Channel\x12\x1a\n\x05\x65vent\x18\x04 \x01(\x0e\x32\x0b.bbgo.Event\x12\x1f\n\x08\x62\x61lances\x18\x05 \x03(\x0b\x32\r.bbgo.Balance\x12\x1b\n\x06trades\x18\x06 \x03(\x0b\x32\x0b.bbgo.Trade\x12\x1b\n\x06orders\x18\x07 \x03(\x0b\x32\x0b.bbgo.Order\"=\n\x10SubscribeRequest\x12)\n\rsubscriptions\x18\x01 \x03(\x0b\x32\x12.bbgo.Subscription\"q\n\x0cSubscription\x12\x10\n\x08\x65xchange\x18\x01 \x01(\t\x12\x1e\n\x07\x63hannel\x18\x02 \x01(\x0e\x32\r.bbgo.Channel\x12\x0e\n\x06symbol\x18\x03 \x01(\t\x12\r\n\x05\x64\x65pth\x18\x04 \x01(\t\x12\x10\n\x08interval\x18\x05 \x01(\t\"\xa1\x02\n\nMarketData\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x10\n\x08\x65xchange\x18\x02 \x01(\t\x12\x0e\n\x06symbol\x18\x03 \x01(\t\x12\x1e\n\x07\x63hannel\x18\x04 \x01(\x0e\x32\r.bbgo.Channel\x12\x1a\n\x05\x65vent\x18\x05 \x01(\x0e\x32\x0b.bbgo.Event\x12\x1a\n\x05\x64\x65pth\x18\x06 \x01(\x0b\x32\x0b.bbgo.Depth\x12\x1a\n\x05kline\x18\x07 \x01(\x0b\x32\x0b.bbgo.KLine\x12\x1c\n\x06ticker\x18\t \x01(\x0b\x32\x0c.bbgo.Ticker\x12\x1b\n\x06trades\x18\x08 \x03(\x0b\x32\x0b.bbgo.Trade\x12\x15\n\rsubscribed_at\x18\x0c \x01(\x03\x12\x1a\n\x05\x65rror\x18\r \x01(\x0b\x32\x0b.bbgo.Error\"k\n\x05\x44\x65pth\x12\x10\n\x08\x65xchange\x18\x01 \x01(\t\x12\x0e\n\x06symbol\x18\x02 \x01(\t\x12\x1f\n\x04\x61sks\x18\x03 \x03(\x0b\x32\x11.bbgo.PriceVolume\x12\x1f\n\x04\x62ids\x18\x04 \x03(\x0b\x32\x11.bbgo.PriceVolume\",\n\x0bPriceVolume\x12\r\n\x05price\x18\x01 \x01(\t\x12\x0e\n\x06volume\x18\x02 \x01(\t\"\xc7\x01\n\x05Trade\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x10\n\x08\x65xchange\x18\x02 \x01(\t\x12\x0e\n\x06symbol\x18\x03 \x01(\t\x12\n\n\x02id\x18\x04 \x01(\t\x12\r\n\x05price\x18\x05 \x01(\t\x12\x10\n\x08quantity\x18\x06 \x01(\t\x12\x12\n\ncreated_at\x18\x07 \x01(\x03\x12\x18\n\x04side\x18\x08 \x01(\x0e\x32\n.bbgo.Side\x12\x14\n\x0c\x66\x65\x65_currency\x18\t \x01(\t\x12\x0b\n\x03\x66\x65\x65\x18\n \x01(\t\x12\r\n\x05maker\x18\x0b \x01(\x08\"r\n\x06Ticker\x12\x10\n\x08\x65xchange\x18\x01 \x01(\t\x12\x0e\n\x06symbol\x18\x02 \x01(\t\x12\x0c\n\x04open\x18\x03 \x01(\x01\x12\x0c\n\x04high\x18\x04 \x01(\x01\x12\x0b\n\x03low\x18\x05 \x01(\x01\x12\r\n\x05\x63lose\x18\x06 \x01(\x01\x12\x0e\n\x06volume\x18\x07 \x01(\x01\"\x93\x02\n\x05Order\x12\x10\n\x08\x65xchange\x18\x01 \x01(\t\x12\x0e\n\x06symbol\x18\x02 \x01(\t\x12\n\n\x02id\x18\x03 \x01(\t\x12\x18\n\x04side\x18\x04 \x01(\x0e\x32\n.bbgo.Side\x12#\n\norder_type\x18\x05 \x01(\x0e\x32\x0f.bbgo.OrderType\x12\r\n\x05price\x18\x06 \x01(\t\x12\x12\n\nstop_price\x18\x07 \x01(\t\x12\x0e\n\x06status\x18\t \x01(\t\x12\x10\n\x08quantity\x18\x0b \x01(\t\x12\x19\n\x11\x65xecuted_quantity\x18\x0c \x01(\t\x12\x17\n\x0f\x63lient_order_id\x18\x0e \x01(\t\x12\x10\n\x08group_id\x18\x0f \x01(\x03\x12\x12\n\ncreated_at\x18\n \x01(\x03\"\xdf\x01\n\x0bSubmitOrder\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x10\n\x08\x65xchange\x18\x02 \x01(\t\x12\x0e\n\x06symbol\x18\x03 \x01(\t\x12\x18\n\x04side\x18\x04 \x01(\x0e\x32\n.bbgo.Side\x12\r\n\x05price\x18\x06 \x01(\t\x12\x10\n\x08quantity\x18\x05 \x01(\t\x12\x12\n\nstop_price\x18\x07 \x01(\t\x12#\n\norder_type\x18\x08 \x01(\x0e\x32\x0f.bbgo.OrderType\x12\x17\n\x0f\x63lient_order_id\x18\t \x01(\t\x12\x10\n\x08group_id\x18\n \x01(\x03\"s\n\x07\x42\x61lance\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x10\n\x08\x65xchange\x18\x02 \x01(\t\x12\x10\n\x08\x63urrency\x18\x03 \x01(\t\x12\x11\n\tavailable\x18\x04 \x01(\t\x12\x0e\n\x06locked\x18\x05 \x01(\t\x12\x10\n\x08\x62orrowed\x18\x06 \x01(\t\"O\n\x12SubmitOrderRequest\x12\x0f\n\x07session\x18\x01 \x01(\t\x12(\n\rsubmit_orders\x18\x02 \x03(\x0b\x32\x11.bbgo.SubmitOrder\"_\n\x13SubmitOrderResponse\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x1b\n\x06orders\x18\x02 \x03(\x0b\x32\x0b.bbgo.Order\x12\x1a\n\x05\x65rror\x18\x03 \x01(\x0b\x32\x0b.bbgo.Error\"P\n\x12\x43\x61ncelOrderRequest\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x10\n\x08order_id\x18\x02 \x01(\t\x12\x17\n\x0f\x63lient_order_id\x18\x03 \x01(\t\"M\n\x13\x43\x61ncelOrderResponse\x12\x1a\n\x05order\x18\x01 \x01(\x0b\x32\x0b.bbgo.Order\x12\x1a\n\x05\x65rror\x18\x02 \x01(\x0b\x32\x0b.bbgo.Error\"Y\n\x11QueryOrderRequest\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\n\n\x02id\x18\x02 \x01(\t\x12\x17\n\x0f\x63lient_order_id\x18\x03 \x01(\t\x12\x0e\n\x06symbol\x18\x04 \x01(\t\"L\n\x12QueryOrderResponse\x12\x1a\n\x05order\x18\x01 \x01(\x0b\x32\x0b.bbgo.Order\x12\x1a\n\x05\x65rror\x18\x02 \x01(\x0b\x32\x0b.bbgo.Error\"\xc3\x01\n\x12QueryOrdersRequest\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x0e\n\x06symbol\x18\x02 \x01(\t\x12\r\n\x05state\x18\x03 \x03(\t\x12\x10\n\x08order_by\x18\x04 \x01(\t\x12\x10\n\x08group_id\x18\x05 \x01(\x03\x12\x12\n\npagination\x18\x06 \x01(\x08\x12\x0c\n\x04page\x18\x07 \x01(\x03\x12\r\n\x05limit\x18\x08 \x01(\x03\x12\x0e\n\x06offset\x18\t \x01(\x03\x12\x0c\n\x04\x66rom\x18\n \x01(\x03\x12\n\n\x02to\x18\x0b \x01(\x03\"N\n\x13QueryOrdersResponse\x12\x1b\n\x06orders\x18\x01 \x03(\x0b\x32\x0b.bbgo.Order\x12\x1a\n\x05\x65rror\x18\x02 \x01(\x0b\x32\x0b.bbgo.Error\"\xb6\x01\n\x12QueryTradesRequest\x12\x10\n\x08\x65xchange\x18\x01 \x01(\t\x12\x0e\n\x06symbol\x18\x02 \x01(\t\x12\x11\n\ttimestamp\x18\x03 \x01(\x03\x12\x0c\n\x04\x66rom\x18\x04 \x01(\x03\x12\n\n\x02to\x18\x05 \x01(\x03\x12\x10\n\x08order_by\x18\x06 \x01(\t\x12\x12\n\npagination\x18\x07 \x01(\x08\x12\x0c\n\x04page\x18\x08 \x01(\x03\x12\r\n\x05limit\x18\t \x01(\x03\x12\x0e\n\x06offset\x18\n \x01(\x03\"N\n\x13QueryTradesResponse\x12\x1b\n\x06trades\x18\x01 \x03(\x0b\x32\x0b.bbgo.Trade\x12\x1a\n\x05\x65rror\x18\x02 \x01(\x0b\x32\x0b.bbgo.Error\"}\n\x12QueryKLinesRequest\x12\x10\n\x08\x65xchange\x18\x01 \x01(\t\x12\x0e\n\x06symbol\x18\x02 \x01(\t\x12\x10\n\x08interval\x18\x03 \x01(\t\x12\x12\n\nstart_time\x18\x04 \x01(\x03\x12\x10\n\x08\x65nd_time\x18\x05 \x01(\x03\x12\r\n\x05limit\x18\x06 \x01(\x03\"N\n\x13QueryKLinesResponse\x12\x1b\n\x06klines\x18\x01 \x03(\x0b\x32\x0b.bbgo.KLine\x12\x1a\n\x05\x65rror\x18\x02 \x01(\x0b\x32\x0b.bbgo.Error\"\xce\x01\n\x05KLine\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x10\n\x08\x65xchange\x18\x02 \x01(\t\x12\x0e\n\x06symbol\x18\x03 \x01(\t\x12\x0c\n\x04open\x18\x04 \x01(\t\x12\x0c\n\x04high\x18\x05 \x01(\t\x12\x0b\n\x03low\x18\x06 \x01(\t\x12\r\n\x05\x63lose\x18\x07 \x01(\t\x12\x0e\n\x06volume\x18\x08 \x01(\t\x12\x14\n\x0cquote_volume\x18\t \x01(\t\x12\x12\n\nstart_time\x18\n \x01(\x03\x12\x10\n\x08\x65nd_time\x18\x0b \x01(\x03\x12\x0e\n\x06\x63losed\x18\x0c \x01(\x08\"\'\n\x14QueryBalancesRequest\x12\x0f\n\x07session\x18\x01 \x01(\t\"T\n\x15QueryBalancesResponse\x12\x1f\n\x08\x62\x61lances\x18\x01 \x03(\x0b\x32\r.bbgo.Balance\x12\x1a\n\x05\x65rror\x18\x02 \x01(\x0b\x32\x0b.bbgo.Error\"\xff\x01\n\x08Position\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x10\n\x08\x65xchange\x18\x02 \x01(\t\x12\x0e\n\x06symbol\x18\x03 \x01(\t\x12\x15\n\rbase_currency\x18\x04 \x01(\t\x12\x16\n\x0equote_currency\x18\x05 \x01(\t\x12\x0c\n\x04\x62\x61se\x18\x06 \x01(\t\x12\r\n\x05quote\x18\x07 \x01(\t\x12\x14\n\x0c\x61verage_cost\x18\x08 \x01(\t\x12\x1a\n\x12\x61\x63\x63umulated_profit\x18\t \x01(\t\x12\x10\n\x08strategy\x18\n \x01(\t\x12\x1c\n\x14strategy_instance_id\x18\x0b \x01(\t\x12\x12\n\nchanged_at\x18\x0c \x01(\x03\"8\n\x15QueryPositionsRequest\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x0e\n\x06symbol\x18\x02 \x01(\t\"W\n\x16QueryPositionsResponse\x12!\n\tpositions\x18\x01 \x03(\x0b\x32\x0e.bbgo.Position\x12\x1a\n\x05\x65rror\x18\x02 \x01(\x0b\x32\x0b.bbgo.Error\"\xdf\x01\n\nMarginInfo\x12\x0f\n\x07session\x18\x01 \x01(\t\x12\x10\n\x08\x65xchange\x18\x02 \x01(\t\x12\x10\n\x08isolated\x18\x03 \x01(\x08\x12\x17\n\x0fisolated_symbol\x18\x04 \x01(\t\x12\x14\n\x0cmargin_level\x18\x05 \x01(\t\x12\x14\n\x0cmargin_ratio\x18\x06 \x01(\t\x12\x19\n\x11liquidation_price\x18\x07 \x01(\t\x12\x1b\n\x13total_account_value\x18\x08 \x01(\t\x12\x1f\n\x08\x62\x61lances\x18\t \x03(\x0b\x32\r.bbgo.Balance\")\n\x16QueryMarginInfoRequest\x12\x0f\n\x07session\x18\x01 \x01(\t\"]\n\x17QueryMarginInfoResponse\x12&\n\x0cmargin_infos\x18\x01 \x03(\x0b\x32\x10.bbgo.MarginInfo\x12\x1a\n\x05\x65rror\x18\x02 \x01(\x0b\x32\x0b.bbgo.Error\"\x8f\x01\n\x08Strategy\x12\n\n\x02id\x18\x01 \x01(\t\x12\x0f\n\x07session\x18\x02 \x01(\t\x12\x10\n\x08strategy\x18\x03 \x01(\t\x12\x13\n\x0binstance_id\x18\x04 \x01(\t\x12\x0e\n\x06status\x18\x05 \x01(\t\x12\x12\n\ntoggleable\x18\x06 \x01(\x08\x12\x1b\n\x13\x65mergency_stoppable\x18\x07 \x01(\x08\"(\n\x15ListStrategiesRequest\x12\x0f\n\x07session\x18\x01 \x01(\t\"X\n\x16ListStrategiesResponse\x12\"\n\nstrategies\x18\x01 \x03(\x0b\x32\x0e.bbgo.Strategy\x12\x1a\n\x05\x65rror\x18\x02 \x01(\x0b\x32\x0b.bbgo.Error\"\x1d\n\x0fStrategyRequest\x12\n\n\x02id\x18\x01 \x01(\t\"P\n\x10StrategyResponse\x12 \n\x08strategy\x18\x01 \x01(\x0b\x32\x0e.bbgo.Strategy\x12\x1a\n\x05\x65rror\x18\x02 \x01(\x0b\x32\x0b.bbgo.Error\"\xe9\x01\n\x06Signal\x12\n\n\x02id\x18\x01 \x01(\t\x12\x10\n\x08strategy\x18\x02 \x01(\t\x12\x0e\n\x06symbol\x18\x03 \x01(\t\x12\x1e\n\x04type\x18\x04 \x01(\x0e\x32\x10.bbgo.SignalType\x12\x17\n\x0ftarget_position\x18\x05 \x01(\t\x12\x10\n\x08quantity\x18\x07 \x01(\t\x12#\n\norder_type\x18\x08 \x01(\x0e\x32\x0f.bbgo.OrderType\x12\r\n\x05price\x18\t \x01(\t\x12\x12\n\ncreated_at\x18\n \x01(\x03\x12\x1e\n\x04side\x18\x0b \x01(\x0e\x32\x10.bbgo.SignalSide\"3\n\x13SubmitSignalRequest\x12\x1c\n\x06signal\x18\x01 \x01(\x0b\x32\x0c.bbgo.Signal\"O\n\x14SubmitSignalResponse\x12\x1b\n\x06orders\x18\x01 \x03(\x0b\x32\x0b.bbgo.Order\x12\x1a\n\x05\x65rror\x18\x02 \x01(\x0b\x32\x0b.bbgo.Error*n\n\x05\x45vent\x12\x0b\n\x07UNKNOWN\x10\x00\x12\x0e\n\nSUBSCRIBED\x10\x01\x12\x10\n\x0cUNSUBSCRIBED\x10\x02\x12\x0c\n\x08SNAPSHOT\x10\x03\x12\n\n\x06UPDATE\x10\x04\x12\x11\n\rAUTHENTICATED\x10\x05\x12\t\n\x05\x45RROR\x10\x63*M\n\x07\x43hannel\x12\x08\n\x04\x42OOK\x10\x00\x12\t\n\x05TRADE\x10\x01\x12\n\n\x06TICKER\x10\x02\x12\t\n\x05KLINE\x10\x03\x12\x0b\n\x07\x42\x41LANCE\x10\x04\x12\t\n\x05ORDER\x10\x05*\x19\n\x04Side\x12\x07\n\x03\x42UY\x10\x00\x12\x08\n\x04SELL\x10\x01*a\n\tOrderType\x12\n\n\x06MARKET\x10\x00\x12\t\n\x05LIMIT\x10\x01\x12\x0f\n\x0bSTOP_MARKET\x10\x02\x12\x0e\n\nSTOP_LIMIT\x10\x03\x12\r\n\tPOST_ONLY\x10\x04\x12\r\n\tIOC_LIMIT\x10\x05*3\n\nSignalType\x12\x13\n\x0fTARGET_POSITION\x10\x00\x12\x10\n\x0cORDER_INTENT\x10\x01*T\n\nSignalSide\x12\x1b\n\x17SIGNAL_SIDE_UNSPECIFIED\x10\x00\x12\x13\n\x0fSIGNAL_SIDE_BUY\x10\x01\x12\x14\n\x10SIGNAL_SIDE_SELL\x10\x02\x32\x94\x01\n\x11MarketDataService\x12\x39\n\tSubscribe\x12\x16.bbgo.SubscribeRequest\x1a\x10.bbgo.MarketData\"\x00\x30\x01\x12\x44\n\x0bQueryKLines\x12\x18.bbgo.QueryKLinesRequest\x1a\x19.bbgo.QueryKLinesResponse\"\x00\x32I\n\x0fUserDataService\x12\x36\n\tSubscribe\x12\x15.bbgo.UserDataRequest\x1a\x0e.bbgo.UserData\"\x00\x30\x01\x32\xeb\x02\n\x0eTradingService\x12\x44\n\x0bSubmitOrder\x12\x18.bbgo.SubmitOrderRequest\x1a\x19.bbgo.SubmitOrderResponse\"\x00\x12\x44\n\x0b\x43\x61ncelOrder\x12\x18.bbgo.CancelOrderRequest\x1a\x19.bbgo.CancelOrderResponse\"\x00\x12\x41\n\nQueryOrder\x12\x17.bbgo.QueryOrderRequest\x1a\x18.bbgo.QueryOrderResponse\"\x00\x12\x44\n\x0bQueryOrders\x12\x18.bbgo.QueryOrdersRequest\x1a\x19.bbgo.QueryOrdersResponse\"\x00\x12\x44\n\x0bQueryTrades\x12\x18.bbgo.QueryTradesRequest\x1a\x19.bbgo.QueryTradesResponse\"\x00\x32\xfd\x01\n\x0e\x41\x63\x63ountService\x12J\n\rQueryBalances\x12\x1a.bbgo.QueryBalancesRequest\x1a\x1b.bbgo.QueryBalancesResponse\"\x00\x12M\n\x0eQueryPositions\x12\x1b.bbgo.QueryPositionsRequest\x1a\x1c.bbgo.QueryPositionsResponse\"\x00\x12P\n\x0fQueryMarginInfo\x12\x1c.bbgo.QueryMarginInfoRequest\x1a\x1d.bbgo.QueryMarginInfoResponse\"\x00\x32\xf9\x02\n\x0fStrategyService\x12M\n\x0eListStrategies\x12\x1b.bbgo.ListStrategiesRequest\x1a\x1c.bbgo.ListStrategiesResponse\"\x00\x12\x46\n\x13QueryStrategyStatus\x12\x15.bbgo.StrategyRequest\x1a\x16.bbgo.StrategyResponse\"\x00\x12\x42\n\x0fSuspendStrategy\x12\x15.bbgo.StrategyRequest\x1a\x16.bbgo.StrategyResponse\"\x00\x12\x41\n\x0eResumeStrategy\x12\x15.bbgo.StrategyRequest\x1a\x16.bbgo.StrategyResponse\"\x00\x12H\n\x15\x45mergencyStopStrategy\x12\x15.bbgo.StrategyRequest\x1a\x16.bbgo.StrategyResponse\"\x00\x32X\n\rSignalService\x12G\n\x0cSubmitSignal\x12\x19.bbgo.SubmitSignalRequest\x1a\x1a.bbgo.SubmitSignalResponse\"\x00\x42\x07Z\x05../pbb\x06proto3')
 
 _EVENT = DESCRIPTOR.enum_types_by_name['Event']
 Event = enum_type_wrapper.EnumTypeWrapper(_EVENT)
@@ -25,6 +25,10 @@ _SIDE = DESCRIPTOR.enum_types_by_name['Side']
 Side = enum_type_wrapper.EnumTypeWrapper(_SIDE)
 _ORDERTYPE = DESCRIPTOR.enum_types_by_name['OrderType']
 OrderType = enum_type_wrapper.EnumTypeWrapper(_ORDERTYPE)
+_SIGNALTYPE = DESCRIPTOR.enum_types_by_name['SignalType']
+SignalType = enum_type_wrapper.EnumTypeWrapper(_SIGNALTYPE)
+_SIGNALSIDE = DESCRIPTOR.enum_types_by_name['SignalSide']
+SignalSide = enum_type_wrapper.EnumTypeWrapper(_SIGNALSIDE)
 UNKNOWN = 0
 SUBSCRIBED = 1
 UNSUBSCRIBED = 2
@@ -46,6 +50,11 @@ STOP_MARKET = 2
 STOP_LIMIT = 3
 POST_ONLY = 4
 IOC_LIMIT = 5
+TARGET_POSITION = 0
+ORDER_INTENT = 1
+SIGNAL_SIDE_UNSPECIFIED = 0
+SIGNAL_SIDE_BUY = 1
+SIGNAL_SIDE_SELL = 2
 
 
 _EMPTY = DESCRIPTOR.message_types_by_name['Empty']
@@ -75,6 +84,22 @@ _QUERYTRADESRESPONSE = DESCRIPTOR.message_types_by_name['QueryTradesResponse']
 _QUERYKLINESREQUEST = DESCRIPTOR.message_types_by_name['QueryKLinesRequest']
 _QUERYKLINESRESPONSE = DESCRIPTOR.message_types_by_name['QueryKLinesResponse']
 _KLINE = DESCRIPTOR.message_types_by_name['KLine']
+_QUERYBALANCESREQUEST = DESCRIPTOR.message_types_by_name['QueryBalancesRequest']
+_QUERYBALANCESRESPONSE = DESCRIPTOR.message_types_by_name['QueryBalancesResponse']
+_POSITION = DESCRIPTOR.message_types_by_name['Position']
+_QUERYPOSITIONSREQUEST = DESCRIPTOR.message_types_by_name['QueryPositionsRequest']
+_QUERYPOSITIONSRESPONSE = DESCRIPTOR.message_types_by_name['QueryPositionsResponse']
+_MARGININFO = DESCRIPTOR.message_types_by_name['MarginInfo']
+_QUERYMARGININFOREQUEST = DESCRIPTOR.message_types_by_name['QueryMarginInfoRequest']
+_QUERYMARGININFORESPONSE = DESCRIPTOR.message_types_by_name['QueryMarginInfoResponse']
+_STRATEGY = DESCRIPTOR.message_types_by_name['Strategy']
+_LISTSTRATEGIESREQUEST = DESCRIPTOR.message_types_by_name['ListStrategiesRequest']
+_LISTSTRATEGIESRESPONSE = DESCRIPTOR.message_types_by_name['ListStrategiesResponse']
+_STRATEGYREQUEST = DESCRIPTOR.message_types_by_name['StrategyRequest']
+_STRATEGYRESPONSE = DESCRIPTOR.message_types_by_name['StrategyResponse']
+_SIGNAL = DESCRIPTOR.message_types_by_name['Signal']
+_SUBMITSIGNALREQUEST = DESCRIPTOR.message_types_by_name['SubmitSignalRequest']
+_SUBMITSIGNALRESPONSE = DESCRIPTOR.message_types_by_name['SubmitSignalResponse']
 Empty = _reflection.GeneratedProtocolMessageType('Empty', (_message.Message,), {
   'DESCRIPTOR' : _EMPTY,
   '__module__' : 'bbgo_pb2'
@@ -264,21 +289,140 @@ KLine = _reflection.GeneratedProtocolMessageType('KLine', (_message.Message,), {
   })
 _sym_db.RegisterMessage(KLine)
 
+QueryBalancesRequest = _reflection.GeneratedProtocolMessageType('QueryBalancesRequest', (_message.Message,), {
+  'DESCRIPTOR' : _QUERYBALANCESREQUEST,
+  '__module__' : 'bbgo_pb2'
+  # @@protoc_insertion_point(class_scope:bbgo.QueryBalancesRequest)
+  })
+_sym_db.RegisterMessage(QueryBalancesRequest)
+
+QueryBalancesResponse = _reflection.GeneratedProtocolMessageType('QueryBalancesResponse', (_message.Message,), {
+  'DESCRIPTOR' : _QUERYBALANCESRESPONSE,
+  '__module__' : 'bbgo_pb2'
+  # @@protoc_insertion_point(class_scope:bbgo.QueryBalancesResponse)
+  })
+_sym_db.RegisterMessage(QueryBalancesResponse)
+
+Position = _reflection.GeneratedProtocolMessageType('Position', (_message.Message,), {
+  'DESCRIPTOR' : _POSITION,
+  '__module__' : 'bbgo_pb2'
+  # @@protoc_insertion_point(class_scope:bbgo.Position)
+  })
+_sym_db.RegisterMessage(Position)
+
+QueryPositionsRequest = _reflection.GeneratedProtocolMessageType('QueryPositionsRequest', (_message.Message,), {
+  'DESCRIPTOR' : _QUERYPOSITIONSREQUEST,
+  '__module__' : 'bbgo_pb2'
+  # @@protoc_insertion_point(class_scope:bbgo.QueryPositionsRequest)
+  })
+_sym_db.RegisterMessage(QueryPositionsRequest)
+
+QueryPositionsResponse = _reflection.GeneratedProtocolMessageType('QueryPositionsResponse', (_message.Message,), {
+  'DESCRIPTOR' : _QUERYPOSITIONSRESPONSE,
+  '__module__' : 'bbgo_pb2'
+  # @@protoc_insertion_point(class_scope:bbgo.QueryPositionsResponse)
+  })
+_sym_db.RegisterMessage(QueryPositionsResponse)
+
+MarginInfo = _reflection.GeneratedProtocolMessageType('MarginInfo', (_message.Message,), {
+  'DESCRIPTOR' : _MARGININFO,
+  '__module__' : 'bbgo_pb2'
+  # @@protoc_insertion_point(class_scope:bbgo.MarginInfo)
+  })
+_sym_db.RegisterMessage(MarginInfo)
+
+QueryMarginInfoRequest = _reflection.GeneratedProtocolMessageType('QueryMarginInfoRequest', (_message.Message,), {
+  'DESCRIPTOR' : _QUERYMARGININFOREQUEST,
+  '__module__' : 'bbgo_pb2'
+  # @@protoc_insertion_point(class_scope:bbgo.QueryMarginInfoRequest)
+  })
+_sym_db.RegisterMessage(QueryMarginInfoRequest)
+
+QueryMarginInfoResponse = _reflection.GeneratedProtocolMessageType('QueryMarginInfoResponse', (_message.Message,), {
+  'DESCRIPTOR' : _QUERYMARGININFORESPONSE,
+  '__module__' : 'bbgo_pb2'
+  # @@protoc_insertion_point(class_scope:bbgo.QueryMarginInfoResponse)
+  })
+_sym_db.RegisterMessage(QueryMarginInfoResponse)
+
+Strategy = _reflection.GeneratedProtocolMessageType('Strategy', (_message.Message,), {
+  'DESCRIPTOR' : _STRATEGY,
+  '__module__' : 'bbgo_pb2'
+  # @@protoc_insertion_point(class_scope:bbgo.Strategy)
+  })
+_sym_db.RegisterMessage(Strategy)
+
+ListStrategiesRequest = _reflection.GeneratedProtocolMessageType('ListStrategiesRequest', (_message.Message,), {
+  'DESCRIPTOR' : _LISTSTRATEGIESREQUEST,
+  '__module__' : 'bbgo_pb2'
+  # @@protoc_insertion_point(class_scope:bbgo.ListStrategiesRequest)
+  })
+_sym_db.RegisterMessage(ListStrategiesRequest)
+
+ListStrategiesResponse = _reflection.GeneratedProtocolMessageType('ListStrategiesResponse', (_message.Message,), {
+  'DESCRIPTOR' : _LISTSTRATEGIESRESPONSE,
+  '__module__' : 'bbgo_pb2'
+  # @@protoc_insertion_point(class_scope:bbgo.ListStrategiesResponse)
+  })
+_sym_db.RegisterMessage(ListStrategiesResponse)
+
+StrategyRequest = _reflection.GeneratedProtocolMessageType('StrategyRequest', (_message.Message,), {
+  'DESCRIPTOR' : _STRATEGYREQUEST,
+  '__module__' : 'bbgo_pb2'
+  # @@protoc_insertion_point(class_scope:bbgo.StrategyRequest)
+  })
+_sym_db.RegisterMessage(StrategyRequest)
+
+StrategyResponse = _reflection.GeneratedProtocolMessageType('StrategyResponse', (_message.Message,), {
+  'DESCRIPTOR' : _STRATEGYRESPONSE,
+  '__module__' : 'bbgo_pb2'
+  # @@protoc_insertion_point(class_scope:bbgo.StrategyResponse)
+  })
+_sym_db.RegisterMessage(StrategyResponse)
+
+Signal = _reflection.GeneratedProtocolMessageType('Signal', (_message.Message,), {
+  'DESCRIPTOR' : _SIGNAL,
+  '__module__' : 'bbgo_pb2'
+  # @@protoc_insertion_point(class_scope:bbgo.Signal)
+  })
+_sym_db.RegisterMessage(Signal)
+
+SubmitSignalRequest = _reflection.GeneratedProtocolMessageType('SubmitSignalRequest', (_message.Message,), {
+  'DESCRIPTOR' : _SUBMITSIGNALREQUEST,
+  '__module__' : 'bbgo_pb2'
+  # @@protoc_insertion_point(class_scope:bbgo.SubmitSignalRequest)
+  })
+_sym_db.RegisterMessage(SubmitSignalRequest)
+
+SubmitSignalResponse = _reflection.GeneratedProtocolMessageType('SubmitSignalResponse', (_message.Message,), {
+  'DESCRIPTOR' : _SUBMITSIGNALRESPONSE,
+  '__module__' : 'bbgo_pb2'
+  # @@protoc_insertion_point(class_scope:bbgo.SubmitSignalResponse)
+  })
+_sym_db.RegisterMessage(SubmitSignalResponse)
+
 _MARKETDATASERVICE = DESCRIPTOR.services_by_name['MarketDataService']
 _USERDATASERVICE = DESCRIPTOR.services_by_name['UserDataService']
 _TRADINGSERVICE = DESCRIPTOR.services_by_name['TradingService']
+_ACCOUNTSERVICE = DESCRIPTOR.services_by_name['AccountService']
+_STRATEGYSERVICE = DESCRIPTOR.services_by_name['StrategyService']
+_SIGNALSERVICE = DESCRIPTOR.services_by_name['SignalService']
 if _descriptor._USE_C_DESCRIPTORS == False:
 
   DESCRIPTOR._options = None
   DESCRIPTOR._serialized_options = b'Z\005../pb'
-  _EVENT._serialized_start=3305
-  _EVENT._serialized_end=3415
-  _CHANNEL._serialized_start=3417
-  _CHANNEL._serialized_end=3494
-  _SIDE._serialized_start=3496
-  _SIDE._serialized_end=3521
-  _ORDERTYPE._serialized_start=3523
-  _ORDERTYPE._serialized_end=3620
+  _EVENT._serialized_start=5004
+  _EVENT._serialized_end=5114
+  _CHANNEL._serialized_start=5116
+  _CHANNEL._serialized_end=5193
+  _SIDE._serialized_start=5195
+  _SIDE._serialized_end=5220
+  _ORDERTYPE._serialized_start=5222
+  _ORDERTYPE._serialized_end=5319
+  _SIGNALTYPE._serialized_start=5321
+  _SIGNALTYPE._serialized_end=5372
+  _SIGNALSIDE._serialized_start=5374
+  _SIGNALSIDE._serialized_end=5458
   _EMPTY._serialized_start=20
   _EMPTY._serialized_end=27
   _ERROR._serialized_start=29
@@ -316,27 +460,65 @@ if _descriptor._USE_C_DESCRIPTORS == False:
   _CANCELORDERRESPONSE._serialized_start=2140
   _CANCELORDERRESPONSE._serialized_end=2217
   _QUERYORDERREQUEST._serialized_start=2219
-  _QUERYORDERREQUEST._serialized_end=2292
-  _QUERYORDERRESPONSE._serialized_start=2294
-  _QUERYORDERRESPONSE._serialized_end=2370
-  _QUERYORDERSREQUEST._serialized_start=2373
-  _QUERYORDERSREQUEST._serialized_end=2542
-  _QUERYORDERSRESPONSE._serialized_start=2544
-  _QUERYORDERSRESPONSE._serialized_end=2622
-  _QUERYTRADESREQUEST._serialized_start=2625
-  _QUERYTRADESREQUEST._serialized_end=2807
-  _QUERYTRADESRESPONSE._serialized_start=2809
-  _QUERYTRADESRESPONSE._serialized_end=2887
-  _QUERYKLINESREQUEST._serialized_start=2889
-  _QUERYKLINESREQUEST._serialized_end=3014
-  _QUERYKLINESRESPONSE._serialized_start=3016
-  _QUERYKLINESRESPONSE._serialized_end=3094
-  _KLINE._serialized_start=3097
-  _KLINE._serialized_end=3303
-  _MARKETDATASERVICE._serialized_start=3623
-  _MARKETDATASERVICE._serialized_end=3771
-  _USERDATASERVICE._serialized_start=3773
-  _USERDATASERVICE._serialized_end=3846
-  _TRADINGSERVICE._serialized_start=3849
-  _TRADINGSERVICE._serialized_end=4212
+  _QUERYORDERREQUEST._serialized_end=2308
+  _QUERYORDERRESPONSE._serialized_start=2310
+  _QUERYORDERRESPONSE._serialized_end=2386
+  _QUERYORDERSREQUEST._serialized_start=2389
+  _QUERYORDERSREQUEST._serialized_end=2584
+  _QUERYORDERSRESPONSE._serialized_start=2586
+  _QUERYORDERSRESPONSE._serialized_end=2664
+  _QUERYTRADESREQUEST._serialized_start=2667
+  _QUERYTRADESREQUEST._serialized_end=2849
+  _QUERYTRADESRESPONSE._serialized_start=2851
+  _QUERYTRADESRESPONSE._serialized_end=2929
+  _QUERYKLINESREQUEST._serialized_start=2931
+  _QUERYKLINESREQUEST._serialized_end=3056
+  _QUERYKLINESRESPONSE._serialized_start=3058
+  _QUERYKLINESRESPONSE._serialized_end=3136
+  _KLINE._serialized_start=3139
+  _KLINE._serialized_end=3345
+  _QUERYBALANCESREQUEST._serialized_start=3347
+  _QUERYBALANCESREQUEST._serialized_end=3386
+  _QUERYBALANCESRESPONSE._serialized_start=3388
+  _QUERYBALANCESRESPONSE._serialized_end=3472
+  _POSITION._serialized_start=3475
+  _POSITION._serialized_end=3730
+  _QUERYPOSITIONSREQUEST._serialized_start=3732
+  _QUERYPOSITIONSREQUEST._serialized_end=3788
+  _QUERYPOSITIONSRESPONSE._serialized_start=3790
+  _QUERYPOSITIONSRESPONSE._serialized_end=3877
+  _MARGININFO._serialized_start=3880
+  _MARGININFO._serialized_end=4103
+  _QUERYMARGININFOREQUEST._serialized_start=4105
+  _QUERYMARGININFOREQUEST._serialized_end=4146
+  _QUERYMARGININFORESPONSE._serialized_start=4148
+  _QUERYMARGININFORESPONSE._serialized_end=4241
+  _STRATEGY._serialized_start=4244
+  _STRATEGY._serialized_end=4387
+  _LISTSTRATEGIESREQUEST._serialized_start=4389
+  _LISTSTRATEGIESREQUEST._serialized_end=4429
+  _LISTSTRATEGIESRESPONSE._serialized_start=4431
+  _LISTSTRATEGIESRESPONSE._serialized_end=4519
+  _STRATEGYREQUEST._serialized_start=4521
+  _STRATEGYREQUEST._serialized_end=4550
+  _STRATEGYRESPONSE._serialized_start=4552
+  _STRATEGYRESPONSE._serialized_end=4632
+  _SIGNAL._serialized_start=4635
+  _SIGNAL._serialized_end=4868
+  _SUBMITSIGNALREQUEST._serialized_start=4870
+  _SUBMITSIGNALREQUEST._serialized_end=4921
+  _SUBMITSIGNALRESPONSE._serialized_start=4923
+  _SUBMITSIGNALRESPONSE._serialized_end=5002
+  _MARKETDATASERVICE._serialized_start=5461
+  _MARKETDATASERVICE._serialized_end=5609
+  _USERDATASERVICE._serialized_start=5611
+  _USERDATASERVICE._serialized_end=5684
+  _TRADINGSERVICE._serialized_start=5687
+  _TRADINGSERVICE._serialized_end=6050
+  _ACCOUNTSERVICE._serialized_start=6053
+  _ACCOUNTSERVICE._serialized_end=6306
+  _STRATEGYSERVICE._serialized_start=6309
+  _STRATEGYSERVICE._serialized_end=6686
+  _SIGNALSERVICE._serialized_start=6688
+  _SIGNALSERVICE._serialized_end=6776
 # @@protoc_insertion_point(module_scope)
@@ -352,3 +352,384 @@ class TradingService(object):
             bbgo__pb2.QueryTradesResponse.FromString,
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+
+class AccountServiceStub(object):
+    """Missing associated documentation comment in .proto file."""
+
+    def __init__(self, channel):
+        """Constructor.
+
+        Args:
+            channel: A grpc.Channel.
+        """
+        self.QueryBalances = channel.unary_unary(
+                '/bbgo.AccountService/QueryBalances',
+                request_serializer=bbgo__pb2.QueryBalancesRequest.SerializeToString,
+                response_deserializer=bbgo__pb2.QueryBalancesResponse.FromString,
+                )
+        self.QueryPositions = channel.unary_unary(
+                '/bbgo.AccountService/QueryPositions',
+                request_serializer=bbgo__pb2.QueryPositionsRequest.SerializeToString,
+                response_deserializer=bbgo__pb2.QueryPositionsResponse.FromString,
+                )
+        self.QueryMarginInfo = channel.unary_unary(
+                '/bbgo.AccountService/QueryMarginInfo',
+                request_serializer=bbgo__pb2.QueryMarginInfoRequest.SerializeToString,
+                response_deserializer=bbgo__pb2.QueryMarginInfoResponse.FromString,
+                )
+
+
+class AccountServiceServicer(object):
+    """Missing associated documentation comment in .proto file."""
+
+    def QueryBalances(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def QueryPositions(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def QueryMarginInfo(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+
+def add_AccountServiceServicer_to_server(servicer, server):
+    rpc_method_handlers = {
+            'QueryBalances': grpc.unary_unary_rpc_method_handler(
+                    servicer.QueryBalances,
+                    request_deserializer=bbgo__pb2.QueryBalancesRequest.FromString,
+                    response_serializer=bbgo__pb2.QueryBalancesResponse.SerializeToString,
+            ),
+            'QueryPositions': grpc.unary_unary_rpc_method_handler(
+                    servicer.QueryPositions,
+                    request_deserializer=bbgo__pb2.QueryPositionsRequest.FromString,
+                    response_serializer=bbgo__pb2.QueryPositionsResponse.SerializeToString,
+            ),
+            'QueryMarginInfo': grpc.unary_unary_rpc_method_handler(
+                    servicer.QueryMarginInfo,
+                    request_deserializer=bbgo__pb2.QueryMarginInfoRequest.FromString,
+                    response_serializer=bbgo__pb2.QueryMarginInfoResponse.SerializeToString,
+            ),
+    }
+    generic_handler = grpc.method_handlers_generic_handler(
+            'bbgo.AccountService', rpc_method_handlers)
+    server.add_generic_rpc_handlers((generic_handler,))
+
+
+ # This class is part of an EXPERIMENTAL API.
+class AccountService(object):
+    """Missing associated documentation comment in .proto file."""
+
+    @staticmethod
+    def QueryBalances(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/bbgo.AccountService/QueryBalances',
+            bbgo__pb2.QueryBalancesRequest.SerializeToString,
+            bbgo__pb2.QueryBalancesResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def QueryPositions(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/bbgo.AccountService/QueryPositions',
+            bbgo__pb2.QueryPositionsRequest.SerializeToString,
+            bbgo__pb2.QueryPositionsResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def QueryMarginInfo(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/bbgo.AccountService/QueryMarginInfo',
+            bbgo__pb2.QueryMarginInfoRequest.SerializeToString,
+            bbgo__pb2.QueryMarginInfoResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+
+class StrategyServiceStub(object):
+    """Missing associated documentation comment in .proto file."""
+
+    def __init__(self, channel):
+        """Constructor.
+
+        Args:
+            channel: A grpc.Channel.
+        """
+        self.ListStrategies = channel.unary_unary(
+                '/bbgo.StrategyService/ListStrategies',
+                request_serializer=bbgo__pb2.ListStrategiesRequest.SerializeToString,
+                response_deserializer=bbgo__pb2.ListStrategiesResponse.FromString,
+                )
+        self.QueryStrategyStatus = channel.unary_unary(
+                '/bbgo.StrategyService/QueryStrategyStatus',
+                request_serializer=bbgo__pb2.StrategyRequest.SerializeToString,
+                response_deserializer=bbgo__pb2.StrategyResponse.FromString,
+                )
+        self.SuspendStrategy = channel.unary_unary(
+                '/bbgo.StrategyService/SuspendStrategy',
+                request_serializer=bbgo__pb2.StrategyRequest.SerializeToString,
+                response_deserializer=bbgo__pb2.StrategyResponse.FromString,
+                )
+        self.ResumeStrategy = channel.unary_unary(
+                '/bbgo.StrategyService/ResumeStrategy',
+                request_serializer=bbgo__pb2.StrategyRequest.SerializeToString,
+                response_deserializer=bbgo__pb2.StrategyResponse.FromString,
+                )
+        self.EmergencyStopStrategy = channel.unary_unary(
+                '/bbgo.StrategyService/EmergencyStopStrategy',
+                request_serializer=bbgo__pb2.StrategyRequest.SerializeToString,
+                response_deserializer=bbgo__pb2.StrategyResponse.FromString,
+                )
+
+
+class StrategyServiceServicer(object):
+    """Missing associated documentation comment in .proto file."""
+
+    def ListStrategies(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def QueryStrategyStatus(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def SuspendStrategy(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ResumeStrategy(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def EmergencyStopStrategy(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+
+def add_StrategyServiceServicer_to_server(servicer, server):
+    rpc_method_handlers = {
+            'ListStrategies': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListStrategies,
+                    request_deserializer=bbgo__pb2.ListStrategiesRequest.FromString,
+                    response_serializer=bbgo__pb2.ListStrategiesResponse.SerializeToString,
+            ),
+            'QueryStrategyStatus': grpc.unary_unary_rpc_method_handler(
+                    servicer.QueryStrategyStatus,
+                    request_deserializer=bbgo__pb2.StrategyRequest.FromString,
+                    response_serializer=bbgo__pb2.StrategyResponse.SerializeToString,
+            ),
+            'SuspendStrategy': grpc.unary_unary_rpc_method_handler(
+                    servicer.SuspendStrategy,
+                    request_deserializer=bbgo__pb2.StrategyRequest.FromString,
+                    response_serializer=bbgo__pb2.StrategyResponse.SerializeToString,
+            ),
+            'ResumeStrategy': grpc.unary_unary_rpc_method_handler(
+                    servicer.ResumeStrategy,
+                    request_deserializer=bbgo__pb2.StrategyRequest.FromString,
+                    response_serializer=bbgo__pb2.StrategyResponse.SerializeToString,
+            ),
+            'EmergencyStopStrategy': grpc.unary_unary_rpc_method_handler(
+                    servicer.EmergencyStopStrategy,
+                    request_deserializer=bbgo__pb2.StrategyRequest.FromString,
+                    response_serializer=bbgo__pb2.StrategyResponse.SerializeToString,
+            ),
+    }
+    generic_handler = grpc.method_handlers_generic_handler(
+            'bbgo.StrategyService', rpc_method_handlers)
+    server.add_generic_rpc_handlers((generic_handler,))
+
+
+ # This class is part of an EXPERIMENTAL API.
+class StrategyService(object):
+    """Missing associated documentation comment in .proto file."""
+
+    @staticmethod
+    def ListStrategies(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/bbgo.StrategyService/ListStrategies',
+            bbgo__pb2.ListStrategiesRequest.SerializeToString,
+            bbgo__pb2.ListStrategiesResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def QueryStrategyStatus(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/bbgo.StrategyService/QueryStrategyStatus',
+            bbgo__pb2.StrategyRequest.SerializeToString,
+            bbgo__pb2.StrategyResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def SuspendStrategy(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/bbgo.StrategyService/SuspendStrategy',
+            bbgo__pb2.StrategyRequest.SerializeToString,
+            bbgo__pb2.StrategyResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ResumeStrategy(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/bbgo.StrategyService/ResumeStrategy',
+            bbgo__pb2.StrategyRequest.SerializeToString,
+            bbgo__pb2.StrategyResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def EmergencyStopStrategy(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/bbgo.StrategyService/EmergencyStopStrategy',
+            bbgo__pb2.StrategyRequest.SerializeToString,
+            bbgo__pb2.StrategyResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+
+class SignalServiceStub(object):
+    """Missing associated documentation comment in .proto file."""
+
+    def __init__(self, channel):
+        """Constructor.
+
+        Args:
+            channel: A grpc.Channel.
+        """
+        self.SubmitSignal = channel.unary_unary(
+                '/bbgo.SignalService/SubmitSignal',
+                request_serializer=bbgo__pb2.SubmitSignalRequest.SerializeToString,
+                response_deserializer=bbgo__pb2.SubmitSignalResponse.FromString,
+                )
+
+
+class SignalServiceServicer(object):
+    """Missing associated documentation comment in .proto file."""
+
+    def SubmitSignal(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+
+def add_SignalServiceServicer_to_server(servicer, server):
+    rpc_method_handlers = {
+            'SubmitSignal': grpc.unary_unary_rpc_method_handler(
+                    servicer.SubmitSignal,
+                    request_deserializer=bbgo__pb2.SubmitSignalRequest.FromString,
+                    response_serializer=bbgo__pb2.SubmitSignalResponse.SerializeToString,
+            ),
+    }
+    generic_handler = grpc.method_handlers_generic_handler(
+            'bbgo.SignalService', rpc_method_handlers)
+    server.add_generic_rpc_handlers((generic_handler,))
+
+
+ # This class is part of an EXPERIMENTAL API.
+class SignalService(object):
+    """Missing associated documentation comment in .proto file."""
+
+    @staticmethod
+    def SubmitSignal(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/bbgo.SignalService/SubmitSignal',
+            bbgo__pb2.SubmitSignalRequest.SerializeToString,
+            bbgo__pb2.SubmitSignalResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
@@ -0,0 +1,22 @@
+import os
+
+import click
+
+from bbgo import SignalService
+
+
+@click.command()
+@click.option('--host', default='127.0.0.1')
+@click.option('--port', default=50051)
+@click.option('--symbol', default='BTCUSDT')
+@click.option('--target-position', default='0.01')
+def main(host, port, symbol, target_position):
+    service = SignalService(host, port, auth_token=os.environ.get('EXTERNAL_SIGNAL_AUTH_TOKEN', ''))
+
+    orders = service.submit_target_position(symbol=symbol, target_position=target_position)
+    for order in orders:
+        print(order)
+
+
+if __name__ == '__main__':
+    main()